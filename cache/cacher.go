@@ -36,6 +36,9 @@ var (
 	log        = logger.GetOrCreate("cacheLog")
 )
 
+// invalidationChannel carries the keys every instance should drop from its local cache.
+const invalidationChannel = "CacheInvalidation"
+
 func InitCacher(cfg config.CacheConfig) {
 	initOnce.Do(func() {
 
@@ -106,6 +109,8 @@ func InitCacher(cfg config.CacheConfig) {
 			redis: redisdb,
 			bolt:  boltDb,
 		}
+
+		go cacher.listenInvalidations()
 	})
 }
 
@@ -139,6 +144,28 @@ func (c *Cacher) Get(k string, v interface{}) error {
 	return err
 }
 
+func (c *Cacher) Delete(k string) error {
+	return c.cache.Delete(c.ctx, k)
+}
+
+// DeleteEverywhere removes the key from redis and from the local cache of every instance,
+// for values that must not be served stale after a change.
+func (c *Cacher) DeleteEverywhere(k string) error {
+	err := c.Delete(k)
+	if err != nil {
+		return err
+	}
+
+	return c.redis.Publish(c.ctx, invalidationChannel, k).Err()
+}
+
+func (c *Cacher) listenInvalidations() {
+	pubsub := c.redis.Subscribe(c.ctx, invalidationChannel)
+	for msg := range pubsub.Channel() {
+		c.cache.DeleteFromLocalCache(msg.Payload)
+	}
+}
+
 func (c *Cacher) GetStats() Stats {
 	return cacher.stats
 }
@@ -0,0 +1,77 @@
+package cdn
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	incomingDir    = "incoming/"
+	uploadIdLength = 16
+)
+
+var (
+	ErrDirectUploadNotSupported = errors.New("uploader does not support direct uploads")
+	ErrInvalidUploadKey         = errors.New("invalid upload key")
+	ErrUnsupportedContentType   = errors.New("unsupported image content type")
+	ErrUploadTooLarge           = errors.New("upload exceeds max size")
+
+	directUploadContentTypes = map[string]bool{
+		"image/png":  true,
+		"image/jpeg": true,
+		"image/gif":  true,
+		"image/webp": true,
+	}
+
+	uploadIdPattern = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+// PresignedUpload is where a client sends an upload directly, with the headers to send along.
+// Key is handed back to complete the upload once it is stored.
+type PresignedUpload struct {
+	Key       string            `json:"key"`
+	Url       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+	MaxBytes  int64             `json:"maxBytes"`
+}
+
+// DirectUploader lets clients upload to the store without going through the api.
+// Uploads land under an incoming key and are only processed into variants by CompleteUpload.
+type DirectUploader interface {
+	PresignUpload(ctx context.Context, imgID string, contentType string, maxBytes int64) (*PresignedUpload, error)
+	CompleteUpload(ctx context.Context, key string, imgID string, maxBytes int64) (*ImageVariants, error)
+}
+
+func IsDirectUploadContentType(contentType string) bool {
+	return directUploadContentTypes[contentType]
+}
+
+func newIncomingKey(prefix string, imgID string) (string, error) {
+	id := make([]byte, uploadIdLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return incomingPrefix(prefix, imgID) + hex.EncodeToString(id), nil
+}
+
+// validateIncomingKey checks a key handed back by a client was presigned for imgID.
+func validateIncomingKey(prefix string, imgID string, key string) error {
+	uploadId := strings.TrimPrefix(key, incomingPrefix(prefix, imgID))
+	if uploadId == key || len(uploadId) != hex.EncodedLen(uploadIdLength) || !uploadIdPattern.MatchString(uploadId) {
+		return ErrInvalidUploadKey
+	}
+
+	return nil
+}
+
+func incomingPrefix(prefix string, imgID string) string {
+	return prefix + incomingDir + imgID + "/"
+}
@@ -18,6 +18,7 @@ var (
 const (
 	local     = "local"
 	cloudyCDN = "cloudy"
+	s3CDN     = "s3"
 )
 
 func InitUploader(cfg config.CDNConfig) {
@@ -39,12 +40,29 @@ func GetImageUploaderOrErr() (ImageUploader, error) {
 	return imgUploader, nil
 }
 
+// GetDirectUploaderOrErr returns the uploader when it can hand out direct upload urls.
+func GetDirectUploaderOrErr() (DirectUploader, error) {
+	upl, err := GetImageUploaderOrErr()
+	if err != nil {
+		return nil, err
+	}
+
+	direct, ok := upl.(DirectUploader)
+	if !ok {
+		return nil, ErrDirectUploadNotSupported
+	}
+
+	return direct, nil
+}
+
 func makeUploader(cfg config.CDNConfig) (ImageUploader, error) {
 	switch cfg.Selector {
 	case local:
 		return NewLocalUploader(cfg), nil
 	case cloudyCDN:
 		return NewCloudyUploader(cfg)
+	case s3CDN:
+		return NewS3Uploader(cfg)
 	default:
 		return nil, errors.New("unknown selector provided")
 	}
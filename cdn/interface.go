@@ -2,7 +2,10 @@ package cdn
 
 import "context"
 
+// ImageUploader stores uploads processed into variants. UploadBase64 returns the url of the full size
+// in the original format, UploadVariants every variant.
 type ImageUploader interface {
 	UploadBase64(ctx context.Context, b64Img, imgID string) (string, error)
+	UploadVariants(ctx context.Context, b64Img, imgID string) (*ImageVariants, error)
 	GetImage(fileName string) ([]byte, string, error)
 }
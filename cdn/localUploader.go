@@ -1,22 +1,19 @@
 package cdn
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"image"
-	"image/gif"
-	"image/jpeg"
-	"image/png"
-	"io"
 	"io/ioutil"
-	"os"
+	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 )
 
+const imageContentTypePrefix = "image/"
+
 var (
 	UnknownImgTypeErr = errors.New("unknown image type")
 )
@@ -33,57 +30,38 @@ func NewLocalUploader(cfg config.CDNConfig) *localUploader {
 	}
 }
 
-func (lu *localUploader) UploadBase64(_ context.Context, b64Img, imgID string) (string, error) {
-	imgBytes, err := Base64ToBytes(b64Img)
-	if err != nil {
-		return "", err
-	}
-
-	filePath := filepath.Join(lu.rootDir, imgID)
-	err = ioutil.WriteFile(filePath, imgBytes, 0644)
+func (lu *localUploader) UploadBase64(ctx context.Context, b64Img, imgID string) (string, error) {
+	variants, err := lu.UploadVariants(ctx, b64Img, imgID)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s%s", lu.baseUrl, imgID), nil
+	return variants.Url, nil
 }
 
-func (lu *localUploader) GetImage(fileName string) ([]byte, string, error) {
-	filePath := filepath.Join(lu.rootDir, fileName)
-	f, err := os.Open(filePath)
-	if err != nil {
-		return nil, "", err
-	}
-	defer func(f *os.File) {
-		closeErr := f.Close()
-		if closeErr != nil {
-			log.Warn("GetImage - failed to close file", "err", err.Error())
+func (lu *localUploader) UploadVariants(_ context.Context, b64Img, imgID string) (*ImageVariants, error) {
+	return uploadVariants(b64Img, imgID, func(key string, _ string, data []byte) (string, error) {
+		err := ioutil.WriteFile(filepath.Join(lu.rootDir, key), data, 0644)
+		if err != nil {
+			return "", err
 		}
-	}(f)
 
-	img, imgType, err := image.Decode(f)
-	if err != nil {
-		return nil, "", err
-	}
+		return fmt.Sprintf("%s%s", lu.baseUrl, key), nil
+	})
+}
 
-	buf := new(bytes.Buffer)
-	err = lu.encodeImageByType(imgType, img, buf)
+// GetImage serves the stored bytes as they are, variants are already encoded.
+func (lu *localUploader) GetImage(fileName string) ([]byte, string, error) {
+	filePath := filepath.Join(lu.rootDir, filepath.Base(fileName))
+	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return nil, "", err
 	}
 
-	return buf.Bytes(), imgType, nil
-}
-
-func (lu *localUploader) encodeImageByType(imgType string, img image.Image, w io.Writer) error {
-	switch imgType {
-	case "jpeg", "jpg":
-		return jpeg.Encode(w, img, nil)
-	case "png":
-		return png.Encode(w, img)
-	case "gif":
-		return gif.Encode(w, img, nil)
-	default:
-		return UnknownImgTypeErr
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, imageContentTypePrefix) {
+		return nil, "", UnknownImgTypeErr
 	}
+
+	return data, strings.TrimPrefix(contentType, imageContentTypePrefix), nil
 }
@@ -1,7 +1,9 @@
 package cdn
 
 import (
+	"bytes"
 	"encoding/base64"
+	"image/png"
 	"strings"
 	"testing"
 
@@ -9,24 +11,56 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var testUploader = NewLocalUploader(config.CDNConfig{
-	BaseUrl: "something://",
-	RootDir: "",
-})
-
 func TestLocalUploader_UploadBase64(t *testing.T) {
 	t.Parallel()
 
-	url, err := testUploader.UploadBase64(nil, imgStr, "testLocal")
+	uploader := NewLocalUploader(config.CDNConfig{BaseUrl: "something://", RootDir: t.TempDir()})
+
+	url, err := uploader.UploadBase64(nil, imgStr, "testLocal")
 	require.Nil(t, err)
 	require.True(t, strings.Contains(url, "testLocal"))
+	require.True(t, strings.HasSuffix(url, "-full.png"))
+}
+
+func TestLocalUploader_UploadVariants(t *testing.T) {
+	t.Parallel()
+
+	uploader := NewLocalUploader(config.CDNConfig{BaseUrl: "something://", RootDir: t.TempDir()})
+
+	variants, err := uploader.UploadVariants(nil, imgStr, "testLocal")
+	require.Nil(t, err)
+	require.Equal(t, "png", variants.Format)
+	require.Len(t, variants.Variants, 6)
+
+	for _, variant := range variants.Variants {
+		fileName := strings.TrimPrefix(variant.Url, "something://")
+		require.True(t, IsContentHashed(fileName))
+
+		imgBytes, imgType, err := uploader.GetImage(fileName)
+		require.Nil(t, err)
+		require.Equal(t, variant.Format, imgType)
+		require.NotEmpty(t, imgBytes)
+	}
 }
 
 func TestLocalUploader_GetImage(t *testing.T) {
 	t.Parallel()
 
-	imgBytes, imgType, err := testUploader.GetImage("testLocal")
+	uploader := NewLocalUploader(config.CDNConfig{BaseUrl: "something://", RootDir: t.TempDir()})
+	url, err := uploader.UploadBase64(nil, imgStr, "testLocal")
+	require.Nil(t, err)
+
+	imgBytes, imgType, err := uploader.GetImage(strings.TrimPrefix(url, "something://"))
 	require.Nil(t, err)
 	require.True(t, imgType == "png")
-	require.Equal(t, base64.StdEncoding.EncodeToString(imgBytes), stripB64Str(imgStr))
+
+	// the upload is re-encoded, so only the image itself is kept
+	original, err := base64.StdEncoding.DecodeString(stripB64Str(imgStr))
+	require.Nil(t, err)
+	originalConfig, err := png.DecodeConfig(bytes.NewReader(original))
+	require.Nil(t, err)
+	imgConfig, err := png.DecodeConfig(bytes.NewReader(imgBytes))
+	require.Nil(t, err)
+	require.Equal(t, originalConfig.Width, imgConfig.Width)
+	require.Equal(t, originalConfig.Height, imgConfig.Height)
 }
@@ -0,0 +1,131 @@
+package cdn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const (
+	jpegSOI            = 0xD8
+	jpegSOS            = 0xDA
+	jpegAPP1           = 0xE1
+	exifOrientation    = 0x0112
+	defaultOrientation = 1
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// jpegOrientation reads the EXIF orientation of a jpeg, 1 when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegSOI {
+		return defaultOrientation
+	}
+
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return defaultOrientation
+		}
+		marker := data[offset+1]
+		if marker == jpegSOS {
+			return defaultOrientation
+		}
+
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return defaultOrientation
+		}
+
+		segment := data[offset+4 : end]
+		if marker == jpegAPP1 && bytes.HasPrefix(segment, exifHeader) {
+			return tiffOrientation(segment[len(exifHeader):])
+		}
+		offset = end
+	}
+
+	return defaultOrientation
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return defaultOrientation
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return defaultOrientation
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return defaultOrientation
+	}
+
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for index := 0; index < entries; index++ {
+		entry := ifd + 2 + index*12
+		if entry+12 > len(tiff) {
+			return defaultOrientation
+		}
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientation {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return defaultOrientation
+			}
+			return orientation
+		}
+	}
+
+	return defaultOrientation
+}
+
+// applyOrientation turns the image upright, orientations 5 to 8 swap width and height.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= defaultOrientation || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,270 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	defaultS3Region          = "us-east-1"
+	defaultPresignExpirySecs = 900
+
+	s3Timeout = time.Second * 50
+)
+
+// s3Uploader stores variants in any S3 compatible store, AWS, MinIO or R2.
+type s3Uploader struct {
+	client        *s3.S3
+	bucketName    string
+	prefix        string
+	publicUrl     string
+	presignExpiry time.Duration
+}
+
+func NewS3Uploader(cfg config.CDNConfig) (*s3Uploader, error) {
+	if cfg.BucketName == "" {
+		return nil, fmt.Errorf("s3 bucket name is required")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	awsCfg := aws.NewConfig().
+		WithRegion(region).
+		WithS3ForcePathStyle(cfg.UsePathStyle)
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.AccessKey != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""))
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	publicUrl, err := s3PublicUrl(cfg, region)
+	if err != nil {
+		return nil, err
+	}
+
+	expirySecs := cfg.PresignExpirySecs
+	if expirySecs == 0 {
+		expirySecs = defaultPresignExpirySecs
+	}
+
+	return &s3Uploader{
+		client:        s3.New(sess),
+		bucketName:    cfg.BucketName,
+		prefix:        cfg.UploadPath,
+		publicUrl:     publicUrl,
+		presignExpiry: time.Duration(expirySecs) * time.Second,
+	}, nil
+}
+
+// s3PublicUrl is the url objects are served from, keys are appended to it.
+// BaseUrl wins when set, for a CDN or a public domain in front of the bucket.
+func s3PublicUrl(cfg config.CDNConfig, region string) (string, error) {
+	if cfg.BaseUrl != "" {
+		return strings.TrimSuffix(cfg.BaseUrl, "/") + "/", nil
+	}
+
+	if cfg.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", cfg.BucketName, region), nil
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return "", fmt.Errorf("invalid s3 endpoint %s", cfg.Endpoint)
+	}
+
+	if cfg.UsePathStyle {
+		return fmt.Sprintf("%s://%s/%s/", endpoint.Scheme, endpoint.Host, cfg.BucketName), nil
+	}
+
+	return fmt.Sprintf("%s://%s.%s/", endpoint.Scheme, cfg.BucketName, endpoint.Host), nil
+}
+
+func (su *s3Uploader) UploadBase64(ctx context.Context, b64Img, imgID string) (string, error) {
+	variants, err := su.UploadVariants(ctx, b64Img, imgID)
+	if err != nil {
+		return "", err
+	}
+
+	return variants.Url, nil
+}
+
+func (su *s3Uploader) UploadVariants(ctx context.Context, b64Img, imgID string) (*ImageVariants, error) {
+	ctx, cancel := context.WithTimeout(ctx, s3Timeout)
+	defer cancel()
+
+	return uploadVariants(b64Img, imgID, su.store(ctx))
+}
+
+func (su *s3Uploader) store(ctx context.Context) variantStore {
+	return func(key string, contentType string, data []byte) (string, error) {
+		_, err := su.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket:       aws.String(su.bucketName),
+			Key:          aws.String(su.prefix + key),
+			Body:         bytes.NewReader(data),
+			ContentType:  aws.String(contentType),
+			CacheControl: aws.String(ImmutableCacheControl),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return su.publicUrl + su.prefix + key, nil
+	}
+}
+
+func (su *s3Uploader) GetImage(fileName string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	output, err := su.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(su.bucketName),
+		Key:    aws.String(su.prefix + filepath.Base(fileName)),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer output.Body.Close()
+
+	data, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := aws.StringValue(output.ContentType)
+	if !strings.HasPrefix(contentType, imageContentTypePrefix) {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, imageContentTypePrefix) {
+		return nil, "", UnknownImgTypeErr
+	}
+
+	return data, strings.TrimPrefix(contentType, imageContentTypePrefix), nil
+}
+
+// PresignUpload signs a PUT of an incoming object. A presigned PUT cannot bound its size,
+// so maxBytes is enforced by CompleteUpload.
+func (su *s3Uploader) PresignUpload(_ context.Context, imgID string, contentType string, maxBytes int64) (*PresignedUpload, error) {
+	if !IsDirectUploadContentType(contentType) {
+		return nil, ErrUnsupportedContentType
+	}
+
+	key, err := newIncomingKey(su.prefix, imgID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := su.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(su.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	signedUrl, err := req.Presign(su.presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedUpload{
+		Key:       key,
+		Url:       signedUrl,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: time.Now().Add(su.presignExpiry).UTC(),
+		MaxBytes:  maxBytes,
+	}, nil
+}
+
+// CompleteUpload processes an incoming object into variants, then removes it.
+// Incoming objects that are never completed are left to a lifecycle rule on the incoming prefix.
+func (su *s3Uploader) CompleteUpload(ctx context.Context, key string, imgID string, maxBytes int64) (*ImageVariants, error) {
+	err := validateIncomingKey(su.prefix, imgID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s3Timeout)
+	defer cancel()
+
+	head, err := su.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(su.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s3NotFoundAs(err, ErrInvalidUploadKey)
+	}
+	if aws.Int64Value(head.ContentLength) > maxBytes {
+		su.deleteIncoming(ctx, key)
+		return nil, ErrUploadTooLarge
+	}
+
+	output, err := su.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(su.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s3NotFoundAs(err, ErrInvalidUploadKey)
+	}
+	defer output.Body.Close()
+
+	// the object may have been replaced since the head request
+	data, err := ioutil.ReadAll(io.LimitReader(output.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		su.deleteIncoming(ctx, key)
+		return nil, ErrUploadTooLarge
+	}
+
+	variants, err := uploadVariantBytes(data, imgID, su.store(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	su.deleteIncoming(ctx, key)
+	return variants, nil
+}
+
+func (su *s3Uploader) deleteIncoming(ctx context.Context, key string) {
+	_, err := su.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(su.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Warn("could not delete incoming upload", "key", key, "err", err.Error())
+	}
+}
+
+func s3NotFoundAs(err error, notFound error) error {
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+		return notFound
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3PublicUrl(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      config.CDNConfig
+		expected string
+	}{
+		{"aws", config.CDNConfig{BucketName: "pics"}, "https://pics.s3.eu-west-1.amazonaws.com/"},
+		{"path style", config.CDNConfig{BucketName: "pics", Endpoint: "http://localhost:9000", UsePathStyle: true}, "http://localhost:9000/pics/"},
+		{"virtual host", config.CDNConfig{BucketName: "pics", Endpoint: "https://r2.example.com"}, "https://pics.r2.example.com/"},
+		{"base url", config.CDNConfig{BucketName: "pics", Endpoint: "https://r2.example.com", BaseUrl: "https://cdn.youbei.io"}, "https://cdn.youbei.io/"},
+	}
+
+	for _, test := range tests {
+		publicUrl, err := s3PublicUrl(test.cfg, "eu-west-1")
+		require.Nil(t, err, test.name)
+		require.Equal(t, test.expected, publicUrl, test.name)
+	}
+
+	_, err := s3PublicUrl(config.CDNConfig{BucketName: "pics", Endpoint: "localhost"}, "eu-west-1")
+	require.NotNil(t, err)
+}
+
+func TestValidateIncomingKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := newIncomingKey("images/", "erd1.profile")
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(key, "images/incoming/erd1.profile/"))
+	require.Nil(t, validateIncomingKey("images/", "erd1.profile", key))
+
+	require.Equal(t, ErrInvalidUploadKey, validateIncomingKey("images/", "erd1.cover", key))
+	require.Equal(t, ErrInvalidUploadKey, validateIncomingKey("", "erd1.profile", key))
+	require.Equal(t, ErrInvalidUploadKey, validateIncomingKey("images/", "erd1.profile", key+"/../x"))
+	require.Equal(t, ErrInvalidUploadKey, validateIncomingKey("images/", "erd1.profile", "images/incoming/erd1.profile/"))
+	require.Equal(t, ErrInvalidUploadKey, validateIncomingKey("images/", "erd1.profile", "images/erd1.profile-full.png"))
+}
+
+func TestS3Uploader_PresignUnsupportedContentType(t *testing.T) {
+	t.Parallel()
+
+	uploader, err := NewS3Uploader(config.CDNConfig{BucketName: "pics", Endpoint: "http://localhost:9000", UsePathStyle: true})
+	require.Nil(t, err)
+
+	_, err = uploader.PresignUpload(context.Background(), "erd1.profile", "image/svg+xml", 1024)
+	require.Equal(t, ErrUnsupportedContentType, err)
+}
+
+// TestS3Uploader_DirectUpload runs against a real store, a local MinIO for instance:
+// S3_TEST_ENDPOINT=http://localhost:9000 S3_TEST_BUCKET=youbei S3_TEST_ACCESS_KEY=minioadmin S3_TEST_SECRET_KEY=minioadmin
+func TestS3Uploader_DirectUpload(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	uploader, err := NewS3Uploader(config.CDNConfig{
+		BucketName:   os.Getenv("S3_TEST_BUCKET"),
+		UploadPath:   "test/",
+		Endpoint:     endpoint,
+		AccessKey:    os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey:    os.Getenv("S3_TEST_SECRET_KEY"),
+		UsePathStyle: true,
+	})
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	data, err := Base64ToBytes(imgStr)
+	require.Nil(t, err)
+
+	presigned, err := uploader.PresignUpload(ctx, "testS3", "image/png", int64(len(data)))
+	require.Nil(t, err)
+	require.Equal(t, http.MethodPut, presigned.Method)
+
+	req, err := http.NewRequest(presigned.Method, presigned.Url, bytes.NewReader(data))
+	require.Nil(t, err)
+	for header, value := range presigned.Headers {
+		req.Header.Set(header, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = uploader.CompleteUpload(ctx, presigned.Key, "testS3", int64(len(data))-1)
+	require.Equal(t, ErrUploadTooLarge, err)
+
+	presigned, err = uploader.PresignUpload(ctx, "testS3", "image/png", int64(len(data)))
+	require.Nil(t, err)
+	req, err = http.NewRequest(presigned.Method, presigned.Url, bytes.NewReader(data))
+	require.Nil(t, err)
+	for header, value := range presigned.Headers {
+		req.Header.Set(header, value)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+
+	variants, err := uploader.CompleteUpload(ctx, presigned.Key, "testS3", int64(len(data)))
+	require.Nil(t, err)
+	require.Len(t, variants.Variants, 6)
+	require.True(t, strings.HasSuffix(variants.Url, "-full.png"))
+
+	img, imgType, err := uploader.GetImage(variants.Url[strings.LastIndex(variants.Url, "/")+1:])
+	require.Nil(t, err)
+	require.Equal(t, "png", imgType)
+	require.NotEmpty(t, img)
+
+	_, err = uploader.CompleteUpload(ctx, presigned.Key, "testS3", int64(len(data)))
+	require.Equal(t, ErrInvalidUploadKey, err)
+}
@@ -1,6 +1,7 @@
 package cdn
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -41,34 +42,32 @@ func NewCloudyUploader(cfg config.CDNConfig) (*cloudyUploader, error) {
 }
 
 func (cu *cloudyUploader) UploadBase64(ctx context.Context, b64Img, imgID string) (string, error) {
-	buf, err := Base64ToReader(b64Img)
+	variants, err := cu.UploadVariants(ctx, b64Img, imgID)
 	if err != nil {
 		return "", err
 	}
 
+	return variants.Url, nil
+}
+
+func (cu *cloudyUploader) UploadVariants(ctx context.Context, b64Img, imgID string) (*ImageVariants, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
 	defer cancel()
-	// Upload an object with storage.Writer.
-	wc := cu.cl.Bucket(cu.bucketName).Object(cu.uploadPath + imgID).NewWriter(ctx)
-	if _, err := io.Copy(wc, buf); err != nil {
-		return "", fmt.Errorf("io.Copy: %v", err)
-	}
-	if err := wc.Close(); err != nil {
-		return "", fmt.Errorf("Writer.Close: %v", err)
-	}
 
-	// res, err := cu.cloudy.Upload.Upload(ctx, buf, uploader.UploadParams{
-	// 	PublicID:  imgID,
-	// 	Overwrite: true,
-	// })
-	// if err != nil {
-	// 	return "", err
-	// }
-	// if res.Error.Message != "" {
-	// 	return "", fmt.Errorf("%s", res.Error.Message)
-	// }
+	return uploadVariants(b64Img, imgID, func(key string, contentType string, data []byte) (string, error) {
+		// Upload an object with storage.Writer.
+		wc := cu.cl.Bucket(cu.bucketName).Object(cu.uploadPath + key).NewWriter(ctx)
+		wc.ContentType = contentType
+		wc.CacheControl = ImmutableCacheControl
+		if _, err := io.Copy(wc, bytes.NewReader(data)); err != nil {
+			return "", fmt.Errorf("io.Copy: %v", err)
+		}
+		if err := wc.Close(); err != nil {
+			return "", fmt.Errorf("Writer.Close: %v", err)
+		}
 
-	return fmt.Sprintf("https://storage.googleapis.com/%s/%s%s", cu.bucketName, cu.uploadPath, imgID), nil
+		return fmt.Sprintf("https://storage.googleapis.com/%s/%s%s", cu.bucketName, cu.uploadPath, key), nil
+	})
 }
 
 func (cu *cloudyUploader) GetImage(_ string) ([]byte, string, error) {
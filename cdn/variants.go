@@ -0,0 +1,251 @@
+package cdn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+)
+
+type Variant string
+
+const (
+	VariantThumbnail Variant = "thumb"
+	VariantCard      Variant = "card"
+	VariantFull      Variant = "full"
+
+	FormatWebp = "webp"
+
+	// ImmutableCacheControl is served for variant keys, MutableCacheControl for files stored before variants.
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+	MutableCacheControl   = "public, max-age=3600"
+
+	// MaxImageDimension and MaxImagePixels bound what is decoded, checked on the header before decoding.
+	MaxImageDimension = 8192
+	MaxImagePixels    = 40000000
+	MinImageDimension = 16
+
+	contentHashLength = 16
+	webpQuality       = 80
+	jpegQuality       = 85
+
+	variantKeyFormat = "%s-%s-%s.%s"
+)
+
+var (
+	ErrImageTooLarge = fmt.Errorf("image exceeds %dx%d or %d pixels", MaxImageDimension, MaxImageDimension, MaxImagePixels)
+	ErrImageTooSmall = fmt.Errorf("image is smaller than %dx%d", MinImageDimension, MinImageDimension)
+
+	// variantSizes is the longest side of each variant, images are never upscaled.
+	variantSizes = []struct {
+		variant Variant
+		maxSide int
+	}{
+		{VariantThumbnail, 256},
+		{VariantCard, 640},
+		{VariantFull, 2048},
+	}
+
+	contentHashedKey = regexp.MustCompile(fmt.Sprintf(`-[0-9a-f]{%d}-(%s|%s|%s)\.[a-z]+$`, contentHashLength, VariantThumbnail, VariantCard, VariantFull))
+)
+
+// ImageVariant is one stored rendition of an upload.
+type ImageVariant struct {
+	Variant Variant `json:"variant"`
+	Format  string  `json:"format"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	Url     string  `json:"url"`
+}
+
+// ImageVariants are the renditions of an upload. Url is the full size in the original format.
+// Keys hold the hash of the upload, so a stored file never changes and can be cached forever.
+type ImageVariants struct {
+	Hash     string         `json:"hash"`
+	Format   string         `json:"format"`
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	Url      string         `json:"url"`
+	Variants []ImageVariant `json:"variants"`
+}
+
+type variantFile struct {
+	ImageVariant
+	key         string
+	contentType string
+	data        []byte
+}
+
+// variantStore writes a processed file and returns its public url.
+type variantStore func(key string, contentType string, data []byte) (string, error)
+
+// IsContentHashed tells whether a file name is a variant key, whose content never changes.
+func IsContentHashed(fileName string) bool {
+	return contentHashedKey.MatchString(fileName)
+}
+
+// VariantKey is the file name of a variant of the upload imgID with the given hash.
+func VariantKey(imgID string, hash string, variant Variant, format string) string {
+	return fmt.Sprintf(variantKeyFormat, imgID, hash, variant, format)
+}
+
+func uploadVariants(b64Img string, imgID string, store variantStore) (*ImageVariants, error) {
+	imgBytes, err := Base64ToBytes(b64Img)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadVariantBytes(imgBytes, imgID, store)
+}
+
+func uploadVariantBytes(imgBytes []byte, imgID string, store variantStore) (*ImageVariants, error) {
+	files, variants, err := processImage(imgBytes, imgID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		url, err := store(file.key, file.contentType, file.data)
+		if err != nil {
+			return nil, err
+		}
+
+		file.Url = url
+		variants.Variants = append(variants.Variants, file.ImageVariant)
+		if file.Variant == VariantFull && file.Format == variants.Format {
+			variants.Url = url
+		}
+	}
+
+	return variants, nil
+}
+
+// processImage validates the dimensions of an upload, applies its orientation and renders every variant
+// in WebP and in its original format. Re-encoding drops EXIF and every other metadata.
+func processImage(data []byte, imgID string) ([]variantFile, *ImageVariants, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, UnknownImgTypeErr
+	}
+	err = validateDimensions(config.Width, config.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	hash := sha256.Sum256(data)
+	variants := &ImageVariants{
+		Hash:   hex.EncodeToString(hash[:])[:contentHashLength],
+		Format: format,
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	formats := []string{FormatWebp}
+	if format != FormatWebp {
+		formats = append(formats, format)
+	}
+
+	var files []variantFile
+	for _, size := range variantSizes {
+		resized := resizeToFit(img, size.maxSide)
+		for _, outFormat := range formats {
+			encoded, err := encodeImage(outFormat, resized)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			files = append(files, variantFile{
+				ImageVariant: ImageVariant{
+					Variant: size.variant,
+					Format:  outFormat,
+					Width:   resized.Bounds().Dx(),
+					Height:  resized.Bounds().Dy(),
+				},
+				key:         VariantKey(imgID, variants.Hash, size.variant, outFormat),
+				contentType: "image/" + outFormat,
+				data:        encoded,
+			})
+		}
+	}
+
+	return files, variants, nil
+}
+
+func validateDimensions(width int, height int) error {
+	if width < MinImageDimension || height < MinImageDimension {
+		return ErrImageTooSmall
+	}
+	if width > MaxImageDimension || height > MaxImageDimension || width*height > MaxImagePixels {
+		return ErrImageTooLarge
+	}
+
+	return nil
+}
+
+func resizeToFit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	if width >= height {
+		height = maxInt(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = maxInt(1, width*maxSide/height)
+		height = maxSide
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Over, nil)
+
+	return resized
+}
+
+func encodeImage(format string, img image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	var err error
+	switch format {
+	case FormatWebp:
+		err = webp.Encode(buf, img, &webp.Options{Quality: webpQuality})
+	case "jpeg":
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(buf, img)
+	case "gif":
+		err = gif.Encode(buf, img, nil)
+	default:
+		err = UnknownImgTypeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,108 @@
+package cdn
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestPng(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, png.Encode(buf, img))
+
+	return buf.Bytes()
+}
+
+func TestProcessImage_Variants(t *testing.T) {
+	t.Parallel()
+
+	files, variants, err := processImage(encodeTestPng(t, 3000, 1500), "img")
+	require.Nil(t, err)
+	require.Equal(t, 3000, variants.Width)
+	require.Len(t, files, 6)
+
+	sizes := map[Variant]int{}
+	for _, file := range files {
+		require.Equal(t, VariantKey("img", variants.Hash, file.Variant, file.Format), file.key)
+		require.Equal(t, file.Width/2, file.Height)
+		sizes[file.Variant] = file.Width
+
+		decoded, format, err := image.Decode(bytes.NewReader(file.data))
+		require.Nil(t, err)
+		require.Equal(t, file.Format, format)
+		require.Equal(t, file.Width, decoded.Bounds().Dx())
+	}
+	require.Equal(t, 256, sizes[VariantThumbnail])
+	require.Equal(t, 640, sizes[VariantCard])
+	require.Equal(t, 2048, sizes[VariantFull])
+}
+
+func TestProcessImage_Dimensions(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := processImage(encodeTestPng(t, 8, 100), "img")
+	require.Equal(t, ErrImageTooSmall, err)
+
+	_, _, err = processImage(encodeTestPng(t, MaxImageDimension+1, 16), "img")
+	require.Equal(t, ErrImageTooLarge, err)
+
+	_, _, err = processImage([]byte("not an image"), "img")
+	require.Equal(t, UnknownImgTypeErr, err)
+}
+
+func TestProcessImage_StripsExifAndAppliesOrientation(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	buf := new(bytes.Buffer)
+	require.Nil(t, jpeg.Encode(buf, img, nil))
+	withExif := insertExifOrientation(buf.Bytes(), 6)
+	require.Equal(t, 6, jpegOrientation(withExif))
+
+	files, variants, err := processImage(withExif, "img")
+	require.Nil(t, err)
+	require.Equal(t, 20, variants.Width)
+	require.Equal(t, 40, variants.Height)
+
+	for _, file := range files {
+		require.False(t, bytes.Contains(file.data, exifHeader))
+		if file.Format == "jpeg" {
+			require.Equal(t, defaultOrientation, jpegOrientation(file.data))
+		}
+	}
+}
+
+func TestIsContentHashed(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, IsContentHashed("erd1abc.profile-0123456789abcdef-thumb.webp"))
+	require.False(t, IsContentHashed("erd1abc.profile"))
+	require.False(t, IsContentHashed("erd1abc.profile-0123456789abcdef-huge.webp"))
+}
+
+// insertExifOrientation adds a big endian APP1 segment with only the orientation tag after the SOI marker.
+func insertExifOrientation(jpegData []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	length := len(payload) + 2
+
+	segment := []byte{0xFF, jpegAPP1, byte(length >> 8), byte(length)}
+	segment = append(segment, payload...)
+
+	return append(append(append([]byte{}, jpegData[:2]...), segment...), jpegData[2:]...)
+}
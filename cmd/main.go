@@ -10,13 +10,16 @@ import (
 
 	"github.com/ENFT-DAO/youbei-api/stats/aggregator"
 	"github.com/ENFT-DAO/youbei-api/stats/gatherer"
+	"github.com/ENFT-DAO/youbei-api/stats/trending"
 
 	"github.com/ENFT-DAO/youbei-api/cache"
 	"github.com/ENFT-DAO/youbei-api/cdn"
 	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
 	"github.com/ENFT-DAO/youbei-api/interaction"
 	"github.com/ENFT-DAO/youbei-api/logging"
 	"github.com/ENFT-DAO/youbei-api/proxy"
+	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/ElrondNetwork/elrond-go-core/core/check"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
@@ -119,6 +122,8 @@ func startProxy(ctx *cli.Context) error {
 	}
 
 	establishConnections(cfg)
+	trending.Init(cfg.Trending)
+	services.InitDrafts(cfg.Drafts)
 
 	api, err := proxy.NewWebServer(cfg)
 	if err != nil {
@@ -158,6 +163,7 @@ func establishConnections(cfg *config.GeneralConfig) {
 	cache.InitCacher(cfg.Cache)
 	storage.Connect(cfg.Database)
 	cdn.InitUploader(cfg.CDN)
+	fulfillment.InitProvider(cfg.Fulfillment, cfg.ExternalCredential)
 }
 
 func initLogger(ctx *cli.Context) (logging.FileLogger, error) {
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/ENFT-DAO/youbei-api/cache"
 	"github.com/ENFT-DAO/youbei-api/cdn"
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/interaction"
 	"github.com/ENFT-DAO/youbei-api/stats/aggregator"
+	"github.com/ENFT-DAO/youbei-api/stats/trending"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/urfave/cli"
 	"os"
@@ -24,8 +29,20 @@ var (
 		Usage: "This flag specifies the directory where the proxy will store logs.",
 		Value: "",
 	}
+
+	backfillFrom = cli.StringFlag{
+		Name:  "from",
+		Usage: "First day to aggregate, as YYYY-MM-DD (UTC).",
+	}
+
+	backfillTo = cli.StringFlag{
+		Name:  "to",
+		Usage: "Last day to aggregate, as YYYY-MM-DD (UTC). Defaults to today.",
+	}
 )
 
+const backfillDateLayout = "2006-01-02"
+
 func main() {
 	app := cli.NewApp()
 
@@ -35,6 +52,18 @@ func main() {
 		generalConfigFile,
 		workingDirectory,
 	}
+	app.Commands = []cli.Command{
+		{
+			Name:   "backfill",
+			Usage:  "Rebuilds the hourly buckets and their rollups for a date range, then exits",
+			Action: backfill,
+			Flags: []cli.Flag{
+				generalConfigFile,
+				backfillFrom,
+				backfillTo,
+			},
+		},
+	}
 
 	err := app.Run(os.Args)
 	if err != nil {
@@ -58,6 +87,7 @@ func startProxy(ctx *cli.Context) error {
 	}
 
 	establishConnections(cfg)
+	trending.Init(cfg.Trending)
 
 	agg := aggregator.GetManager()
 	if agg != nil {
@@ -69,6 +99,37 @@ func startProxy(ctx *cli.Context) error {
 	return nil
 }
 
+func backfill(ctx *cli.Context) error {
+	from, err := time.Parse(backfillDateLayout, ctx.String(backfillFrom.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --from date: %w", err)
+	}
+
+	to := time.Now().UTC()
+	if ctx.IsSet(backfillTo.Name) {
+		to, err = time.Parse(backfillDateLayout, ctx.String(backfillTo.Name))
+		if err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+	}
+	// the last day is included
+	to = to.Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+	if !to.After(from) {
+		return errors.New("--to must not be before --from")
+	}
+
+	cfg, err := config.LoadConfig(ctx.String(generalConfigFile.Name))
+	if err != nil {
+		return err
+	}
+
+	establishConnections(cfg)
+	defer cache.CloseCacher()
+
+	return aggregator.Backfill(from, to)
+}
+
 func establishConnections(cfg *config.GeneralConfig) {
 	interaction.InitBlockchainInteractor(cfg.Blockchain)
 	cache.InitCacher(cfg.Cache)
@@ -77,7 +138,7 @@ func establishConnections(cfg *config.GeneralConfig) {
 }
 
 func waitForGracefulShutdown() {
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, os.Kill)
 	<-quit
 
@@ -19,6 +19,10 @@ type GeneralConfig struct {
 	ExternalCredential ExternalCredentialConfig
 	Proxy              ProxyConfig
 	CarbonSetting      CarbonSettingConfig
+	Trending           TrendingConfig
+	Drafts             DraftsConfig
+	Print              PrintConfig
+	Fulfillment        FulfillmentConfig
 }
 
 type ConnectorApiConfig struct {
@@ -67,6 +71,7 @@ type BlockchainConfig struct {
 	WithdrawFromMinterGasLimit           uint64
 	RequestWithdrawThroughMinterGasLimit uint64
 	UpdateSaleStartGasLimit              uint64
+	SetWhitelistMerkleRootGasLimit       uint64
 	NoFeeOnMintContracts                 []string
 }
 
@@ -85,10 +90,12 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	JwtSecret     string
-	JwtIssuer     string
-	JwtKeySeedHex string
-	JwtExpiryMins int
+	JwtSecret           string
+	JwtIssuer           string
+	JwtExpiryMins       int
+	RefreshExpiryHours  int
+	LoginOrigins        []string
+	ChallengeExpirySecs int
 }
 
 type CacheConfig struct {
@@ -121,6 +128,14 @@ type CDNConfig struct {
 	Selector string
 	BaseUrl  string
 	RootDir  string
+	// S3 selector, any S3 compatible store. BucketName and UploadPath are shared with cloudy,
+	// BaseUrl overrides the public url of the objects.
+	Endpoint          string
+	Region            string
+	AccessKey         string
+	SecretKey         string
+	UsePathStyle      bool
+	PresignExpirySecs uint64
 }
 type ProxyConfig struct {
 	List []string
@@ -129,10 +144,42 @@ type ExternalCredentialConfig struct {
 	DreamshipAPIKey string
 }
 
+// FulfillmentConfig selects the print provider, dreamship or mock, how long its requests may take
+// and the secret authenticating its webhooks.
+type FulfillmentConfig struct {
+	Selector      string
+	ApiUrl        string
+	TimeoutSecs   uint64
+	WebhookSecret string
+}
+
+// PrintConfig sets where print orders are paid, how long an EGLD quote is locked
+// and the share of the printed items cost owed to the collection creators, 0 disabling it.
+type PrintConfig struct {
+	PaymentAddress        string
+	QuoteExpirySecs       uint64
+	CreatorRoyaltyPercent float64
+}
+
 type CarbonSettingConfig struct {
 	StaticAddress string
 }
 
+type DraftsConfig struct {
+	ExpiryHours      uint64
+	SweepMinutes     uint64
+	MaxDraftsPerType uint64
+}
+
+type TrendingConfig struct {
+	WindowHours           uint64
+	HalfLifeHours         float64
+	SalesVelocityWeight   float64
+	UniqueBuyersWeight    float64
+	FloorMovementWeight   float64
+	ListingActivityWeight float64
+}
+
 func (d DatabaseConfig) Url() string {
 	format := "host=%s port=%d user=%s password=%s dbname=%s sslmode=%s"
 	return fmt.Sprintf(format, d.Host, d.Port, d.User, d.Password, d.DbName, d.SslMode)
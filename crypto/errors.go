@@ -11,3 +11,7 @@ var ErrInvalidPublicKey = errors.New("invalid public key")
 var ErrInvalidPrivateKey = errors.New("invalid private key")
 
 var ErrInvalidSignature = errors.New("invalid signature")
+
+var ErrEmptyMerkleTree = errors.New("merkle tree has no leaves")
+
+var ErrMerkleLeafNotFound = errors.New("merkle leaf not found")
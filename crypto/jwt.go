@@ -7,7 +7,10 @@ import (
 )
 
 type JwtClaims struct {
-	Address string
+	Address         string
+	SessionId       uint64            `json:"sid,omitempty"`
+	Role            string            `json:"role,omitempty"`
+	CollectionRoles map[uint64]string `json:"croles,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -16,12 +19,15 @@ var isExpired = func(claims JwtClaims) bool {
 }
 
 func GenerateJwt(address, secret, issuer string, minsToExpiration int) (string, error) {
-	claims := JwtClaims{
-		Address: address,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().UTC().Add(time.Minute * time.Duration(minsToExpiration)).Unix(),
-			Issuer:    issuer,
-		},
+	return GenerateSessionJwt(JwtClaims{Address: address}, secret, issuer, minsToExpiration)
+}
+
+// GenerateSessionJwt generates a jwt with the session and role claims already set, the standard claims are filled here.
+// Binding the jwt to a refresh session lets revoking the session revoke the jwt.
+func GenerateSessionJwt(claims JwtClaims, secret, issuer string, minsToExpiration int) (string, error) {
+	claims.StandardClaims = jwt.StandardClaims{
+		ExpiresAt: time.Now().UTC().Add(time.Minute * time.Duration(minsToExpiration)).Unix(),
+		Issuer:    issuer,
 	}
 	payload := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
 
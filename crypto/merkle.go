@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ElrondNetwork/elrond-go-core/hashing/keccak"
+)
+
+// MerkleTree is a keccak tree where every pair is hashed in sorted order, so proofs
+// don't need to carry left/right positions. An odd node is carried up unchanged.
+type MerkleTree struct {
+	levels [][][]byte
+}
+
+// ComputeWhitelistLeaf hashes the allocation of an address the way the minter contract does: keccak(address || amount as u64 big endian).
+func ComputeWhitelistLeaf(address []byte, amount uint64) []byte {
+	amountBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(amountBytes, amount)
+
+	payload := make([]byte, 0, len(address)+len(amountBytes))
+	payload = append(payload, address...)
+	payload = append(payload, amountBytes...)
+
+	return keccak.NewKeccak().Compute(string(payload))
+}
+
+func NewMerkleTree(leaves [][]byte) (*MerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrEmptyMerkleTree
+	}
+
+	levels := [][][]byte{leaves}
+	for current := leaves; len(current) > 1; {
+		next := make([][]byte, 0, (len(current)+1)/2)
+		for index := 0; index < len(current); index += 2 {
+			if index+1 == len(current) {
+				next = append(next, current[index])
+				continue
+			}
+			next = append(next, hashPair(current[index], current[index+1]))
+		}
+
+		levels = append(levels, next)
+		current = next
+	}
+
+	return &MerkleTree{levels: levels}, nil
+}
+
+func (tree *MerkleTree) Root() []byte {
+	return tree.levels[len(tree.levels)-1][0]
+}
+
+// Proof returns the sibling hashes from the leaf at index up to the root.
+func (tree *MerkleTree) Proof(index int) ([][]byte, error) {
+	if index < 0 || index >= len(tree.levels[0]) {
+		return nil, ErrMerkleLeafNotFound
+	}
+
+	proof := make([][]byte, 0, len(tree.levels)-1)
+	for _, level := range tree.levels[:len(tree.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+func VerifyMerkleProof(root []byte, leaf []byte, proof [][]byte) bool {
+	computed := leaf
+	for _, sibling := range proof {
+		computed = hashPair(computed, sibling)
+	}
+
+	return bytes.Equal(computed, root)
+}
+
+func hashPair(left []byte, right []byte) []byte {
+	if bytes.Compare(left, right) > 0 {
+		left, right = right, left
+	}
+
+	payload := make([]byte, 0, len(left)+len(right))
+	payload = append(payload, left...)
+	payload = append(payload, right...)
+
+	return keccak.NewKeccak().Compute(string(payload))
+}
@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleTree_ProofsShouldVerifyForEveryLeaf(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []int{1, 2, 3, 5, 8} {
+		leaves := make([][]byte, count)
+		for index := range leaves {
+			leaves[index] = ComputeWhitelistLeaf(bytes.Repeat([]byte{byte(index + 1)}, 32), uint64(index+1))
+		}
+
+		tree, err := NewMerkleTree(leaves)
+		require.Nil(t, err)
+
+		for index, leaf := range leaves {
+			proof, err := tree.Proof(index)
+			require.Nil(t, err)
+			require.True(t, VerifyMerkleProof(tree.Root(), leaf, proof))
+		}
+	}
+}
+
+func TestMerkleTree_ShouldRejectWrongAmount(t *testing.T) {
+	t.Parallel()
+
+	address := bytes.Repeat([]byte{1}, 32)
+	leaves := [][]byte{
+		ComputeWhitelistLeaf(address, 2),
+		ComputeWhitelistLeaf(bytes.Repeat([]byte{2}, 32), 1),
+		ComputeWhitelistLeaf(bytes.Repeat([]byte{3}, 32), 1),
+	}
+
+	tree, err := NewMerkleTree(leaves)
+	require.Nil(t, err)
+
+	proof, err := tree.Proof(0)
+	require.Nil(t, err)
+	require.False(t, VerifyMerkleProof(tree.Root(), ComputeWhitelistLeaf(address, 5), proof))
+
+	_, err = tree.Proof(3)
+	require.Equal(t, ErrMerkleLeafNotFound, err)
+}
+
+func TestMerkleTree_NoLeaves(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewMerkleTree(nil)
+	require.Equal(t, ErrEmptyMerkleTree, err)
+}
@@ -30,6 +30,28 @@ type AttributeStat struct {
 	Total     uint64      `json:"total"`
 }
 
+type AttributeAnalytics struct {
+	TraitType        string      `json:"trait_type"`
+	Value            interface{} `json:"value"`
+	Supply           uint64      `json:"supply"`
+	SupplyPercentage float64     `json:"supplyPercentage"`
+	Listed           uint64      `json:"listed"`
+	FloorPrice       float64     `json:"floorPrice"`
+	LastSalePrice    float64     `json:"lastSalePrice"`
+	Volume7d         float64     `json:"volume7d"`
+}
+
+type CollectionAttributeAnalytics struct {
+	ItemsTotal uint64               `json:"itemsTotal"`
+	Attributes []AttributeAnalytics `json:"attributes"`
+}
+
+type FloorPricePoint struct {
+	Hour        int64   `json:"hour"`
+	FloorPrice  float64 `json:"floorPrice"`
+	ListedCount uint64  `json:"listedCount"`
+}
+
 type MetadataLinkResponse struct {
 	Name       string      `json:"name"`
 	Image      string      `json:"image"`
@@ -42,6 +64,18 @@ type ExtendedCollectionDto struct {
 
 	CreatorName          string `json:"creatorName"`
 	CreatorWalletAddress string `json:"creatorWalletAddress"`
+
+	DuplicateWarning *CollectionDuplicateWarning `json:"duplicateWarning"`
+}
+
+// CollectionDuplicateWarning tells a collection copies the images of a verified collection.
+// Status is open while moderators have not reviewed it, confirmed after.
+type CollectionDuplicateWarning struct {
+	Status                   string  `json:"status"`
+	MatchedCollectionTokenId string  `json:"matchedCollectionTokenId"`
+	MatchedCollectionName    string  `json:"matchedCollectionName"`
+	Score                    float64 `json:"score"`
+	Similarity               float64 `json:"similarity"`
 }
 
 type CollectionCacheInfo struct {
@@ -0,0 +1,13 @@
+package entities
+
+// AttributeAggregate is one trait value of a collection as aggregated by the database.
+// Value holds the raw json text of the trait value, so numbers and strings stay distinct.
+type AttributeAggregate struct {
+	TraitType     string  `json:"traitType"`
+	Value         string  `json:"value"`
+	Supply        uint64  `json:"supply"`
+	Listed        uint64  `json:"listed"`
+	FloorPrice    float64 `json:"floorPrice"`
+	LastSalePrice float64 `json:"lastSalePrice"`
+	Volume        float64 `json:"volume"`
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "gorm.io/datatypes"
+
+// AuditLog records a privileged or creator action. Rows are only ever appended.
+// Diff maps each changed field to its before and after values.
+type AuditLog struct {
+	ID         uint64         `gorm:"primaryKey" json:"id"`
+	Actor      string         `json:"actor" gorm:"index"`
+	Action     string         `json:"action" gorm:"index"`
+	TargetType string         `json:"targetType" gorm:"index:idx_audit_log_target"`
+	TargetId   string         `json:"targetId" gorm:"index:idx_audit_log_target"`
+	Diff       datatypes.JSON `json:"diff"`
+	RequestId  string         `json:"requestId" gorm:"index"`
+	CreatedAt  int64          `json:"createdAt" gorm:"index"`
+}
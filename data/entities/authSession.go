@@ -0,0 +1,15 @@
+package entities
+
+// AuthSession is a refresh session of one device. Only the hash of the current refresh token is kept,
+// every refresh rotates it and presenting an older token revokes the session.
+type AuthSession struct {
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	Address     string `json:"address" gorm:"index"`
+	RefreshHash string `json:"-" gorm:"index"`
+	UserAgent   string `json:"userAgent"`
+	Ip          string `json:"ip"`
+	CreatedAt   int64  `json:"createdAt"`
+	LastUsedAt  int64  `json:"lastUsedAt"`
+	ExpiresAt   int64  `json:"expiresAt"`
+	RevokedAt   int64  `json:"revokedAt"`
+}
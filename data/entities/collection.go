@@ -23,6 +23,8 @@ type Collection struct {
 	Flags                    datatypes.JSON `json:"flags"`
 	ProfileImageLink         string         `json:"profileImageLink"`
 	CoverImageLink           string         `json:"coverImageLink"`
+	ProfileImageVariants     datatypes.JSON `json:"profileImageVariants"`
+	CoverImageVariants       datatypes.JSON `json:"coverImageVariants"`
 	IsVerified               bool           `json:"isVerified"`
 	IsStakeable              bool           `json:"isStakeable" gorm:"default:false"`
 	Type                     uint64         `json:"type"`
@@ -32,6 +34,8 @@ type Collection struct {
 	MintStartDate            uint64         `json:"mintStartDate"`
 	MintEndDate              uint64         `json:"mintEndDate"`
 	CreatorID                uint64         `json:"creatorId"`
+	IsHidden                 bool           `json:"isHidden" gorm:"default:false"`
+	IsDelisted               bool           `json:"isDelisted" gorm:"default:false"`
 	//`gorm:"type:bool;default:false"`
 
 	//AccountName              string `json:"accountName"`
@@ -0,0 +1,20 @@
+package entities
+
+type CollectionRoleType string
+
+const (
+	CollectionRoleOwner            CollectionRoleType = "owner"
+	CollectionRoleEditor           CollectionRoleType = "editor"
+	CollectionRoleWhitelistManager CollectionRoleType = "whitelistManager"
+)
+
+// CollectionRole lets a creator share the management of a collection. The creator is always an owner
+// and has no row here.
+type CollectionRole struct {
+	ID           uint64             `gorm:"primaryKey" json:"id"`
+	CollectionID uint64             `json:"collectionId" gorm:"uniqueIndex:idx_collection_role_address"`
+	Address      string             `json:"address" gorm:"uniqueIndex:idx_collection_role_address;index"`
+	Role         CollectionRoleType `json:"role"`
+	GrantedBy    string             `json:"grantedBy"`
+	CreatedAt    int64              `json:"createdAt"`
+}
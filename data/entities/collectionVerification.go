@@ -0,0 +1,54 @@
+package entities
+
+import "gorm.io/datatypes"
+
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+type VerificationCheck string
+
+const (
+	VerificationCheckPassed  VerificationCheck = "passed"
+	VerificationCheckFailed  VerificationCheck = "failed"
+	VerificationCheckUnknown VerificationCheck = "unknown"
+)
+
+// CollectionVerification is the application of a creator to get a collection verified.
+// SignerAddress signed the verification message, proving it is ContractAddress or owns that contract.
+// The automated checks are run on application and can be run again by reviewers: OwnershipCheck compares
+// the signer with the owner of the contract on chain, the duplicate fields copy the worst open or confirmed
+// duplicate flag of the collection. Reasons is the list of reasons given with the decision.
+type CollectionVerification struct {
+	ID                         uint64              `gorm:"primaryKey" json:"id"`
+	CollectionID               uint64              `gorm:"index;not null" json:"collectionId"`
+	Collection                 Collection          `gorm:"foreignKey:CollectionID" json:"collection"`
+	ApplicantAddress           string              `gorm:"index;not null" json:"applicantAddress"`
+	Website                    string              `json:"website"`
+	TwitterLink                string              `json:"twitterLink"`
+	DiscordLink                string              `json:"discordLink"`
+	TelegramLink               string              `json:"telegramLink"`
+	InstagramLink              string              `json:"instagramLink"`
+	TeamContact                string              `json:"teamContact"`
+	Evidence                   string              `json:"evidence"`
+	ContractAddress            string              `json:"contractAddress"`
+	SignerAddress              string              `json:"signerAddress"`
+	Signature                  string              `json:"signature"`
+	ContractOwner              string              `json:"contractOwner"`
+	OwnershipCheck             VerificationCheck   `json:"ownershipCheck"`
+	DuplicateScore             float64             `json:"duplicateScore"`
+	DuplicateMatchedCollection string              `json:"duplicateMatchedCollection"`
+	DuplicateFlagStatus        DuplicateFlagStatus `json:"duplicateFlagStatus"`
+	ChecksRunAt                int64               `json:"checksRunAt"`
+	Status                     VerificationStatus  `gorm:"index;not null" json:"status"`
+	Reasons                    datatypes.JSON      `json:"reasons"`
+	ReviewNote                 string              `json:"reviewNote"`
+	ReviewedBy                 string              `json:"reviewedBy"`
+	ReviewedAt                 int64               `json:"reviewedAt"`
+	CreatedAt                  int64               `json:"createdAt"`
+	UpdatedAt                  int64               `json:"updatedAt"`
+}
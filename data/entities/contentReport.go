@@ -0,0 +1,70 @@
+package entities
+
+type ReportTargetType string
+
+const (
+	ReportTargetToken      ReportTargetType = "token"
+	ReportTargetCollection ReportTargetType = "collection"
+	ReportTargetAccount    ReportTargetType = "account"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonScam      ReportReason = "scam"
+	ReportReasonOffensive ReportReason = "offensive"
+	ReportReasonCopyright ReportReason = "copyright"
+	ReportReasonSpam      ReportReason = "spam"
+	ReportReasonOther     ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportActioned  ReportStatus = "actioned"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+type ModerationAction string
+
+const (
+	ModerationHide    ModerationAction = "hide"
+	ModerationUnhide  ModerationAction = "unhide"
+	ModerationDelist  ModerationAction = "delist"
+	ModerationRelist  ModerationAction = "relist"
+	ModerationBan     ModerationAction = "ban"
+	ModerationUnban   ModerationAction = "unban"
+	ModerationDismiss ModerationAction = "dismiss"
+)
+
+// ContentReport is a user report of a token, collection or account.
+// TargetID is the collection token id of tokens and collections, and the address of accounts.
+// TargetNonce is only set for tokens. Reports stay open until a moderator acts on their target.
+type ContentReport struct {
+	ID              uint64           `gorm:"primaryKey" json:"id"`
+	TargetType      ReportTargetType `gorm:"index:content_report_target;not null" json:"targetType"`
+	TargetID        string           `gorm:"index:content_report_target;not null" json:"targetId"`
+	TargetNonce     uint64           `gorm:"index:content_report_target" json:"targetNonce"`
+	Reason          ReportReason     `gorm:"not null" json:"reason"`
+	Details         string           `json:"details"`
+	ReporterAddress string           `gorm:"index;not null" json:"reporterAddress"`
+	Status          ReportStatus     `gorm:"index;not null" json:"status"`
+	Action          ModerationAction `json:"action"`
+	ResolvedBy      string           `json:"resolvedBy"`
+	ResolutionNote  string           `json:"resolutionNote"`
+	ResolvedAt      int64            `json:"resolvedAt"`
+	CreatedAt       int64            `json:"createdAt"`
+}
+
+// ReportedTarget is a target with open reports, as listed in the moderation queue.
+// Reasons is the comma separated list of the distinct reasons it was reported for.
+type ReportedTarget struct {
+	TargetType      ReportTargetType `json:"targetType"`
+	TargetID        string           `json:"targetId"`
+	TargetNonce     uint64           `json:"targetNonce"`
+	Reports         int64            `json:"reports"`
+	Reasons         string           `json:"reasons"`
+	FirstReportedAt int64            `json:"firstReportedAt"`
+	LastReportedAt  int64            `json:"lastReportedAt"`
+}
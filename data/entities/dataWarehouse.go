@@ -16,10 +16,54 @@ type AggregatedVolumePerCollectionPerHour struct {
 	BuyVolume      float64 `json:"buyVolume"`
 	ListVolume     float64 `json:"listVolume"`
 	WithdrawVolume float64 `json:"withdrawVolume"`
+	BuyCount       uint64  `json:"buyCount"`
 }
 
 type GroupAggregatedVolumePerCollection struct {
 	Total        float64 `json:"total"`
+	Count        uint64  `json:"count"`
 	CollectionId uint64  `json:"collectionId"`
 	Type         string  `json:"type"`
 }
+
+type CollectionFloorPricePerHour struct {
+	ID           uint64  `gorm:"primaryKey" json:"id"`
+	Hour         int64   `json:"hour" gorm:"uniqueIndex:uidx_collection_floor_price_per_hour"`
+	CollectionId uint64  `json:"collectionId" gorm:"uniqueIndex:uidx_collection_floor_price_per_hour"`
+	FloorPrice   float64 `json:"floorPrice"`
+	ListedCount  uint64  `json:"listedCount"`
+}
+
+type CollectionOwnersPerHour struct {
+	ID           uint64 `gorm:"primaryKey" json:"id"`
+	Hour         int64  `json:"hour" gorm:"uniqueIndex:uidx_collection_owners_per_hour"`
+	CollectionId uint64 `json:"collectionId" gorm:"uniqueIndex:uidx_collection_owners_per_hour"`
+	OwnersTotal  uint64 `json:"ownersTotal"`
+}
+
+type CollectionWindowVolume struct {
+	CollectionId uint64  `json:"collectionId"`
+	Volume       float64 `json:"volume"`
+	SalesCount   uint64  `json:"salesCount"`
+}
+
+type RollupPeriod string
+
+const (
+	RollupDay   RollupPeriod = "day"
+	RollupWeek  RollupPeriod = "week"
+	RollupMonth RollupPeriod = "month"
+)
+
+// AggregatedVolumeRollup sums the hourly collection buckets over a day, a week or a month.
+// PeriodKey is YYYYMMDD for days, the YYYYMMDD of the monday for weeks and YYYYMM for months.
+type AggregatedVolumeRollup struct {
+	ID             uint64       `gorm:"primaryKey" json:"id"`
+	Period         RollupPeriod `json:"period" gorm:"uniqueIndex:uidx_aggregated_volume_rollup"`
+	PeriodKey      int64        `json:"periodKey" gorm:"uniqueIndex:uidx_aggregated_volume_rollup"`
+	CollectionId   uint64       `json:"collectionId" gorm:"uniqueIndex:uidx_aggregated_volume_rollup"`
+	BuyVolume      float64      `json:"buyVolume"`
+	ListVolume     float64      `json:"listVolume"`
+	WithdrawVolume float64      `json:"withdrawVolume"`
+	BuyCount       uint64       `json:"buyCount"`
+}
@@ -0,0 +1,25 @@
+package entities
+
+import "gorm.io/datatypes"
+
+type DraftType string
+
+const (
+	DraftCreateCollection DraftType = "createCollection"
+	DraftBulkListing      DraftType = "bulkListing"
+	DraftProfileEdit      DraftType = "profileEdit"
+)
+
+// Draft is a typed, versioned replacement of SessionState. A user can keep several drafts of the same type;
+// every update must carry the version it was based on and bumps it.
+type Draft struct {
+	ID        uint64         `gorm:"primaryKey" json:"id"`
+	Address   string         `json:"address" gorm:"index"`
+	Type      DraftType      `json:"type"`
+	Name      string         `json:"name"`
+	Version   uint64         `json:"version"`
+	JsonData  datatypes.JSON `json:"jsonData"`
+	ExpiresAt int64          `json:"expiresAt" gorm:"index"`
+	CreatedAt int64          `json:"createdAt"`
+	UpdatedAt int64          `json:"updatedAt"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+// TokenImageHash holds the perceptual hashes of the media of a token. The uint64 hashes are stored
+// as their signed bit pattern, postgres has no unsigned bigint.
+// Media that could not be fetched or decoded is recorded as failed. Media that cannot be decoded is only retried
+// once its link changes, fetch errors are retried from RetryAt on, until RetryAt is left at zero after the last attempt.
+type TokenImageHash struct {
+	ID           uint64 `gorm:"primaryKey" json:"id"`
+	TokenID      uint64 `gorm:"uniqueIndex;not null" json:"tokenId"`
+	CollectionID uint64 `gorm:"index;not null" json:"collectionId"`
+	ImageLink    string `json:"imageLink"`
+	PHash        int64  `json:"pHash"`
+	DHash        int64  `json:"dHash"`
+	Failed       bool   `json:"failed" gorm:"default:false"`
+	Error        string `json:"error"`
+	Attempts     uint64 `json:"attempts" gorm:"default:0"`
+	RetryAt      int64  `json:"retryAt"`
+	CreatedAt    int64  `json:"createdAt"`
+	UpdatedAt    int64  `json:"updatedAt"`
+}
+
+type DuplicateFlagStatus string
+
+const (
+	DuplicateFlagOpen      DuplicateFlagStatus = "open"
+	DuplicateFlagConfirmed DuplicateFlagStatus = "confirmed"
+	DuplicateFlagDismissed DuplicateFlagStatus = "dismissed"
+)
+
+// CollectionDuplicateFlag is a collection whose images largely match a verified collection, waiting for
+// or past moderation. Score is the share of its hashed tokens matching a token of the verified collection,
+// Similarity the average similarity of those matches.
+type CollectionDuplicateFlag struct {
+	ID                  uint64              `gorm:"primaryKey" json:"id"`
+	CollectionID        uint64              `gorm:"uniqueIndex:duplicate_flag_pair;not null" json:"collectionId"`
+	Collection          Collection          `gorm:"foreignKey:CollectionID" json:"collection"`
+	MatchedCollectionID uint64              `gorm:"uniqueIndex:duplicate_flag_pair;not null" json:"matchedCollectionId"`
+	MatchedCollection   Collection          `gorm:"foreignKey:MatchedCollectionID" json:"matchedCollection"`
+	ComparedTokens      uint64              `json:"comparedTokens"`
+	MatchedTokens       uint64              `json:"matchedTokens"`
+	Score               float64             `json:"score"`
+	Similarity          float64             `json:"similarity"`
+	Status              DuplicateFlagStatus `gorm:"index;not null" json:"status"`
+	ReviewedBy          string              `json:"reviewedBy"`
+	ReviewNote          string              `json:"reviewNote"`
+	ReviewedAt          int64               `json:"reviewedAt"`
+	CreatedAt           int64               `json:"createdAt"`
+	UpdatedAt           int64               `json:"updatedAt"`
+}
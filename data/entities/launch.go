@@ -0,0 +1,63 @@
+package entities
+
+type LaunchStatus string
+
+const (
+	LaunchInProgress LaunchStatus = "inProgress"
+	LaunchCompleted  LaunchStatus = "completed"
+	LaunchAbandoned  LaunchStatus = "abandoned"
+)
+
+type LaunchStepStatus string
+
+const (
+	LaunchStepPending   LaunchStepStatus = "pending"
+	LaunchStepSubmitted LaunchStepStatus = "submitted"
+	LaunchStepCompleted LaunchStepStatus = "completed"
+	LaunchStepFailed    LaunchStepStatus = "failed"
+)
+
+// CollectionLaunch holds everything needed to issue, deploy and register a collection,
+// plus what the chain returned along the way (token id, minter contract address).
+type CollectionLaunch struct {
+	ID               uint64                 `gorm:"primaryKey" json:"id"`
+	Address          string                 `json:"address" gorm:"index"`
+	Status           LaunchStatus           `json:"status"`
+	CurrentStep      string                 `json:"currentStep"`
+	CollectionName   string                 `json:"collectionName"`
+	Description      string                 `json:"description"`
+	TokenName        string                 `json:"tokenName"`
+	TokenTicker      string                 `json:"tokenTicker"`
+	TokenId          string                 `json:"tokenId"`
+	ContractAddress  string                 `json:"contractAddress"`
+	Royalties        float64                `json:"royalties"`
+	TokenNameBase    string                 `json:"tokenNameBase"`
+	ImageBaseLink    string                 `json:"imageBaseLink"`
+	ImageExt         string                 `json:"imageExt"`
+	MetadataBaseLink string                 `json:"metadataBaseLink"`
+	Price            string                 `json:"price"` // nominal
+	MaxSupply        uint64                 `json:"maxSupply"`
+	SaleStart        uint64                 `json:"saleStart"`
+	CollectionID     uint64                 `json:"collectionId"`
+	Steps            []CollectionLaunchStep `json:"steps" gorm:"foreignKey:LaunchID"`
+	CreatedAt        int64                  `json:"createdAt" gorm:"autoCreateTime:milli"`
+	UpdatedAt        int64                  `json:"updatedAt" gorm:"autoUpdateTime:milli"`
+}
+
+type CollectionLaunchStep struct {
+	ID          uint64           `gorm:"primaryKey" json:"id"`
+	LaunchID    uint64           `json:"launchId" gorm:"index"`
+	Name        string           `json:"name"`
+	Position    uint64           `json:"position"`
+	Status      LaunchStepStatus `json:"status"`
+	TxHash      string           `json:"txHash"`
+	Error       string           `json:"error"`
+	SubmittedAt int64            `json:"submittedAt"`
+	CompletedAt int64            `json:"completedAt"`
+}
+
+// LaunchStepCount tells how many launches are waiting on a step.
+type LaunchStepCount struct {
+	CurrentStep string `json:"currentStep"`
+	Count       uint64 `json:"count"`
+}
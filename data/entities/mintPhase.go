@@ -0,0 +1,18 @@
+package entities
+
+// CollectionMintPhase is one step of a mint schedule (OG, whitelist, public...).
+// Phases of a collection are ordered by Position and don't overlap. A zero EndDate leaves the phase open,
+// a zero WalletCap means no per-wallet limit and WhitelistType uses the Whitelist_type consts, none being public.
+type CollectionMintPhase struct {
+	ID            uint64  `gorm:"primaryKey" json:"id"`
+	CollectionID  uint64  `json:"collectionId" gorm:"uniqueIndex:collection_mint_phase_position"`
+	Position      uint64  `json:"position" gorm:"uniqueIndex:collection_mint_phase_position"`
+	Name          string  `json:"name"`
+	PriceString   string  `json:"priceString"` // in blockchain units
+	PriceNominal  float64 `json:"priceNominal"`
+	StartDate     uint64  `json:"startDate"`
+	EndDate       uint64  `json:"endDate"`
+	WalletCap     uint64  `json:"walletCap"`
+	WhitelistType uint64  `json:"whitelistType"`
+	CreatedAt     int64   `json:"createdAt" gorm:"autoCreateTime:milli"`
+}
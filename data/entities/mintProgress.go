@@ -0,0 +1,34 @@
+package entities
+
+// MintTransaction is a successful mint call indexed from a collection minter contract.
+type MintTransaction struct {
+	ID           uint64  `gorm:"primaryKey" json:"id"`
+	TxHash       string  `json:"txHash" gorm:"uniqueIndex"`
+	CollectionID uint64  `json:"collectionId" gorm:"index"`
+	PhaseID      uint64  `json:"phaseId"` // 0 when the collection had no open phase
+	Minter       string  `json:"minter"`
+	Count        uint64  `json:"count"`
+	Value        float64 `json:"value"`
+	Timestamp    uint64  `json:"timestamp"`
+}
+
+// CollectionMintProgress is maintained from the indexed mints and reconciled with the contract view.
+// Minted never goes below the last total sold reported by the contract, so missed transactions can't hide mints.
+type CollectionMintProgress struct {
+	ID               uint64  `gorm:"primaryKey" json:"id"`
+	CollectionID     uint64  `json:"collectionId" gorm:"uniqueIndex"`
+	MaxSupply        uint64  `json:"maxSupply"`
+	Minted           uint64  `json:"minted"`
+	ChainMinted      uint64  `json:"chainMinted"`
+	UniqueMinters    uint64  `json:"uniqueMinters"`
+	Revenue          float64 `json:"revenue"`
+	LastTimestamp    uint64  `json:"lastTimestamp"`    // last indexed mint transaction
+	LastTimestampTxs uint64  `json:"lastTimestampTxs"` // transactions indexed at LastTimestamp, skipped when paging from it
+	ReconciledAt     int64   `json:"reconciledAt"`
+	UpdatedAt        int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"`
+}
+
+type MintPhaseSold struct {
+	PhaseID uint64 `json:"phaseId"`
+	Sold    uint64 `json:"sold"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "gorm.io/datatypes"
+
+const (
+	NotificationCollectionVerification = "collection.verification"
+)
+
+// Notification is a message for the owner of an address, like the decision on a verification application.
+// Data holds what the client needs to link the notification, its shape depends on the kind.
+type Notification struct {
+	ID        uint64         `gorm:"primaryKey" json:"id"`
+	Address   string         `gorm:"index;not null" json:"address"`
+	Kind      string         `gorm:"not null" json:"kind"`
+	Message   string         `json:"message"`
+	Data      datatypes.JSON `json:"data"`
+	IsRead    bool           `gorm:"default:false" json:"isRead"`
+	CreatedAt int64          `json:"createdAt"`
+}
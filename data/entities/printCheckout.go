@@ -0,0 +1,36 @@
+package entities
+
+import "gorm.io/datatypes"
+
+type PrintCheckoutStatus string
+
+const (
+	// PrintCheckoutQuoted waits for the EGLD payment of the quote.
+	PrintCheckoutQuoted PrintCheckoutStatus = "quoted"
+	// PrintCheckoutPaid has a verified payment but was not accepted by the fulfillment provider yet.
+	PrintCheckoutPaid      PrintCheckoutStatus = "paid"
+	PrintCheckoutSubmitted PrintCheckoutStatus = "submitted"
+)
+
+// PrintCheckout is a print order quoted in EGLD. The order is only forwarded to the fulfillment provider
+// once a transaction paying the quote was verified on chain.
+type PrintCheckout struct {
+	ID              uint64              `gorm:"primaryKey" json:"id"`
+	Address         string              `json:"address" gorm:"index"`
+	Status          PrintCheckoutStatus `json:"status"`
+	Order           datatypes.JSON      `json:"order"`
+	CostUsd         float64             `json:"costUsd"`
+	ShippingUsd     float64             `json:"shippingUsd"`
+	RoyaltyUsd      float64             `json:"royaltyUsd"`
+	Royalties       datatypes.JSON      `json:"royalties"`
+	EgldPrice       float64             `json:"egldPrice"`
+	AmountNominal   float64             `json:"amountNominal"`
+	Amount          string              `json:"amount"`
+	PaymentReceiver string              `json:"paymentReceiver"`
+	QuoteExpiresAt  int64               `json:"quoteExpiresAt"`
+	PaymentTxHash   string              `json:"paymentTxHash" gorm:"index:idx_print_checkout_payment,unique,where:payment_tx_hash <> ''"`
+	ProviderOrderId string              `json:"providerOrderId"`
+	Error           string              `json:"error"`
+	CreatedAt       int64               `json:"createdAt"`
+	UpdatedAt       int64               `json:"updatedAt"`
+}
@@ -0,0 +1,61 @@
+package entities
+
+import "gorm.io/datatypes"
+
+type PrintOrderStatus string
+
+const (
+	PrintOrderSubmitted  PrintOrderStatus = "submitted"
+	PrintOrderProduction PrintOrderStatus = "production"
+	PrintOrderShipped    PrintOrderStatus = "shipped"
+	PrintOrderDelivered  PrintOrderStatus = "delivered"
+	PrintOrderCancelled  PrintOrderStatus = "cancelled"
+	PrintOrderRejected   PrintOrderStatus = "rejected"
+)
+
+// PrintOrder is a paid print checkout accepted by the fulfillment provider. Its status only moves
+// along the print order transitions, every change is kept in History.
+type PrintOrder struct {
+	ID              uint64                   `gorm:"primaryKey" json:"id"`
+	CheckoutID      uint64                   `json:"checkoutId" gorm:"uniqueIndex"`
+	Address         string                   `json:"address" gorm:"index"`
+	ReferenceId     string                   `json:"referenceId" gorm:"uniqueIndex"`
+	ProviderOrderId uint64                   `json:"providerOrderId"`
+	Status          PrintOrderStatus         `json:"status"`
+	ProviderStatus  string                   `json:"providerStatus"`
+	ShippingMethod  string                   `json:"shippingMethod"`
+	ShippingAddress datatypes.JSON           `json:"shippingAddress"`
+	Tracking        datatypes.JSON           `json:"tracking"`
+	CostUsd         float64                  `json:"costUsd"`
+	Items           []PrintOrderItem         `json:"items" gorm:"foreignKey:OrderID"`
+	History         []PrintOrderStatusChange `json:"history,omitempty" gorm:"foreignKey:OrderID"`
+	CreatedAt       int64                    `json:"createdAt"`
+	UpdatedAt       int64                    `json:"updatedAt"`
+}
+
+type PrintOrderItem struct {
+	ID              uint64 `gorm:"primaryKey" json:"id"`
+	OrderID         uint64 `json:"orderId" gorm:"index"`
+	TokenIdentifier string `json:"tokenIdentifier"`
+	ItemVariant     int64  `json:"itemVariant"`
+	Quantity        int64  `json:"quantity"`
+	PrintArea       string `json:"printArea"`
+	ImageUrl        string `json:"imageUrl"`
+}
+
+type PrintOrderStatusChange struct {
+	ID             uint64           `gorm:"primaryKey" json:"id"`
+	OrderID        uint64           `json:"orderId" gorm:"index"`
+	From           PrintOrderStatus `json:"from"`
+	To             PrintOrderStatus `json:"to"`
+	ProviderStatus string           `json:"providerStatus"`
+	CreatedAt      int64            `json:"createdAt"`
+}
+
+// PrintOrderWebhookEvent marks a provider webhook as processed, the key is the hash of its body.
+type PrintOrderWebhookEvent struct {
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	EventKey    string `json:"eventKey" gorm:"uniqueIndex"`
+	ReferenceId string `json:"referenceId" gorm:"index"`
+	ReceivedAt  int64  `json:"receivedAt"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+// PrintProduct is an item of the fulfillment provider offered for prints. Disabled products stay
+// listed for admins but are not quoted.
+type PrintProduct struct {
+	ID             uint64 `gorm:"primaryKey" json:"id"`
+	ProviderItemId int64  `json:"providerItemId" gorm:"uniqueIndex"`
+	Name           string `json:"name"`
+	Enabled        bool   `json:"enabled"`
+	CreatedAt      int64  `json:"createdAt"`
+	UpdatedAt      int64  `json:"updatedAt"`
+}
@@ -0,0 +1,24 @@
+package entities
+
+type PrintRoyaltyStatus string
+
+const (
+	PrintRoyaltyOwed PrintRoyaltyStatus = "owed"
+	PrintRoyaltyPaid PrintRoyaltyStatus = "paid"
+)
+
+// PrintRoyalty is the share of a paid print checkout owed to the creator of a printed collection.
+// Amount is denominated EGLD, paid out from the print payment address.
+type PrintRoyalty struct {
+	ID             uint64             `gorm:"primaryKey" json:"id"`
+	CheckoutID     uint64             `json:"checkoutId" gorm:"index"`
+	CollectionID   uint64             `json:"collectionId"`
+	CreatorAddress string             `json:"creatorAddress" gorm:"index"`
+	Usd            float64            `json:"usd"`
+	AmountNominal  float64            `json:"amountNominal"`
+	Amount         string             `json:"amount"`
+	Status         PrintRoyaltyStatus `json:"status"`
+	PaidTxHash     string             `json:"paidTxHash" gorm:"index:idx_print_royalty_paid_tx,unique,where:paid_tx_hash <> ''"`
+	CreatedAt      int64              `json:"createdAt"`
+	PaidAt         int64              `json:"paidAt"`
+}
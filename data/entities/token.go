@@ -38,6 +38,8 @@ type Token struct {
 	RarityScoreNorm      float64        `json:"rarityScoreNorm" gorm:"default:0.0"`
 	IsRarityInserted     bool           `json:"isRarityInserted" gorm:"default:false"`
 	RarityLastUpdated    uint64         `json:"rarityLastUpdated" gorm:"autoUpdateTime:milli;default:0"`
+	IsHidden             bool           `json:"isHidden" gorm:"default:false"`
+	IsDelisted           bool           `json:"isDelisted" gorm:"default:false"`
 }
 
 type TokenBC struct {
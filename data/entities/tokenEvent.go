@@ -0,0 +1,47 @@
+package entities
+
+type TokenEventKind string
+
+const (
+	TokenEventMint         TokenEventKind = "mint"
+	TokenEventListing      TokenEventKind = "listing"
+	TokenEventDelisting    TokenEventKind = "delisting"
+	TokenEventPriceChange  TokenEventKind = "price_change"
+	TokenEventOffer        TokenEventKind = "offer"
+	TokenEventOfferCancel  TokenEventKind = "offer_cancel"
+	TokenEventBid          TokenEventKind = "bid"
+	TokenEventAuctionStart TokenEventKind = "auction_start"
+	TokenEventAuctionEnd   TokenEventKind = "auction_end"
+	TokenEventSale         TokenEventKind = "sale"
+	TokenEventTransfer     TokenEventKind = "transfer"
+	TokenEventStake        TokenEventKind = "stake"
+	TokenEventUnstake      TokenEventKind = "unstake"
+)
+
+// TokenEvent is a change of state of a token, kept for its provenance.
+// Unlike offers and bids, events are never deleted. FromAddress is who acted or gave the token away
+// and ToAddress who received it, if anyone. Amounts are in EGLD, AmountString in the smallest unit.
+type TokenEvent struct {
+	ID            uint64         `gorm:"primaryKey" json:"id"`
+	TokenID       uint64         `gorm:"index:token_event_unique,unique;not null" json:"tokenId"`
+	Kind          TokenEventKind `gorm:"index:token_event_unique,unique;not null" json:"kind"`
+	TxHash        string         `gorm:"index:token_event_unique,unique" json:"txHash"`
+	Timestamp     uint64         `gorm:"index:token_event_unique,unique" json:"timestamp"`
+	FromAddress   string         `json:"fromAddress"`
+	ToAddress     string         `json:"toAddress"`
+	AmountNominal float64        `json:"amountNominal"`
+	AmountString  string         `json:"amountString"`
+	CreatedAt     int64          `json:"createdAt"`
+}
+
+// TokenHistoryCursor follows the transactions of a token read for its provenance.
+// Pending is set whenever the token may have new transactions and is cleared once they are all indexed,
+// unless it was requested again in the meantime.
+type TokenHistoryCursor struct {
+	TokenID          uint64 `gorm:"primaryKey;autoIncrement:false" json:"tokenId"`
+	Pending          bool   `gorm:"index" json:"pending"`
+	RequestedAt      int64  `json:"requestedAt"`
+	LastTimestamp    uint64 `json:"lastTimestamp"`    // last indexed transaction
+	LastTimestampTxs uint64 `json:"lastTimestampTxs"` // transactions indexed at LastTimestamp, skipped when paging from it
+	UpdatedAt        int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"`
+}
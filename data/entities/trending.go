@@ -0,0 +1,34 @@
+package entities
+
+type TrendingOverrideMode string
+
+const (
+	TrendingPin      TrendingOverrideMode = "pin"
+	TrendingSuppress TrendingOverrideMode = "suppress"
+)
+
+type CollectionTrendingScore struct {
+	ID              uint64  `gorm:"primaryKey" json:"id"`
+	CollectionId    uint64  `json:"collectionId" gorm:"uniqueIndex"`
+	Score           float64 `json:"score"`
+	SalesVelocity   float64 `json:"salesVelocity"`
+	UniqueBuyers    float64 `json:"uniqueBuyers"`
+	FloorMovement   float64 `json:"floorMovement"`
+	ListingActivity float64 `json:"listingActivity"`
+	UpdatedAt       int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"`
+}
+
+type CollectionTrendingOverride struct {
+	ID           uint64               `gorm:"primaryKey" json:"id"`
+	CollectionId uint64               `json:"collectionId" gorm:"uniqueIndex"`
+	Mode         TrendingOverrideMode `json:"mode"`
+	CreatedAt    int64                `json:"createdAt" gorm:"autoCreateTime:milli"`
+}
+
+// CollectionTrendingActivity holds time decayed transaction counts, a fresh event weighs 1.
+type CollectionTrendingActivity struct {
+	CollectionId uint64  `json:"collectionId"`
+	Sales        float64 `json:"sales"`
+	Listings     float64 `json:"listings"`
+	UniqueBuyers float64 `json:"uniqueBuyers"`
+}
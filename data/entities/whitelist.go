@@ -2,10 +2,10 @@ package entities
 
 type Whitelist struct {
 	ID           uint64 `gorm:"primaryKey" json:"id"` //PK
-	CollectionID uint64 `json:"collectionId"`         //FK to the
-	Address      string `json:"address"`
+	CollectionID uint64 `json:"collectionId" gorm:"uniqueIndex:idx_whitelist_collection_address_type"` //FK to the
+	Address      string `json:"address" gorm:"uniqueIndex:idx_whitelist_collection_address_type"`
 	Amount       uint64 `json:"amount"  gorm:"default:1"`
-	Type         uint64 `json:"type"` // use the Const defined below
+	Type         uint64 `json:"type" gorm:"uniqueIndex:idx_whitelist_collection_address_type"` // use the Const defined below
 	CreatedAt    uint64 `json:"createdAt" gorm:"autoCreateTime:milli"`
 	ModifiedAt   uint64 `json:"modifiedAt" gorm:"autoUpdateTime:milli"` // Set to current unix seconds on updaing or if it is zero on creating
 }
@@ -0,0 +1,21 @@
+package entities
+
+// WhitelistMerkleRoot is a frozen snapshot of the mint allocations of a collection.
+// Version grows by one per collection every time a new tree is built.
+type WhitelistMerkleRoot struct {
+	ID           uint64 `gorm:"primaryKey" json:"id"`
+	CollectionID uint64 `json:"collectionId" gorm:"uniqueIndex:idx_whitelist_merkle_collection_version"`
+	Version      uint64 `json:"version" gorm:"uniqueIndex:idx_whitelist_merkle_collection_version"`
+	Root         string `json:"root"` // hex encoded
+	LeavesCount  uint64 `json:"leavesCount"`
+	CreatedAt    int64  `json:"createdAt" gorm:"autoCreateTime:milli"`
+}
+
+// WhitelistMerkleLeaf keeps the allocation used for a leaf, so proofs stay valid after the whitelist is edited.
+type WhitelistMerkleLeaf struct {
+	ID       uint64 `gorm:"primaryKey" json:"id"`
+	RootID   uint64 `json:"rootId" gorm:"index"`
+	Position uint64 `json:"position"`
+	Address  string `json:"address"`
+	Amount   uint64 `json:"amount"`
+}
@@ -2,11 +2,14 @@ package formatter
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
@@ -42,12 +45,20 @@ var (
 	updateSaleStartEndpointName              = "updateSaleStart"
 	updateBuyerWhiteListCheckEndpointName    = "updateBuyerWhitelistCheck"
 	getBuyerWhiteListCheckEndpointName       = "getBuyerWhiteListCheck"
+	setWhitelistMerkleRootEndpointName       = "setWhitelistMerkleRoot"
+	setMintPhaseEndpointName                 = "setMintPhase"
 	stakeCollectionTemplateEndpointName      = "addStakableTokenIdentifier"
 	unstakeCollectionTemplateEndpointName    = "removeStakableTokenIdentifier"
 )
 
 const RoyaltiesBP = 100
 
+const (
+	// a plain transfer costs the base gas plus the gas of every data byte
+	transferBaseGasLimit    = 50000
+	transferGasLimitPerByte = 1500
+)
+
 type Transaction struct {
 	Nonce     uint64 `json:"nonce"`
 	Value     string `json:"value"`
@@ -310,6 +321,25 @@ func (f *TxFormatter) DepositTxTemplate(senderAddr string, payment string) Trans
 	}
 }
 
+// PrintPaymentTxTemplate is the EGLD transfer paying a print checkout quote. Its data references the checkout.
+func (f *TxFormatter) PrintPaymentTxTemplate(checkout *entities.PrintCheckout) Transaction {
+	txData := services.PrintPaymentData(checkout.ID)
+
+	return Transaction{
+		Nonce:     0,
+		Value:     checkout.Amount,
+		RcvAddr:   checkout.PaymentReceiver,
+		SndAddr:   checkout.Address,
+		GasPrice:  f.config.GasPrice,
+		GasLimit:  transferBaseGasLimit + transferGasLimitPerByte*uint64(len(txData)),
+		Data:      txData,
+		Signature: "",
+		ChainID:   f.config.ChainID,
+		Version:   1,
+		Options:   0,
+	}
+}
+
 func (f *TxFormatter) WithdrawTxTemplate(senderAddr string, amount float64) Transaction {
 	txData := withdrawEndpointName
 	if amount != 0 {
@@ -443,6 +473,42 @@ func (f *TxFormatter) NewIssueNFTTxTemplate(
 	}
 }
 
+// LaunchStepTxTemplate builds the transaction of an on-chain launch step from the launch parameters
+// and what the previous steps returned.
+func (f *TxFormatter) LaunchStepTxTemplate(launch *entities.CollectionLaunch, stepName string) (*Transaction, error) {
+	switch stepName {
+	case services.LaunchStepIssue:
+		template := f.NewIssueNFTTxTemplate(launch.Address, launch.TokenName, launch.TokenTicker)
+		return &template, nil
+	case services.LaunchStepDeploy:
+		price, err := strconv.ParseFloat(launch.Price, 64)
+		if err != nil {
+			return nil, err
+		}
+		template := f.DeployNFTTemplateTxTemplate(
+			launch.Address,
+			launch.TokenId,
+			launch.Royalties,
+			launch.TokenNameBase,
+			launch.ImageBaseLink,
+			launch.ImageExt,
+			price,
+			launch.MaxSupply,
+			launch.SaleStart,
+			launch.MetadataBaseLink,
+		)
+		return &template, nil
+	case services.LaunchStepSetRoles:
+		return f.SetSpecialRolesTxTemplate(launch.Address, launch.TokenId, launch.ContractAddress)
+	case services.LaunchStepChangeOwner:
+		return f.ChangeOwnerTxTemplate(launch.Address, launch.ContractAddress)
+	case services.LaunchStepSaleStart:
+		return f.UpdateSaleStartTemplateTxTemplate(launch.Address, launch.ContractAddress, launch.SaleStart)
+	default:
+		return nil, services.ErrLaunchStepServerSide
+	}
+}
+
 func (f *TxFormatter) DeployNFTTemplateTxTemplate(
 	walletAddress string,
 	tokenId string,
@@ -688,6 +754,57 @@ func (f *TxFormatter) UpdateSaleStartTemplateTxTemplate(
 
 }
 
+// UpdateMintPhasesTemplateTxTemplates builds one setMintPhase call per phase of the schedule, in order.
+func (f *TxFormatter) UpdateMintPhasesTemplateTxTemplates(
+	walletAddress string,
+	contractAddress string,
+	phases []entities.CollectionMintPhase,
+) ([]Transaction, error) {
+	templates := make([]Transaction, len(phases))
+	for index, phase := range phases {
+		template, err := f.UpdateMintPhaseTemplateTxTemplate(walletAddress, contractAddress, phase)
+		if err != nil {
+			return nil, err
+		}
+		templates[index] = *template
+	}
+
+	return templates, nil
+}
+
+func (f *TxFormatter) UpdateMintPhaseTemplateTxTemplate(
+	walletAddress string,
+	contractAddress string,
+	phase entities.CollectionMintPhase,
+) (*Transaction, error) {
+	price, ok := big.NewInt(0).SetString(phase.PriceString, 10)
+	if !ok {
+		return nil, errors.New("invalid phase price")
+	}
+
+	txData := setMintPhaseEndpointName +
+		"@" + hex.EncodeToString(big.NewInt(int64(phase.Position)).Bytes()) +
+		"@" + hex.EncodeToString(price.Bytes()) +
+		"@" + hex.EncodeToString(big.NewInt(int64(phase.StartDate)).Bytes()) +
+		"@" + hex.EncodeToString(big.NewInt(int64(phase.EndDate)).Bytes()) +
+		"@" + hex.EncodeToString(big.NewInt(int64(phase.WalletCap)).Bytes()) +
+		"@" + hex.EncodeToString(big.NewInt(int64(phase.WhitelistType)).Bytes())
+
+	return &Transaction{
+		Nonce:     0,
+		Value:     "0",
+		RcvAddr:   contractAddress,
+		SndAddr:   walletAddress,
+		GasPrice:  f.config.GasPrice,
+		GasLimit:  f.config.UpdateSaleStartGasLimit,
+		Data:      txData,
+		Signature: "",
+		ChainID:   f.config.ChainID,
+		Version:   1,
+		Options:   0,
+	}, nil
+}
+
 func (f *TxFormatter) UpdateBuyerWhiteListCheckTemplateTxTemplate(
 	walletAddress string,
 	contractAddress string,
@@ -712,6 +829,37 @@ func (f *TxFormatter) UpdateBuyerWhiteListCheckTemplateTxTemplate(
 
 }
 
+// SetWhitelistMerkleRootTxTemplate pushes a whitelist snapshot to the minter, buyers then mint with a proof instead of per-address storage.
+func (f *TxFormatter) SetWhitelistMerkleRootTxTemplate(
+	walletAddress string,
+	contractAddress string,
+	rootHex string,
+	version uint64,
+) (*Transaction, error) {
+	root, err := hex.DecodeString(rootHex)
+	if err != nil {
+		return nil, err
+	}
+
+	txData := setWhitelistMerkleRootEndpointName +
+		"@" + hex.EncodeToString(root) +
+		"@" + hex.EncodeToString(big.NewInt(int64(version)).Bytes())
+
+	return &Transaction{
+		Nonce:     0,
+		Value:     "0",
+		RcvAddr:   contractAddress,
+		SndAddr:   walletAddress,
+		GasPrice:  f.config.GasPrice,
+		GasLimit:  f.config.SetWhitelistMerkleRootGasLimit,
+		Data:      txData,
+		Signature: "",
+		ChainID:   f.config.ChainID,
+		Version:   1,
+		Options:   0,
+	}, nil
+}
+
 func (f *TxFormatter) GetBuyerWhiteListCheckTemplateTxTemplate(
 	walletAddress string,
 	contractAddress string,
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 	"github.com/stretchr/testify/require"
 )
@@ -90,3 +91,18 @@ func defaultConfig() config.BlockchainConfig {
 		WithdrawNftGasLimit: 15_000_000,
 	}
 }
+
+func TestTxFormatter_PrintPaymentTxTemplate(t *testing.T) {
+	formatter := NewTxFormatter(defaultConfig())
+
+	tx := formatter.PrintPaymentTxTemplate(&entities.PrintCheckout{
+		ID:              12,
+		Address:         "erd17s2pz8qrds6ake3qwheezgy48wzf7dr5nhdpuu2h4rr4mt5rt9ussj7xzh",
+		Amount:          "1500000000000000000",
+		PaymentReceiver: "erd1qqqqqqqqqqqqqpgq3k89y42xjk2z05zu5vtjkcgsvhvjhu6nt9usruf2td",
+	})
+
+	require.Equal(t, "printCheckout:12", tx.Data)
+	require.Equal(t, "1500000000000000000", tx.Value)
+	require.Equal(t, uint64(50000+1500*16), tx.GasLimit)
+}
@@ -0,0 +1,212 @@
+package fulfillment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/hashicorp/go-uuid"
+)
+
+const (
+	DefaultDreamshipApiUrl = "https://api.dreamship.com/v1"
+	DefaultTimeoutSecs     = 15
+
+	dreamshipItemUrl             = "%s/items/%d/"
+	dreamshipShippingUrl         = "%s/items/%d/%s/"
+	dreamshipOrdersUrl           = "%s/orders/"
+	dreamshipOrderByReferenceUrl = "%s/orders/?reference_id=%s"
+	dreamshipUSShipping          = "us-shipping-methods"
+	dreamshipInterShipping       = "international-shipping-methods"
+
+	// maxErrorBodyLength keeps provider error messages short in our own errors.
+	maxErrorBodyLength = 256
+)
+
+type dreamshipProvider struct {
+	client        *http.Client
+	apiUrl        string
+	apiKey        string
+	webhookSecret string
+}
+
+type dreamshipOrderList struct {
+	Data []entities.ItemWebhook `json:"data"`
+}
+
+func NewDreamshipProvider(cfg config.FulfillmentConfig, credentials config.ExternalCredentialConfig) *dreamshipProvider {
+	apiUrl := strings.TrimSuffix(cfg.ApiUrl, "/")
+	if apiUrl == "" {
+		apiUrl = DefaultDreamshipApiUrl
+	}
+
+	timeoutSecs := cfg.TimeoutSecs
+	if timeoutSecs == 0 {
+		timeoutSecs = DefaultTimeoutSecs
+	}
+
+	return &dreamshipProvider{
+		client:        &http.Client{Timeout: time.Duration(timeoutSecs) * time.Second},
+		apiUrl:        apiUrl,
+		apiKey:        credentials.DreamshipAPIKey,
+		webhookSecret: cfg.WebhookSecret,
+	}
+}
+
+func (dp *dreamshipProvider) GetItem(ctx context.Context, itemId int64) (entities.DreamshipItems, error) {
+	var item entities.DreamshipItems
+
+	err := dp.do(ctx, http.MethodGet, fmt.Sprintf(dreamshipItemUrl, dp.apiUrl, itemId), nil, &item)
+	if err == errNotFound {
+		return item, ErrItemNotFound
+	}
+
+	return item, err
+}
+
+func (dp *dreamshipProvider) GetShippingMethods(ctx context.Context, region string, itemId int64) (map[string]entities.ShippingMethodResponse, error) {
+	shipping := dreamshipInterShipping
+	if region == USShippingRegion {
+		shipping = dreamshipUSShipping
+	}
+
+	var methods map[string]entities.ShippingMethodResponse
+	err := dp.do(ctx, http.MethodGet, fmt.Sprintf(dreamshipShippingUrl, dp.apiUrl, itemId, shipping), nil, &methods)
+	if err == errNotFound {
+		return nil, ErrItemNotFound
+	}
+
+	return methods, err
+}
+
+// CreateOrder sets a new reference id on the order unless it has one, the provider returns it along with its own order id.
+// Callers that retry set their own reference id, so they can look the order up with GetOrder first.
+func (dp *dreamshipProvider) CreateOrder(ctx context.Context, order entities.DreamshipOrderItems) (entities.ItemWebhook, error) {
+	var response entities.ItemWebhook
+
+	if order.ReferenceId == "" {
+		referenceId, err := uuid.GenerateUUID()
+		if err != nil {
+			return response, err
+		}
+		order.ReferenceId = referenceId
+	}
+
+	orderJson, err := json.Marshal(order)
+	if err != nil {
+		return response, err
+	}
+
+	err = dp.do(ctx, http.MethodPost, fmt.Sprintf(dreamshipOrdersUrl, dp.apiUrl), orderJson, &response)
+	if err == errNotFound {
+		return response, ErrProviderRejected
+	}
+	if err != nil {
+		return response, err
+	}
+	if response.ReferenceId == "" {
+		return response, ErrProviderRejected
+	}
+
+	return response, nil
+}
+
+func (dp *dreamshipProvider) GetOrder(ctx context.Context, referenceId string) (entities.ItemWebhook, error) {
+	var orders dreamshipOrderList
+
+	err := dp.do(ctx, http.MethodGet, fmt.Sprintf(dreamshipOrderByReferenceUrl, dp.apiUrl, url.QueryEscape(referenceId)), nil, &orders)
+	if err == errNotFound {
+		return entities.ItemWebhook{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return entities.ItemWebhook{}, err
+	}
+
+	for _, order := range orders.Data {
+		if order.ReferenceId == referenceId {
+			return order, nil
+		}
+	}
+
+	return entities.ItemWebhook{}, ErrOrderNotFound
+}
+
+func (dp *dreamshipProvider) ParseWebhook(header http.Header, body []byte) (entities.ItemWebhook, error) {
+	var order entities.ItemWebhook
+
+	err := VerifyWebhook(dp.webhookSecret, header, body)
+	if err != nil {
+		return order, err
+	}
+
+	err = json.Unmarshal(body, &order)
+	if err != nil || order.ReferenceId == "" {
+		return order, ErrInvalidWebhook
+	}
+
+	return order, nil
+}
+
+// do sends a request to the api and decodes the json answer in response.
+// Network errors and server errors are reported as unavailable, other error statuses as rejected.
+func (dp *dreamshipProvider) do(ctx context.Context, method string, requestUrl string, body []byte, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", dp.apiKey))
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	res, err := dp.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: status %d", ErrProviderUnavailable, res.StatusCode)
+	case res.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("%w: status %d: %s", ErrProviderRejected, res.StatusCode, truncate(string(resBody), maxErrorBodyLength))
+	}
+
+	err = json.Unmarshal(resBody, response)
+	if err != nil {
+		log.Debug("could not decode provider response", "url", requestUrl, "err", err)
+		return fmt.Errorf("%w: invalid response", ErrProviderUnavailable)
+	}
+
+	return nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
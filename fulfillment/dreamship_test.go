@@ -0,0 +1,126 @@
+package fulfillment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDreamship(t *testing.T, handler http.HandlerFunc) *dreamshipProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewDreamshipProvider(config.FulfillmentConfig{ApiUrl: server.URL + "/"}, config.ExternalCredentialConfig{DreamshipAPIKey: "key"})
+}
+
+func TestDreamshipProvider_GetItem(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestDreamship(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		if r.URL.Path != "/items/19/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":19,"name":"Canvas","item_variants":[{"id":1001,"cost":20}]}`))
+	})
+
+	item, err := provider.GetItem(context.Background(), 19)
+	require.Nil(t, err)
+	require.Equal(t, "Canvas", item.Name)
+	require.Equal(t, float64(20), item.ItemVariants[0].Cost)
+
+	_, err = provider.GetItem(context.Background(), 20)
+	require.Equal(t, ErrItemNotFound, err)
+}
+
+func TestDreamshipProvider_Errors(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusBadRequest
+	provider := newTestDreamship(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"detail":"invalid address"}`))
+	})
+
+	_, err := provider.CreateOrder(context.Background(), entities.DreamshipOrderItems{})
+	require.True(t, errors.Is(err, ErrProviderRejected))
+	require.Contains(t, err.Error(), "invalid address")
+
+	status = http.StatusBadGateway
+	_, err = provider.GetShippingMethods(context.Background(), USShippingRegion, 19)
+	require.True(t, errors.Is(err, ErrProviderUnavailable))
+}
+
+func TestDreamshipProvider_Timeout(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestDreamship(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	provider.client.Timeout = 50 * time.Millisecond
+
+	_, err := provider.GetOrder(context.Background(), "ref")
+	require.True(t, errors.Is(err, ErrProviderUnavailable))
+}
+
+func TestDreamshipProvider_GetOrder(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestDreamship(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "ref-1", r.URL.Query().Get("reference_id"))
+		_, _ = w.Write([]byte(`{"data":[{"id":5,"status":"shipped","reference_id":"ref-1"}]}`))
+	})
+
+	order, err := provider.GetOrder(context.Background(), "ref-1")
+	require.Nil(t, err)
+	require.Equal(t, "shipped", order.Status)
+}
+
+func TestDreamshipProvider_CreateOrderKeepsReferenceId(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestDreamship(t, func(w http.ResponseWriter, r *http.Request) {
+		var order entities.DreamshipOrderItems
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&order))
+		_, _ = w.Write([]byte(`{"id":5,"status":"pending","reference_id":"` + order.ReferenceId + `"}`))
+	})
+
+	created, err := provider.CreateOrder(context.Background(), entities.DreamshipOrderItems{ReferenceId: "checkout-8"})
+	require.Nil(t, err)
+	require.Equal(t, "checkout-8", created.ReferenceId)
+
+	created, err = provider.CreateOrder(context.Background(), entities.DreamshipOrderItems{})
+	require.Nil(t, err)
+	require.NotEmpty(t, created.ReferenceId)
+	require.NotEqual(t, "checkout-8", created.ReferenceId)
+}
+
+func TestDreamshipProvider_ParseWebhook(t *testing.T) {
+	t.Parallel()
+
+	provider := NewDreamshipProvider(config.FulfillmentConfig{WebhookSecret: "secret"}, config.ExternalCredentialConfig{})
+	body := []byte(`{"id":5,"status":"production","reference_id":"ref-1","cost":"27.00"}`)
+
+	_, err := provider.ParseWebhook(http.Header{}, body)
+	require.Equal(t, ErrInvalidWebhookSignature, err)
+
+	header := http.Header{}
+	header.Set(WebhookSignatureHeader, WebhookSignature("secret", body))
+	order, err := provider.ParseWebhook(header, body)
+	require.Nil(t, err)
+	require.Equal(t, "ref-1", order.ReferenceId)
+
+	body = []byte(`{"id":5}`)
+	header.Set(WebhookSignatureHeader, WebhookSignature("secret", body))
+	_, err = provider.ParseWebhook(header, body)
+	require.Equal(t, ErrInvalidWebhook, err)
+}
@@ -0,0 +1,16 @@
+package fulfillment
+
+import "errors"
+
+var (
+	ErrProviderUnavailable = errors.New("fulfillment provider unavailable")
+	ErrProviderRejected    = errors.New("fulfillment provider rejected the request")
+	ErrItemNotFound        = errors.New("fulfillment item not found")
+	ErrOrderNotFound       = errors.New("fulfillment order not found")
+	ErrInvalidWebhook      = errors.New("invalid fulfillment webhook")
+
+	ErrWebhookNotConfigured    = errors.New("fulfillment webhooks are not configured")
+	ErrInvalidWebhookSignature = errors.New("invalid fulfillment webhook signature")
+
+	errNotFound = errors.New("not found")
+)
@@ -0,0 +1,51 @@
+package fulfillment
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	logger "github.com/ElrondNetwork/elrond-go-logger"
+)
+
+var (
+	once     sync.Once
+	provider FulfillmentProvider
+
+	log = logger.GetOrCreate("fulfillment")
+)
+
+const (
+	dreamship = "dreamship"
+	mock      = "mock"
+)
+
+func InitProvider(cfg config.FulfillmentConfig, credentials config.ExternalCredentialConfig) {
+	once.Do(func() {
+		prov, err := makeProvider(cfg, credentials)
+		if err != nil {
+			panic(err)
+		}
+
+		provider = prov
+	})
+}
+
+func GetProviderOrErr() (FulfillmentProvider, error) {
+	if provider == nil {
+		return nil, errors.New("no fulfillment provider initialized")
+	}
+
+	return provider, nil
+}
+
+func makeProvider(cfg config.FulfillmentConfig, credentials config.ExternalCredentialConfig) (FulfillmentProvider, error) {
+	switch cfg.Selector {
+	case dreamship, "":
+		return NewDreamshipProvider(cfg, credentials), nil
+	case mock:
+		return NewMockProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, errors.New("unknown selector provided")
+	}
+}
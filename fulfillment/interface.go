@@ -0,0 +1,24 @@
+package fulfillment
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+)
+
+const (
+	USShippingRegion            = "us"
+	InternationalShippingRegion = "international"
+)
+
+// FulfillmentProvider prints and ships orders. Items and orders use the provider ids,
+// orders are referenced by the reference id set on creation. ParseWebhook only returns
+// orders of authenticated webhooks.
+type FulfillmentProvider interface {
+	GetItem(ctx context.Context, itemId int64) (entities.DreamshipItems, error)
+	GetShippingMethods(ctx context.Context, region string, itemId int64) (map[string]entities.ShippingMethodResponse, error)
+	CreateOrder(ctx context.Context, order entities.DreamshipOrderItems) (entities.ItemWebhook, error)
+	GetOrder(ctx context.Context, referenceId string) (entities.ItemWebhook, error)
+	ParseWebhook(header http.Header, body []byte) (entities.ItemWebhook, error)
+}
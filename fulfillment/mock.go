@@ -0,0 +1,174 @@
+package fulfillment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+)
+
+const (
+	MockItemId          = 19
+	MockVariantId       = 1001
+	MockShippingMethod  = "standard"
+	mockVariantCost     = 20
+	mockShippingCost    = 7
+	mockOrderStatus     = "pending"
+	mockReferenceFormat = "mock-%d"
+)
+
+var mockInterCountries = []string{"CA", "DE", "FR", "GB"}
+
+// mockProvider fulfills nothing. It serves one item shipped to the US and a few countries with the standard method
+// and keeps created orders in memory, for local runs and tests. Webhooks are only verified when a secret is set.
+type mockProvider struct {
+	mut           sync.Mutex
+	items         map[int64]entities.DreamshipItems
+	orders        map[string]entities.ItemWebhook
+	nextId        uint64
+	webhookSecret string
+}
+
+func NewMockProvider(webhookSecret string) *mockProvider {
+	return &mockProvider{
+		webhookSecret: webhookSecret,
+		items: map[int64]entities.DreamshipItems{
+			MockItemId: {
+				Id:           MockItemId,
+				Name:         "Mock canvas",
+				ItemVariants: []entities.ItemVariants{{Id: MockVariantId, Name: "Mock canvas 12x12", Availability: "available", Cost: mockVariantCost}},
+			},
+		},
+		orders: map[string]entities.ItemWebhook{},
+		nextId: 1,
+	}
+}
+
+// AddItem makes another item available.
+func (mp *mockProvider) AddItem(item entities.DreamshipItems) {
+	mp.mut.Lock()
+	defer mp.mut.Unlock()
+
+	mp.items[item.Id] = item
+}
+
+func (mp *mockProvider) GetItem(_ context.Context, itemId int64) (entities.DreamshipItems, error) {
+	mp.mut.Lock()
+	defer mp.mut.Unlock()
+
+	item, ok := mp.items[itemId]
+	if !ok {
+		return entities.DreamshipItems{}, ErrItemNotFound
+	}
+
+	return item, nil
+}
+
+func (mp *mockProvider) GetShippingMethods(ctx context.Context, region string, itemId int64) (map[string]entities.ShippingMethodResponse, error) {
+	_, err := mp.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []entities.ShippingMethod{{Method: MockShippingMethod, Cost: mockShippingCost, DeliveryDaysMin: 3, DeliveryDaysMax: 7}}
+	if region == USShippingRegion {
+		return map[string]entities.ShippingMethodResponse{"US": {Code: "US", Name: "United States", Methods: methods}}, nil
+	}
+
+	zones := map[string]entities.ShippingMethodResponse{}
+	for _, country := range mockInterCountries {
+		zones[country] = entities.ShippingMethodResponse{Code: country, Name: country, Methods: methods}
+	}
+
+	return zones, nil
+}
+
+func (mp *mockProvider) CreateOrder(_ context.Context, order entities.DreamshipOrderItems) (entities.ItemWebhook, error) {
+	mp.mut.Lock()
+	defer mp.mut.Unlock()
+
+	if len(order.LineItems) == 0 {
+		return entities.ItemWebhook{}, ErrProviderRejected
+	}
+
+	cost := 0.0
+	for _, lineItem := range order.LineItems {
+		if !mp.hasVariant(lineItem.ItemVariant) {
+			return entities.ItemWebhook{}, fmt.Errorf("%w: unknown variant %d", ErrProviderRejected, lineItem.ItemVariant)
+		}
+		cost += mockVariantCost * float64(lineItem.Quantity)
+	}
+
+	referenceId := order.ReferenceId
+	if referenceId == "" {
+		referenceId = fmt.Sprintf(mockReferenceFormat, mp.nextId)
+	}
+
+	created := entities.ItemWebhook{
+		Id:          mp.nextId,
+		Status:      mockOrderStatus,
+		ReferenceId: referenceId,
+		Cost:        strconv.FormatFloat(cost+mockShippingCost, 'f', 2, 64),
+		TestOrder:   true,
+		LineItem:    order.LineItems,
+		Address:     order.Address,
+	}
+	mp.orders[created.ReferenceId] = created
+	mp.nextId++
+
+	return created, nil
+}
+
+func (mp *mockProvider) GetOrder(_ context.Context, referenceId string) (entities.ItemWebhook, error) {
+	mp.mut.Lock()
+	defer mp.mut.Unlock()
+
+	order, ok := mp.orders[referenceId]
+	if !ok {
+		return entities.ItemWebhook{}, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// ParseWebhook also updates the stored order, so a test can move an order forward with a webhook.
+func (mp *mockProvider) ParseWebhook(header http.Header, body []byte) (entities.ItemWebhook, error) {
+	var order entities.ItemWebhook
+
+	if mp.webhookSecret != "" {
+		err := VerifyWebhook(mp.webhookSecret, header, body)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	err := json.Unmarshal(body, &order)
+	if err != nil || order.ReferenceId == "" {
+		return order, ErrInvalidWebhook
+	}
+
+	mp.mut.Lock()
+	defer mp.mut.Unlock()
+
+	if _, ok := mp.orders[order.ReferenceId]; ok {
+		mp.orders[order.ReferenceId] = order
+	}
+
+	return order, nil
+}
+
+func (mp *mockProvider) hasVariant(variantId int64) bool {
+	for _, item := range mp.items {
+		for _, variant := range item.ItemVariants {
+			if variant.Id == variantId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package fulfillment
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockProvider_OrderLifecycle(t *testing.T) {
+	t.Parallel()
+
+	provider := NewMockProvider("")
+
+	methods, err := provider.GetShippingMethods(context.Background(), InternationalShippingRegion, MockItemId)
+	require.Nil(t, err)
+	require.Equal(t, MockShippingMethod, methods["FR"].Methods[0].Method)
+
+	_, err = provider.CreateOrder(context.Background(), entities.DreamshipOrderItems{
+		LineItems: []entities.LineItem{{ItemVariant: 9999, Quantity: 1}},
+	})
+	require.ErrorIs(t, err, ErrProviderRejected)
+
+	created, err := provider.CreateOrder(context.Background(), entities.DreamshipOrderItems{
+		LineItems: []entities.LineItem{{ItemVariant: MockVariantId, Quantity: 2}},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "47.00", created.Cost)
+
+	_, err = provider.ParseWebhook(http.Header{}, []byte(`{"status":"shipped","reference_id":"`+created.ReferenceId+`"}`))
+	require.Nil(t, err)
+
+	order, err := provider.GetOrder(context.Background(), created.ReferenceId)
+	require.Nil(t, err)
+	require.Equal(t, "shipped", order.Status)
+}
@@ -0,0 +1,51 @@
+package fulfillment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of the body keyed with the webhook secret.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookSecretHeader carries the webhook secret itself, for providers that can not sign.
+	WebhookSecretHeader = "X-Webhook-Secret"
+
+	webhookSignaturePrefix = "sha256="
+)
+
+// VerifyWebhook accepts a body signed with the secret, or sent along with the secret.
+// Without a configured secret every webhook is refused.
+func VerifyWebhook(secret string, header http.Header, body []byte) error {
+	if secret == "" {
+		return ErrWebhookNotConfigured
+	}
+
+	signature := strings.TrimPrefix(header.Get(WebhookSignatureHeader), webhookSignaturePrefix)
+	if signature != "" {
+		expected := WebhookSignature(secret, body)
+		if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+			return ErrInvalidWebhookSignature
+		}
+
+		return nil
+	}
+
+	sharedSecret := header.Get(WebhookSecretHeader)
+	if sharedSecret == "" || subtle.ConstantTimeCompare([]byte(sharedSecret), []byte(secret)) != 1 {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
+
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package fulfillment
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"reference_id":"ref-1"}`)
+
+	require.Equal(t, ErrWebhookNotConfigured, VerifyWebhook("", http.Header{}, body))
+	require.Equal(t, ErrInvalidWebhookSignature, VerifyWebhook("secret", http.Header{}, body))
+
+	header := http.Header{}
+	header.Set(WebhookSignatureHeader, "sha256="+WebhookSignature("secret", body))
+	require.Nil(t, VerifyWebhook("secret", header, body))
+	require.Equal(t, ErrInvalidWebhookSignature, VerifyWebhook("secret", header, []byte(`{"reference_id":"ref-2"}`)))
+
+	header = http.Header{}
+	header.Set(WebhookSecretHeader, "secret")
+	require.Nil(t, VerifyWebhook("secret", header, body))
+
+	header.Set(WebhookSecretHeader, "other")
+	require.Equal(t, ErrInvalidWebhookSignature, VerifyWebhook("secret", header, body))
+}
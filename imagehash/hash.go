@@ -0,0 +1,191 @@
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+
+	// decoders of the formats token media come in
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// HashBits is the length of both hashes, distances range from 0 to HashBits.
+	HashBits = 64
+
+	// MaxImagePixels bounds what is decoded, checked on the header before decoding.
+	MaxImagePixels = 40000000
+
+	phashSize   = 32
+	phashLowDim = 8
+	dhashWidth  = 9
+	dhashHeight = 8
+)
+
+var ErrImageTooLarge = errors.New("image too large to hash")
+
+// Hashes are the perceptual hashes of an image. PHash survives resizing, compression and
+// small color changes, DHash catches the same picture with a shifted gradient structure.
+type Hashes struct {
+	PHash uint64
+	DHash uint64
+}
+
+// HashImageBytes decodes an image and computes its hashes. Only the first frame of an animation is hashed.
+func HashImageBytes(data []byte) (*Hashes, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return HashImage(img), nil
+}
+
+func HashImage(img image.Image) *Hashes {
+	return &Hashes{
+		PHash: PHash(img),
+		DHash: DHash(img),
+	}
+}
+
+// PHash thresholds the lowest frequencies of the DCT of a 32x32 grayscale image on their median.
+func PHash(img image.Image) uint64 {
+	pixels := grayscale(img, phashSize, phashSize)
+
+	coefficients := dct2d(pixels, phashSize)
+	lows := make([]float64, 0, phashLowDim*phashLowDim)
+	for y := 0; y < phashLowDim; y++ {
+		for x := 0; x < phashLowDim; x++ {
+			lows = append(lows, coefficients[y][x])
+		}
+	}
+
+	// the DC term is the average brightness, it would skew the median
+	median := medianOf(lows[1:])
+
+	var hash uint64
+	for i, value := range lows {
+		if value > median {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+// DHash compares each pixel of a 9x8 grayscale image with its right neighbour.
+func DHash(img image.Image) uint64 {
+	pixels := grayscale(img, dhashWidth, dhashHeight)
+
+	var hash uint64
+	bit := uint(0)
+	for y := 0; y < dhashHeight; y++ {
+		for x := 0; x < dhashWidth-1; x++ {
+			if pixels[y][x] < pixels[y][x+1] {
+				hash |= 1 << bit
+			}
+			bit++
+		}
+	}
+
+	return hash
+}
+
+// Distance is the number of differing bits of two hashes.
+func Distance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity maps the distance of two hashes to [0, 1], 1 being the same hash.
+func Similarity(a uint64, b uint64) float64 {
+	return 1 - float64(Distance(a, b))/HashBits
+}
+
+func grayscale(img image.Image, width int, height int) [][]float64 {
+	// transparent pixels are hashed over white, like most pages display them
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	small := image.NewGray(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), flat, flat.Bounds(), draw.Src, nil)
+
+	pixels := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		pixels[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			pixels[y][x] = float64(small.At(x, y).(color.Gray).Y)
+		}
+	}
+
+	return pixels
+}
+
+// dct2d is a separable DCT-II over the rows then the columns of a square matrix.
+func dct2d(pixels [][]float64, size int) [][]float64 {
+	cosines := make([][]float64, size)
+	for k := 0; k < size; k++ {
+		cosines[k] = make([]float64, size)
+		for n := 0; n < size; n++ {
+			cosines[k][n] = math.Cos(math.Pi / float64(size) * (float64(n) + 0.5) * float64(k))
+		}
+	}
+
+	rows := make([][]float64, size)
+	for y := 0; y < size; y++ {
+		rows[y] = make([]float64, size)
+		for k := 0; k < size; k++ {
+			sum := 0.0
+			for n := 0; n < size; n++ {
+				sum += pixels[y][n] * cosines[k][n]
+			}
+			rows[y][k] = sum
+		}
+	}
+
+	result := make([][]float64, size)
+	for k := 0; k < size; k++ {
+		result[k] = make([]float64, size)
+	}
+	for x := 0; x < size; x++ {
+		for k := 0; k < size; k++ {
+			sum := 0.0
+			for n := 0; n < size; n++ {
+				sum += rows[n][x] * cosines[k][n]
+			}
+			result[k][x] = sum
+		}
+	}
+
+	return result
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
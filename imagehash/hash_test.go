@@ -0,0 +1,90 @@
+package imagehash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/draw"
+)
+
+func testPattern(width int, height int, seed int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx := x * 255 / width
+			fy := y * 255 / height
+			switch seed {
+			case 0:
+				img.Set(x, y, color.RGBA{R: uint8(fx), G: uint8(fy), B: uint8((fx + fy) / 2), A: 255})
+			default:
+				if (x*8/width+y*8/height)%2 == 0 {
+					img.Set(x, y, color.RGBA{R: 240, G: 30, B: 30, A: 255})
+				} else {
+					img.Set(x, y, color.RGBA{R: 20, G: 20, B: 200, A: 255})
+				}
+			}
+		}
+	}
+
+	// a blob off center, so the hashes are not symmetric
+	for y := height / 5; y < height/2; y++ {
+		for x := width / 6; x < width/3; x++ {
+			img.Set(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestHashes_SurviveResizeAndCompression(t *testing.T) {
+	t.Parallel()
+
+	original := testPattern(400, 400, 0)
+
+	resized := image.NewRGBA(image.Rect(0, 0, 173, 173))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), original, original.Bounds(), draw.Src, nil)
+	var compressed bytes.Buffer
+	require.Nil(t, jpeg.Encode(&compressed, resized, &jpeg.Options{Quality: 40}))
+
+	copied, err := HashImageBytes(compressed.Bytes())
+	require.Nil(t, err)
+
+	hashes := HashImage(original)
+	require.LessOrEqual(t, Distance(hashes.PHash, copied.PHash), 6)
+	require.LessOrEqual(t, Distance(hashes.DHash, copied.DHash), 6)
+}
+
+func TestHashes_DifferentImages(t *testing.T) {
+	t.Parallel()
+
+	first := HashImage(testPattern(300, 300, 0))
+	second := HashImage(testPattern(300, 300, 1))
+
+	require.Greater(t, Distance(first.PHash, second.PHash), 12)
+	require.Greater(t, Distance(first.DHash, second.DHash), 12)
+}
+
+func TestHashImageBytes_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := HashImageBytes([]byte("not an image"))
+	require.NotNil(t, err)
+
+	var encoded bytes.Buffer
+	require.Nil(t, png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8000, 6000))))
+	_, err = HashImageBytes(encoded.Bytes())
+	require.Equal(t, ErrImageTooLarge, err)
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 1.0, Similarity(0xF0F0, 0xF0F0))
+	require.Equal(t, 0.0, Similarity(0, ^uint64(0)))
+	require.Equal(t, 0.75, Similarity(0, 0xFFFF))
+}
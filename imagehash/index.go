@@ -0,0 +1,77 @@
+package imagehash
+
+// Index is a BK-tree over hashes, it finds every hash within a distance without comparing them all.
+// Values are what the caller attached to a hash, several values may share one.
+type Index struct {
+	root *indexNode
+	size int
+}
+
+type indexNode struct {
+	hash     uint64
+	values   []int
+	children map[int]*indexNode
+}
+
+func NewIndex() *Index {
+	return &Index{}
+}
+
+func (idx *Index) Len() int {
+	return idx.size
+}
+
+func (idx *Index) Add(hash uint64, value int) {
+	idx.size++
+	if idx.root == nil {
+		idx.root = &indexNode{hash: hash, values: []int{value}}
+		return
+	}
+
+	node := idx.root
+	for {
+		distance := Distance(node.hash, hash)
+		if distance == 0 {
+			node.values = append(node.values, value)
+			return
+		}
+
+		child, ok := node.children[distance]
+		if !ok {
+			if node.children == nil {
+				node.children = map[int]*indexNode{}
+			}
+			node.children[distance] = &indexNode{hash: hash, values: []int{value}}
+			return
+		}
+
+		node = child
+	}
+}
+
+// Search calls visit with every value whose hash is at most maxDistance away from hash.
+func (idx *Index) Search(hash uint64, maxDistance int, visit func(value int, distance int)) {
+	if idx.root == nil {
+		return
+	}
+
+	pending := []*indexNode{idx.root}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		distance := Distance(node.hash, hash)
+		if distance <= maxDistance {
+			for _, value := range node.values {
+				visit(value, distance)
+			}
+		}
+
+		// by the triangle inequality only children at distance-maxDistance..distance+maxDistance can match
+		for childDistance, child := range node.children {
+			if childDistance >= distance-maxDistance && childDistance <= distance+maxDistance {
+				pending = append(pending, child)
+			}
+		}
+	}
+}
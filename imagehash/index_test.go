@@ -0,0 +1,64 @@
+package imagehash
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex_SearchMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewSource(7))
+	hashes := make([]uint64, 2000)
+	idx := NewIndex()
+	for i := range hashes {
+		hashes[i] = random.Uint64()
+		// near copies of earlier hashes, like the tokens of a copymint
+		if i%3 == 0 && i > 0 {
+			hashes[i] = hashes[i-1] ^ (1 << uint(random.Intn(HashBits)))
+		}
+		idx.Add(hashes[i], i)
+	}
+	require.Equal(t, len(hashes), idx.Len())
+
+	for query := 0; query < 50; query++ {
+		probe := hashes[random.Intn(len(hashes))] ^ (1 << uint(random.Intn(HashBits)))
+
+		var found []int
+		idx.Search(probe, 10, func(value int, distance int) {
+			require.Equal(t, Distance(hashes[value], probe), distance)
+			found = append(found, value)
+		})
+
+		var expected []int
+		for i, hash := range hashes {
+			if Distance(hash, probe) <= 10 {
+				expected = append(expected, i)
+			}
+		}
+
+		sort.Ints(found)
+		require.Equal(t, expected, found)
+	}
+}
+
+func TestIndex_DuplicateHashes(t *testing.T) {
+	t.Parallel()
+
+	idx := NewIndex()
+	idx.Add(42, 1)
+	idx.Add(42, 2)
+
+	var found []int
+	idx.Search(42, 0, func(value int, _ int) {
+		found = append(found, value)
+	})
+	require.Equal(t, []int{1, 2}, found)
+
+	NewIndex().Search(42, 64, func(_ int, _ int) {
+		t.Fatal("empty index matched")
+	})
+}
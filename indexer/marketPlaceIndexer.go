@@ -14,6 +14,7 @@ import (
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/emurmotol/ethconv"
@@ -270,6 +271,15 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 					if err != nil {
 						lerr.Println(err.Error())
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventListing,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						AmountNominal: token.PriceNominal,
+						AmountString:  token.PriceString,
+					})
 				} else if actions["isOffer"] && !failedTx {
 					toUpdate = false
 					offerStr := mainDataParts[3]
@@ -303,6 +313,15 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 					if err != nil {
 						lerr.Println(err.Error())
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventOffer,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						AmountNominal: offerNominal,
+						AmountString:  offer.String(),
+					})
 				} else if actions["isAcceptOffer"] && !failedTx {
 					toUpdate = true
 					offerorAddrHex := mainDataParts[3]
@@ -365,6 +384,16 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 						lerr.Println("REPEAT", err.Error())
 						goto txloop
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventSale,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						ToAddress:     offerorAddrStr,
+						AmountNominal: token.PriceNominal,
+						AmountString:  token.PriceString,
+					})
 				} else if actions["isCancelOffer"] && !failedTx {
 					toUpdate = false
 					err := storage.DeleteOfferByOfferorForTokenId(senderAdress, token.ID)
@@ -372,6 +401,13 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 						lerr.Println("REPEAT", err.Error())
 						goto txloop
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:     token.ID,
+						Kind:        entities.TokenEventOfferCancel,
+						TxHash:      orgTx.TxHash,
+						Timestamp:   orgTx.Timestamp,
+						FromAddress: senderAdress,
+					})
 				} else if actions["isOnAuction"] && strings.Contains(string(data), "startAuction") && !failedTx {
 					toUpdate = true
 					fmt.Println("is_on_auction", dataParts)
@@ -414,6 +450,15 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 						lerr.Println(err.Error())
 
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventAuctionStart,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						AmountNominal: token.PriceNominal,
+						AmountString:  token.PriceString,
+					})
 				} else if actions["isWithdrawn"] && !failedTx {
 					toUpdate = true
 					token.OnSale = false
@@ -431,6 +476,13 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 					if err != nil {
 						lerr.Println(err.Error())
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:     token.ID,
+						Kind:        entities.TokenEventDelisting,
+						TxHash:      orgTx.TxHash,
+						Timestamp:   orgTx.Timestamp,
+						FromAddress: senderAdress,
+					})
 				} else if actions["isBuyNft"] && strings.Contains(string(data), "Seller") && !failedTx {
 					toUpdate = true
 					token.OnSale = false
@@ -474,6 +526,16 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 					if err != nil {
 						lerr.Println(err.Error())
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventSale,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   user.Address,
+						ToAddress:     senderAdress,
+						AmountNominal: token.PriceNominal,
+						AmountString:  token.PriceString,
+					})
 				} else if actions["isBid"] && !failedTx {
 					toUpdate = true
 					bidStr := mainDataParts[3]
@@ -495,6 +557,15 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 						lerr.Println("REPEAT", err.Error())
 						goto txloop
 					}
+					services.RecordTokenEvent(entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventBid,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						AmountNominal: bidNominal,
+						AmountString:  bid.String(),
+					})
 				} else if actions["isEndAuction"] && strings.Contains(string(data), "ESDTNFTTransfer") && !failedTx {
 					toUpdate = true
 					token.OnSale = false
@@ -535,6 +606,21 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 					if err != nil {
 						lerr.Println(err.Error())
 					}
+					auctionEnd := entities.TokenEvent{
+						TokenID:       token.ID,
+						Kind:          entities.TokenEventAuctionEnd,
+						TxHash:        orgTx.TxHash,
+						Timestamp:     orgTx.Timestamp,
+						FromAddress:   senderAdress,
+						ToAddress:     user.Address,
+						AmountNominal: token.PriceNominal,
+						AmountString:  token.PriceString,
+					}
+					services.RecordTokenEvent(auctionEnd)
+					if typeOfTx == entities.BuyToken {
+						auctionEnd.Kind = entities.TokenEventSale
+						services.RecordTokenEvent(auctionEnd)
+					}
 				}
 				if token.LastMarketTimestamp <= txTimestamp && toUpdate && !failedTx {
 					token.LastMarketTimestamp = txTimestamp
@@ -557,6 +643,10 @@ func (mpi *MarketPlaceIndexer) StartWorker() {
 						lerr.Println(err.Error(), "MAINLOOP", "error updating token ", fmt.Sprintf("tokenID %d", token.ID))
 						goto mainLoop
 					}
+					err = collstats.UpdateFloorPriceForCollection(token.CollectionID)
+					if err != nil {
+						lerr.Println("could not refresh floor price", err.Error())
+					}
 				}
 				lastProcessed = tx
 			}
@@ -0,0 +1,112 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const mintReconcilePeriod = 10 * time.Minute
+
+// after is inclusive, so the transactions already indexed at the cursor timestamp are skipped with from.
+var getMintTransactionsAPI = "%s/accounts/%s/transactions?after=%d&from=%d&size=50&order=asc&withScResults=true"
+
+// MintIndexer follows the transactions of every minter contract and keeps the mint progress of the collections.
+// Every mintReconcilePeriod the progress is checked against the contract view.
+type MintIndexer struct {
+	ElrondAPI     string `json:"elrondApi"`
+	ElrondAPISec  string `json:"elrondApiSec"`
+	Logger        *log.Logger
+	Delay         time.Duration // delay between each pass in second
+	lastReconcile time.Time
+}
+
+func NewMintIndexer(elrondAPI string, elrondAPISec string, delay uint64) (*MintIndexer, error) {
+	l := log.New(os.Stderr, "", log.LUTC|log.LstdFlags|log.Lshortfile)
+	return &MintIndexer{
+		ElrondAPI:    elrondAPI,
+		ElrondAPISec: elrondAPISec,
+		Delay:        time.Duration(delay),
+		Logger:       l}, nil
+}
+
+func (mi *MintIndexer) StartWorker() {
+	api := mi.ElrondAPI
+	if mi.ElrondAPISec != "" {
+		api = mi.ElrondAPISec
+	}
+
+	for {
+		time.Sleep(time.Second * mi.Delay)
+
+		cols, err := storage.GetAllCollections()
+		if err != nil {
+			mi.Logger.Println(err.Error())
+			continue
+		}
+
+		shouldReconcile := time.Since(mi.lastReconcile) > mintReconcilePeriod
+		for index := range cols {
+			colObj := &cols[index]
+			if colObj.ContractAddress == "" {
+				continue
+			}
+
+			mi.indexCollection(colObj, api)
+
+			if shouldReconcile {
+				_, err = services.ReconcileCollectionMintProgress(colObj)
+				if err != nil {
+					zlog.Error("error reconcile mint progress", zap.String("collection", colObj.CollectionTokenID), zap.Error(err))
+				}
+			}
+		}
+
+		if shouldReconcile {
+			mi.lastReconcile = time.Now()
+		}
+	}
+}
+
+func (mi *MintIndexer) indexCollection(colObj *entities.Collection, api string) {
+	var lastTimestamp, lastTimestampTxs uint64
+	progress, err := storage.GetCollectionMintProgress(colObj.ID)
+	if err == nil {
+		lastTimestamp = progress.LastTimestamp
+		lastTimestampTxs = progress.LastTimestampTxs
+	} else if err != gorm.ErrRecordNotFound {
+		zlog.Error("error getting mint progress", zap.Error(err))
+		return
+	}
+
+	url := fmt.Sprintf(getMintTransactionsAPI, api, colObj.ContractAddress, lastTimestamp, lastTimestampTxs)
+	res, err := services.GetResponse(url)
+	if err != nil {
+		mi.Logger.Println(err.Error())
+		mi.Logger.Println(url)
+		return
+	}
+
+	var txs []entities.TransactionBC
+	err = json.Unmarshal(res, &txs)
+	if err != nil {
+		mi.Logger.Println("error unmarshal mint transactions", err.Error())
+		return
+	}
+	if len(txs) == 0 {
+		return
+	}
+
+	_, err = services.IndexMintTransactions(colObj, txs, lastTimestamp, lastTimestampTxs)
+	if err != nil {
+		zlog.Error("error index mint transactions", zap.String("collection", colObj.CollectionTokenID), zap.Error(err))
+	}
+}
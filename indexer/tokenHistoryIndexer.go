@@ -0,0 +1,56 @@
+package indexer
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+)
+
+// tokenHistoryBatchSize is how many pending tokens get a page of history on every pass.
+const tokenHistoryBatchSize = 20
+
+// TokenHistoryIndexer reads the transactions of the tokens queued with services.RequestTokenHistory,
+// one page per token and pass, so newly indexed collections don't hold up the other indexers.
+type TokenHistoryIndexer struct {
+	ElrondAPI    string `json:"elrondApi"`
+	ElrondAPISec string `json:"elrondApiSec"`
+	Logger       *log.Logger
+	Delay        time.Duration // delay between each pass in second
+}
+
+func NewTokenHistoryIndexer(elrondAPI string, elrondAPISec string, delay uint64) (*TokenHistoryIndexer, error) {
+	l := log.New(os.Stderr, "", log.LUTC|log.LstdFlags|log.Lshortfile)
+	return &TokenHistoryIndexer{
+		ElrondAPI:    elrondAPI,
+		ElrondAPISec: elrondAPISec,
+		Delay:        time.Duration(delay),
+		Logger:       l}, nil
+}
+
+func (thi *TokenHistoryIndexer) StartWorker() {
+	api := thi.ElrondAPI
+	if thi.ElrondAPISec != "" {
+		api = thi.ElrondAPISec
+	}
+
+	for {
+		time.Sleep(time.Second * thi.Delay)
+
+		cursors, err := storage.GetPendingTokenHistoryCursors(tokenHistoryBatchSize)
+		if err != nil {
+			thi.Logger.Println(err.Error())
+			continue
+		}
+
+		for index := range cursors {
+			err = services.IndexTokenHistory(&cursors[index], api)
+			if err != nil {
+				zlog.Error("error index token history", zap.Uint64("token", cursors[index].TokenID), zap.Error(err))
+			}
+		}
+	}
+}
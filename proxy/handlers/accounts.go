@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -12,17 +13,23 @@ import (
 	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 const (
-	baseAccountsEndpoint        = "/accounts"
-	accountByIdEndpoint         = "/:walletAddress"
-	accountTokensEndpoint       = "/:walletAddress/tokens"
-	accountTokensOnSaleEndpoint = "/:walletAddress/tokens/onsale"
-	accountCollectionsEndpoint  = "/:walletAddress/collections/:offset/:limit"
-	accountProfileEndpoint      = "/:walletAddress/profile"
-	accountCoverEndpoint        = "/:walletAddress/cover"
-	imageEndpoint               = "/image/:filename"
+	baseAccountsEndpoint         = "/accounts"
+	accountByIdEndpoint          = "/:walletAddress"
+	accountTokensEndpoint        = "/:walletAddress/tokens"
+	accountTokensOnSaleEndpoint  = "/:walletAddress/tokens/onsale"
+	accountCollectionsEndpoint   = "/:walletAddress/collections/:offset/:limit"
+	accountProfileEndpoint       = "/:walletAddress/profile"
+	accountCoverEndpoint         = "/:walletAddress/cover"
+	accountRoleEndpoint          = "/:walletAddress/role"
+	accountImageUploadEndpoint   = "/:walletAddress/images/upload"
+	accountImageCompleteEndpoint = "/:walletAddress/images/upload/complete"
+	accountNotificationsEndpoint = "/:walletAddress/notifications/:offset/:limit"
+	accountMarkReadEndpoint      = "/:walletAddress/notifications/read"
+	imageEndpoint                = "/image/:filename"
 )
 
 type accountsHandler struct {
@@ -35,6 +42,11 @@ func NewAccountsHandler(groupHandler *groupHandler, authCfg config.AuthConfig) {
 		{Method: http.MethodPost, Path: accountByIdEndpoint, HandlerFunc: handler.set},
 		{Method: http.MethodPost, Path: accountProfileEndpoint, HandlerFunc: handler.setAccountProfile},
 		{Method: http.MethodPost, Path: accountCoverEndpoint, HandlerFunc: handler.setAccountCover},
+		{Method: http.MethodPost, Path: accountImageUploadEndpoint, HandlerFunc: handler.presignAccountImage},
+		{Method: http.MethodPost, Path: accountImageCompleteEndpoint, HandlerFunc: handler.completeAccountImage},
+		{Method: http.MethodPost, Path: accountRoleEndpoint, HandlerFunc: handler.setAccountRole, Permission: services.PermissionManageRoles},
+		{Method: http.MethodGet, Path: accountNotificationsEndpoint, HandlerFunc: handler.getNotifications},
+		{Method: http.MethodPost, Path: accountMarkReadEndpoint, HandlerFunc: handler.readNotifications},
 	}
 	endpointGroupHandler := EndpointGroupHandler{
 		Root:             baseAccountsEndpoint,
@@ -95,6 +107,7 @@ func (h *accountsHandler) get(c *gin.Context) {
 // @Success 200 {object} entities.Account
 // @Failure 400 {object} dtos.ApiResponse
 // @Failure 401 {object} dtos.ApiResponse
+// @Failure 403 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
 // @Router /accounts/{walletAddress} [post]
 func (h *accountsHandler) set(c *gin.Context) {
@@ -126,6 +139,10 @@ func (h *accountsHandler) set(c *gin.Context) {
 		innerErr = services.UpdateAccount(account, &request)
 	}
 
+	if errors.Is(innerErr, services.ErrAccountBanned) {
+		dtos.JsonResponse(c, http.StatusForbidden, nil, innerErr.Error())
+		return
+	}
 	if innerErr != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, innerErr.Error())
 		return
@@ -134,14 +151,62 @@ func (h *accountsHandler) set(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, account, "")
 }
 
+// @Summary Set account role
+// @Description Sets the global role of an account (user, moderator, support or admin) and signs it out of every device. Restricted to admins.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param walletAddress path string true "wallet address"
+// @Param setAccountRoleRequest body services.SetAccountRoleRequest true "account role"
+// @Success 200 {object} string
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /accounts/{walletAddress}/role [post]
+func (h *accountsHandler) setAccountRole(c *gin.Context) {
+	var request services.SetAccountRoleRequest
+	walletAddress := c.Param("walletAddress")
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	account, err := storage.GetAccountByAddress(walletAddress)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	err = services.SetAccountRole(walletAddress, &request)
+	if err == services.ErrUnknownAccountRole {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditAccountRole, services.AuditTargetAccount, walletAddress,
+		services.SetAccountRoleRequest{Role: account.Role}, request)
+	dtos.JsonResponse(c, http.StatusOK, "", "")
+}
+
 // @Summary Set account profile image
-// @Description Expects base64 std encoding of the image representation. Returns empty string. Max size of byte array is 512KB.
+// @Description Expects base64 std encoding of the image representation. Returns the image variants. Max size of byte array is 512KB.
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param walletAddress path string true "wallet address"
 // @Param image body string true "base64 encoded image"
-// @Success 200 {object} string
+// @Success 200 {object} cdn.ImageVariants
 // @Failure 400 {object} dtos.ApiResponse
 // @Failure 401 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
@@ -169,23 +234,23 @@ func (h *accountsHandler) setAccountProfile(c *gin.Context) {
 		return
 	}
 
-	link, err := services.SetAccountProfileImage(walletAddress, cacheInfo.AccountId, &imageBase64)
+	variants, err := services.SetAccountProfileImage(walletAddress, cacheInfo.AccountId, &imageBase64)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, link, "")
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
 }
 
 // @Summary Set account cover image
-// @Description Expects base64 std encoding of the image representation. Returns empty string. Max size of byte array is 1MB.
+// @Description Expects base64 std encoding of the image representation. Returns the image variants. Max size of byte array is 1MB.
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param walletAddress path string true "wallet address"
 // @Param image body string true "base64 encoded image"
-// @Success 200 {object} string
+// @Success 200 {object} cdn.ImageVariants
 // @Failure 400 {object} dtos.ApiResponse
 // @Failure 401 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
@@ -213,13 +278,96 @@ func (h *accountsHandler) setAccountCover(c *gin.Context) {
 		return
 	}
 
-	link, err := services.SetAccountCoverImage(walletAddress, cacheInfo.AccountId, &imageBase64)
+	variants, err := services.SetAccountCoverImage(walletAddress, cacheInfo.AccountId, &imageBase64)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, link, "")
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
+}
+
+// @Summary Presign an account image upload
+// @Description Returns a url to upload a profile or cover image straight to the store, then complete it with the returned key. Only available with direct upload stores.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param walletAddress path string true "wallet address"
+// @Param request body services.ImageUploadRequest true "image kind and content type"
+// @Success 200 {object} cdn.PresignedUpload
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Failure 501 {object} dtos.ApiResponse
+// @Router /accounts/{walletAddress}/images/upload [post]
+func (h *accountsHandler) presignAccountImage(c *gin.Context) {
+	var request services.ImageUploadRequest
+	walletAddress := c.Param("walletAddress")
+
+	jwtAddress := c.GetString(middleware.AddressKey)
+	if jwtAddress != walletAddress {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+		return
+	}
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	upload, err := services.PresignAccountImageUpload(walletAddress, request)
+	if err != nil {
+		dtos.JsonResponse(c, imageUploadErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, upload, "")
+}
+
+// @Summary Complete an account image upload
+// @Description Processes an image uploaded to a presigned url and sets it as the account profile or cover. Returns the image variants.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param walletAddress path string true "wallet address"
+// @Param request body services.ImageUploadCompleteRequest true "image kind and upload key"
+// @Success 200 {object} cdn.ImageVariants
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Failure 501 {object} dtos.ApiResponse
+// @Router /accounts/{walletAddress}/images/upload/complete [post]
+func (h *accountsHandler) completeAccountImage(c *gin.Context) {
+	var request services.ImageUploadCompleteRequest
+	walletAddress := c.Param("walletAddress")
+
+	jwtAddress := c.GetString(middleware.AddressKey)
+	if jwtAddress != walletAddress {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+		return
+	}
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	cacheInfo, err := services.GetOrAddAccountCacheInfo(walletAddress)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	variants, err := services.CompleteAccountImageUpload(walletAddress, cacheInfo.AccountId, request)
+	if err != nil {
+		dtos.JsonResponse(c, imageUploadErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
 }
 
 // @Summary Gets tokens for an account.
@@ -382,3 +530,89 @@ func (h *accountsHandler) getAccountCollections(c *gin.Context) {
 
 	dtos.JsonResponse(c, http.StatusOK, collections, "")
 }
+
+// @Summary Get the notifications of an account
+// @Description Notifications newest first, with how many are unread. Only the account itself can read them.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param walletAddress path string true "wallet address"
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Success 200 {object} services.Notifications
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /accounts/{walletAddress}/notifications/{offset}/{limit} [get]
+func (h *accountsHandler) getNotifications(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+
+	jwtAddress := c.GetString(middleware.AddressKey)
+	if walletAddress != jwtAddress {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "unauthorized")
+		return
+	}
+
+	offset, err := strconv.ParseUint(c.Param("offset"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(c.Param("limit"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	notifications, err := services.GetNotifications(walletAddress, int(offset), int(limit))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, notifications, "")
+}
+
+// @Summary Mark notifications as read
+// @Description Marks the listed notifications of the account as read, or all of them when none is listed. Returns how many were marked.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param walletAddress path string true "wallet address"
+// @Param request body services.MarkNotificationsReadRequest true "notification ids"
+// @Success 200 {object} int64
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /accounts/{walletAddress}/notifications/read [post]
+func (h *accountsHandler) readNotifications(c *gin.Context) {
+	var request services.MarkNotificationsReadRequest
+	walletAddress := c.Param("walletAddress")
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	jwtAddress := c.GetString(middleware.AddressKey)
+	if walletAddress != jwtAddress {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "unauthorized")
+		return
+	}
+
+	marked, err := services.MarkNotificationsRead(walletAddress, &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, marked, "")
+}
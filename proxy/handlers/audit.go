@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	baseAuditEndpoint   = "/audit"
+	auditLogsEndpoint   = "/logs/:offset/:limit"
+	auditExportEndpoint = "/export"
+)
+
+type auditHandler struct {
+}
+
+func NewAuditHandler(groupHandler *groupHandler, authCfg config.AuthConfig) {
+	handler := &auditHandler{}
+
+	endpoints := []EndpointHandler{
+		{Method: http.MethodGet, Path: auditLogsEndpoint, HandlerFunc: handler.getLogs, Permission: services.PermissionAuditRead},
+		{Method: http.MethodGet, Path: auditExportEndpoint, HandlerFunc: handler.exportLogs, Permission: services.PermissionAuditRead},
+	}
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseAuditEndpoint,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: endpoints,
+	}
+	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+}
+
+// @Summary Query the audit log.
+// @Description Lists privileged and creator actions, newest first. Every filter is optional, from and to are unix seconds. Limit is capped at 100. Admin only.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param actor query string false "actor address"
+// @Param action query string false "action, e.g. collection.adminSection"
+// @Param targetType query string false "target type, collection or account"
+// @Param targetId query string false "target id"
+// @Param from query uint false "from unix seconds"
+// @Param to query uint false "to unix seconds"
+// @Success 200 {object} services.AuditLogs
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /audit/logs/{offset}/{limit} [get]
+func (handler *auditHandler) getLogs(c *gin.Context) {
+	offset, err := strconv.Atoi(c.Param("offset"))
+	if err != nil || offset < 0 {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "invalid offset")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Param("limit"))
+	if err != nil || limit < 0 {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "invalid limit")
+		return
+	}
+
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	logs, err := services.GetAuditLogs(filter, offset, limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, logs, "")
+}
+
+// @Summary Export the audit log.
+// @Description Exports the matching audit entries as a csv file, newest first. Takes the same filters as the query endpoint. Admin only.
+// @Tags audit
+// @Produce text/csv
+// @Param actor query string false "actor address"
+// @Param action query string false "action, e.g. collection.adminSection"
+// @Param targetType query string false "target type, collection or account"
+// @Param targetId query string false "target id"
+// @Param from query uint false "from unix seconds"
+// @Param to query uint false "to unix seconds"
+// @Success 200 {file} file
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /audit/export [get]
+func (handler *auditHandler) exportLogs(c *gin.Context) {
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	buff, err := services.ExportAuditLogsCsv(filter)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.ContentAsFileResponse(c, fmt.Sprintf("audit-%d.csv", time.Now().Unix()), buff)
+}
+
+func auditLogFilter(c *gin.Context) (storage.AuditLogFilter, error) {
+	filter := storage.AuditLogFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("targetType"),
+		TargetId:   c.Query("targetId"),
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		filter.From, err = strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		filter.To, err = strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	return filter, nil
+}
+
+// auditActor is the caller of an audited endpoint, as the jwt and request id middlewares set it.
+func auditActor(c *gin.Context) services.AuditActor {
+	return services.AuditActor{
+		Address:   c.GetString(middleware.AddressKey),
+		RequestId: c.GetString(middleware.RequestIdKey),
+	}
+}
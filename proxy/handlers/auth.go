@@ -3,23 +3,42 @@ package handlers
 import (
 	"encoding/hex"
 	"net/http"
+	"time"
 
+	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
 	"github.com/ENFT-DAO/youbei-api/services"
 	erdgoData "github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	baseAuthEndpoint    = "/auth"
-	accessAuthEndpoint  = "/access"
-	refreshAuthEndpoint = "/refresh"
+	baseAuthEndpoint      = "/auth"
+	challengeAuthEndpoint = "/challenge"
+	accessAuthEndpoint    = "/access"
+	refreshAuthEndpoint   = "/refresh"
+	logoutAuthEndpoint    = "/logout"
+	logoutAllAuthEndpoint = "/logout-all"
+	sessionsAuthEndpoint  = "/sessions"
 )
 
+type createChallengeRequest struct {
+	Address string `json:"address"`
+	// Origin defaults to the Origin header of the request.
+	Origin string `json:"origin"`
+}
+
 type createTokenRequest struct {
 	Address   string `json:"address"`
 	Signature string `json:"signature"`
-	Message   string `json:"message"`
+	// Token is the token of the challenge, signed by the wallet as address+token.
+	Token string `json:"token"`
+}
+
+type logoutRequest struct {
+	// SessionIds defaults to the session of the caller.
+	SessionIds []uint64 `json:"sessionIds"`
 }
 
 type tokenPayload struct {
@@ -31,12 +50,13 @@ type authHandler struct {
 	service services.AuthService
 }
 
-func NewAuthHandler(groupHandler *groupHandler, authService services.AuthService) {
+func NewAuthHandler(groupHandler *groupHandler, authService services.AuthService, authCfg config.AuthConfig) {
 	h := authHandler{
 		service: authService,
 	}
 
 	endpoints := []EndpointHandler{
+		{Method: http.MethodPost, Path: challengeAuthEndpoint, HandlerFunc: h.createChallenge},
 		{Method: http.MethodPost, Path: accessAuthEndpoint, HandlerFunc: h.createAccessToken},
 		{Method: http.MethodPost, Path: refreshAuthEndpoint, HandlerFunc: h.refreshAccessToken},
 	}
@@ -48,10 +68,67 @@ func NewAuthHandler(groupHandler *groupHandler, authService services.AuthService
 	}
 
 	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+
+	sessionEndpoints := []EndpointHandler{
+		{Method: http.MethodPost, Path: logoutAuthEndpoint, HandlerFunc: h.logout},
+		{Method: http.MethodPost, Path: logoutAllAuthEndpoint, HandlerFunc: h.logoutAll},
+		{Method: http.MethodGet, Path: sessionsAuthEndpoint, HandlerFunc: h.getSessions},
+	}
+
+	sessionGroupHandler := EndpointGroupHandler{
+		Root:             baseAuthEndpoint,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: sessionEndpoints,
+	}
+
+	groupHandler.AddEndpointGroupHandler(sessionGroupHandler)
+}
+
+// @Summary Login challenge
+// @Description Issues a single use login challenge for an address. Its token must be signed by the wallet as address+token and sent to /auth/access before it expires.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param challengeRequest body createChallengeRequest true "create challenge request"
+// @Success 200 {object} services.LoginChallenge
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /auth/challenge [post]
+func (h *authHandler) createChallenge(c *gin.Context) {
+	req := createChallengeRequest{}
+
+	err := c.Bind(&req)
+	if err != nil {
+		h.badReqResp(c, err.Error())
+		return
+	}
+
+	pk, err := erdgoData.NewAddressFromBech32String(req.Address)
+	if err != nil {
+		h.badReqResp(c, err.Error())
+		return
+	}
+
+	origin := req.Origin
+	if origin == "" {
+		origin = c.GetHeader("Origin")
+	}
+
+	challenge, err := h.service.CreateChallenge(pk.AddressAsBech32String(), origin, time.Now())
+	if err == services.ErrChallengeOrigin {
+		h.badReqResp(c, err.Error())
+		return
+	}
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, challenge, "")
 }
 
 // @Summary Access credentials
-// @Description Creates an access credentials
+// @Description Creates an access credentials from a signed login challenge. A challenge can only be used once.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -81,15 +158,13 @@ func (h *authHandler) createAccessToken(c *gin.Context) {
 		return
 	}
 
-	msgBytes, err := hex.DecodeString(req.Message)
-	if err != nil {
-		h.badReqResp(c, err.Error())
-		return
+	device := services.AuthDevice{
+		UserAgent: c.GetHeader("User-Agent"),
+		Ip:        c.ClientIP(),
 	}
-
-	jwt, refresh, err := h.service.CreateToken(pk.AddressBytes(), sigBytes, msgBytes)
+	jwt, refresh, err := h.service.CreateToken(pk.AddressBytes(), sigBytes, req.Token, device, time.Now())
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, err.Error())
 		return
 	}
 
@@ -100,7 +175,7 @@ func (h *authHandler) createAccessToken(c *gin.Context) {
 }
 
 // @Summary Refresh credentials
-// @Description Refreshes the access credentials
+// @Description Refreshes expired access credentials. The refresh token is rotated, reusing an old one revokes the session.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -118,9 +193,9 @@ func (h *authHandler) refreshAccessToken(c *gin.Context) {
 		return
 	}
 
-	jwt, refresh, err := h.service.RefreshToken(req.AccessToken, req.RefreshToken)
+	jwt, refresh, err := h.service.RefreshToken(req.AccessToken, req.RefreshToken, time.Now())
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, err.Error())
 		return
 	}
 
@@ -130,6 +205,80 @@ func (h *authHandler) refreshAccessToken(c *gin.Context) {
 	}, "")
 }
 
+// @Summary Logout
+// @Description Revokes the given sessions of the caller, or the current one when none is given.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param logoutRequest body logoutRequest false "sessions to revoke"
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /auth/logout [post]
+func (h *authHandler) logout(c *gin.Context) {
+	req := logoutRequest{}
+
+	if c.Request.ContentLength > 0 {
+		err := c.Bind(&req)
+		if err != nil {
+			h.badReqResp(c, err.Error())
+			return
+		}
+	}
+
+	sessionIds := req.SessionIds
+	if len(sessionIds) == 0 {
+		sessionIds = []uint64{c.GetUint64(middleware.SessionIdKey)}
+	}
+
+	err := h.service.Logout(c.GetString(middleware.AddressKey), sessionIds, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, nil, "")
+}
+
+// @Summary Logout everywhere
+// @Description Revokes every session of the caller, including the current one.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /auth/logout-all [post]
+func (h *authHandler) logoutAll(c *gin.Context) {
+	err := h.service.LogoutAll(c.GetString(middleware.AddressKey), time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, nil, "")
+}
+
+// @Summary Active sessions
+// @Description Lists the active sessions of the caller, one per logged in device.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} []services.AuthSessionInfo
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /auth/sessions [get]
+func (h *authHandler) getSessions(c *gin.Context) {
+	sessions, err := h.service.GetSessions(c.GetString(middleware.AddressKey), c.GetUint64(middleware.SessionIdKey), time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, sessions, "")
+}
+
 func (h *authHandler) badReqResp(c *gin.Context, err string) {
 	dtos.JsonResponse(c, http.StatusBadRequest, nil, err)
 }
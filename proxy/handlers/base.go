@@ -1,6 +1,10 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/gin-gonic/gin"
+)
 
 type EndpointGroupHandler struct {
 	Root             string
@@ -12,6 +16,8 @@ type EndpointHandler struct {
 	Path        string
 	Method      string
 	HandlerFunc gin.HandlerFunc
+	// Permission is checked before the handler runs, the group must authorize the caller.
+	Permission services.Permission
 }
 
 type groupHandler struct {
@@ -30,6 +36,10 @@ func (g *groupHandler) RegisterEndpoints(r *gin.Engine) {
 			routerGroup := r.Group(groupRoot).Use(handlersGroup.Middlewares...)
 			{
 				for _, h := range handlersGroup.EndpointHandlers {
+					if h.Permission != "" {
+						routerGroup.Handle(h.Method, h.Path, middleware.RequirePermission(h.Permission), h.HandlerFunc)
+						continue
+					}
 					routerGroup.Handle(h.Method, h.Path, h.HandlerFunc)
 				}
 			}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
@@ -17,6 +19,7 @@ import (
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type RankingEntry = collstats.LeaderboardEntry
@@ -29,18 +32,37 @@ const (
 	collectionTokensEndpoint                  = "/:collectionId/tokens/:offset/:limit"
 	collectionProfileEndpoint                 = "/:collectionId/profile"
 	collectionCoverEndpoint                   = "/:collectionId/cover"
+	collectionImageUploadEndpoint             = "/:collectionId/images/upload"
+	collectionImageCompleteEndpoint           = "/:collectionId/images/upload/complete"
 	collectionMintInfoEndpoint                = "/:collectionId/mintInfo"
+	collectionAttributesEndpoint              = "/:collectionId/attributes"
+	collectionFloorHistoryEndpoint            = "/:collectionId/floorHistory"
 	collectionRankingEndpoint                 = "/rankings/:offset/:limit"
+	collectionWindowRankingEndpoint           = "/windowRankings/:window/:offset/:limit"
 	collectionAllEndpoint                     = "/all"
 	collectionVerifiedEndpoint                = "/verified/:limit"
 	collectionVerifiedByStaticAddressEndpoint = "/verified/by_address/:limit"
 	collectionNoteworthyEndpoint              = "/noteworthy/:limit"
 	collectionTrendingEndpoint                = "/trending/:limit"
+	collectionTopEndpoint                     = "/top/:limit"
 	collectionByTokenIDEndpoint               = "/tokenId/:tokenId"
 	collectionUpdateMintStartDateEndpoint     = "/:collectionId/mintStartDate"
+	collectionMintPhasesEndpoint              = "/:collectionId/mintPhases"
+	collectionMintProgressEndpoint            = "/:collectionId/mintProgress"
+	collectionMintProgressStreamEndpoint      = "/:collectionId/mintProgress/stream"
 	collectionUpdateAdminSectionEndpoint      = "/:collectionId/adminSection"
+	collectionUpdateTrendingEndpoint          = "/:collectionId/trending"
 	collectionUpdateStakingOn                 = "/:collectionId/stake"
 	collectionUpdateStakingOff                = "/:collectionId/unstake"
+	collectionRolesEndpoint                   = "/:collectionId/roles"
+	collectionRoleByAddressEndpoint           = "/:collectionId/roles/:address"
+	collectionVerificationEndpoint            = "/:collectionId/verification"
+
+	defaultFloorHistoryDays = 30
+	maxFloorHistoryDays     = 365
+
+	// mintProgressHeartbeatPeriod keeps idle progress streams from being dropped by proxies.
+	mintProgressHeartbeatPeriod = 15 * time.Second
 )
 
 type CollectionTokensQueryBody struct {
@@ -71,14 +93,23 @@ func NewCollectionsHandler(groupHandler *groupHandler, authCfg config.AuthConfig
 	}
 
 	endpoints := []EndpointHandler{
-		{Method: http.MethodPost, Path: collectionByNameEndpoint, HandlerFunc: handler.set},
+		{Method: http.MethodPost, Path: collectionByNameEndpoint, HandlerFunc: handler.set, Permission: services.PermissionCollectionEdit},
 		{Method: http.MethodPost, Path: collectionCreateEndpoint, HandlerFunc: handler.create},
-		{Method: http.MethodPost, Path: collectionProfileEndpoint, HandlerFunc: handler.setCollectionProfile},
-		{Method: http.MethodPost, Path: collectionCoverEndpoint, HandlerFunc: handler.setCollectionCover},
-		{Method: http.MethodPost, Path: collectionUpdateMintStartDateEndpoint, HandlerFunc: handler.updateMintStartDate},
-		{Method: http.MethodPost, Path: collectionUpdateAdminSectionEndpoint, HandlerFunc: handler.updateAdminSection},
-		{Method: http.MethodPost, Path: collectionUpdateStakingOn, HandlerFunc: handler.updateStakingOn},
-		{Method: http.MethodPost, Path: collectionUpdateStakingOff, HandlerFunc: handler.updateStakingOff},
+		{Method: http.MethodPost, Path: collectionProfileEndpoint, HandlerFunc: handler.setCollectionProfile, Permission: services.PermissionCollectionEdit},
+		{Method: http.MethodPost, Path: collectionCoverEndpoint, HandlerFunc: handler.setCollectionCover, Permission: services.PermissionCollectionEdit},
+		{Method: http.MethodPost, Path: collectionImageUploadEndpoint, HandlerFunc: handler.presignCollectionImage, Permission: services.PermissionCollectionEdit},
+		{Method: http.MethodPost, Path: collectionImageCompleteEndpoint, HandlerFunc: handler.completeCollectionImage, Permission: services.PermissionCollectionEdit},
+		{Method: http.MethodPost, Path: collectionUpdateMintStartDateEndpoint, HandlerFunc: handler.updateMintStartDate, Permission: services.PermissionCollectionMint},
+		{Method: http.MethodPost, Path: collectionMintPhasesEndpoint, HandlerFunc: handler.setMintPhases, Permission: services.PermissionCollectionMint},
+		{Method: http.MethodPost, Path: collectionUpdateAdminSectionEndpoint, HandlerFunc: handler.updateAdminSection, Permission: services.PermissionCollectionsAdmin},
+		{Method: http.MethodPost, Path: collectionUpdateTrendingEndpoint, HandlerFunc: handler.updateTrending, Permission: services.PermissionCollectionsAdmin},
+		{Method: http.MethodPost, Path: collectionUpdateStakingOn, HandlerFunc: handler.updateStakingOn, Permission: services.PermissionCollectionsAdmin},
+		{Method: http.MethodPost, Path: collectionUpdateStakingOff, HandlerFunc: handler.updateStakingOff, Permission: services.PermissionCollectionsAdmin},
+		{Method: http.MethodGet, Path: collectionRolesEndpoint, HandlerFunc: handler.getRoles, Permission: services.PermissionCollectionRoles},
+		{Method: http.MethodPost, Path: collectionRolesEndpoint, HandlerFunc: handler.setRole, Permission: services.PermissionCollectionRoles},
+		{Method: http.MethodDelete, Path: collectionRoleByAddressEndpoint, HandlerFunc: handler.deleteRole, Permission: services.PermissionCollectionRoles},
+		{Method: http.MethodGet, Path: collectionVerificationEndpoint, HandlerFunc: handler.getVerification, Permission: services.PermissionCollectionEdit},
+		{Method: http.MethodPost, Path: collectionVerificationEndpoint, HandlerFunc: handler.applyForVerification, Permission: services.PermissionCollectionEdit},
 	}
 	endpointGroupHandler := EndpointGroupHandler{
 		Root:             baseCollectionsEndpoint,
@@ -92,12 +123,19 @@ func NewCollectionsHandler(groupHandler *groupHandler, authCfg config.AuthConfig
 		{Method: http.MethodGet, Path: collectionByNameEndpoint, HandlerFunc: handler.get},
 		{Method: http.MethodPost, Path: collectionTokensEndpoint, HandlerFunc: handler.getTokens},
 		{Method: http.MethodGet, Path: collectionMintInfoEndpoint, HandlerFunc: handler.getMintInfo},
+		{Method: http.MethodGet, Path: collectionMintPhasesEndpoint, HandlerFunc: handler.getMintPhases},
+		{Method: http.MethodGet, Path: collectionMintProgressEndpoint, HandlerFunc: handler.getMintProgress},
+		{Method: http.MethodGet, Path: collectionMintProgressStreamEndpoint, HandlerFunc: handler.streamMintProgress},
+		{Method: http.MethodGet, Path: collectionAttributesEndpoint, HandlerFunc: handler.getAttributes},
+		{Method: http.MethodGet, Path: collectionFloorHistoryEndpoint, HandlerFunc: handler.getFloorHistory},
 		{Method: http.MethodPost, Path: collectionRankingEndpoint, HandlerFunc: handler.getCollectionRankings},
+		{Method: http.MethodPost, Path: collectionWindowRankingEndpoint, HandlerFunc: handler.getCollectionWindowRankings},
 		{Method: http.MethodPost, Path: collectionAllEndpoint, HandlerFunc: handler.getAll},
 		{Method: http.MethodGet, Path: collectionVerifiedEndpoint, HandlerFunc: handler.getCollectionVerified},
 		{Method: http.MethodGet, Path: collectionVerifiedByStaticAddressEndpoint, HandlerFunc: handler.getCollectionVerifiedByAddress},
 		{Method: http.MethodGet, Path: collectionNoteworthyEndpoint, HandlerFunc: handler.getCollectionNoteworthy},
 		{Method: http.MethodGet, Path: collectionTrendingEndpoint, HandlerFunc: handler.getCollectionTrending},
+		{Method: http.MethodGet, Path: collectionTopEndpoint, HandlerFunc: handler.getCollectionTop},
 		{Method: http.MethodGet, Path: collectionByTokenIDEndpoint, HandlerFunc: handler.getCollectionByTokenID},
 	}
 	publicEndpointGroupHandler := EndpointGroupHandler{
@@ -262,6 +300,39 @@ func (handler *collectionsHandler) getCollectionTrending(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, collections, "")
 }
 
+// @Summary Get the top collections of the homepage
+// @Description Ranks the collections by their volume traded over the last 24 hours.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param limit path uint true "limit"
+// @Success 200 {object} []collstats.WindowRankingEntry
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/top/{limit} [get]
+func (handler *collectionsHandler) getCollectionTop(c *gin.Context) {
+	limitStr := c.Param("limit")
+	limit, err := strconv.ParseUint(limitStr, 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	entries, err := collstats.GetWindowRankingEntries(collstats.Window24h, collstats.VolumeTraded, 0, int(limit)-1, true)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, entries, "")
+}
+
 // @Summary Gets collection by collection id.
 // @Description Retrieves a collection by id.
 // @Tags collections
@@ -300,11 +371,18 @@ func (handler *collectionsHandler) get(c *gin.Context) {
 		return
 	}
 
+	duplicateWarning, err := services.GetCollectionDuplicateWarning(collection)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
 	extendedDto := dtos.ExtendedCollectionDto{
 		Collection:           *collection,
 		Statistics:           *collectionStats,
 		CreatorWalletAddress: creator.Address,
 		CreatorName:          creator.Name,
+		DuplicateWarning:     duplicateWarning,
 	}
 
 	dtos.JsonResponse(c, http.StatusOK, extendedDto, "")
@@ -347,31 +425,70 @@ func (handler *collectionsHandler) updateMintStartDate(c *gin.Context) {
 		return
 	}
 
-	creator, err := storage.GetAccountById(collection.CreatorID)
+	before := *collection
+	err = services.UpdateCollectionMintStartDate(collection, &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditCollectionMintStartDate, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, collection)
+	dtos.JsonResponse(c, http.StatusOK, collection, "")
+}
+
+// @Summary Get the verification status of a collection
+// @Description Returns the message to sign with the wallet controlling the contract address of the collection, and the last verification application with its decision.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} services.CollectionVerificationStatus
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/verification [get]
+func (handler *collectionsHandler) getVerification(c *gin.Context) {
+	tokenId := c.Param("collectionId")
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
 		return
 	}
 
-	isAdmin := c.GetBool(middleware.IsAdminKey)
-	jwtAddress := c.GetString(middleware.AddressKey)
-	if creator.Address != jwtAddress && !isAdmin {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
 		return
 	}
 
-	err = services.UpdateCollectionMintStartDate(collection, &request)
+	applicant := c.GetString(middleware.AddressKey)
+	status, err := services.GetCollectionVerificationStatus(collection, applicant)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, collection, "")
+	dtos.JsonResponse(c, http.StatusOK, status, "")
 }
 
-func (handler *collectionsHandler) updateAdminSection(c *gin.Context) {
-
-	var request services.UpdateCollectionAdminSectionRequest
+// @Summary Apply for the verification of a collection
+// @Description Files a verification application with the social links and team contact of the collection. The signature is the one of the verification message by the contract address, or by the owner of that contract, given as signer address. Ownership and duplicate image checks are run for the reviewers.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param request body services.VerificationApplicationRequest true "application"
+// @Success 200 {object} entities.CollectionVerification
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 403 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/verification [post]
+func (handler *collectionsHandler) applyForVerification(c *gin.Context) {
+	var request services.VerificationApplicationRequest
 	tokenId := c.Param("collectionId")
 
 	err := c.BindJSON(&request)
@@ -392,26 +509,73 @@ func (handler *collectionsHandler) updateAdminSection(c *gin.Context) {
 		return
 	}
 
-	isAdmin := c.GetBool(middleware.IsAdminKey)
+	applicant := c.GetString(middleware.AddressKey)
+	verification, err := services.ApplyForCollectionVerification(collection, applicant, &request, handler.blockchainApi(), time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, verificationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, verification, "")
+}
 
-	if !isAdmin {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+func (handler *collectionsHandler) updateAdminSection(c *gin.Context) {
+
+	var request services.UpdateCollectionAdminSectionRequest
+	tokenId := c.Param("collectionId")
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	before := *collection
 	err = services.UpdateCollectionAdminSection(collection, &request)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
+	services.RecordAudit(auditActor(c), services.AuditCollectionAdminSection, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, collection)
 	dtos.JsonResponse(c, http.StatusOK, collection, "")
 }
 
-func (handler *collectionsHandler) updateStakingOn(c *gin.Context) {
-
+// @Summary Pin or suppress a collection in trending
+// @Description Mode is pin, suppress or empty to go back to the computed score. Admin only.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param updateCollectionTrendingRequest body services.UpdateCollectionTrendingRequest true "trending override"
+// @Success 200 {object} entities.Collection
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/trending [post]
+func (handler *collectionsHandler) updateTrending(c *gin.Context) {
+	var request services.UpdateCollectionTrendingRequest
 	tokenId := c.Param("collectionId")
 
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
 	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
@@ -424,23 +588,25 @@ func (handler *collectionsHandler) updateStakingOn(c *gin.Context) {
 		return
 	}
 
-	isAdmin := c.GetBool(middleware.IsAdminKey)
-
-	if !isAdmin {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+	beforeMode, err := services.GetCollectionTrendingMode(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	err = services.UpdateCollectionStaking(collection, true)
+	err = services.UpdateCollectionTrendingOverride(collection, &request)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
+	services.RecordAudit(auditActor(c), services.AuditCollectionTrending, services.AuditTargetCollection, services.CollectionAuditTarget(collection),
+		services.UpdateCollectionTrendingRequest{Mode: beforeMode}, request)
+
 	dtos.JsonResponse(c, http.StatusOK, collection, "")
 }
 
-func (handler *collectionsHandler) updateStakingOff(c *gin.Context) {
+func (handler *collectionsHandler) updateStakingOn(c *gin.Context) {
 
 	tokenId := c.Param("collectionId")
 
@@ -456,19 +622,41 @@ func (handler *collectionsHandler) updateStakingOff(c *gin.Context) {
 		return
 	}
 
-	isAdmin := c.GetBool(middleware.IsAdminKey)
+	before := *collection
+	err = services.UpdateCollectionStaking(collection, true)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditCollectionStaking, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, collection)
+	dtos.JsonResponse(c, http.StatusOK, collection, "")
+}
 
-	if !isAdmin {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+func (handler *collectionsHandler) updateStakingOff(c *gin.Context) {
+
+	tokenId := c.Param("collectionId")
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
 		return
 	}
 
+	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	before := *collection
 	err = services.UpdateCollectionStaking(collection, false)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
+	services.RecordAudit(auditActor(c), services.AuditCollectionStaking, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, collection)
 	dtos.JsonResponse(c, http.StatusOK, collection, "")
 }
 
@@ -506,29 +694,14 @@ func (handler *collectionsHandler) set(c *gin.Context) {
 		return
 	}
 
-	isAdmin := c.GetBool(middleware.IsAdminKey)
-
-	if !isAdmin {
-
-		creator, err := storage.GetAccountById(collection.CreatorID)
-		if err != nil {
-			dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
-			return
-		}
-
-		jwtAddress := c.GetString(middleware.AddressKey)
-		if creator.Address != jwtAddress {
-			dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
-			return
-		}
-	}
-
+	before := *collection
 	err = services.UpdateCollection(collection, &request)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
+	services.RecordAudit(auditActor(c), services.AuditCollectionUpdate, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, collection)
 	dtos.JsonResponse(c, http.StatusOK, collection, "")
 }
 
@@ -645,14 +818,15 @@ func (handler *collectionsHandler) getTokens(c *gin.Context) {
 }
 
 // @Summary Set collection profile image
-// @Description Expects base64 std encoding of the image representation. Returns empty string. Max size of byte array is 1MB.
+// @Description Expects base64 std encoding of the image representation. Returns the image variants. Max size of byte array is 1MB.
 // @Tags collections
 // @Accept json
 // @Produce json
 // @Param collectionId path string true "collection id"
 // @Param image body string true "base64 encoded image"
-// @Success 200 {object} string
+// @Success 200 {object} cdn.ImageVariants
 // @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
 // @Router /collections/{collectionId}/profile [post]
 func (handler *collectionsHandler) setCollectionProfile(c *gin.Context) {
@@ -666,102 +840,134 @@ func (handler *collectionsHandler) setCollectionProfile(c *gin.Context) {
 	}
 
 	imageBase64 := buf.String()
-	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
 		return
 	}
 
-	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	variants, err := services.SetCollectionProfileImage(tokenId, collection.ID, &imageBase64)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	account, err := storage.GetAccountById(collection.CreatorID)
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
+}
+
+// @Summary Set collection cover image
+// @Description Expects base64 std encoding of the image representation. Returns the image variants. Max size of byte array is 1MB.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param image body string true "base64 encoded image"
+// @Success 200 {object} cdn.ImageVariants
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/cover [post]
+func (handler *collectionsHandler) setCollectionCover(c *gin.Context) {
+	tokenId := c.Param("collectionId")
+
+	buf := new(bytes.Buffer)
+	_, err := buf.ReadFrom(c.Request.Body)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
-	jwtAddress := c.GetString(middleware.AddressKey)
-	if account.Address != jwtAddress {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+	imageBase64 := buf.String()
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
 		return
 	}
 
-	link, err := services.SetCollectionProfileImage(tokenId, cacheInfo.CollectionId, &imageBase64)
+	variants, err := services.SetCollectionCoverImage(tokenId, collection.ID, &imageBase64)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, link, "")
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
 }
 
-// @Summary Set collection cover image
-// @Description Expects base64 std encoding of the image representation. Returns empty string. Max size of byte array is 1MB.
+// @Summary Presign a collection image upload
+// @Description Returns a url to upload a profile or cover image straight to the store, then complete it with the returned key. Only available with direct upload stores.
 // @Tags collections
 // @Accept json
 // @Produce json
 // @Param collectionId path string true "collection id"
-// @Param image body string true "base64 encoded image"
-// @Success 200 {object} string
+// @Param request body services.ImageUploadRequest true "image kind and content type"
+// @Success 200 {object} cdn.PresignedUpload
 // @Failure 400 {object} dtos.ApiResponse
 // @Failure 401 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
-// @Router /collections/{collectionId}/cover [post]
-func (handler *collectionsHandler) setCollectionCover(c *gin.Context) {
+// @Failure 501 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/images/upload [post]
+func (handler *collectionsHandler) presignCollectionImage(c *gin.Context) {
+	var request services.ImageUploadRequest
 	tokenId := c.Param("collectionId")
 
-	buf := new(bytes.Buffer)
-	_, err := buf.ReadFrom(c.Request.Body)
+	err := c.BindJSON(&request)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
-	imageBase64 := buf.String()
-	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	upload, err := services.PresignCollectionImageUpload(tokenId, request)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		dtos.JsonResponse(c, imageUploadErrorStatus(err), nil, err.Error())
 		return
 	}
 
-	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
-		return
-	}
+	dtos.JsonResponse(c, http.StatusOK, upload, "")
+}
 
-	account, err := storage.GetAccountById(collection.CreatorID)
+// @Summary Complete a collection image upload
+// @Description Processes an image uploaded to a presigned url and sets it as the collection profile or cover. Returns the image variants.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param request body services.ImageUploadCompleteRequest true "image kind and upload key"
+// @Success 200 {object} cdn.ImageVariants
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Failure 501 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/images/upload/complete [post]
+func (handler *collectionsHandler) completeCollectionImage(c *gin.Context) {
+	var request services.ImageUploadCompleteRequest
+	tokenId := c.Param("collectionId")
+
+	err := c.BindJSON(&request)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
-	jwtAddress := c.GetString(middleware.AddressKey)
-	if account.Address != jwtAddress {
-		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
 		return
 	}
 
-	link, err := services.SetCollectionCoverImage(tokenId, cacheInfo.CollectionId, &imageBase64)
+	variants, err := services.CompleteCollectionImageUpload(tokenId, collection.ID, request)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		dtos.JsonResponse(c, imageUploadErrorStatus(err), nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, link, "")
+	dtos.JsonResponse(c, http.StatusOK, variants, "")
 }
 
 // @Summary Gets mint info about a collection.
-// @Description Retrieves max supply and total sold for a collection. Cached for 6 seconds.
+// @Description Retrieves max supply and total sold for a collection, with its mint phases and the current and next phase. Supply is cached for 6 seconds.
 // @Tags collections
 // @Accept json
 // @Produce json
 // @Param collectionId path string true "collection id"
-// @Success 200 {object} services.MintInfo
+// @Success 200 {object} services.CollectionMintInfo
 // @Failure 400 {object} dtos.ApiResponse
 // @Failure 404 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
@@ -786,7 +992,7 @@ func (handler *collectionsHandler) getMintInfo(c *gin.Context) {
 		return
 	}
 
-	mintInfo, err := services.GetMintInfoForContract(collection.ContractAddress)
+	mintInfo, err := services.GetCollectionMintInfo(collection, uint64(time.Now().Unix()))
 	if err != nil {
 		zlog.Error("get_mint_info", zap.Error(err))
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, "")
@@ -796,6 +1002,324 @@ func (handler *collectionsHandler) getMintInfo(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, mintInfo, "")
 }
 
+// @Summary Gets the mint progress of a collection.
+// @Description Retrieves minted count, per-phase sold, unique minters and mint revenue, maintained from indexed mint transactions.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} services.MintProgress
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/mintProgress [get]
+func (handler *collectionsHandler) getMintProgress(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	progress, err := services.GetMintProgress(collection)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, progress, "")
+}
+
+// @Summary Streams the mint progress of a collection.
+// @Description Server-sent events. Sends the current progress, then a "progress" event every time a mint is indexed or the progress is reconciled.
+// @Tags collections
+// @Produce text/event-stream
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} services.MintProgress
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/mintProgress/stream [get]
+func (handler *collectionsHandler) streamMintProgress(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	progress, err := services.GetMintProgress(collection)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	subscription := services.SubscribeMintProgress(ctx, collection.CollectionTokenID)
+	defer subscription.Close()
+	messages := subscription.Channel()
+	heartbeat := time.NewTicker(mintProgressHeartbeatPeriod)
+	defer heartbeat.Stop()
+
+	c.SSEvent("progress", progress)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			// a comment line, ignored by event source clients
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case message, open := <-messages:
+			if !open {
+				return false
+			}
+			c.SSEvent("progress", message.Payload)
+			return true
+		}
+	})
+}
+
+// @Summary Gets the mint phases of a collection.
+// @Description Retrieves the ordered mint schedule of a collection.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} []entities.CollectionMintPhase
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/mintPhases [get]
+func (handler *collectionsHandler) getMintPhases(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	phases, err := services.GetCollectionMintPhases(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, phases, "")
+}
+
+// @Summary Sets the mint phases of a collection.
+// @Description Replaces the mint schedule. Phases are ordered, must not overlap and only the last one can stay open. Restricted to the collection owners and admins.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param request body services.SetCollectionMintPhasesRequest true "mint phases"
+// @Success 200 {object} []entities.CollectionMintPhase
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/mintPhases [post]
+func (handler *collectionsHandler) setMintPhases(c *gin.Context) {
+	var request services.SetCollectionMintPhasesRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	before, err := services.GetCollectionMintPhases(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	phases, err := services.SetCollectionMintPhases(collection, &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditCollectionMintPhases, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, phases)
+
+	dtos.JsonResponse(c, http.StatusOK, phases, "")
+}
+
+// @Summary Get the roles of a collection.
+// @Description Lists the addresses granted a role on the collection. The creator is always an owner and is not listed. Restricted to the collection owners and admins.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} []entities.CollectionRole
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/roles [get]
+func (handler *collectionsHandler) getRoles(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	roles, err := services.GetCollectionRoles(collection)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, roles, "")
+}
+
+// @Summary Grant a role on a collection.
+// @Description Sets the role (owner, editor or whitelistManager) of an address on the collection. Replacing a role signs the address out. Restricted to the collection owners and admins.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param request body services.SetCollectionRoleRequest true "collection role"
+// @Success 200 {object} entities.CollectionRole
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/roles [post]
+func (handler *collectionsHandler) setRole(c *gin.Context) {
+	var request services.SetCollectionRoleRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	beforeRole, err := services.GetCollectionRoleOf(collection, request.Address)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	role, err := services.SetCollectionRole(collection, c.GetString(middleware.AddressKey), &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditCollectionRoleSet, services.AuditTargetCollection, services.CollectionAuditTarget(collection),
+		services.SetCollectionRoleRequest{Address: request.Address, Role: beforeRole}, request)
+
+	dtos.JsonResponse(c, http.StatusOK, role, "")
+}
+
+// @Summary Revoke a role on a collection.
+// @Description Removes the role of the address on the collection and signs it out. Restricted to the collection owners and admins.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param address path string true "wallet address"
+// @Success 200 {object} string
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/roles/{address} [delete]
+func (handler *collectionsHandler) deleteRole(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	address := c.Param("address")
+	beforeRole, err := services.GetCollectionRoleOf(collection, address)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	err = services.RemoveCollectionRole(collection, address)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditCollectionRoleRemove, services.AuditTargetCollection, services.CollectionAuditTarget(collection),
+		services.SetCollectionRoleRequest{Address: address, Role: beforeRole}, services.SetCollectionRoleRequest{Address: address})
+
+	dtos.JsonResponse(c, http.StatusOK, "", "")
+}
+
+// @Summary Gets attribute analytics of a collection.
+// @Description Retrieves supply, listed count, floor price, last sale price and 7 day volume for every trait value. Cached for 5 minutes.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} dtos.CollectionAttributeAnalytics
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/attributes [get]
+func (handler *collectionsHandler) getAttributes(c *gin.Context) {
+	tokenId := c.Param("collectionId")
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	analytics, err := services.GetCollectionAttributeAnalytics(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, analytics, "")
+}
+
+// @Summary Get collection floor price history
+// @Description Hourly floor price snapshots, oldest first. Days defaults to 30 and is capped at 365.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param days query uint false "days of history"
+// @Success 200 {object} []dtos.FloorPricePoint
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/{collectionId}/floorHistory [get]
+func (handler *collectionsHandler) getFloorHistory(c *gin.Context) {
+	tokenId := c.Param("collectionId")
+
+	days := uint64(defaultFloorHistoryDays)
+	daysStr := c.Query("days")
+	if daysStr != "" {
+		var err error
+		days, err = strconv.ParseUint(daysStr, 10, 0)
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+	}
+	if days > maxFloorHistoryDays {
+		days = maxFloorHistoryDays
+	}
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	history, err := services.GetCollectionFloorPriceHistory(cacheInfo.CollectionId, days)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, history, "")
+}
+
 // @Summary Get collection rankings
 // @Description Acts as a leaderboard
 // @Tags collections
@@ -870,6 +1394,88 @@ func (handler *collectionsHandler) getCollectionRankings(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, entries, "")
 }
 
+// @Summary Get collection rankings over a time window
+// @Description Window is one of 1h, 24h, 7d, 30d or all. Criteria can be volumeTraded, salesCount, floorPrice, floorChange, ownersTotal or ownersChange.
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param window path string true "time window"
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param query body CollectionRankingQueryBody true "sort rules"
+// @Success 200 {object} []collstats.WindowRankingEntry
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /collections/windowRankings/{window}/{offset}/{limit} [post]
+func (handler *collectionsHandler) getCollectionWindowRankings(c *gin.Context) {
+	window := c.Param("window")
+	offsetStr := c.Param("offset")
+	limitStr := c.Param("limit")
+
+	if !collstats.IsValidRankingWindow(window) {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "not a valid ranking window")
+		return
+	}
+
+	var queries CollectionRankingQueryBody
+	err := c.BindJSON(&queries)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	sortRules := queries.SortRules
+
+	offset, err := strconv.ParseUint(offsetStr, 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(limitStr, 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	if sortRules == nil {
+		sortRules = make(map[string]string, 2)
+	}
+
+	if len(sortRules) == 0 {
+		sortRules["criteria"] = collstats.VolumeTraded
+		sortRules["mode"] = "desc"
+	}
+
+	acceptedCriteria := map[string]bool{
+		collstats.VolumeTraded: true,
+		collstats.SalesCount:   true,
+		collstats.FloorPrice:   true,
+		collstats.FloorChange:  true,
+		collstats.OwnersTotal:  true,
+		collstats.OwnersChange: true,
+	}
+	err = testInputSortParams(sortRules, acceptedCriteria)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	isRev := strings.ToLower(sortRules["mode"]) == "desc"
+	entries, err := collstats.GetWindowRankingEntries(window, sortRules["criteria"], int(offset), int(offset+limit)-1, isRev)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, entries, "")
+}
+
 func testInputSortParams(sortParams map[string]string, acceptedCriteria map[string]bool) error {
 	if len(sortParams) == 0 {
 		return nil
@@ -898,3 +1504,29 @@ func testInputSortParams(sortParams map[string]string, acceptedCriteria map[stri
 
 	return nil
 }
+
+func (handler *collectionsHandler) blockchainApi() string {
+	if handler.blockchainCfg.ApiUrlSec != "" {
+		return handler.blockchainCfg.ApiUrlSec
+	}
+
+	return handler.blockchainCfg.ApiUrl
+}
+
+func verificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case services.IsVerificationRequestErr(err):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotContractController):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCollectionAlreadyVerified),
+		errors.Is(err, services.ErrVerificationPending),
+		errors.Is(err, services.ErrVerificationAlreadyReviewed),
+		errors.Is(err, services.ErrOwnershipCheckFailed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
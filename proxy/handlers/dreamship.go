@@ -1,104 +1,83 @@
 package handlers
 
 import (
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
-	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
 	"github.com/ENFT-DAO/youbei-api/services"
-	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 const (
-	baseDreamshipUrl	=	"/print"
-	availableItemsUrl	=	"/available_items"
-	shippingStatusUrl	=	"/shipping_status/:us_or_inter/:item_id"
-	orderUrl			=	"/order/:walletAddress"
-	orderByUserUrl		=	"/order/:walletAddress/:orderId"
-	orderHookUrl		=	"/order/hook"
+	baseDreamshipUrl  = "/print"
+	availableItemsUrl = "/available_items"
+	shippingStatusUrl = "/shipping_status/:us_or_inter/:item_id"
+	orderHookUrl      = "/order/hook"
 )
 
 type dreamshipHandler struct {
-	cfg	config.ExternalCredentialConfig
 }
 
-func NewDreamshipHandler(groupHandler *groupHandler, cfg config.ExternalCredentialConfig) {
-	handler := &dreamshipHandler{cfg: cfg}
+func NewDreamshipHandler(groupHandler *groupHandler) {
+	handler := &dreamshipHandler{}
 
 	endpoints := []EndpointHandler{
 		{Method: http.MethodGet, Path: shippingStatusUrl, HandlerFunc: handler.getShippingStatus},
 		{Method: http.MethodGet, Path: availableItemsUrl, HandlerFunc: handler.getAvailableItems},
-		{Method: http.MethodPost, Path: orderUrl, HandlerFunc: handler.setOrder},
 		{Method: http.MethodPost, Path: orderHookUrl, HandlerFunc: handler.setOrderHook},
-		{Method: http.MethodGet, Path: orderUrl, HandlerFunc: handler.getOrdersList},
-		{Method: http.MethodGet, Path: orderByUserUrl, HandlerFunc: handler.GetOrderByUser},
 	}
 
-	endpointGroupHandler := EndpointGroupHandler {
-		Root: baseDreamshipUrl,
-		Middlewares: []gin.HandlerFunc{},
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseDreamshipUrl,
+		Middlewares:      []gin.HandlerFunc{},
 		EndpointHandlers: endpoints,
 	}
 
 	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
 }
 
+// @Summary Receive a fulfillment provider webhook.
+// @Description Must be signed with the webhook secret (X-Webhook-Signature, hex HMAC-SHA256 of the body) or carry it (X-Webhook-Secret). A webhook delivered again is acknowledged without changes.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /print/order/hook [post]
 func (handler *dreamshipHandler) setOrderHook(c *gin.Context) {
-	var request = entities.ItemWebhook{}
-	err := c.BindJSON(&request)
+	body, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusBadRequest, "", err.Error())
-	}
-	err = services.DreamshipWebHook(request)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, "", err.Error())
-	}
-}
-
-func (handler *dreamshipHandler) GetOrderByUser(c *gin.Context) {
-	walletAddress := c.Param("walletAddress")
-	orderId := c.Param("orderId")
-	data, err := storage.RetrievesAnOrders(walletAddress, orderId)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, "", err.Error())
-		return
-	}
-	dtos.JsonResponse(c, http.StatusAccepted, data, "")
-}
-
-func (handler *dreamshipHandler) getOrdersList(c *gin.Context) {
-	walletAddress := c.Param("walletAddress")
-	data, err := storage.RetrievesUserOrders(walletAddress)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusInternalServerError, "", err.Error())
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
-	dtos.JsonResponse(c, http.StatusAccepted, data, "")
-}
 
-func (handler *dreamshipHandler) setOrder(c *gin.Context) {
-	var request = entities.DreamshipOrderItems{}
-	walletAddress := c.Param("walletAddress")
-	err := c.BindJSON(&request)
-	if err != nil {
-		dtos.JsonResponse(c, http.StatusBadRequest, "", err.Error())
-	}
-	
-	// Service Layer Should be added here.
-	data, err := services.SetOrderHandler(handler.cfg, request, walletAddress)
-	if err != nil{
-		dtos.JsonResponse(c, http.StatusBadRequest, "", err.Error())
+	_, err = services.HandlePrintOrderWebhook(c.Request.Header, body, time.Now())
+	switch {
+	case err == nil:
+		dtos.JsonResponse(c, http.StatusOK, nil, "")
+	case errors.Is(err, fulfillment.ErrInvalidWebhookSignature),
+		errors.Is(err, fulfillment.ErrWebhookNotConfigured):
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, err.Error())
+	case errors.Is(err, fulfillment.ErrInvalidWebhook):
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		dtos.JsonResponse(c, http.StatusNotFound, nil, "unknown order")
+	default:
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 	}
-
-	dtos.JsonResponse(c, http.StatusCreated, data, "")
 }
 
-
 func (handler *dreamshipHandler) getAvailableItems(c *gin.Context) {
-	data, err := services.GetAvailableVariantsHandler(handler.cfg)
+	data, err := services.GetAvailableVariantsHandler()
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, "Cannot Fetch Data")
 		return
@@ -112,12 +91,12 @@ func (handler *dreamshipHandler) getShippingStatus(c *gin.Context) {
 	item, err := strconv.ParseInt(itemId, 10, 64)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusBadRequest, nil, "Please provide correct id for item")
+		return
 	}
-	data, err := services.GetShipmentMethodsAndCostsHandler(handler.cfg, usOrInternational, item)
+	data, err := services.GetShipmentMethodsAndCostsHandler(usOrInternational, item)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, "can not fetch data")
 		return
 	}
 	dtos.JsonResponse(c, http.StatusOK, data, "")
 }
-
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/ENFT-DAO/youbei-api/cdn"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -43,11 +45,37 @@ func (h *imageHandler) getImage(c *gin.Context) {
 		return
 	}
 
+	// variant keys hold the hash of their upload, the file name is a strong validator
+	cacheControl := cdn.MutableCacheControl
+	if cdn.IsContentHashed(filename) {
+		cacheControl = cdn.ImmutableCacheControl
+		etag := fmt.Sprintf(`"%s"`, filename)
+		c.Header("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Header("Cache-Control", cacheControl)
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
 	img, imgType, err := uploader.GetImage(filename)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
+	c.Header("Cache-Control", cacheControl)
 	c.Data(http.StatusOK, fmt.Sprintf(contentTypeImage, imgType), img)
 }
+
+// imageUploadErrorStatus maps direct upload errors of accounts and collections.
+func imageUploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, cdn.ErrDirectUploadNotSupported):
+		return http.StatusNotImplemented
+	case services.IsImageUploadRequestErr(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
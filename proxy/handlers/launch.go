@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/formatter"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	baseLaunchesEndpoint       = "/launches"
+	launchCreateEndpoint       = "/create"
+	launchCurrentEndpoint      = "/current"
+	launchStuckEndpoint        = "/stuck/:offset/:limit"
+	launchByIdEndpoint         = "/:launchId"
+	launchAbandonEndpoint      = "/:launchId/abandon"
+	launchStepEndpoint         = "/:launchId/steps/:step"
+	launchStepTemplateEndpoint = "/:launchId/steps/:step/template"
+
+	defaultLaunchIdleHours = 24
+	maxStuckLaunchesLimit  = 100
+)
+
+type launchHandler struct {
+	blockchainCfg config.BlockchainConfig
+	txFormatter   formatter.TxFormatter
+}
+
+func NewLaunchHandler(groupHandler *groupHandler, authCfg config.AuthConfig, blockchainCfg config.BlockchainConfig) {
+	handler := &launchHandler{
+		blockchainCfg: blockchainCfg,
+		txFormatter:   formatter.NewTxFormatter(blockchainCfg),
+	}
+
+	endpoints := []EndpointHandler{
+		{Method: http.MethodPost, Path: launchCreateEndpoint, HandlerFunc: handler.create},
+		{Method: http.MethodGet, Path: launchCurrentEndpoint, HandlerFunc: handler.getCurrent},
+		{Method: http.MethodGet, Path: launchStuckEndpoint, HandlerFunc: handler.getStuck, Permission: services.PermissionLaunchesSupport},
+		{Method: http.MethodGet, Path: launchByIdEndpoint, HandlerFunc: handler.get},
+		{Method: http.MethodPost, Path: launchAbandonEndpoint, HandlerFunc: handler.abandon},
+		{Method: http.MethodPost, Path: launchStepEndpoint, HandlerFunc: handler.submitStep},
+		{Method: http.MethodGet, Path: launchStepTemplateEndpoint, HandlerFunc: handler.getStepTemplate},
+	}
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseLaunchesEndpoint,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: endpoints,
+	}
+	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+}
+
+// @Summary Start a collection launch.
+// @Description Creates the launch workflow of the caller: issue, deploy, set roles, change owner, sale start, then the collection is created. A launch left in progress is abandoned.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param request body services.CreateLaunchRequest true "launch parameters"
+// @Success 200 {object} entities.CollectionLaunch
+// @Failure 400 {object} dtos.ApiResponse
+// @Router /launches/create [post]
+func (handler *launchHandler) create(c *gin.Context) {
+	var request services.CreateLaunchRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	launch, err := services.StartCollectionLaunch(c.GetString(middleware.AddressKey), &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, launch, "")
+}
+
+// @Summary Resume the current launch.
+// @Description Retrieves the launch in progress of the caller, after checking the chain for the submitted steps.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Success 200 {object} entities.CollectionLaunch
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /launches/current [get]
+func (handler *launchHandler) getCurrent(c *gin.Context) {
+	launch, err := storage.GetActiveCollectionLaunch(c.GetString(middleware.AddressKey))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	handler.respondRefreshed(c, launch)
+}
+
+// @Summary Get a launch.
+// @Description Retrieves a launch after checking the chain for the submitted steps. Restricted to its creator or an admin.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param launchId path uint true "launch id"
+// @Success 200 {object} entities.CollectionLaunch
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /launches/{launchId} [get]
+func (handler *launchHandler) get(c *gin.Context) {
+	launch, ok := getOwnedLaunch(c)
+	if !ok {
+		return
+	}
+
+	handler.respondRefreshed(c, launch)
+}
+
+// @Summary Abandon a launch.
+// @Description Restricted to its creator or an admin.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param launchId path uint true "launch id"
+// @Success 200 {object} entities.CollectionLaunch
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /launches/{launchId}/abandon [post]
+func (handler *launchHandler) abandon(c *gin.Context) {
+	launch, ok := getOwnedLaunch(c)
+	if !ok {
+		return
+	}
+
+	err := services.AbandonCollectionLaunch(launch)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, launch, "")
+}
+
+// @Summary Get the tx-template of a launch step.
+// @Description Builds the transaction of an on-chain step once its dependencies are completed. Only account nonce and signature must be added afterwards.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param launchId path uint true "launch id"
+// @Param step path string true "issue, deploy, setRoles, changeOwner or saleStart"
+// @Success 200 {object} formatter.Transaction
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /launches/{launchId}/steps/{step}/template [get]
+func (handler *launchHandler) getStepTemplate(c *gin.Context) {
+	stepName := c.Param("step")
+
+	launch, ok := getOwnedLaunch(c)
+	if !ok {
+		return
+	}
+
+	_, err := services.GetLaunchStepReady(launch, stepName)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	template, err := handler.txFormatter.LaunchStepTxTemplate(launch, stepName)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, template, "")
+}
+
+// @Summary Submit the transaction of a launch step.
+// @Description Records the tx hash sent for an on-chain step. The step completes once the transaction succeeds on chain.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param launchId path uint true "launch id"
+// @Param step path string true "issue, deploy, setRoles, changeOwner or saleStart"
+// @Param request body services.SubmitLaunchStepRequest true "tx hash"
+// @Success 200 {object} entities.CollectionLaunch
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /launches/{launchId}/steps/{step} [post]
+func (handler *launchHandler) submitStep(c *gin.Context) {
+	var request services.SubmitLaunchStepRequest
+	stepName := c.Param("step")
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	launch, ok := getOwnedLaunch(c)
+	if !ok {
+		return
+	}
+
+	launch, err = services.SubmitLaunchStep(launch, stepName, request.TxHash, handler.blockchainApi(), handler.blockchainCfg)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, launch, "")
+}
+
+// @Summary Get the stuck launches.
+// @Description Counts the launches in progress idle for more than idleHours (default 24) per step they wait on, and lists them. Restricted to support and admins.
+// @Tags launches
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param idleHours query uint false "idle hours"
+// @Success 200 {object} services.StuckLaunches
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /launches/stuck/{offset}/{limit} [get]
+func (handler *launchHandler) getStuck(c *gin.Context) {
+	offset, err := strconv.Atoi(c.Param("offset"))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Param("limit"))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	if limit > maxStuckLaunchesLimit {
+		limit = maxStuckLaunchesLimit
+	}
+
+	idleHours := uint64(defaultLaunchIdleHours)
+	if idleHoursStr := c.Query("idleHours"); idleHoursStr != "" {
+		idleHours, err = strconv.ParseUint(idleHoursStr, 10, 64)
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+	}
+
+	stuck, err := services.GetStuckLaunches(time.Duration(idleHours)*time.Hour, offset, limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, stuck, "")
+}
+
+func (handler *launchHandler) respondRefreshed(c *gin.Context, launch *entities.CollectionLaunch) {
+	launch, err := services.RefreshCollectionLaunch(launch, handler.blockchainApi(), handler.blockchainCfg)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, launch, "")
+}
+
+func (handler *launchHandler) blockchainApi() string {
+	if handler.blockchainCfg.ApiUrlSec != "" {
+		return handler.blockchainCfg.ApiUrlSec
+	}
+
+	return handler.blockchainCfg.ApiUrl
+}
+
+// getOwnedLaunch loads the launch of the path and checks the caller started it or supports launches.
+func getOwnedLaunch(c *gin.Context) (*entities.CollectionLaunch, bool) {
+	launchId, err := strconv.ParseUint(c.Param("launchId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return nil, false
+	}
+
+	launch, err := storage.GetCollectionLaunchById(launchId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return nil, false
+	}
+
+	if launch.Address != c.GetString(middleware.AddressKey) && !middleware.HasPermission(c, services.PermissionLaunchesSupport, nil) {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+		return nil, false
+	}
+
+	return launch, true
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	baseModerationEndpoint            = "/moderation"
+	moderationDuplicatesEndpoint      = "/duplicates/list/:offset/:limit"
+	moderationDuplicateReviewEndpoint = "/duplicates/:flagId/review"
+	moderationReportEndpoint          = "/reports"
+	moderationReportsEndpoint         = "/reports/list/:offset/:limit"
+	moderationQueueEndpoint           = "/queue/:offset/:limit"
+	moderationActionEndpoint          = "/actions"
+	moderationVerificationsEndpoint   = "/verifications/list/:offset/:limit"
+	moderationVerifyChecksEndpoint    = "/verifications/:verificationId/checks"
+	moderationVerifyReviewEndpoint    = "/verifications/:verificationId/review"
+)
+
+type moderationHandler struct {
+	blockchainCfg config.BlockchainConfig
+}
+
+func NewModerationHandler(groupHandler *groupHandler, authCfg config.AuthConfig, blockchainCfg config.BlockchainConfig) {
+	handler := &moderationHandler{
+		blockchainCfg: blockchainCfg,
+	}
+
+	endpoints := []EndpointHandler{
+		{Method: http.MethodGet, Path: moderationDuplicatesEndpoint, HandlerFunc: handler.getDuplicateFlags, Permission: services.PermissionContentModerate},
+		{Method: http.MethodPost, Path: moderationDuplicateReviewEndpoint, HandlerFunc: handler.reviewDuplicateFlag, Permission: services.PermissionContentModerate},
+		{Method: http.MethodPost, Path: moderationReportEndpoint, HandlerFunc: handler.createReport},
+		{Method: http.MethodGet, Path: moderationReportsEndpoint, HandlerFunc: handler.getReports, Permission: services.PermissionContentModerate},
+		{Method: http.MethodGet, Path: moderationQueueEndpoint, HandlerFunc: handler.getQueue, Permission: services.PermissionContentModerate},
+		{Method: http.MethodPost, Path: moderationActionEndpoint, HandlerFunc: handler.applyAction, Permission: services.PermissionContentModerate},
+		{Method: http.MethodGet, Path: moderationVerificationsEndpoint, HandlerFunc: handler.getVerifications, Permission: services.PermissionVerifications},
+		{Method: http.MethodPost, Path: moderationVerifyChecksEndpoint, HandlerFunc: handler.refreshVerificationChecks, Permission: services.PermissionVerifications},
+		{Method: http.MethodPost, Path: moderationVerifyReviewEndpoint, HandlerFunc: handler.reviewVerification, Permission: services.PermissionVerifications},
+	}
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseModerationEndpoint,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: endpoints,
+	}
+	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+}
+
+// @Summary List collections flagged as copies of verified collections.
+// @Description Collections whose token images largely match a verified collection, highest score first. Score is the share of hashed tokens matching, similarity the average similarity of the matches. Moderators only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param status query string false "open, confirmed or dismissed"
+// @Success 200 {object} services.DuplicateFlags
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/duplicates/list/{offset}/{limit} [get]
+func (handler *moderationHandler) getDuplicateFlags(c *gin.Context) {
+	offset, err := strconv.ParseUint(c.Param("offset"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(c.Param("limit"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	status := entities.DuplicateFlagStatus(c.Query("status"))
+	flags, err := services.GetDuplicateFlags(status, int(offset), int(limit))
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, flags, "")
+}
+
+// @Summary Review a collection flagged as a copy.
+// @Description Confirms or dismisses a flag, or reopens it. Confirmed and open flags show a warning on the collection, dismissed ones do not. Moderators only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param flagId path uint true "flag id"
+// @Param request body services.ReviewDuplicateFlagRequest true "decision"
+// @Success 200 {object} string
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/duplicates/{flagId}/review [post]
+func (handler *moderationHandler) reviewDuplicateFlag(c *gin.Context) {
+	var request services.ReviewDuplicateFlagRequest
+
+	flagId, err := strconv.ParseUint(c.Param("flagId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	reviewer := c.GetString(middleware.AddressKey)
+	flag, err := services.ReviewDuplicateFlag(flagId, reviewer, &request, time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditDuplicateFlagReview, services.AuditTargetCollection, services.CollectionAuditTarget(&flag.Collection),
+		services.ReviewDuplicateFlagRequest{Status: flag.Status, Note: flag.ReviewNote}, request)
+	dtos.JsonResponse(c, http.StatusOK, "", "")
+}
+
+// @Summary Report a token, collection or account.
+// @Description Files a report for moderators. Tokens are identified by their collection token id and nonce, collections by their token id and accounts by their address. A user can have one open report per target.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param request body services.CreateReportRequest true "report"
+// @Success 200 {object} entities.ContentReport
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 403 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/reports [post]
+func (handler *moderationHandler) createReport(c *gin.Context) {
+	var request services.CreateReportRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	reporter := c.GetString(middleware.AddressKey)
+	report, err := services.CreateReport(reporter, &request, time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, report, "")
+}
+
+// @Summary List content reports.
+// @Description Reports newest first, optionally of one status or target. Moderators only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param status query string false "open, actioned or dismissed"
+// @Param targetType query string false "token, collection or account"
+// @Param targetId query string false "collection token id or address"
+// @Param nonce query uint false "token nonce"
+// @Success 200 {object} services.ContentReports
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/reports/list/{offset}/{limit} [get]
+func (handler *moderationHandler) getReports(c *gin.Context) {
+	offset, limit, ok := moderationPage(c)
+	if !ok {
+		return
+	}
+
+	filter := entities.ContentReport{
+		Status:     entities.ReportStatus(c.Query("status")),
+		TargetType: entities.ReportTargetType(c.Query("targetType")),
+		TargetID:   c.Query("targetId"),
+	}
+	if nonce := c.Query("nonce"); nonce != "" {
+		parsed, err := strconv.ParseUint(nonce, 10, 64)
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+		filter.TargetNonce = parsed
+	}
+
+	reports, err := services.GetContentReports(&filter, offset, limit)
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, reports, "")
+}
+
+// @Summary Get the moderation queue.
+// @Description Targets with open reports, the most reported first, with the reasons they were reported for. Moderators only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param targetType query string false "token, collection or account"
+// @Success 200 {object} services.ModerationQueue
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/queue/{offset}/{limit} [get]
+func (handler *moderationHandler) getQueue(c *gin.Context) {
+	offset, limit, ok := moderationPage(c)
+	if !ok {
+		return
+	}
+
+	targetType := entities.ReportTargetType(c.Query("targetType"))
+	queue, err := services.GetModerationQueue(targetType, offset, limit)
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, queue, "")
+}
+
+// @Summary Act on a reported target.
+// @Description Hides, unhides, delists or relists a token or collection, bans or unbans an account, or dismisses the reports. Hidden targets leave the explorer, search and rankings, delisted ones only the explorer. The open reports of the target are closed with the action. Moderators only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param request body services.ModerationActionRequest true "action"
+// @Success 200 {object} services.ModerationActionResult
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/actions [post]
+func (handler *moderationHandler) applyAction(c *gin.Context) {
+	var request services.ModerationActionRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	moderator := c.GetString(middleware.AddressKey)
+	result, err := services.ApplyModerationAction(moderator, &request, time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, moderationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	targetType, targetId := result.AuditTarget()
+	services.RecordAudit(auditActor(c), services.AuditModerationAction, targetType, targetId, result.Before, result.After)
+	dtos.JsonResponse(c, http.StatusOK, result, "")
+}
+
+// @Summary List collection verification applications.
+// @Description Applications oldest first, with their automated checks: whether the signer controls the contract on chain, and the duplicate image score of the collection. Reviewers only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {object} services.CollectionVerifications
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/verifications/list/{offset}/{limit} [get]
+func (handler *moderationHandler) getVerifications(c *gin.Context) {
+	offset, limit, ok := moderationPage(c)
+	if !ok {
+		return
+	}
+
+	status := entities.VerificationStatus(c.Query("status"))
+	verifications, err := services.GetCollectionVerifications(status, offset, limit)
+	if err != nil {
+		dtos.JsonResponse(c, verificationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, verifications, "")
+}
+
+// @Summary Run the checks of a verification application again.
+// @Description Checks the contract ownership on chain and the duplicate image score again. Reviewers only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param verificationId path uint true "application id"
+// @Success 200 {object} entities.CollectionVerification
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/verifications/{verificationId}/checks [post]
+func (handler *moderationHandler) refreshVerificationChecks(c *gin.Context) {
+	verificationId, err := strconv.ParseUint(c.Param("verificationId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	verification, err := services.RefreshCollectionVerificationChecks(verificationId, handler.blockchainApi(), time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, verificationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, verification, "")
+}
+
+// @Summary Decide on a verification application.
+// @Description Approves or rejects a pending application. Approving verifies the collection, rejecting needs at least one reason. An application whose ownership check failed is only approved with overrideOwnershipCheck and a note, kept in the audit log. The applicant is notified of the decision and its reasons. Reviewers only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param verificationId path uint true "application id"
+// @Param request body services.ReviewVerificationRequest true "decision"
+// @Success 200 {object} string
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /moderation/verifications/{verificationId}/review [post]
+func (handler *moderationHandler) reviewVerification(c *gin.Context) {
+	var request services.ReviewVerificationRequest
+
+	verificationId, err := strconv.ParseUint(c.Param("verificationId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	reviewer := c.GetString(middleware.AddressKey)
+	verification, err := services.ReviewCollectionVerification(verificationId, reviewer, &request, time.Now().Unix())
+	if err != nil {
+		dtos.JsonResponse(c, verificationErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	services.RecordAudit(auditActor(c), services.AuditVerificationReview, services.AuditTargetCollection, services.CollectionAuditTarget(&verification.Collection),
+		services.ReviewVerificationRequest{Decision: verification.Status}, request)
+	dtos.JsonResponse(c, http.StatusOK, "", "")
+}
+
+func (handler *moderationHandler) blockchainApi() string {
+	if handler.blockchainCfg.ApiUrlSec != "" {
+		return handler.blockchainCfg.ApiUrlSec
+	}
+
+	return handler.blockchainCfg.ApiUrl
+}
+
+func moderationPage(c *gin.Context) (int, int, bool) {
+	offset, err := strconv.ParseUint(c.Param("offset"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return 0, 0, false
+	}
+
+	limit, err := strconv.ParseUint(c.Param("limit"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return 0, 0, false
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return 0, 0, false
+	}
+
+	return int(offset), int(limit), true
+}
+
+func moderationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrUnknownDuplicateFlagStatus), services.IsModerationRequestErr(err):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAccountBanned):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrReportAlreadyOpen):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
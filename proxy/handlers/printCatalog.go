@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	printProductsEndpoint    = "/products"
+	printProductByIdEndpoint = "/products/:productId"
+)
+
+type printCatalogHandler struct {
+}
+
+func NewPrintCatalogHandler(groupHandler *groupHandler, authCfg config.AuthConfig) {
+	handler := &printCatalogHandler{}
+
+	endpoints := []EndpointHandler{
+		{Method: http.MethodGet, Path: printProductsEndpoint, HandlerFunc: handler.getAll, Permission: services.PermissionPrintCatalog},
+		{Method: http.MethodPost, Path: printProductsEndpoint, HandlerFunc: handler.add, Permission: services.PermissionPrintCatalog},
+		{Method: http.MethodPut, Path: printProductByIdEndpoint, HandlerFunc: handler.update, Permission: services.PermissionPrintCatalog},
+		{Method: http.MethodDelete, Path: printProductByIdEndpoint, HandlerFunc: handler.delete, Permission: services.PermissionPrintCatalog},
+	}
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseDreamshipUrl,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: endpoints,
+	}
+	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+}
+
+// @Summary List the print catalog.
+// @Description Lists every product of the catalog, disabled ones included. Admin only.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Success 200 {object} []entities.PrintProduct
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /print/products [get]
+func (handler *printCatalogHandler) getAll(c *gin.Context) {
+	products, err := services.GetPrintCatalog()
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, products, "")
+}
+
+// @Summary Add a product to the print catalog.
+// @Description Offers an item of the fulfillment provider for prints. The name defaults to the provider one. Admin only.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param request body services.PrintProductRequest true "product"
+// @Success 200 {object} entities.PrintProduct
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Failure 502 {object} dtos.ApiResponse
+// @Router /print/products [post]
+func (handler *printCatalogHandler) add(c *gin.Context) {
+	var request services.PrintProductRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	product, err := services.AddPrintProduct(&request)
+	if err != nil {
+		dtos.JsonResponse(c, printCatalogErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, product, "")
+}
+
+// @Summary Update a product of the print catalog.
+// @Description Renames, enables or disables a product. Admin only.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param productId path uint true "product id"
+// @Param request body services.PrintProductRequest true "product, the provider item id is ignored"
+// @Success 200 {object} entities.PrintProduct
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /print/products/{productId} [put]
+func (handler *printCatalogHandler) update(c *gin.Context) {
+	var request services.PrintProductRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	productId, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	product, err := services.UpdatePrintProduct(productId, &request)
+	if err != nil {
+		dtos.JsonResponse(c, printCatalogErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, product, "")
+}
+
+// @Summary Remove a product from the print catalog.
+// @Description Admin only.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param productId path uint true "product id"
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /print/products/{productId} [delete]
+func (handler *printCatalogHandler) delete(c *gin.Context) {
+	productId, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = services.DeletePrintProduct(productId)
+	if err != nil {
+		dtos.JsonResponse(c, printCatalogErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, nil, "")
+}
+
+func printCatalogErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, fulfillment.ErrItemNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPrintProductExists):
+		return http.StatusConflict
+	case errors.Is(err, fulfillment.ErrProviderRejected),
+		errors.Is(err, fulfillment.ErrProviderUnavailable):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/formatter"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	printCheckoutEndpoint         = "/checkout"
+	printCheckoutByIdEndpoint     = "/checkout/:checkoutId"
+	printCheckoutTemplateEndpoint = "/checkout/:checkoutId/template"
+	printCheckoutPaymentEndpoint  = "/checkout/:checkoutId/payment"
+	printOrdersEndpoint           = "/orders/list/:offset/:limit"
+	printOrderByIdEndpoint        = "/orders/:orderId"
+	printRoyaltiesEndpoint        = "/royalties/:offset/:limit"
+	printRoyaltyPayoutEndpoint    = "/royalties/:royaltyId/payout"
+)
+
+type printCheckoutHandler struct {
+	printCfg      config.PrintConfig
+	blockchainCfg config.BlockchainConfig
+	txFormatter   formatter.TxFormatter
+}
+
+func NewPrintCheckoutHandler(groupHandler *groupHandler, authCfg config.AuthConfig, printCfg config.PrintConfig, blockchainCfg config.BlockchainConfig) {
+	handler := &printCheckoutHandler{
+		printCfg:      printCfg,
+		blockchainCfg: blockchainCfg,
+		txFormatter:   formatter.NewTxFormatter(blockchainCfg),
+	}
+
+	endpoints := []EndpointHandler{
+		{Method: http.MethodPost, Path: printCheckoutEndpoint, HandlerFunc: handler.create},
+		{Method: http.MethodGet, Path: printCheckoutByIdEndpoint, HandlerFunc: handler.get},
+		{Method: http.MethodGet, Path: printCheckoutTemplateEndpoint, HandlerFunc: handler.getTemplate},
+		{Method: http.MethodPost, Path: printCheckoutPaymentEndpoint, HandlerFunc: handler.submitPayment},
+		{Method: http.MethodGet, Path: printOrdersEndpoint, HandlerFunc: handler.getOrders},
+		{Method: http.MethodGet, Path: printOrderByIdEndpoint, HandlerFunc: handler.getOrder},
+		{Method: http.MethodGet, Path: printRoyaltiesEndpoint, HandlerFunc: handler.getRoyalties},
+		{Method: http.MethodPost, Path: printRoyaltyPayoutEndpoint, HandlerFunc: handler.markRoyaltyPaid, Permission: services.PermissionPrintRoyalties},
+	}
+	endpointGroupHandler := EndpointGroupHandler{
+		Root:             baseDreamshipUrl,
+		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
+		EndpointHandlers: endpoints,
+	}
+	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
+}
+
+// @Summary Quote a print order.
+// @Description Prints tokens owned by the caller, checked in the index and on chain, using the token image. Quotes the provider cost, shipping and creator royalties of the order in EGLD and locks the quote for a short window. The order is only sent to the provider once paid.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param order body services.PrintOrderRequest true "print order"
+// @Success 200 {object} entities.PrintCheckout
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /print/checkout [post]
+func (handler *printCheckoutHandler) create(c *gin.Context) {
+	var request services.PrintOrderRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	checkout, err := services.CreatePrintCheckout(
+		handler.printCfg,
+		handler.blockchainApi(),
+		handler.blockchainCfg.MarketplaceAddress,
+		c.GetString(middleware.AddressKey),
+		&request,
+		time.Now(),
+	)
+	if err != nil {
+		dtos.JsonResponse(c, printCheckoutErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, checkout, "")
+}
+
+// @Summary Get a print checkout.
+// @Description Restricted to the address that created it.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param checkoutId path uint true "checkout id"
+// @Success 200 {object} entities.PrintCheckout
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /print/checkout/{checkoutId} [get]
+func (handler *printCheckoutHandler) get(c *gin.Context) {
+	checkout, ok := getOwnedPrintCheckout(c)
+	if !ok {
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, checkout, "")
+}
+
+// @Summary Get the payment tx-template of a print checkout.
+// @Description Builds the EGLD transfer paying the quote. Only account nonce and signature must be added afterwards.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param checkoutId path uint true "checkout id"
+// @Success 200 {object} formatter.Transaction
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /print/checkout/{checkoutId}/template [get]
+func (handler *printCheckoutHandler) getTemplate(c *gin.Context) {
+	checkout, ok := getOwnedPrintCheckout(c)
+	if !ok {
+		return
+	}
+
+	if checkout.Status != entities.PrintCheckoutQuoted {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "checkout already paid")
+		return
+	}
+	if checkout.QuoteExpiresAt <= time.Now().Unix() {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "quote expired")
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, handler.txFormatter.PrintPaymentTxTemplate(checkout), "")
+}
+
+// @Summary Submit the payment of a print checkout.
+// @Description Verifies the payment transaction on chain (final, sender, receiver, amount, checkout reference, sent before the quote expired), then forwards the order. Can be called again if forwarding failed.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param checkoutId path uint true "checkout id"
+// @Param request body services.SubmitPrintPaymentRequest true "payment tx hash"
+// @Success 200 {object} entities.PrintCheckout
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Failure 502 {object} dtos.ApiResponse
+// @Router /print/checkout/{checkoutId}/payment [post]
+func (handler *printCheckoutHandler) submitPayment(c *gin.Context) {
+	var request services.SubmitPrintPaymentRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	checkout, ok := getOwnedPrintCheckout(c)
+	if !ok {
+		return
+	}
+
+	checkout, err = services.SubmitPrintPayment(handler.blockchainApi(), checkout, request.TxHash, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, printCheckoutErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, checkout, "")
+}
+
+// @Summary List the print orders of the caller.
+// @Description Orders accepted by the fulfillment provider with their items, newest first.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Success 200 {object} services.PrintOrders
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /print/orders/list/{offset}/{limit} [get]
+func (handler *printCheckoutHandler) getOrders(c *gin.Context) {
+	offset, err := strconv.ParseUint(c.Param("offset"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(c.Param("limit"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	orders, err := services.GetPrintOrders(c.GetString(middleware.AddressKey), int(offset), int(limit))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, orders, "")
+}
+
+// @Summary Get a print order of the caller.
+// @Description Returns the order with its items, shipping address, tracking links and status history.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param orderId path uint true "order id"
+// @Success 200 {object} entities.PrintOrder
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /print/orders/{orderId} [get]
+func (handler *printCheckoutHandler) getOrder(c *gin.Context) {
+	orderId, err := strconv.ParseUint(c.Param("orderId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	order, err := services.GetPrintOrder(c.GetString(middleware.AddressKey), orderId)
+	if err != nil {
+		dtos.JsonResponse(c, printCheckoutErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, order, "")
+}
+
+// @Summary List the print royalties of the caller.
+// @Description Royalties owed to or paid to the caller as creator of printed collections, newest first.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Param status query string false "owed or paid"
+// @Success 200 {object} []entities.PrintRoyalty
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /print/royalties/{offset}/{limit} [get]
+func (handler *printCheckoutHandler) getRoyalties(c *gin.Context) {
+	offset, err := strconv.ParseUint(c.Param("offset"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(c.Param("limit"), 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	status := entities.PrintRoyaltyStatus(c.Query("status"))
+	if status != "" && status != entities.PrintRoyaltyOwed && status != entities.PrintRoyaltyPaid {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, "invalid status")
+		return
+	}
+
+	royalties, err := services.GetCreatorPrintRoyalties(c.GetString(middleware.AddressKey), status, int(offset), int(limit))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, royalties, "")
+}
+
+// @Summary Record the payout of a print royalty.
+// @Description Verifies the payout transaction on chain (final, sent from the print payment address to the creator, at least the owed amount) and closes the royalty.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param royaltyId path uint true "royalty id"
+// @Param request body services.MarkPrintRoyaltyPaidRequest true "payout tx hash"
+// @Success 200 {object} entities.PrintRoyalty
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Router /print/royalties/{royaltyId}/payout [post]
+func (handler *printCheckoutHandler) markRoyaltyPaid(c *gin.Context) {
+	var request services.MarkPrintRoyaltyPaidRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	royaltyId, err := strconv.ParseUint(c.Param("royaltyId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	royalty, err := services.MarkPrintRoyaltyPaid(handler.printCfg, handler.blockchainApi(), royaltyId, request.TxHash, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, printCheckoutErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, royalty, "")
+}
+
+func (handler *printCheckoutHandler) blockchainApi() string {
+	if handler.blockchainCfg.ApiUrlSec != "" {
+		return handler.blockchainCfg.ApiUrlSec
+	}
+
+	return handler.blockchainCfg.ApiUrl
+}
+
+func getOwnedPrintCheckout(c *gin.Context) (*entities.PrintCheckout, bool) {
+	checkoutId, err := strconv.ParseUint(c.Param("checkoutId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return nil, false
+	}
+
+	checkout, err := services.GetPrintCheckoutForAddress(c.GetString(middleware.AddressKey), checkoutId)
+	if err != nil {
+		dtos.JsonResponse(c, printCheckoutErrorStatus(err), nil, err.Error())
+		return nil, false
+	}
+
+	return checkout, true
+}
+
+func printCheckoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrPrintCheckoutNotOwned),
+		errors.Is(err, services.ErrPrintTokenNotOwned):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrPrintTokenNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrPrintPaymentTxUsed),
+		errors.Is(err, services.ErrPrintRoyaltyPaid):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrPrintPaymentPending),
+		errors.Is(err, services.ErrPrintPaymentFailed),
+		errors.Is(err, services.ErrPrintPaymentSender),
+		errors.Is(err, services.ErrPrintPaymentReceiver),
+		errors.Is(err, services.ErrPrintPaymentAmount),
+		errors.Is(err, services.ErrPrintPaymentData),
+		errors.Is(err, services.ErrPrintPaymentLate),
+		errors.Is(err, services.ErrUnknownPrintVariant),
+		errors.Is(err, services.ErrInvalidPrintQuantity),
+		errors.Is(err, services.ErrUnknownShippingMethod),
+		errors.Is(err, services.ErrEmptyPrintOrder),
+		errors.Is(err, services.ErrTooManyPrintItems),
+		errors.Is(err, services.ErrPrintTokenNoImage):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPrintOrderNotAccepted),
+		errors.Is(err, fulfillment.ErrProviderRejected),
+		errors.Is(err, fulfillment.ErrProviderUnavailable):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
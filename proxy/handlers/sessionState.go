@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
 
 	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 const (
@@ -20,6 +25,9 @@ const (
 	sessionStatesRetreiveEndpoint                          = "/retrieve"
 	sessionStatesUpdateEndpoint                            = "/update"
 	sessionStatesDeleteEndpoint                            = "/delete"
+	sessionStatesDraftTypesEndpoint                        = "/drafts/types"
+	sessionStatesDraftsEndpoint                            = "/drafts"
+	sessionStatesDraftByIdEndpoint                         = "/drafts/:draftId"
 )
 
 type stateSessionsHandler struct {
@@ -35,6 +43,12 @@ func NewSessionStatesHandler(groupHandler *groupHandler, authCfg config.AuthConf
 		{Method: http.MethodPost, Path: sessionStatesRetreiveEndpoint, HandlerFunc: handler.retrieve},
 		{Method: http.MethodPost, Path: sessionStatesUpdateEndpoint, HandlerFunc: handler.update},
 		{Method: http.MethodPost, Path: sessionStatesDeleteEndpoint, HandlerFunc: handler.delete},
+		{Method: http.MethodGet, Path: sessionStatesDraftTypesEndpoint, HandlerFunc: handler.getDraftTypes},
+		{Method: http.MethodGet, Path: sessionStatesDraftsEndpoint, HandlerFunc: handler.getDrafts},
+		{Method: http.MethodPost, Path: sessionStatesDraftsEndpoint, HandlerFunc: handler.createDraft},
+		{Method: http.MethodGet, Path: sessionStatesDraftByIdEndpoint, HandlerFunc: handler.getDraft},
+		{Method: http.MethodPut, Path: sessionStatesDraftByIdEndpoint, HandlerFunc: handler.updateDraft},
+		{Method: http.MethodDelete, Path: sessionStatesDraftByIdEndpoint, HandlerFunc: handler.deleteDraft},
 	}
 	endpointGroupHandler := EndpointGroupHandler{
 		Root:             baseSessionStatesEndpoint,
@@ -158,3 +172,180 @@ func (handler *stateSessionsHandler) delete(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, strResult, "")
 
 }
+
+// @Summary Get the draft types.
+// @Description Lists the registered draft types with the json schema their data is validated against.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Success 200 {object} []services.DraftTypeDefinition
+// @Router /session-states/drafts/types [get]
+func (handler *stateSessionsHandler) getDraftTypes(c *gin.Context) {
+	dtos.JsonResponse(c, http.StatusOK, services.GetDraftTypes(), "")
+}
+
+// @Summary Get the caller drafts.
+// @Description Lists the drafts of the caller that did not expire, most recently updated first.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Param type query string false "draft type"
+// @Success 200 {object} []entities.Draft
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /session-states/drafts [get]
+func (handler *stateSessionsHandler) getDrafts(c *gin.Context) {
+	draftType := entities.DraftType(c.Query("type"))
+
+	drafts, err := services.GetDraftsForAddress(c.GetString(middleware.AddressKey), draftType, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, draftErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, drafts, "")
+}
+
+// @Summary Create a draft.
+// @Description Saves a new draft of the caller. The data must match the schema of the draft type.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Param request body services.CreateDraftRequest true "draft"
+// @Success 200 {object} entities.Draft
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /session-states/drafts [post]
+func (handler *stateSessionsHandler) createDraft(c *gin.Context) {
+	var request services.CreateDraftRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	draft, err := services.CreateDraft(c.GetString(middleware.AddressKey), &request, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, draftErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, draft, "")
+}
+
+// @Summary Get a draft.
+// @Description Restricted to the draft owner.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Param draftId path uint true "draft id"
+// @Success 200 {object} entities.Draft
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /session-states/drafts/{draftId} [get]
+func (handler *stateSessionsHandler) getDraft(c *gin.Context) {
+	draftId, ok := getDraftIdFromPath(c)
+	if !ok {
+		return
+	}
+
+	draft, err := services.GetDraftForAddress(c.GetString(middleware.AddressKey), draftId, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, draftErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, draft, "")
+}
+
+// @Summary Update a draft.
+// @Description Replaces the name and data of a draft and extends its expiry. The version must be the one last read, otherwise 409 is returned and the draft should be read again.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Param draftId path uint true "draft id"
+// @Param request body services.UpdateDraftRequest true "draft"
+// @Success 200 {object} entities.Draft
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 409 {object} dtos.ApiResponse
+// @Router /session-states/drafts/{draftId} [put]
+func (handler *stateSessionsHandler) updateDraft(c *gin.Context) {
+	draftId, ok := getDraftIdFromPath(c)
+	if !ok {
+		return
+	}
+
+	var request services.UpdateDraftRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	draft, err := services.UpdateDraft(c.GetString(middleware.AddressKey), draftId, &request, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, draftErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, draft, "")
+}
+
+// @Summary Delete a draft.
+// @Description Restricted to the draft owner.
+// @Tags session-states
+// @Accept json
+// @Produce json
+// @Param draftId path uint true "draft id"
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /session-states/drafts/{draftId} [delete]
+func (handler *stateSessionsHandler) deleteDraft(c *gin.Context) {
+	draftId, ok := getDraftIdFromPath(c)
+	if !ok {
+		return
+	}
+
+	err := services.DeleteDraft(c.GetString(middleware.AddressKey), draftId, time.Now())
+	if err != nil {
+		dtos.JsonResponse(c, draftErrorStatus(err), nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, nil, "")
+}
+
+func getDraftIdFromPath(c *gin.Context) (uint64, bool) {
+	draftId, err := strconv.ParseUint(c.Param("draftId"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return 0, false
+	}
+
+	return draftId, true
+}
+
+func draftErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrDraftNotOwned):
+		return http.StatusUnauthorized
+	case errors.Is(err, storage.ErrDraftVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrUnknownDraftType),
+		errors.Is(err, services.ErrInvalidDraftData),
+		errors.Is(err, services.ErrDraftDataTooLarge),
+		errors.Is(err, services.ErrDraftNameTooLong),
+		errors.Is(err, services.ErrTooManyDrafts):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
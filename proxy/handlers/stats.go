@@ -12,7 +12,9 @@ import (
 	"github.com/ENFT-DAO/youbei-api/cache"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
 	"github.com/gin-gonic/gin"
 )
@@ -22,8 +24,8 @@ var (
 	StatsTotalVolumeLastUpdateKeyFormat = "Stats:Volume:TotalLastUpdate"
 	StatsTotalVolumeExpirePeriod        = 2 * time.Hour
 
-	StatsTotalVolumePerDayKeyFormat    = "Stats:Volume:%s"
-	StatsTotalVolumePerDayExpirePeriod = time.Hour * 24 * 1
+	StatsVolumeLastWeekKeyFormat    = "Stats:Volume:LastWeek"
+	StatsVolumeLastWeekExpirePeriod = 15 * time.Minute
 
 	StatsTokensTotalCountKeyFormat    = "Stats:Tokens:TotalCount"
 	StatsTokensTotalCountExpirePeriod = 15 * time.Minute
@@ -41,10 +43,12 @@ const (
 	StatTotalVolumeLastWeekPerDay       = "/volume/lastWeek"
 	StatTokensTotalCount                = "/tokens/totalCount"
 	StatListTransactionsWithPagination  = "/transactions/list/:timestamp/:currentPage/:nextPage"
+	StatVolumeHistory                   = "/volume/history/:period"
 )
 
 const (
-	StatsPageSize = 20
+	StatsPageSize   = 20
+	StatsDateLayout = "2006-01-02"
 )
 
 type statsHandler struct {
@@ -58,6 +62,7 @@ func NewStatsHandler(groupHandler *groupHandler) {
 		{Method: http.MethodGet, Path: StatTransactionsCountByDateEndpoint, HandlerFunc: handler.getTradeCounts},
 		{Method: http.MethodGet, Path: StatTotalVolumeEndpoint, HandlerFunc: handler.getTotalTradesVolume},
 		{Method: http.MethodGet, Path: StatTotalVolumeLastWeekPerDay, HandlerFunc: handler.getTotalTradesVolumeLastWeek},
+		{Method: http.MethodGet, Path: StatVolumeHistory, HandlerFunc: handler.getVolumeHistory},
 		{Method: http.MethodGet, Path: StatTokensTotalCount, HandlerFunc: handler.getTokensTotalCount},
 		{Method: http.MethodGet, Path: StatListTransactionsWithPagination, HandlerFunc: handler.getTransactionsListWithPagination},
 	}
@@ -157,12 +162,13 @@ func (handler *statsHandler) getTotalTradesVolume(c *gin.Context) {
 		totalVolume, _ = new(big.Float).SetString(totalStr.(string))
 		totalVolumeLastUpdate = totalLU.(int64)
 	} else {
-		// get it from database and also cache it
-		totalV, err := storage.GetTotalTradedVolume()
+		// get it from the monthly rollups and also cache it
+		total, err := storage.GetMarketVolumeTotal()
 		if err != nil {
 			dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 			return
 		}
+		totalV := big.NewFloat(total.BuyVolume)
 		totalVolume = totalV
 		totalVolumeLastUpdate = time.Now().UTC().Unix()
 
@@ -197,39 +203,40 @@ func (handler *statsHandler) getTotalTradesVolumeLastWeek(c *gin.Context) {
 
 	// Let's find out today
 	today := time.Now().UTC()
-	//dateFormat := "2021-02-01"
 
 	// Let's check the cache first
 	localCacher := cache.GetLocalCacher()
 
-	for i := 0; i < 7; i++ {
-		tempDate := today.Add(-24 * time.Duration(i) * time.Hour)
-		finalDate := fmt.Sprintf("%4d-%02d-%02d", tempDate.Year(), tempDate.Month(), tempDate.Day())
+	var rollups []entities.AggregatedVolumeRollup
+	cached, errRead := localCacher.Get(StatsVolumeLastWeekKeyFormat)
+	if errRead == nil {
+		rollups = cached.([]entities.AggregatedVolumeRollup)
+	} else {
+		// get it from the daily rollups and also cache it
+		var err error
+		rollups, err = storage.GetMarketVolumeRollups(entities.RollupDay, utils.DayKey(today.AddDate(0, 0, -6)), utils.DayKey(today))
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
 
-		var totalVolume *big.Float
-
-		key := fmt.Sprintf(StatsTotalVolumePerDayKeyFormat, finalDate)
-		totalStr, errRead := localCacher.Get(key)
-		if errRead == nil {
-			totalVolume, _ = new(big.Float).SetString(totalStr.(string))
-		} else {
-			// get it from database and also cache it
-			totalV, err := storage.GetTotalTradedVolumeByDate(finalDate)
-			if err != nil {
-				totalVolume = big.NewFloat(0)
-				//dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
-				//return
-			}
-			totalVolume = totalV
-
-			err = localCacher.SetWithTTLSync(StatsTotalVolumeKeyFormat, totalV.String(), StatsTotalVolumePerDayExpirePeriod)
-			if err != nil {
-				logInstance.Debug("could not set cache", "err", err)
-			}
+		err = localCacher.SetWithTTLSync(StatsVolumeLastWeekKeyFormat, rollups, StatsVolumeLastWeekExpirePeriod)
+		if err != nil {
+			logInstance.Debug("could not set cache", "err", err)
 		}
+	}
+
+	volumePerDay := make(map[int64]float64, len(rollups))
+	for _, rollup := range rollups {
+		volumePerDay[rollup.PeriodKey] = rollup.BuyVolume
+	}
+
+	for i := 0; i < 7; i++ {
+		tempDate := today.AddDate(0, 0, -i)
+		finalDate := fmt.Sprintf("%4d-%02d-%02d", tempDate.Year(), tempDate.Month(), tempDate.Day())
 
 		result = append(result, dtos.TradesVolume{
-			Sum:  totalVolume.String(),
+			Sum:  big.NewFloat(volumePerDay[utils.DayKey(tempDate)]).String(),
 			Date: finalDate,
 		})
 	}
@@ -237,6 +244,57 @@ func (handler *statsHandler) getTotalTradesVolumeLastWeek(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, result, "")
 }
 
+// @Summary Gets Volume History
+// @Description Gets the daily, weekly or monthly volume rollups between two dates (YYYY-MM-DD, UTC), optionally for a single collection
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param period path string true "day, week or month"
+// @Param from query string true "first day"
+// @Param to query string false "last day, defaults to today"
+// @Param collectionId query string false "collection token id"
+// @Success 200 {object} []entities.AggregatedVolumeRollup
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /stats/volume/history/{period} [get]
+func (handler *statsHandler) getVolumeHistory(c *gin.Context) {
+	period := entities.RollupPeriod(c.Param("period"))
+
+	from, err := time.Parse(StatsDateLayout, c.Query("from"))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	to := time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		to, err = time.Parse(StatsDateLayout, toStr)
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+	}
+
+	collectionId := uint64(0)
+	if tokenId := c.Query("collectionId"); tokenId != "" {
+		cacheInfo, innerErr := collstats.GetOrAddCollectionCacheInfo(tokenId)
+		if innerErr != nil {
+			dtos.JsonResponse(c, http.StatusNotFound, nil, innerErr.Error())
+			return
+		}
+		collectionId = cacheInfo.CollectionId
+	}
+
+	rollups, err := services.GetVolumeHistory(period, from, to, collectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, rollups, "")
+}
+
 // @Summary Gets Total Tokens Count
 // @Description Gets Total Tokens Count
 // @Tags stats
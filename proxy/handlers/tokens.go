@@ -28,6 +28,7 @@ const (
 	tokenStakeEndpoint               = "/stake-fc/:walletAddress/:tokenName/:tokenNonce"
 	offersForTokenIdAndNonceEndpoint = "/:tokenId/:nonce/offers/:offset/:limit"
 	bidsForTokenIdAndNonceEndpoint   = "/:tokenId/:nonce/bids/:offset/:limit"
+	tokenProvenanceEndpoint          = "/:tokenId/:nonce/provenance/:offset/:limit"
 	refreshTokenMetadataEndpoint     = "/:tokenId/:nonce/refresh"
 	tokenMetadataRelayEndpoint       = "/metadata/relay"
 	tokensListMetadataEndpoint       = "/list/:offset/:limit"
@@ -67,6 +68,7 @@ func NewTokensHandler(groupHandler *groupHandler, authCfg config.AuthConfig, cfg
 		{Method: http.MethodPost, Path: availableTokensEndpoint, HandlerFunc: handler.getAvailableTokens},
 		{Method: http.MethodGet, Path: offersForTokenIdAndNonceEndpoint, HandlerFunc: handler.getOffers},
 		{Method: http.MethodGet, Path: bidsForTokenIdAndNonceEndpoint, HandlerFunc: handler.getBids},
+		{Method: http.MethodGet, Path: tokenProvenanceEndpoint, HandlerFunc: handler.getProvenance},
 		{Method: http.MethodGet, Path: tokenMetadataRelayEndpoint, HandlerFunc: handler.relayMetadataResponse},
 		{Method: http.MethodPost, Path: refreshTokenMetadataEndpoint, HandlerFunc: handler.refresh},
 		{Method: http.MethodPost, Path: tokensListMetadataEndpoint, HandlerFunc: handler.getList},
@@ -171,7 +173,7 @@ func (handler *tokensHandler) stake(c *gin.Context) {
 		api = handler.blockchainConfig.ApiUrlSec
 	}
 
-	services.StakeToken(request, api, handler.blockchainConfig.MarketplaceAddress)
+	services.StakeToken(request, api, handler.blockchainConfig.StakingAddress)
 
 	dtos.JsonResponse(c, http.StatusOK, nil, "")
 }
@@ -379,6 +381,65 @@ func (handler *tokensHandler) getBids(c *gin.Context) {
 	dtos.JsonResponse(c, http.StatusOK, bidsDtos, "")
 }
 
+// @Summary Get provenance of token
+// @Description Retrieves every change of state of a token (identified by tokenId and nonce), oldest first, starting with its mint
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenId path string true "token id"
+// @Param nonce path int true "token nonce"
+// @Param offset path uint true "offset"
+// @Param limit path uint true "limit"
+// @Success 200 {object} services.TokenProvenance
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /tokens/{tokenId}/{nonce}/provenance/{offset}/{limit} [get]
+func (handler *tokensHandler) getProvenance(c *gin.Context) {
+	tokenId := c.Param("tokenId")
+	nonceString := c.Param("nonce")
+	offsetStr := c.Param("offset")
+	limitStr := c.Param("limit")
+
+	nonce, err := strconv.ParseUint(nonceString, 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseUint(offsetStr, 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseUint(limitStr, 10, 0)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	err = ValidateLimit(limit)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	token, err := storage.GetTokenByTokenIdAndNonce(tokenId, nonce)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	provenance, err := services.GetTokenProvenance(token, int(offset), int(limit))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, provenance, "")
+}
+
 // @Summary Gets metadata link response. Cached.
 // @Description Make request with ?url=link
 // @Tags tokens
@@ -7,11 +7,13 @@ import (
 
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/formatter"
+	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
@@ -46,6 +48,8 @@ const (
 	updateSaleStartFormatEndpoint              = "/update-sale-start/:userAddress/:contractAddress/:saleStart"
 	updateBuyerWhiteListCheckFormatEndpoint    = "/update-buyer-whitelist-check/:userAddress/:contractAddress/:whiteListCheck"
 	getBuyerWhiteListCheckFormatEndpoint       = "/get-buyer-whitelist-check/:userAddress/:contractAddress"
+	setWhitelistMerkleRootFormatEndpoint       = "/set-whitelist-root/:userAddress/:collectionId"
+	updateMintPhasesFormatEndpoint             = "/update-mint-phases/:userAddress/:collectionId"
 )
 
 type txTemplateHandler struct {
@@ -88,6 +92,8 @@ func NewTxTemplateHandler(groupHandler *groupHandler, blockchainConfig config.Bl
 		{Method: http.MethodGet, Path: updateSaleStartFormatEndpoint, HandlerFunc: handler.updateSaleStart},
 		{Method: http.MethodGet, Path: updateBuyerWhiteListCheckFormatEndpoint, HandlerFunc: handler.updateBuyerWhiteListCheck},
 		{Method: http.MethodGet, Path: getBuyerWhiteListCheckFormatEndpoint, HandlerFunc: handler.getBuyerWhiteListCheck},
+		{Method: http.MethodGet, Path: setWhitelistMerkleRootFormatEndpoint, HandlerFunc: handler.setWhitelistMerkleRoot},
+		{Method: http.MethodGet, Path: updateMintPhasesFormatEndpoint, HandlerFunc: handler.updateMintPhases},
 	}
 
 	endpointGroupHandler := EndpointGroupHandler{
@@ -643,10 +649,30 @@ func (handler *txTemplateHandler) getMintNftTxTemplate(c *gin.Context) {
 		dtos.JsonResponse(c, http.StatusBadRequest, nil, "you can't mint more than 10 per batch")
 		return
 	}
+
+	mintPrice := collection.MintPricePerTokenNominal
+	phases, err := storage.GetMintPhasesByCollectionId(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+	if len(phases) > 0 {
+		currentPhase, _ := services.FindMintPhase(phases, uint64(time.Now().Unix()))
+		if currentPhase == nil {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, "no mint phase is open")
+			return
+		}
+		if currentPhase.WalletCap != 0 && numberOfTokens > currentPhase.WalletCap {
+			dtos.JsonResponse(c, http.StatusBadRequest, nil, "you can't mint more than the phase wallet cap")
+			return
+		}
+		mintPrice = currentPhase.PriceNominal
+	}
+
 	template, err := handler.txFormatter.NewMintNftsTxTemplate(
 		userAddress,
 		collection.ContractAddress,
-		collection.MintPricePerTokenNominal,
+		mintPrice,
 		numberOfTokens,
 		collection.CollectionTokenID,
 		[]byte(""),
@@ -1007,3 +1033,95 @@ func (handler *txTemplateHandler) getBuyerWhiteListCheck(c *gin.Context) {
 
 	dtos.JsonResponse(c, http.StatusOK, template, "")
 }
+
+// @Summary Gets tx-template for pushing a whitelist merkle root.
+// @Description Retrieves tx-template that sets the latest (or the given) whitelist merkle root on the collection minter. Only account nonce and signature must be added afterwards.
+// @Tags tx-template
+// @Accept json
+// @Produce json
+// @Param userAddress path string true "user address"
+// @Param collectionId path string true "collection id"
+// @Param version query uint false "snapshot version"
+// @Success 200 {object} formatter.Transaction
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /tx-template/set-whitelist-root/{userAddress}/{collectionId} [get]
+func (handler *txTemplateHandler) setWhitelistMerkleRoot(c *gin.Context) {
+	userAddress := c.Param("userAddress")
+	tokenId := c.Param("collectionId")
+
+	version, err := merkleVersion(c)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	var root *entities.WhitelistMerkleRoot
+	if version == 0 {
+		root, err = storage.GetLatestWhitelistMerkleRoot(collection.ID)
+	} else {
+		root, err = storage.GetWhitelistMerkleRoot(collection.ID, version)
+	}
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	template, err := handler.txFormatter.SetWhitelistMerkleRootTxTemplate(userAddress, collection.ContractAddress, root.Root, root.Version)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, template, "")
+}
+
+// @Summary Gets tx-templates for the mint phases of a collection.
+// @Description Retrieves one setMintPhase tx-template per stored phase, in order. Only account nonce and signature must be added afterwards.
+// @Tags tx-template
+// @Accept json
+// @Produce json
+// @Param userAddress path string true "user address"
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} []formatter.Transaction
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /tx-template/update-mint-phases/{userAddress}/{collectionId} [get]
+func (handler *txTemplateHandler) updateMintPhases(c *gin.Context) {
+	userAddress := c.Param("userAddress")
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	phases, err := storage.GetMintPhasesByCollectionId(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+	if len(phases) == 0 {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, "no mint phases")
+		return
+	}
+
+	templates, err := handler.txFormatter.UpdateMintPhasesTemplateTxTemplates(userAddress, collection.ContractAddress, phases)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, templates, "")
+}
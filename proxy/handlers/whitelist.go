@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
 	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 const (
-	baseWhitelistEndpoint    = "/whitelists"
-	whitelistByAddress       = "/:address"
-	whitelistByAddressAmount = "/:address/:amount"
-	//whitelistBuyLimitByAddresses = "buy-limit/:contract-address/:user-address"
+	baseWhitelistEndpoint      = "/whitelists"
+	whitelistByCollection      = "/:collectionId"
+	whitelistByAddress         = "/:collectionId/:address"
+	whitelistImportEndpoint    = "/:collectionId/import"
+	whitelistExportEndpoint    = "/:collectionId/export"
+	whitelistReconcileEndpoint = "/:collectionId/reconcile"
+	whitelistMerkleEndpoint    = "/:collectionId/merkle"
+	whitelistProofEndpoint     = "/:collectionId/proof/:address"
 )
 
 type whitelistHandler struct {
@@ -27,34 +36,66 @@ func NewWhitelistHandler(groupHandler *groupHandler, authCfg config.AuthConfig,
 	handler := &whitelistHandler{blockchainCfg: blockchainCfg}
 
 	endpoints := []EndpointHandler{
-		{Method: http.MethodPost, Path: whitelistByAddress, HandlerFunc: handler.GetWhitelistByAddress},
-		{Method: http.MethodPost, Path: whitelistByAddressAmount, HandlerFunc: handler.UpdateWhitelistAmountByAddress},
-		/*{Method: http.MethodPost, Path: whitelistLimitByAddresses, HandlerFunc: handler.GetWhitelistLimitByAddresses},*/
+		{Method: http.MethodGet, Path: whitelistByCollection, HandlerFunc: handler.getCollectionWhitelist, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodPost, Path: whitelistByCollection, HandlerFunc: handler.setWhitelistEntry, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodGet, Path: whitelistByAddress, HandlerFunc: handler.getWhitelistByAddress},
+		{Method: http.MethodDelete, Path: whitelistByAddress, HandlerFunc: handler.deleteWhitelistEntry, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodPost, Path: whitelistImportEndpoint, HandlerFunc: handler.importWhitelist, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodGet, Path: whitelistExportEndpoint, HandlerFunc: handler.exportWhitelist, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodPost, Path: whitelistReconcileEndpoint, HandlerFunc: handler.reconcileWhitelist, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodGet, Path: whitelistMerkleEndpoint, HandlerFunc: handler.getWhitelistMerkleRoot},
+		{Method: http.MethodPost, Path: whitelistMerkleEndpoint, HandlerFunc: handler.buildWhitelistMerkleRoot, Permission: services.PermissionCollectionWhitelist},
+		{Method: http.MethodGet, Path: whitelistProofEndpoint, HandlerFunc: handler.getWhitelistMerkleProof},
 	}
 	endpointGroupHandler := EndpointGroupHandler{
-		Root:             baseCollectionsEndpoint,
+		Root:             baseWhitelistEndpoint,
 		Middlewares:      []gin.HandlerFunc{middleware.Authorization(authCfg.JwtSecret)},
 		EndpointHandlers: endpoints,
 	}
 	groupHandler.AddEndpointGroupHandler(endpointGroupHandler)
 }
 
-// @Summary Set collection info.
-// @Description Sets info for a collection.
-// @Tags collections
+// @Summary Get the whitelist of a collection.
+// @Description Lists every allocation of the collection. Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
 // @Accept json
 // @Produce json
 // @Param collectionId path string true "collection id"
-// @Param updateCollectionRequest body services.UpdateCollectionRequest true "collection info"
-// @Success 200 {object} entities.Collection
+// @Success 200 {object} []entities.Whitelist
 // @Failure 401 {object} dtos.ApiResponse
 // @Failure 404 {object} dtos.ApiResponse
 // @Failure 500 {object} dtos.ApiResponse
-// @Router /collections/{collectionId} [post]
-func (handler *whitelistHandler) UpdateWhitelistAmountByAddress(c *gin.Context) {
+// @Router /whitelists/{collectionId} [get]
+func (handler *whitelistHandler) getCollectionWhitelist(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	whitelists, err := services.GetCollectionWhitelist(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, whitelists, "")
+}
+
+// @Summary Add or update a whitelist allocation.
+// @Description Sets the amount of an address for a phase (1 buy, 2 mint, 3 both). Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param setWhitelistRequest body services.SetWhitelistRequest true "whitelist allocation"
+// @Success 200 {object} entities.Whitelist
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId} [post]
+func (handler *whitelistHandler) setWhitelistEntry(c *gin.Context) {
 	var request services.SetWhitelistRequest
-	address := c.Param("address")
-	strAmount := c.Param("amount")
 
 	err := c.BindJSON(&request)
 	if err != nil {
@@ -62,49 +103,379 @@ func (handler *whitelistHandler) UpdateWhitelistAmountByAddress(c *gin.Context)
 		return
 	}
 
-	whitelist, err := storage.GetWhitelistByAddress(address)
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	before, err := services.GetWhitelistAuditState(collection.ID, request.Address)
 	if err != nil {
-		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	whitelist, err := services.SetCollectionWhitelistEntry(collection.ID, &request)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	recordWhitelistAudit(c, services.AuditWhitelistSet, collection, request.Address, before)
+
+	dtos.JsonResponse(c, http.StatusOK, whitelist, "")
+}
+
+// @Summary Get the allocations of an address.
+// @Description Restricted to the address itself, the collection whitelist managers or an admin.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param address path string true "wallet address"
+// @Success 200 {object} []entities.Whitelist
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/{address} [get]
+func (handler *whitelistHandler) getWhitelistByAddress(c *gin.Context) {
+	address := c.Param("address")
+
+	collection, ok := getAddressCollection(c, address)
+	if !ok {
+		return
+	}
+
+	whitelists, err := services.GetWhitelistForAddress(collection.ID, address)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, whitelists, "")
+}
+
+// @Summary Remove a whitelist allocation.
+// @Description Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param address path string true "wallet address"
+// @Param type query uint true "whitelist type"
+// @Success 200 {object} dtos.ApiResponse
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/{address} [delete]
+func (handler *whitelistHandler) deleteWhitelistEntry(c *gin.Context) {
+	address := c.Param("address")
+
+	whitelistType, err := services.ParseWhitelistType(c.Query("type"))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
-	//parse the string amount to Uint amount
-	amount, err := strconv.ParseUint(strAmount, 10, 64)
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
 
+	before, err := services.GetWhitelistAuditState(collection.ID, address)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	err = services.DeleteCollectionWhitelistEntry(collection.ID, address, whitelistType)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
 		return
 	}
 
-	//err = services.UpdateWhitelistAmountByAddress(amount, address)
-	err = storage.UpdateWhitelistAmountByAddress(amount, address)
+	recordWhitelistAudit(c, services.AuditWhitelistDelete, collection, address, before)
+
+	dtos.JsonResponse(c, http.StatusOK, nil, "")
+}
+
+// @Summary Import whitelist allocations.
+// @Description Adds or updates allocations from a csv (address,amount,type) or a json array body. Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
+// @Accept plain
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {object} []entities.Whitelist
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/import [post]
+func (handler *whitelistHandler) importWhitelist(c *gin.Context) {
+	format := whitelistFormat(c)
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	before, err := services.GetWhitelistAuditState(collection.ID, "")
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, whitelist, "")
+	whitelists, err := services.ImportCollectionWhitelist(collection.ID, format, c.Request.Body)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	recordWhitelistAudit(c, services.AuditWhitelistImport, collection, "", before)
+
+	dtos.JsonResponse(c, http.StatusOK, whitelists, "")
+}
+
+// @Summary Export the whitelist of a collection.
+// @Description Exports every allocation as a csv file or as json. Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
+// @Produce application/csv
+// @Param collectionId path string true "collection id"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Failure 500 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/export [get]
+func (handler *whitelistHandler) exportWhitelist(c *gin.Context) {
+	format := whitelistFormat(c)
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	if format == services.WhitelistFormatJson {
+		whitelists, err := services.GetCollectionWhitelist(collection.ID)
+		if err != nil {
+			dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		dtos.JsonResponse(c, http.StatusOK, whitelists, "")
+		return
+	}
+
+	buff, err := services.ExportCollectionWhitelistCsv(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusInternalServerError, nil, err.Error())
+		return
+	}
+
+	dtos.ContentAsFileResponse(c, fmt.Sprintf("whitelist-%s.csv", collection.CollectionTokenID), buff)
 }
 
-// @Summary Gets mint info about a collection.
-// @Description Retrieves max supply and total sold for a collection. Cached for 6 seconds.
-// @Tags collections
+// @Summary Reconcile the whitelist with the contract.
+// @Description Compares a page of the buy allocations with the contract's getBuyLimit, pass the returned next cursor as after to get the following page. With apply=true the stored amounts are set to the contract values and allocations the contract gives nothing are deleted. Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
 // @Accept json
 // @Produce json
 // @Param collectionId path string true "collection id"
-// @Success 200 {object} services.MintInfo
+// @Param after query uint false "cursor of the page"
+// @Param limit query uint false "entries per page, at most 100"
+// @Param apply query bool false "update mismatching allocations"
+// @Success 200 {object} services.WhitelistReconciliationPage
 // @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
 // @Failure 404 {object} dtos.ApiResponse
-// @Failure 500 {object} dtos.ApiResponse
-// @Router /collections/{collectionId}/mintInfo [get]
-func (handler *whitelistHandler) GetWhitelistByAddress(c *gin.Context) {
+// @Router /whitelists/{collectionId}/reconcile [post]
+func (handler *whitelistHandler) reconcileWhitelist(c *gin.Context) {
+	apply := c.Query("apply") == "true"
+
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.MaxWhitelistReconcileEntries)))
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	page, err := services.ReconcileCollectionWhitelist(collection, after, limit, apply)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, page, "")
+}
+
+// @Summary Build a whitelist merkle root.
+// @Description Freezes the mint allocations of the collection into a new merkle snapshot version. Restricted to the collection owner, its whitelist managers or an admin.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Success 200 {object} entities.WhitelistMerkleRoot
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/merkle [post]
+func (handler *whitelistHandler) buildWhitelistMerkleRoot(c *gin.Context) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	root, err := services.BuildWhitelistMerkleSnapshot(collection.ID)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, root, "")
+}
+
+// @Summary Get a whitelist merkle root.
+// @Description Returns the latest merkle snapshot of the collection, or the requested version.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param version query uint false "snapshot version"
+// @Success 200 {object} entities.WhitelistMerkleRoot
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/merkle [get]
+func (handler *whitelistHandler) getWhitelistMerkleRoot(c *gin.Context) {
+	version, err := merkleVersion(c)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return
+	}
+
+	root, err := services.GetWhitelistMerkleRoot(collection.ID, version)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return
+	}
+
+	dtos.JsonResponse(c, http.StatusOK, root, "")
+}
+
+// @Summary Get the merkle proof of an address.
+// @Description Returns the allocation and proof of the address in the latest merkle snapshot, or in the requested version. Restricted to the address itself, the collection whitelist managers or an admin.
+// @Tags whitelists
+// @Accept json
+// @Produce json
+// @Param collectionId path string true "collection id"
+// @Param address path string true "wallet address"
+// @Param version query uint false "snapshot version"
+// @Success 200 {object} services.WhitelistMerkleProof
+// @Failure 400 {object} dtos.ApiResponse
+// @Failure 401 {object} dtos.ApiResponse
+// @Failure 404 {object} dtos.ApiResponse
+// @Router /whitelists/{collectionId}/proof/{address} [get]
+func (handler *whitelistHandler) getWhitelistMerkleProof(c *gin.Context) {
 	address := c.Param("address")
 
-	whitelist, err := services.GetWhitelist(address) //storage.GetWhitelistByAddress(address)
+	version, err := merkleVersion(c)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	collection, ok := getAddressCollection(c, address)
+	if !ok {
+		return
+	}
+
+	proof, err := services.GetWhitelistMerkleProof(collection.ID, version, address)
 	if err != nil {
 		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
 		return
 	}
 
-	dtos.JsonResponse(c, http.StatusOK, whitelist, "")
+	dtos.JsonResponse(c, http.StatusOK, proof, "")
+}
+
+func merkleVersion(c *gin.Context) (uint64, error) {
+	versionStr := c.Query("version")
+	if versionStr == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(versionStr, 10, 64)
+}
+
+func whitelistFormat(c *gin.Context) string {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	if format == "" {
+		return services.WhitelistFormatCsv
+	}
+
+	return format
+}
+
+// getCollectionFromPath returns the collection loaded by the permission middleware, or loads it from the path.
+func getCollectionFromPath(c *gin.Context) (*entities.Collection, bool) {
+	if value, exists := c.Get(middleware.CollectionKey); exists {
+		if collection, ok := value.(*entities.Collection); ok {
+			return collection, true
+		}
+	}
+
+	tokenId := c.Param("collectionId")
+
+	cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(tokenId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return nil, false
+	}
+
+	collection, err := storage.GetCollectionById(cacheInfo.CollectionId)
+	if err != nil {
+		dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+		return nil, false
+	}
+
+	return collection, true
+}
+
+// recordWhitelistAudit audits a change of the allocations of the address, or of the whole collection when it is empty.
+func recordWhitelistAudit(c *gin.Context, action string, collection *entities.Collection, address string, before map[string]uint64) {
+	after, err := services.GetWhitelistAuditState(collection.ID, address)
+	if err != nil {
+		zlog.Error("could not read whitelist for audit", zap.Uint64("collectionId", collection.ID), zap.Error(err))
+		return
+	}
+
+	services.RecordAudit(auditActor(c), action, services.AuditTargetCollection, services.CollectionAuditTarget(collection), before, after)
+}
+
+// getAddressCollection loads the collection of the path for the address itself or a whitelist manager.
+func getAddressCollection(c *gin.Context, address string) (*entities.Collection, bool) {
+	collection, ok := getCollectionFromPath(c)
+	if !ok {
+		return nil, false
+	}
+
+	if c.GetString(middleware.AddressKey) != address && !middleware.HasPermission(c, services.PermissionCollectionWhitelist, collection) {
+		dtos.JsonResponse(c, http.StatusUnauthorized, nil, "")
+		return nil, false
+	}
+
+	return collection, true
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ENFT-DAO/youbei-api/crypto"
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +15,16 @@ const (
 	noBearerPresent = "No authorization bearer provided"
 	incorrectBearer = "Incorrect bearer provided"
 	invalidJwtToken = "Invalid or expired token"
+	revokedSession  = "Session revoked"
 
 	bearerSplitOn = "Bearer "
 	authHeaderKey = "Authorization"
 
-	AddressKey = "address"
-	IsAdminKey = "isAdmin"
+	AddressKey         = "address"
+	IsAdminKey         = "isAdmin"
+	SessionIdKey       = "sessionId"
+	RoleKey            = "role"
+	CollectionRolesKey = "collectionRoles"
 )
 
 var returnUnauthorized = func(c *gin.Context, errMessage string) {
@@ -49,15 +54,19 @@ func Authorization(secret string) gin.HandlerFunc {
 			return
 		}
 
-		//claims.Address
-		// Get the account base on web-wallet address
-		// check the role if it's "RoleAdmin"
-
-		account, _ := services.GetOrCreateAccount(claims.Address)
-		isRoleAdmin := (account.Role == "RoleAdmin")
+		revoked, err := services.IsAuthSessionRevoked(claims.SessionId)
+		if err != nil || revoked {
+			returnUnauthorized(c, revokedSession)
+			c.Abort()
+			return
+		}
 
+		// the roles are cached in the claims when the jwt is issued
 		c.Set(AddressKey, claims.Address)
-		c.Set(IsAdminKey, isRoleAdmin)
+		c.Set(IsAdminKey, entities.AccountRole(claims.Role) == entities.RoleAdmin)
+		c.Set(RoleKey, claims.Role)
+		c.Set(CollectionRolesKey, claims.CollectionRoles)
+		c.Set(SessionIdKey, claims.SessionId)
 		c.Next()
 	}
 }
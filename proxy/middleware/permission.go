@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/services"
+	"github.com/ENFT-DAO/youbei-api/stats/collstats"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CollectionKey holds the collection of the path once a collection permission was checked.
+	CollectionKey = "collection"
+
+	missingPermission = "Missing permission"
+)
+
+// RequirePermission must run after Authorization. For a collection permission, the collection is read
+// from the collectionId path param and kept under CollectionKey for the handler.
+func RequirePermission(permission services.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var collection *entities.Collection
+		if services.IsCollectionPermission(permission) {
+			cacheInfo, err := collstats.GetOrAddCollectionCacheInfo(c.Param("collectionId"))
+			if err != nil {
+				dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+				c.Abort()
+				return
+			}
+
+			collection, err = storage.GetCollectionById(cacheInfo.CollectionId)
+			if err != nil {
+				dtos.JsonResponse(c, http.StatusNotFound, nil, err.Error())
+				c.Abort()
+				return
+			}
+			c.Set(CollectionKey, collection)
+		}
+
+		if !HasPermission(c, permission, collection) {
+			returnUnauthorized(c, missingPermission)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasPermission checks the roles of the jwt. A collection role missing from the claims is looked up,
+// so a role granted after the jwt was issued applies right away.
+func HasPermission(c *gin.Context, permission services.Permission, collection *entities.Collection) bool {
+	if services.GlobalRoleHasPermission(entities.AccountRole(c.GetString(RoleKey)), permission) {
+		return true
+	}
+
+	if collection == nil || !services.IsCollectionPermission(permission) {
+		return false
+	}
+
+	claimed, _ := c.Get(CollectionRolesKey)
+	collectionRoles, _ := claimed.(map[uint64]string)
+	role := entities.CollectionRoleType(collectionRoles[collection.ID])
+	if services.CollectionRoleHasPermission(role, permission) {
+		return true
+	}
+
+	role, err := services.GetCollectionRoleOf(collection, c.GetString(AddressKey))
+	if err != nil {
+		return false
+	}
+
+	return services.CollectionRoleHasPermission(role, permission)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+	RequestIdKey    = "requestId"
+
+	maxRequestIdLen = 64
+	requestIdBytes  = 16
+)
+
+// RequestId keeps the request id sent by the client, or generates one, and echoes it in the response.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.Request.Header.Get(RequestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLen {
+			requestId = newRequestId()
+		}
+
+		c.Set(RequestIdKey, requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+func newRequestId() string {
+	idBytes := make([]byte, requestIdBytes)
+	_, err := rand.Read(idBytes)
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(idBytes)
+}
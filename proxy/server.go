@@ -12,6 +12,7 @@ import (
 	"github.com/ENFT-DAO/youbei-api/process"
 	"github.com/ENFT-DAO/youbei-api/proxier"
 	"github.com/ENFT-DAO/youbei-api/proxy/handlers"
+	"github.com/ENFT-DAO/youbei-api/proxy/middleware"
 	"github.com/ENFT-DAO/youbei-api/services"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -22,6 +23,7 @@ var corsHeaders = []string{
 	"Content-Length",
 	"Content-Type",
 	"Authorization",
+	middleware.RequestIdHeader,
 }
 
 var ctx = context.Background()
@@ -49,6 +51,7 @@ func NewWebServer(cfg *config.GeneralConfig) (*webServer, error) {
 	//corsCfg.a
 
 	router.Use(cors.New(corsCfg))
+	router.Use(middleware.RequestId())
 
 	groupHandler := handlers.NewGroupHandler()
 
@@ -65,8 +68,19 @@ func NewWebServer(cfg *config.GeneralConfig) (*webServer, error) {
 	if err != nil {
 		return nil, err
 	}
+	mintIndexer, err := indexer.NewMintIndexer(cfg.Blockchain.ApiUrl, cfg.Blockchain.ApiUrlSec, cfg.Blockchain.CollectionAPIDelay)
+	if err != nil {
+		return nil, err
+	}
+	tokenHistoryIndexer, err := indexer.NewTokenHistoryIndexer(cfg.Blockchain.ApiUrl, cfg.Blockchain.ApiUrlSec, cfg.Blockchain.CollectionAPIDelay)
+	if err != nil {
+		return nil, err
+	}
 	go collectionIndexer.StartWorker()
 	go marketPlaceIndexer.StartWorker()
+	go mintIndexer.StartWorker()
+	go tokenHistoryIndexer.StartWorker()
+	go services.StartDraftSweeper()
 	observerMonitor := process.NewObserverMonitor(
 		bot,
 		ctx,
@@ -95,11 +109,13 @@ func NewWebServer(cfg *config.GeneralConfig) (*webServer, error) {
 		return nil, err
 	}
 
-	handlers.NewAuthHandler(groupHandler, *authService)
+	handlers.NewAuthHandler(groupHandler, *authService, cfg.Auth)
 	handlers.NewTokensHandler(groupHandler, cfg.Auth, cfg.Blockchain)
 	handlers.NewCollectionsHandler(groupHandler, cfg.Auth, cfg.Blockchain, cfg.CarbonSetting)
+	handlers.NewWhitelistHandler(groupHandler, cfg.Auth, cfg.Blockchain)
 
 	handlers.NewSessionStatesHandler(groupHandler, cfg.Auth, cfg.Blockchain)
+	handlers.NewLaunchHandler(groupHandler, cfg.Auth, cfg.Blockchain)
 
 	handlers.NewTransactionsHandler(groupHandler)
 	handlers.NewTxTemplateHandler(groupHandler, cfg.Blockchain)
@@ -114,8 +130,12 @@ func NewWebServer(cfg *config.GeneralConfig) (*webServer, error) {
 	handlers.NewReportHandler(groupHandler)
 	handlers.NewActivitiesHandler(groupHandler)
 	handlers.NewExplorerHandler(groupHandler)
+	handlers.NewAuditHandler(groupHandler, cfg.Auth)
 
-	handlers.NewDreamshipHandler(groupHandler, cfg.ExternalCredential)
+	handlers.NewDreamshipHandler(groupHandler)
+	handlers.NewPrintCheckoutHandler(groupHandler, cfg.Auth, cfg.Print, cfg.Blockchain)
+	handlers.NewPrintCatalogHandler(groupHandler, cfg.Auth)
+	handlers.NewModerationHandler(groupHandler, cfg.Auth, cfg.Blockchain)
 
 	//
 
@@ -83,6 +83,10 @@ func CreateAccount(addres string, request *SetAccountRequest) (*entities.Account
 }
 
 func UpdateAccount(account *entities.Account, request *SetAccountRequest) error {
+	if account.IsBanned {
+		return ErrAccountBanned
+	}
+
 	err := checkValidSetAccountRequest(request)
 	if err != nil {
 		return err
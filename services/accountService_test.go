@@ -24,7 +24,7 @@ func Test_GetOrCreateAccount(t *testing.T) {
 
 func Test_SearchAccount(T *testing.T) {
 	connectToDb()
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 
 	acc := &entities.Account{
 		Name: "this name is uniquee",
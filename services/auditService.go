@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	AuditCollectionUpdate        = "collection.update"
+	AuditCollectionAdminSection  = "collection.adminSection"
+	AuditCollectionTrending      = "collection.trending"
+	AuditCollectionStaking       = "collection.staking"
+	AuditCollectionMintStartDate = "collection.mintStartDate"
+	AuditCollectionMintPhases    = "collection.mintPhases"
+	AuditCollectionRoleSet       = "collection.role.set"
+	AuditCollectionRoleRemove    = "collection.role.remove"
+	AuditWhitelistSet            = "whitelist.set"
+	AuditWhitelistDelete         = "whitelist.delete"
+	AuditWhitelistImport         = "whitelist.import"
+	AuditAccountRole             = "account.role"
+	AuditDuplicateFlagReview     = "moderation.duplicate.review"
+	AuditModerationAction        = "moderation.action"
+	AuditVerificationReview      = "collection.verification.review"
+
+	AuditTargetCollection = "collection"
+	AuditTargetAccount    = "account"
+	AuditTargetToken      = "token"
+
+	MaxAuditLogsLimit = 100
+	// auditValueKey holds the change of values that are not json objects, like a list of phases.
+	auditValueKey = "value"
+)
+
+var auditCsvHeader = []string{"id", "createdAt", "actor", "action", "targetType", "targetId", "requestId", "diff"}
+
+// AuditActor is who performed an audited action and the request it came with.
+type AuditActor struct {
+	Address   string
+	RequestId string
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditLogs struct {
+	Total int64               `json:"total"`
+	Logs  []entities.AuditLog `json:"logs"`
+}
+
+// DiffAuditValues compares the json representations of before and after. Objects are compared field by field,
+// a nil before or after stands for an object without fields.
+func DiffAuditValues(before interface{}, after interface{}) (map[string]AuditChange, error) {
+	beforeValue, err := toAuditValue(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterValue, err := toAuditValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string]AuditChange{}
+	beforeFields, beforeIsObject := beforeValue.(map[string]interface{})
+	afterFields, afterIsObject := afterValue.(map[string]interface{})
+	if beforeValue == nil && afterIsObject {
+		beforeFields, beforeIsObject = map[string]interface{}{}, true
+	}
+	if afterValue == nil && beforeIsObject {
+		afterFields, afterIsObject = map[string]interface{}{}, true
+	}
+
+	if !beforeIsObject || !afterIsObject {
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			diff[auditValueKey] = AuditChange{Before: beforeValue, After: afterValue}
+		}
+		return diff, nil
+	}
+
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			diff[field] = AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			diff[field] = AuditChange{Before: nil, After: value}
+		}
+	}
+
+	return diff, nil
+}
+
+// RecordAudit appends an entry for an action that already succeeded. A failure is logged,
+// it does not undo the action.
+func RecordAudit(actor AuditActor, action string, targetType string, targetId string, before interface{}, after interface{}) {
+	diff, err := DiffAuditValues(before, after)
+	if err != nil {
+		zlog.Error("could not diff audit values", zap.String("action", action), zap.Error(err))
+		return
+	}
+
+	diffJson, err := json.Marshal(diff)
+	if err != nil {
+		zlog.Error("could not encode audit diff", zap.String("action", action), zap.Error(err))
+		return
+	}
+
+	err = storage.AddAuditLog(&entities.AuditLog{
+		Actor:      actor.Address,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Diff:       diffJson,
+		RequestId:  actor.RequestId,
+		CreatedAt:  time.Now().Unix(),
+	})
+	if err != nil {
+		zlog.Error("could not record audit log",
+			zap.String("action", action),
+			zap.String("actor", actor.Address),
+			zap.String("target", targetType+":"+targetId),
+			zap.String("requestId", actor.RequestId),
+			zap.Error(err))
+	}
+}
+
+func CollectionAuditTarget(collection *entities.Collection) string {
+	return strconv.FormatUint(collection.ID, 10)
+}
+
+func GetAuditLogs(filter storage.AuditLogFilter, offset int, limit int) (*AuditLogs, error) {
+	if limit > MaxAuditLogsLimit {
+		limit = MaxAuditLogsLimit
+	}
+
+	total, err := storage.CountAuditLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := storage.GetAuditLogs(filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditLogs{Total: total, Logs: logs}, nil
+}
+
+func ExportAuditLogsCsv(filter storage.AuditLogFilter) (*bytes.Buffer, error) {
+	logs, err := storage.GetAuditLogs(filter, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	csvWrapper, err := utils.NewCsvWrapper()
+	if err != nil {
+		return nil, err
+	}
+	defer csvWrapper.Close()
+
+	records := [][]string{auditCsvHeader}
+	for _, auditLog := range logs {
+		records = append(records, []string{
+			strconv.FormatUint(auditLog.ID, 10),
+			time.Unix(auditLog.CreatedAt, 0).UTC().Format(time.RFC3339),
+			auditLog.Actor,
+			auditLog.Action,
+			auditLog.TargetType,
+			auditLog.TargetId,
+			auditLog.RequestId,
+			string(auditLog.Diff),
+		})
+	}
+
+	err = csvWrapper.WriteBulkRecord(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return csvWrapper.GetBuffer(), nil
+}
+
+func toAuditValue(value interface{}) (interface{}, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
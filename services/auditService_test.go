@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiffAuditValuesObjects(t *testing.T) {
+	before := entities.Collection{ID: 1, Name: "first", IsVerified: false, Priority: 3}
+	after := before
+	after.IsVerified = true
+	after.Priority = 5
+
+	diff, err := DiffAuditValues(before, &after)
+	require.Nil(t, err)
+	require.Len(t, diff, 2)
+	require.Equal(t, AuditChange{Before: false, After: true}, diff["isVerified"])
+	require.Equal(t, AuditChange{Before: float64(3), After: float64(5)}, diff["priority"])
+}
+
+func Test_DiffAuditValuesMaps(t *testing.T) {
+	before := map[string]uint64{"erd1:1": 2, "erd2:2": 1}
+	after := map[string]uint64{"erd1:1": 2, "erd3:1": 4}
+
+	diff, err := DiffAuditValues(before, after)
+	require.Nil(t, err)
+	require.Len(t, diff, 2)
+	require.Equal(t, AuditChange{Before: float64(1), After: nil}, diff["erd2:2"])
+	require.Equal(t, AuditChange{Before: nil, After: float64(4)}, diff["erd3:1"])
+
+	diff, err = DiffAuditValues(nil, map[string]uint64{"erd1:1": 2})
+	require.Nil(t, err)
+	require.Equal(t, AuditChange{Before: nil, After: float64(2)}, diff["erd1:1"])
+}
+
+func Test_DiffAuditValuesLists(t *testing.T) {
+	before := []entities.CollectionMintPhase{{Name: "presale"}}
+
+	diff, err := DiffAuditValues(before, before)
+	require.Nil(t, err)
+	require.Empty(t, diff)
+
+	var nilPhases *entities.CollectionMintPhase
+	diff, err = DiffAuditValues(before, nilPhases)
+	require.Nil(t, err)
+	require.Contains(t, diff, auditValueKey)
+	require.Nil(t, diff[auditValueKey].After)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/cache"
+)
+
+const (
+	LoginChallengeKeyFormat    = "LoginChallenge:%s"
+	DefaultChallengeExpirySecs = 300
+	loginChallengeNonceLen     = 32
+	loginChallengeExtraInfo    = "{}"
+)
+
+var (
+	ErrChallengeNotFound      = errors.New("login challenge not found or already used")
+	ErrChallengeExpired       = errors.New("login challenge expired")
+	ErrChallengeAddress       = errors.New("login challenge was issued for another address")
+	ErrChallengeOrigin        = errors.New("login challenge origin is not allowed")
+	ErrMalformedLoginToken    = errors.New("malformed login token")
+	ErrLoginTokenDoesNotMatch = errors.New("login token does not match the challenge")
+)
+
+// LoginChallenge is issued by the server before a wallet login. Its token follows the native auth layout,
+// base64(origin).nonce.ttl.base64(extraInfo), with a server nonce in place of the block hash.
+// The wallet signs address+token, so a signature is only good once, for one address and one origin.
+type LoginChallenge struct {
+	Nonce     string `json:"nonce"`
+	Address   string `json:"address"`
+	Origin    string `json:"origin"`
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// ChallengeStore keeps the pending challenges. Consume must be atomic: it reports true only for the first call.
+type ChallengeStore interface {
+	Save(challenge *LoginChallenge, ttl time.Duration) error
+	Get(nonce string) (*LoginChallenge, error)
+	Consume(nonce string) (bool, error)
+}
+
+type redisChallengeStore struct{}
+
+func (store *redisChallengeStore) Save(challenge *LoginChallenge, ttl time.Duration) error {
+	payload, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+
+	return cache.GetRedis().Set(cache.GetContext(), fmt.Sprintf(LoginChallengeKeyFormat, challenge.Nonce), payload, ttl).Err()
+}
+
+func (store *redisChallengeStore) Get(nonce string) (*LoginChallenge, error) {
+	payload, err := cache.GetRedis().Get(cache.GetContext(), fmt.Sprintf(LoginChallengeKeyFormat, nonce)).Bytes()
+	if err != nil {
+		return nil, ErrChallengeNotFound
+	}
+
+	var challenge LoginChallenge
+	err = json.Unmarshal(payload, &challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+func (store *redisChallengeStore) Consume(nonce string) (bool, error) {
+	deleted, err := cache.GetRedis().Del(cache.GetContext(), fmt.Sprintf(LoginChallengeKeyFormat, nonce)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return deleted == 1, nil
+}
+
+// CreateChallenge issues a single use login challenge for the address, bound to one of the configured origins.
+func (a *AuthService) CreateChallenge(address string, origin string, now time.Time) (*LoginChallenge, error) {
+	if !a.isAllowedOrigin(origin) {
+		return nil, ErrChallengeOrigin
+	}
+
+	nonceBytes := make([]byte, loginChallengeNonceLen)
+	_, err := rand.Read(nonceBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := a.challengeTTL()
+	nonce := hex.EncodeToString(nonceBytes)
+	challenge := &LoginChallenge{
+		Nonce:     nonce,
+		Address:   address,
+		Origin:    origin,
+		Token:     makeLoginToken(origin, nonce, ttl),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	err = a.challenges.Save(challenge, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// verifyChallenge checks the signed token against its pending challenge and consumes it.
+func (a *AuthService) verifyChallenge(address string, token string, now time.Time, verifySignature func() error) error {
+	origin, nonce, err := parseLoginToken(token)
+	if err != nil {
+		return err
+	}
+	if !a.isAllowedOrigin(origin) {
+		return ErrChallengeOrigin
+	}
+
+	challenge, err := a.challenges.Get(nonce)
+	if err != nil {
+		return err
+	}
+	if challenge.Token != token || challenge.Origin != origin {
+		return ErrLoginTokenDoesNotMatch
+	}
+	if challenge.Address != address {
+		return ErrChallengeAddress
+	}
+	if challenge.ExpiresAt <= now.Unix() {
+		return ErrChallengeExpired
+	}
+
+	err = verifySignature()
+	if err != nil {
+		return err
+	}
+
+	consumed, err := a.challenges.Consume(nonce)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrChallengeNotFound
+	}
+
+	return nil
+}
+
+func (a *AuthService) isAllowedOrigin(origin string) bool {
+	for _, allowed := range a.config.LoginOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *AuthService) challengeTTL() time.Duration {
+	secs := a.config.ChallengeExpirySecs
+	if secs <= 0 {
+		secs = DefaultChallengeExpirySecs
+	}
+
+	return time.Duration(secs) * time.Second
+}
+
+func makeLoginToken(origin string, nonce string, ttl time.Duration) string {
+	return strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(origin)),
+		nonce,
+		strconv.FormatInt(int64(ttl/time.Second), 10),
+		base64.RawURLEncoding.EncodeToString([]byte(loginChallengeExtraInfo)),
+	}, ".")
+}
+
+func parseLoginToken(token string) (string, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", "", ErrMalformedLoginToken
+	}
+
+	origin, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", "", ErrMalformedLoginToken
+	}
+
+	_, err = hex.DecodeString(parts[1])
+	if err != nil || len(parts[1]) != 2*loginChallengeNonceLen {
+		return "", "", ErrMalformedLoginToken
+	}
+
+	return string(origin), parts[1], nil
+}
@@ -1,92 +1,101 @@
 package services
 
 import (
-	"encoding/hex"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/crypto"
 	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 )
 
-type AuthService struct {
-	privKey []byte
-	pubKey  []byte
+// RoleResolver loads the roles put in the jwt claims.
+type RoleResolver func(address string) (*AccountRoles, error)
 
-	config config.AuthConfig
+type AuthService struct {
+	config     config.AuthConfig
+	challenges ChallengeStore
+	sessions   SessionStore
+	roles      RoleResolver
 }
 
 func NewAuthService(cfg config.AuthConfig) (*AuthService, error) {
 	a := AuthService{
-		config: cfg,
-	}
-
-	seedBytes, err := hex.DecodeString(cfg.JwtKeySeedHex)
-	if err != nil {
-		return nil, err
+		config:     cfg,
+		challenges: &redisChallengeStore{},
+		sessions:   &dbSessionStore{},
+		roles:      ResolveAccountRoles,
 	}
 
-	sk := crypto.NewEdKey(seedBytes)
-
-	a.privKey = sk
-	a.pubKey = sk[32:]
-
 	return &a, nil
 }
 
-func (a *AuthService) CreateToken(pubkey, sig, msg []byte) (string, string, error) {
-	err := crypto.VerifySignature(pubkey, msg, sig)
+// CreateToken logs in with a token from CreateChallenge. The signature is the wallet one over address+token.
+// Every login opens a new refresh session for the device.
+func (a *AuthService) CreateToken(pubkey, sig []byte, token string, device AuthDevice, now time.Time) (string, string, error) {
+	address := data.NewAddressFromBytes(pubkey).AddressAsBech32String()
+
+	err := a.verifyChallenge(address, token, now, func() error {
+		return crypto.VerifySignature(pubkey, crypto.ComputeElrondSignableMessage([]byte(address+token)), sig)
+	})
 	if err != nil {
 		return "", "", err
 	}
 
-	bech32Addr := data.NewAddressFromBytes(pubkey)
-
-	jwt, err := a.newJwt(bech32Addr.AddressAsBech32String())
+	session, refresh, err := a.openSession(address, device, now)
 	if err != nil {
 		return "", "", err
 	}
 
-	refresh, err := crypto.SignPayload(a.privKey, []byte(jwt))
-
+	jwt, err := a.newJwt(address, session.ID)
 	if err != nil {
 		return "", "", err
 	}
 
-	return jwt, hex.EncodeToString(refresh), nil
+	return jwt, refresh, nil
 }
 
-func (a *AuthService) RefreshToken(token, refresh string) (string, string, error) {
+// RefreshToken exchanges an expired jwt and the refresh token of its session for new ones.
+// The refresh token is rotated, presenting an already used one revokes the session.
+func (a *AuthService) RefreshToken(token, refresh string, now time.Time) (string, string, error) {
 	claims, err := crypto.GetClaims(token, a.config.JwtSecret, false)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshBytes, err := hex.DecodeString(refresh)
+	session, newRefresh, err := a.rotateSession(claims, refresh, now)
 	if err != nil {
 		return "", "", err
 	}
 
-	err = crypto.VerifySignature(a.pubKey, []byte(token), refreshBytes)
+	newJwt, err := a.newJwt(claims.Address, session.ID)
 	if err != nil {
 		return "", "", err
 	}
 
-	newJwt, err := a.newJwt(claims.Address)
+	return newJwt, newRefresh, nil
+}
+
+// newJwt caches the current roles of the address in the claims, a refresh picks up the role changes.
+func (a *AuthService) newJwt(address string, sessionId uint64) (string, error) {
+	roles, err := a.roles(address)
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 
-	newRefresh, err := crypto.SignPayload(a.privKey, []byte(newJwt))
-	if err != nil {
-		return "", "", err
+	collectionRoles := make(map[uint64]string, len(roles.Collections))
+	for collectionId, role := range roles.Collections {
+		collectionRoles[collectionId] = string(role)
 	}
 
-	return newJwt, hex.EncodeToString(newRefresh), nil
-}
+	claims := crypto.JwtClaims{
+		Address:         address,
+		SessionId:       sessionId,
+		Role:            string(roles.Role),
+		CollectionRoles: collectionRoles,
+	}
 
-func (a *AuthService) newJwt(address string) (string, error) {
-	return crypto.GenerateJwt(
-		address,
+	return crypto.GenerateSessionJwt(
+		claims,
 		a.config.JwtSecret,
 		a.config.JwtIssuer,
 		a.config.JwtExpiryMins,
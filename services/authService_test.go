@@ -3,85 +3,335 @@ package services
 import (
 	libed25519 "crypto/ed25519"
 	"encoding/hex"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/crypto"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 	"github.com/stretchr/testify/require"
 )
 
-func Test_CreateAndRefreshBeforeExpireShouldNotWork(t *testing.T) {
-	seed := "202d2274940909b4f3c23691c857d7d3352a0574cfb96efbf1ef90cbc66e2cbc"
-	msg := []byte("msg")
-
-	seedBytes, _ := hex.DecodeString(seed)
-
-	sk := crypto.NewEdKey(seedBytes)
-	pk := sk[libed25519.PublicKeySize:]
+const testLoginOrigin = "https://youbei.io"
 
-	sig, _ := crypto.SignPayload(sk, msg)
-	verifyErr := crypto.VerifySignature(pk, msg, sig)
-	require.Nil(t, verifyErr)
+type memoryChallengeStore struct {
+	mut        sync.Mutex
+	challenges map[string]LoginChallenge
+}
 
+func newTestAuthService(t *testing.T, expiryMins int) *AuthService {
 	service, err := NewAuthService(config.AuthConfig{
 		JwtSecret:     "supersecret",
 		JwtIssuer:     "localhost:8080",
-		JwtKeySeedHex: "d6592724167553acf9c8cba9a7dbc7f514efc757d7906546cecfdfc5d4c2e8d1",
-		JwtExpiryMins: 15,
+		JwtExpiryMins: expiryMins,
+		LoginOrigins:  []string{testLoginOrigin},
 	})
 	require.Nil(t, err)
 
-	jwt, refresh, err := service.CreateToken(pk, sig, msg)
+	service.challenges = &memoryChallengeStore{challenges: map[string]LoginChallenge{}}
+	service.sessions = &memorySessionStore{sessions: map[uint64]*entities.AuthSession{}}
+	service.roles = func(address string) (*AccountRoles, error) {
+		return &AccountRoles{Role: entities.RoleUser}, nil
+	}
+	return service
+}
+
+type memorySessionStore struct {
+	mut      sync.Mutex
+	lastId   uint64
+	sessions map[uint64]*entities.AuthSession
+}
+
+func (store *memorySessionStore) AddSession(session *entities.AuthSession) error {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	store.lastId++
+	session.ID = store.lastId
+	stored := *session
+	store.sessions[session.ID] = &stored
+	return nil
+}
+
+func (store *memorySessionStore) GetSession(id uint64) (*entities.AuthSession, error) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (store *memorySessionStore) RotateRefreshHash(id uint64, oldHash string, newHash string, lastUsedAt int64, expiresAt int64) (bool, error) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok || session.RefreshHash != oldHash || session.RevokedAt != 0 {
+		return false, nil
+	}
+	session.RefreshHash = newHash
+	session.LastUsedAt = lastUsedAt
+	session.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (store *memorySessionStore) RevokeSessions(address string, ids []uint64, now int64) error {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	for _, session := range store.sessions {
+		if session.Address != address {
+			continue
+		}
+		for _, id := range ids {
+			if id == session.ID {
+				session.RevokedAt = now
+			}
+		}
+		if len(ids) == 0 {
+			session.RevokedAt = now
+		}
+	}
+	return nil
+}
+
+func (store *memorySessionStore) GetActiveSessions(address string, now int64) ([]entities.AuthSession, error) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	var sessions []entities.AuthSession
+	for _, session := range store.sessions {
+		if session.Address == address && session.RevokedAt == 0 && session.ExpiresAt > now {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (store *memoryChallengeStore) Save(challenge *LoginChallenge, _ time.Duration) error {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	store.challenges[challenge.Nonce] = *challenge
+	return nil
+}
+
+func (store *memoryChallengeStore) Get(nonce string) (*LoginChallenge, error) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	challenge, ok := store.challenges[nonce]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	return &challenge, nil
+}
+
+func (store *memoryChallengeStore) Consume(nonce string) (bool, error) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+
+	_, ok := store.challenges[nonce]
+	delete(store.challenges, nonce)
+	return ok, nil
+}
+
+func signLoginToken(sk libed25519.PrivateKey, token string) []byte {
+	pk := sk[libed25519.PublicKeySize:]
+	address := data.NewAddressFromBytes(pk).AddressAsBech32String()
+
+	sig, _ := crypto.SignPayload(sk, crypto.ComputeElrondSignableMessage([]byte(address+token)))
+	return sig
+}
+
+func testLoginKey() libed25519.PrivateKey {
+	seedBytes, _ := hex.DecodeString("202d2274940909b4f3c23691c857d7d3352a0574cfb96efbf1ef90cbc66e2cbc")
+	return crypto.NewEdKey(seedBytes)
+}
+
+func login(t *testing.T, service *AuthService, sk libed25519.PrivateKey) (string, string) {
+	pk := sk[libed25519.PublicKeySize:]
+	now := time.Now()
+
+	challenge, err := service.CreateChallenge(data.NewAddressFromBytes(pk).AddressAsBech32String(), testLoginOrigin, now)
+	require.Nil(t, err)
+
+	jwt, refresh, err := service.CreateToken(pk, signLoginToken(sk, challenge.Token), challenge.Token, AuthDevice{UserAgent: "test"}, now)
 	require.Nil(t, err)
 
+	return jwt, refresh
+}
+
+func Test_CreateAndRefreshBeforeExpireShouldNotWork(t *testing.T) {
+	sk := testLoginKey()
+
+	service := newTestAuthService(t, 15)
+	jwt, refresh := login(t, service, sk)
+
 	// Should err because the token is still valid.
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	jwt, refresh, err := service.RefreshToken(jwt, refresh, time.Now())
 	require.NotNil(t, err)
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
 	require.NotNil(t, err)
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
 	require.NotNil(t, err)
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
 	require.NotNil(t, err)
 }
 
 func Test_CreateAndRefreshAfterExpireShouldWork(t *testing.T) {
-	seed := "202d2274940909b4f3c23691c857d7d3352a0574cfb96efbf1ef90cbc66e2cbc"
-	msg := []byte("msg")
+	sk := testLoginKey()
+
+	service := newTestAuthService(t, -1)
+	jwt, refresh := login(t, service, sk)
+
+	// Should succeed because token expired.
+	jwt, refresh, err := service.RefreshToken(jwt, refresh, time.Now())
+	require.Nil(t, err)
 
-	seedBytes, _ := hex.DecodeString(seed)
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
+	require.Nil(t, err)
 
-	sk := crypto.NewEdKey(seedBytes)
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
+	require.Nil(t, err)
+
+	jwt, refresh, err = service.RefreshToken(jwt, refresh, time.Now())
+	require.Nil(t, err)
+}
+
+func Test_LoginChallengeCannotBeReused(t *testing.T) {
+	sk := testLoginKey()
 	pk := sk[libed25519.PublicKeySize:]
+	address := data.NewAddressFromBytes(pk).AddressAsBech32String()
+	service := newTestAuthService(t, 15)
+	now := time.Now()
 
-	sig, _ := crypto.SignPayload(sk, msg)
-	verifyErr := crypto.VerifySignature(pk, msg, sig)
-	require.Nil(t, verifyErr)
+	challenge, err := service.CreateChallenge(address, testLoginOrigin, now)
+	require.Nil(t, err)
+	sig := signLoginToken(sk, challenge.Token)
 
-	service, err := NewAuthService(config.AuthConfig{
-		JwtSecret:     "supersecret",
-		JwtIssuer:     "localhost:8080",
-		JwtKeySeedHex: "d6592724167553acf9c8cba9a7dbc7f514efc757d7906546cecfdfc5d4c2e8d1",
-		JwtExpiryMins: -1,
-	})
+	_, _, err = service.CreateToken(pk, sig, challenge.Token, AuthDevice{}, now)
 	require.Nil(t, err)
 
-	jwt, refresh, err := service.CreateToken(pk, sig, msg)
+	_, _, err = service.CreateToken(pk, sig, challenge.Token, AuthDevice{}, now)
+	require.Equal(t, ErrChallengeNotFound, err)
+}
+
+func Test_LoginChallengeExpired(t *testing.T) {
+	sk := testLoginKey()
+	pk := sk[libed25519.PublicKeySize:]
+	address := data.NewAddressFromBytes(pk).AddressAsBech32String()
+	service := newTestAuthService(t, 15)
+	issuedAt := time.Now().Add(-time.Hour)
+
+	challenge, err := service.CreateChallenge(address, testLoginOrigin, issuedAt)
 	require.Nil(t, err)
 
-	// Should succeed because token expired.
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	_, _, err = service.CreateToken(pk, signLoginToken(sk, challenge.Token), challenge.Token, AuthDevice{}, time.Now())
+	require.Equal(t, ErrChallengeExpired, err)
+}
+
+func Test_LoginChallengeForeignOrigin(t *testing.T) {
+	sk := testLoginKey()
+	pk := sk[libed25519.PublicKeySize:]
+	address := data.NewAddressFromBytes(pk).AddressAsBech32String()
+	service := newTestAuthService(t, 15)
+	now := time.Now()
+
+	_, err := service.CreateChallenge(address, "https://phishing.example", now)
+	require.Equal(t, ErrChallengeOrigin, err)
+
+	// a token signed for another site must not be accepted either
+	challenge, err := service.CreateChallenge(address, testLoginOrigin, now)
+	require.Nil(t, err)
+	foreignToken := makeLoginToken("https://phishing.example", challenge.Nonce, time.Minute)
+
+	_, _, err = service.CreateToken(pk, signLoginToken(sk, foreignToken), foreignToken, AuthDevice{}, now)
+	require.Equal(t, ErrChallengeOrigin, err)
+}
+
+func Test_LoginChallengeOtherAddressOrSignature(t *testing.T) {
+	sk := testLoginKey()
+	pk := sk[libed25519.PublicKeySize:]
+	service := newTestAuthService(t, 15)
+	now := time.Now()
+
+	otherSeed, _ := hex.DecodeString("d6592724167553acf9c8cba9a7dbc7f514efc757d7906546cecfdfc5d4c2e8d1")
+	otherSk := crypto.NewEdKey(otherSeed)
+	otherAddress := data.NewAddressFromBytes(otherSk[libed25519.PublicKeySize:]).AddressAsBech32String()
+
+	challenge, err := service.CreateChallenge(otherAddress, testLoginOrigin, now)
 	require.Nil(t, err)
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	_, _, err = service.CreateToken(pk, signLoginToken(sk, challenge.Token), challenge.Token, AuthDevice{}, now)
+	require.Equal(t, ErrChallengeAddress, err)
+
+	otherPk := otherSk[libed25519.PublicKeySize:]
+	_, _, err = service.CreateToken(otherPk, signLoginToken(sk, challenge.Token), challenge.Token, AuthDevice{}, now)
+	require.Equal(t, crypto.ErrInvalidSignature, err)
+
+	// a bad signature does not burn the challenge
+	_, _, err = service.CreateToken(otherPk, signLoginToken(otherSk, challenge.Token), challenge.Token, AuthDevice{}, now)
 	require.Nil(t, err)
+}
+
+func Test_RefreshTokenReuseRevokesSession(t *testing.T) {
+	service := newTestAuthService(t, -1)
+	jwt, refresh := login(t, service, testLoginKey())
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	rotatedJwt, rotatedRefresh, err := service.RefreshToken(jwt, refresh, time.Now())
 	require.Nil(t, err)
+	require.NotEqual(t, refresh, rotatedRefresh)
+
+	// the first refresh token was already used
+	_, _, err = service.RefreshToken(jwt, refresh, time.Now())
+	require.Equal(t, ErrRefreshTokenReused, err)
+
+	// and the whole session is gone, even for the legitimate holder of the new token
+	_, _, err = service.RefreshToken(rotatedJwt, rotatedRefresh, time.Now())
+	require.Equal(t, ErrSessionRevoked, err)
+}
+
+func Test_LogoutRevokesSessions(t *testing.T) {
+	sk := testLoginKey()
+	address := data.NewAddressFromBytes(sk[libed25519.PublicKeySize:]).AddressAsBech32String()
+	service := newTestAuthService(t, -1)
+
+	phoneJwt, phoneRefresh := login(t, service, sk)
+	laptopJwt, laptopRefresh := login(t, service, sk)
+	login(t, service, sk)
+
+	sessions, err := service.GetSessions(address, 0, time.Now())
+	require.Nil(t, err)
+	require.Len(t, sessions, 3)
+
+	phoneClaims, err := crypto.GetClaims(phoneJwt, "supersecret", false)
+	require.Nil(t, err)
+	err = service.Logout(address, []uint64{phoneClaims.SessionId}, time.Now())
+	require.Nil(t, err)
+
+	_, _, err = service.RefreshToken(phoneJwt, phoneRefresh, time.Now())
+	require.Equal(t, ErrSessionRevoked, err)
+
+	laptopJwt, laptopRefresh, err = service.RefreshToken(laptopJwt, laptopRefresh, time.Now())
+	require.Nil(t, err)
+
+	err = service.LogoutAll(address, time.Now())
+	require.Nil(t, err)
+
+	_, _, err = service.RefreshToken(laptopJwt, laptopRefresh, time.Now())
+	require.Equal(t, ErrSessionRevoked, err)
 
-	jwt, refresh, err = service.RefreshToken(jwt, refresh)
+	sessions, err = service.GetSessions(address, 0, time.Now())
 	require.Nil(t, err)
+	require.Len(t, sessions, 0)
 }
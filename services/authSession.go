@@ -0,0 +1,256 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/cache"
+	"github.com/ENFT-DAO/youbei-api/crypto"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+)
+
+const (
+	AuthSessionRevokedKeyFormat = "AuthSessionRevoked:%d"
+	AuthSessionRevokedExpiry    = 5 * time.Minute
+	DefaultRefreshExpiryHours   = 30 * 24
+	refreshTokenLen             = 32
+	maxSessionUserAgentLen      = 255
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrSessionRevoked      = errors.New("session revoked")
+	ErrSessionExpired      = errors.New("session expired")
+	ErrRefreshTokenReused  = errors.New("refresh token already used, session revoked")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
+
+// AuthDevice describes where a login comes from, it is shown in the session list.
+type AuthDevice struct {
+	UserAgent string
+	Ip        string
+}
+
+type AuthSessionInfo struct {
+	entities.AuthSession
+	Current bool `json:"current"`
+}
+
+// SessionStore keeps the refresh sessions. RotateRefreshHash must only succeed for the current hash.
+type SessionStore interface {
+	AddSession(session *entities.AuthSession) error
+	GetSession(id uint64) (*entities.AuthSession, error)
+	RotateRefreshHash(id uint64, oldHash string, newHash string, lastUsedAt int64, expiresAt int64) (bool, error)
+	RevokeSessions(address string, ids []uint64, now int64) error
+	GetActiveSessions(address string, now int64) ([]entities.AuthSession, error)
+}
+
+type dbSessionStore struct{}
+
+func (store *dbSessionStore) AddSession(session *entities.AuthSession) error {
+	return storage.AddAuthSession(session)
+}
+
+func (store *dbSessionStore) GetSession(id uint64) (*entities.AuthSession, error) {
+	return storage.GetAuthSessionById(id)
+}
+
+func (store *dbSessionStore) RotateRefreshHash(id uint64, oldHash string, newHash string, lastUsedAt int64, expiresAt int64) (bool, error) {
+	return storage.RotateAuthSessionRefreshHash(id, oldHash, newHash, lastUsedAt, expiresAt)
+}
+
+func (store *dbSessionStore) RevokeSessions(address string, ids []uint64, now int64) error {
+	revoked, err := storage.RevokeAuthSessions(address, ids, now)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range revoked {
+		err = cache.GetCacher().Set(fmt.Sprintf(AuthSessionRevokedKeyFormat, id), true, AuthSessionRevokedExpiry)
+		if err != nil {
+			log.Debug("could not cache revoked session", "id", id, "err", err)
+		}
+	}
+
+	return nil
+}
+
+func (store *dbSessionStore) GetActiveSessions(address string, now int64) ([]entities.AuthSession, error) {
+	return storage.GetActiveAuthSessions(address, now)
+}
+
+// RevokeAllAuthSessions signs an address out of every device, used when its roles change.
+func RevokeAllAuthSessions(address string) {
+	store := &dbSessionStore{}
+	err := store.RevokeSessions(address, nil, time.Now().Unix())
+	if err != nil {
+		log.Debug("could not revoke sessions", "address", address, "err", err)
+	}
+}
+
+// IsAuthSessionRevoked tells the authorization middleware whether a jwt session was revoked or expired.
+// Tokens issued without a session are treated as revoked.
+func IsAuthSessionRevoked(sessionId uint64) (bool, error) {
+	if sessionId == 0 {
+		return true, nil
+	}
+
+	var revoked bool
+	cacheKey := fmt.Sprintf(AuthSessionRevokedKeyFormat, sessionId)
+	err := cache.GetCacher().Get(cacheKey, &revoked)
+	if err == nil {
+		return revoked, nil
+	}
+
+	session, err := storage.GetAuthSessionById(sessionId)
+	if err != nil {
+		return false, err
+	}
+
+	revoked = session.RevokedAt != 0 || session.ExpiresAt <= time.Now().Unix()
+	err = cache.GetCacher().Set(cacheKey, revoked, AuthSessionRevokedExpiry)
+	if err != nil {
+		log.Debug("could not cache session state", "id", sessionId, "err", err)
+	}
+
+	return revoked, nil
+}
+
+func (a *AuthService) GetSessions(address string, currentSessionId uint64, now time.Time) ([]AuthSessionInfo, error) {
+	sessions, err := a.sessions.GetActiveSessions(address, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]AuthSessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, AuthSessionInfo{
+			AuthSession: session,
+			Current:     session.ID == currentSessionId,
+		})
+	}
+
+	return infos, nil
+}
+
+// Logout revokes the given sessions of the address.
+func (a *AuthService) Logout(address string, sessionIds []uint64, now time.Time) error {
+	if len(sessionIds) == 0 {
+		return ErrSessionNotFound
+	}
+
+	return a.sessions.RevokeSessions(address, sessionIds, now.Unix())
+}
+
+func (a *AuthService) LogoutAll(address string, now time.Time) error {
+	return a.sessions.RevokeSessions(address, nil, now.Unix())
+}
+
+func (a *AuthService) openSession(address string, device AuthDevice, now time.Time) (*entities.AuthSession, string, error) {
+	refresh, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	userAgent := device.UserAgent
+	if len(userAgent) > maxSessionUserAgentLen {
+		userAgent = userAgent[:maxSessionUserAgentLen]
+	}
+
+	session := &entities.AuthSession{
+		Address:     address,
+		RefreshHash: refreshHash,
+		UserAgent:   userAgent,
+		Ip:          device.Ip,
+		CreatedAt:   now.Unix(),
+		LastUsedAt:  now.Unix(),
+		ExpiresAt:   now.Add(a.refreshTTL()).Unix(),
+	}
+	err = a.sessions.AddSession(session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, refresh, nil
+}
+
+func (a *AuthService) rotateSession(claims crypto.JwtClaims, refresh string, now time.Time) (*entities.AuthSession, string, error) {
+	if claims.SessionId == 0 {
+		return nil, "", ErrSessionNotFound
+	}
+
+	session, err := a.sessions.GetSession(claims.SessionId)
+	if err != nil {
+		return nil, "", ErrSessionNotFound
+	}
+	if session.Address != claims.Address {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if session.RevokedAt != 0 {
+		return nil, "", ErrSessionRevoked
+	}
+	if session.ExpiresAt <= now.Unix() {
+		return nil, "", ErrSessionExpired
+	}
+
+	presentedHash := hashRefreshToken(refresh)
+	if presentedHash != session.RefreshHash {
+		return nil, "", a.revokeReusedSession(session, now)
+	}
+
+	newRefresh, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	rotated, err := a.sessions.RotateRefreshHash(session.ID, presentedHash, newHash, now.Unix(), now.Add(a.refreshTTL()).Unix())
+	if err != nil {
+		return nil, "", err
+	}
+	if !rotated {
+		return nil, "", a.revokeReusedSession(session, now)
+	}
+
+	return session, newRefresh, nil
+}
+
+// revokeReusedSession is called when an old refresh token comes back: either the client or an attacker
+// holds a copy, so the whole session is closed.
+func (a *AuthService) revokeReusedSession(session *entities.AuthSession, now time.Time) error {
+	err := a.sessions.RevokeSessions(session.Address, []uint64{session.ID}, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+func (a *AuthService) refreshTTL() time.Duration {
+	hours := a.config.RefreshExpiryHours
+	if hours <= 0 {
+		hours = DefaultRefreshExpiryHours
+	}
+
+	return time.Duration(hours) * time.Hour
+}
+
+func newRefreshToken() (string, string, error) {
+	tokenBytes := make([]byte, refreshTokenLen)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh := hex.EncodeToString(tokenBytes)
+	return refresh, hashRefreshToken(refresh), nil
+}
+
+func hashRefreshToken(refresh string) string {
+	hash := sha256.Sum256([]byte(refresh))
+	return hex.EncodeToString(hash[:])
+}
@@ -1,12 +1,9 @@
 package services
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/big"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,11 +11,12 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ENFT-DAO/youbei-api/cache"
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
-	"github.com/ENFT-DAO/youbei-api/interaction"
+	"github.com/ENFT-DAO/youbei-api/stats"
 	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
-	"github.com/boltdb/bolt"
+	"github.com/ENFT-DAO/youbei-api/utils"
 )
 
 const (
@@ -35,9 +33,8 @@ const (
 	CollectionSearchCacheKeyFormat = "CollectionSearch:%s"
 	CollectionSearchExpirePeriod   = 20 * time.Minute
 	MintInfoViewName               = "getMaxSupplyAndTotalSold"
-	MintInfoSetNxKeyFormat         = "MintInfoNX:%s"
-	MintInfoSetNxExpirePeriod      = 6 * time.Second
-	MintInfoBucketName             = "MintInfo"
+	MintInfoCacheKeyFormat         = "MintInfo:%s"
+	MintInfoExpirePeriod           = 6 * time.Second
 
 	CollectionVerifiedCacheKeyFormat = "CollectionVerifiedCacheKey"
 	CollectionVerifiedExpirePeriod   = 5 * time.Minute
@@ -50,6 +47,9 @@ const (
 
 	CollectionVerifiedByAddressCacheKeyFormat = "CollectionVerifiedCacheKey:%s"
 	CollectionVerifiedByAddressExpirePeriod   = 5 * time.Minute
+
+	CollectionAttributesCacheKeyFormat = "CollectionAttributes:%d"
+	CollectionAttributesExpirePeriod   = 5 * time.Minute
 )
 
 type MintInfo struct {
@@ -108,6 +108,10 @@ type UpdateCollectionAdminSectionRequest struct {
 	IsStakeable  bool `json:"isStakeable"`
 }
 
+type UpdateCollectionTrendingRequest struct {
+	Mode string `json:"mode"`
+}
+
 type UpdateCollectionIsWhiteListedRequest struct {
 	IsWhiteListed bool `json:"isWhiteListed"`
 }
@@ -187,23 +191,9 @@ func CreateCollection(request *CreateCollectionRequest, blockchainProxy string)
 
 	//strMintPricePerToken = request.MintPricePerTokenString
 	//const fMintPricePerTokenNominal = 0.0
-	floatPrice, ok := big.NewFloat(0).SetString(request.MintPricePerTokenString)
-	if !ok {
-		return nil, errors.New("couldn't convert price string to blockchain unit")
-	}
-	multiplier := new(big.Float)
-	multiplier.SetInt(big.NewInt(0).Exp(big.NewInt(10), big.NewInt(18), nil))
-	floatPrice.Mul(multiplier, floatPrice)
-	fmt.Println(floatPrice.String())
-	priceBig, _ := floatPrice.Int(nil)
-	// priceBig, ok := big.NewInt(0).Setint(uintPrice)
-	if !ok {
-		return nil, errors.New("couldn't convert price string to blockchain unit")
-	}
-	mintPricePerTokenNominalrequest, err := strconv.ParseFloat(request.MintPricePerTokenString, 64)
-
+	priceBig, mintPricePerTokenNominalrequest, err := convertNominalPrice(request.MintPricePerTokenString)
 	if err != nil {
-		mintPricePerTokenNominalrequest = 0.1
+		return nil, err
 	}
 
 	collection := &entities.Collection{
@@ -443,11 +433,20 @@ func AutoCreateCollection(request *AutoCreateCollectionRequest, blockchainApi st
 	return collection, nil
 }
 
+// UpdateCollectionMintStartDate moves the first mint phase when the collection has a schedule.
 func UpdateCollectionMintStartDate(collection *entities.Collection, request *UpdateCollectionMintStartDateRequest) error {
+	phases, err := storage.GetMintPhasesByCollectionId(collection.ID)
+	if err != nil {
+		return err
+	}
+	if len(phases) > 0 {
+		phases[0].StartDate = request.MintStartDate
+		return saveCollectionMintPhases(collection, phases)
+	}
 
 	collection.MintStartDate = request.MintStartDate
 
-	err := storage.UpdateCollection(collection)
+	err = storage.UpdateCollection(collection)
 	if err != nil {
 		return err
 	}
@@ -474,6 +473,49 @@ func UpdateCollectionAdminSection(collection *entities.Collection, request *Upda
 	return nil
 }
 
+// GetCollectionTrendingMode returns the trending override mode of the collection, empty when the score decides.
+func GetCollectionTrendingMode(collectionId uint64) (string, error) {
+	overrides, err := storage.GetCollectionTrendingOverrides()
+	if err != nil {
+		return "", err
+	}
+
+	for _, override := range overrides {
+		if override.CollectionId == collectionId {
+			return string(override.Mode), nil
+		}
+	}
+
+	return "", nil
+}
+
+// UpdateCollectionTrendingOverride pins or suppresses a collection in the trending list.
+// An empty mode removes the override and lets the score decide again.
+func UpdateCollectionTrendingOverride(collection *entities.Collection, request *UpdateCollectionTrendingRequest) error {
+	var err error
+	switch entities.TrendingOverrideMode(request.Mode) {
+	case entities.TrendingPin, entities.TrendingSuppress:
+		err = storage.AddOrUpdateCollectionTrendingOverride(&entities.CollectionTrendingOverride{
+			CollectionId: collection.ID,
+			Mode:         entities.TrendingOverrideMode(request.Mode),
+		})
+	case "":
+		err = storage.DeleteCollectionTrendingOverride(collection.ID)
+	default:
+		return errors.New("invalid trending mode")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = cache.GetCacher().DeleteEverywhere(CollectionTrendingCacheKeyFormat)
+	if err != nil {
+		log.Debug("could not clear trending cache", "err", err)
+	}
+
+	return nil
+}
+
 func UpdateCollectionStaking(collection *entities.Collection, IsStakeable bool) error {
 
 	collection.IsStakeable = IsStakeable
@@ -675,111 +717,73 @@ func GetCollectionsTrending(limit int) ([]entities.Collection, error) {
 	return collectionArray, nil
 }
 
-func GetMintInfoForContract(contractAddress string) (*MintInfo, error) {
-	redisClient := cache.GetRedis()
-	redisContext := cache.GetContext()
-
-	mintInfoSetNxKey := fmt.Sprintf(MintInfoSetNxKeyFormat, contractAddress)
-	ok, err := redisClient.SetNX(redisContext, mintInfoSetNxKey, true, MintInfoSetNxExpirePeriod).Result()
-	if err != nil {
-		log.Debug("set nx resulted in error", err)
-	}
-
-	shouldDoQuery := ok == true && err == nil
-	if shouldDoQuery {
-		mintInfo, innerErr := setMintInfoCache(contractAddress)
-		if innerErr != nil {
-			_, _ = redisClient.Del(redisContext, mintInfoSetNxKey).Result()
-			return nil, innerErr
-		}
-		return mintInfo, nil
-	} else {
-		return getMintInfoCache(contractAddress)
-	}
-}
-
-func setMintInfoCache(contractAddress string) (*MintInfo, error) {
-	db := cache.GetBolt()
+func GetCollectionAttributeAnalytics(collectionId uint64) (*dtos.CollectionAttributeAnalytics, error) {
+	var byteArray []byte
+	var analytics dtos.CollectionAttributeAnalytics
 
-	bi := interaction.GetBlockchainInteractor()
-	if bi == nil {
-		return nil, errors.New("no blockchain interactor")
+	cacheKey := fmt.Sprintf(CollectionAttributesCacheKeyFormat, collectionId)
+	err := cache.GetCacher().Get(cacheKey, &byteArray)
+	if err == nil {
+		err = json.Unmarshal(byteArray, &analytics)
+		return &analytics, err
 	}
 
-	result, err := bi.DoVmQuery(contractAddress, MintInfoViewName, []string{})
+	computed, err := stats.ComputeAttributeAnalyticsForCollection(collectionId)
 	if err != nil {
 		return nil, err
 	}
-	if len(result) != 2 {
-		return nil, errors.New("unknown result len")
-	}
 
-	maxSupplyHex := hex.EncodeToString(result[0])
-	maxSupply, err := strconv.ParseUint(maxSupplyHex, 16, 64)
-	if err != nil {
-		return nil, err
+	byteArray, err = json.Marshal(computed)
+	if err == nil {
+		err = cache.GetCacher().Set(cacheKey, byteArray, CollectionAttributesExpirePeriod)
+		if err != nil {
+			log.Debug("could not set cache", "err", err)
+		}
 	}
 
-	totalSoldHex := hex.EncodeToString(result[1])
-	if len(result[1]) == 0 {
-		totalSoldHex = "0"
-	}
-	totalSold, err := strconv.ParseUint(totalSoldHex, 16, 64)
+	return computed, nil
+}
+
+func GetCollectionFloorPriceHistory(collectionId uint64, days uint64) ([]dtos.FloorPricePoint, error) {
+	fromHour := utils.HourKey(time.Now().Add(-time.Duration(days) * 24 * time.Hour))
+	records, err := storage.GetCollectionFloorPriceHistory(collectionId, fromHour)
 	if err != nil {
 		return nil, err
 	}
 
-	mintInfo := MintInfo{
-		MaxSupply: maxSupply,
-		TotalSold: totalSold,
-	}
-
-	entryBytes, err := json.Marshal(&mintInfo)
-	if err != nil {
-		log.Debug("could not marshal", err.Error())
-	} else {
-		innerErr := db.Update(func(tx *bolt.Tx) error {
-			bucket, innerErr := tx.CreateBucketIfNotExists([]byte(MintInfoBucketName))
-			if innerErr != nil {
-				return innerErr
-			}
-
-			innerErr = bucket.Put([]byte(contractAddress), entryBytes)
-			return innerErr
+	points := make([]dtos.FloorPricePoint, 0, len(records))
+	for _, record := range records {
+		points = append(points, dtos.FloorPricePoint{
+			Hour:        record.Hour,
+			FloorPrice:  record.FloorPrice,
+			ListedCount: record.ListedCount,
 		})
-
-		if innerErr != nil {
-			log.Debug("could not set to bolt db")
-		}
 	}
 
-	return &mintInfo, nil
+	return points, nil
 }
 
-func getMintInfoCache(contractAddress string) (*MintInfo, error) {
-	db := cache.GetBolt()
+// GetMintInfoForContract reads the contract view through the shared cache, so every instance sees the same value.
+func GetMintInfoForContract(contractAddress string) (*MintInfo, error) {
+	var mintInfo MintInfo
 
-	var bytes []byte
-	err := db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(MintInfoBucketName))
-		if bucket == nil {
-			return errors.New("no bucket for collection cache")
-		}
+	cacheKey := fmt.Sprintf(MintInfoCacheKeyFormat, contractAddress)
+	err := cache.GetCacher().Get(cacheKey, &mintInfo)
+	if err == nil {
+		return &mintInfo, nil
+	}
 
-		bytes = bucket.Get([]byte(contractAddress))
-		return nil
-	})
+	queried, err := queryMintInfo(contractAddress)
 	if err != nil {
 		return nil, err
 	}
 
-	var mintInfo MintInfo
-	err = json.Unmarshal(bytes, &mintInfo)
+	err = cache.GetCacher().Set(cacheKey, queried, MintInfoExpirePeriod)
 	if err != nil {
-		return nil, err
+		log.Debug("could not set cache", "err", err)
 	}
 
-	return &mintInfo, nil
+	return queried, nil
 }
 
 func contains(arr []string, str string) bool {
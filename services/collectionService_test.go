@@ -27,7 +27,7 @@ func Test_BasicProxyRequest(T *testing.T) {
 
 func Test_CreateCollection(T *testing.T) {
 	connectToDb()
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 
 	request := &CreateCollectionRequest{
 		UserAddress:   "erd1ukmgly5c7wdkfvek0fswvjzqcgg3xypfsztuhc6krjzz9xrrmtxsv0cd84",
@@ -51,7 +51,7 @@ func Test_CreateCollection(T *testing.T) {
 
 func Test_GetCollectionStatistics(T *testing.T) {
 	connectToDb()
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 
 	collectionStats, err := stats.ComputeStatisticsForCollection(1)
 	require.Nil(T, err)
@@ -63,7 +63,7 @@ func Test_GetCollectionStatistics(T *testing.T) {
 
 func Test_SearchCollection(T *testing.T) {
 	connectToDb()
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 
 	coll := &entities.Collection{
 		Name: "this name is uniquee",
@@ -126,90 +126,90 @@ func Test_GetCollectionMetadata(t *testing.T) {
 
 	token1 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "red", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "red"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token1)
 	require.Nil(t, err)
 
 	token2 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "green", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "green"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token2)
 	require.Nil(t, err)
 
 	token3 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "blue", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "blue"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token3)
 	require.Nil(t, err)
 
 	token4 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{}`),
+		Attributes:   datatypes.JSON(`[]`),
 	}
 	err = storage.AddToken(&token4)
 	require.Nil(t, err)
 
 	token5 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "green", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "green"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token5)
 	require.Nil(t, err)
 
 	token6 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token6)
 	require.Nil(t, err)
 
 	token7 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "yellow", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "yellow"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token7)
 	require.Nil(t, err)
 
 	token8 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "white", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "white"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token8)
 	require.Nil(t, err)
 
 	token9 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"hair": "white", "background": "dark"}`),
+		Attributes:   datatypes.JSON(`[{"trait_type": "hair", "value": "white"}, {"trait_type": "background", "value": "dark"}]`),
 	}
 	err = storage.AddToken(&token9)
 	require.Nil(t, err)
 
 	token10 := entities.Token{
 		CollectionID: coll.ID,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      1,
-		Attributes:   datatypes.JSON(`{"something_else": "yea"}`),
+		Attributes:   datatypes.JSON(`[{"something_else": "yea"}]`),
 	}
 	err = storage.AddToken(&token10)
 	require.Nil(t, err)
@@ -218,13 +218,9 @@ func Test_GetCollectionMetadata(t *testing.T) {
 	require.Nil(t, err)
 
 	expected := stats.CollectionMetadata{
-		NumItems: 10,
-		Owners:   map[uint64]bool{1: true},
+		NumItems:    10,
+		OwnersTotal: 1,
 		AttrStats: []dtos.AttributeStat{{
-			TraitType: "hair",
-			Value:     "red",
-			Total:     1,
-		}, {
 			TraitType: "background",
 			Value:     "dark",
 			Total:     8,
@@ -232,18 +228,22 @@ func Test_GetCollectionMetadata(t *testing.T) {
 			TraitType: "hair",
 			Value:     "green",
 			Total:     2,
+		}, {
+			TraitType: "hair",
+			Value:     "white",
+			Total:     2,
 		}, {
 			TraitType: "hair",
 			Value:     "blue",
 			Total:     1,
 		}, {
 			TraitType: "hair",
-			Value:     "yellow",
+			Value:     "red",
 			Total:     1,
 		}, {
 			TraitType: "hair",
-			Value:     "white",
-			Total:     2,
+			Value:     "yellow",
+			Total:     1,
 		}, {
 			TraitType: "something_else",
 			Value:     "yea",
@@ -255,7 +255,7 @@ func Test_GetCollectionMetadata(t *testing.T) {
 }
 
 func Test_GetMintInfoFromContract(t *testing.T) {
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 	defer cache.CloseCacher()
 
 	cfg := config.BlockchainConfig{
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ENFT-DAO/youbei-api/crypto"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxVerificationTextLength = 1000
+	MaxVerificationReasons    = 10
+	MaxVerificationsLimit     = 100
+
+	verificationMessageFormat = "I control %s and apply to verify the collection %s on Youbei as %s"
+)
+
+var (
+	ErrCollectionAlreadyVerified    = errors.New("collection is already verified")
+	ErrVerificationPending          = errors.New("collection already has a pending verification application")
+	ErrVerificationAlreadyReviewed  = errors.New("verification application was already reviewed")
+	ErrNoContractAddress            = errors.New("collection has no contract address")
+	ErrMissingTeamContact           = errors.New("team contact is required")
+	ErrVerificationTextTooLong      = fmt.Errorf("verification fields are limited to %d characters", MaxVerificationTextLength)
+	ErrInvalidVerificationSignature = errors.New("signature does not match the verification message")
+	ErrNotContractController        = errors.New("signer neither is nor owns the contract address")
+	ErrUnknownVerificationStatus    = errors.New("unknown verification status")
+	ErrVerificationReasonsRequired  = errors.New("a rejection needs at least one reason")
+	ErrTooManyVerificationReasons   = fmt.Errorf("at most %d reasons can be given", MaxVerificationReasons)
+	ErrOwnershipCheckFailed         = errors.New("ownership check failed, approving needs an ownership check override")
+	ErrOverrideNoteRequired         = errors.New("an ownership check override needs a note")
+)
+
+// ContractOwnerLookup returns the owner of a smart contract, empty for a wallet.
+type ContractOwnerLookup func(address string) (string, error)
+
+type VerificationApplicationRequest struct {
+	Website       string `json:"website"`
+	TwitterLink   string `json:"twitterLink"`
+	DiscordLink   string `json:"discordLink"`
+	TelegramLink  string `json:"telegramLink"`
+	InstagramLink string `json:"instagramLink"`
+	TeamContact   string `json:"teamContact"`
+	Evidence      string `json:"evidence"`
+	SignerAddress string `json:"signerAddress"`
+	Signature     string `json:"signature"`
+}
+
+// CollectionVerificationStatus is what a creator needs to apply: the message to sign with the wallet
+// controlling the contract address, and the last application with its decision.
+type CollectionVerificationStatus struct {
+	IsVerified      bool                             `json:"isVerified"`
+	ContractAddress string                           `json:"contractAddress"`
+	Message         string                           `json:"message"`
+	Application     *entities.CollectionVerification `json:"application"`
+}
+
+// ReviewVerificationRequest is the decision of a reviewer. Approving an application whose ownership check failed
+// needs OverrideOwnershipCheck and a note explaining it, both kept in the audit log of the review.
+type ReviewVerificationRequest struct {
+	Decision               entities.VerificationStatus `json:"decision"`
+	Reasons                []string                    `json:"reasons"`
+	Note                   string                      `json:"note"`
+	OverrideOwnershipCheck bool                        `json:"overrideOwnershipCheck"`
+}
+
+type CollectionVerifications struct {
+	Total         int64                             `json:"total"`
+	Verifications []entities.CollectionVerification `json:"verifications"`
+}
+
+type verificationNotificationData struct {
+	VerificationId    uint64                      `json:"verificationId"`
+	CollectionTokenId string                      `json:"collectionTokenId"`
+	Status            entities.VerificationStatus `json:"status"`
+	Reasons           []string                    `json:"reasons"`
+}
+
+// VerificationMessage is the message the contract controller signs. It names the applicant,
+// so a signature cannot back the application of someone else.
+func VerificationMessage(collection *entities.Collection, applicant string) string {
+	return fmt.Sprintf(verificationMessageFormat, collection.ContractAddress, collection.CollectionTokenID, applicant)
+}
+
+func GetCollectionVerificationStatus(collection *entities.Collection, applicant string) (*CollectionVerificationStatus, error) {
+	status := CollectionVerificationStatus{
+		IsVerified:      collection.IsVerified,
+		ContractAddress: collection.ContractAddress,
+		Message:         VerificationMessage(collection, applicant),
+	}
+
+	application, err := storage.GetLatestCollectionVerification(collection.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	status.Application = application
+
+	return &status, nil
+}
+
+// ApplyForCollectionVerification files a verification application once the signer proved it controls
+// the contract address. The automated checks run right away, an ownership the chain denies rejects the application.
+func ApplyForCollectionVerification(collection *entities.Collection, applicant string, request *VerificationApplicationRequest, api string, now int64) (*entities.CollectionVerification, error) {
+	if collection.IsVerified {
+		return nil, ErrCollectionAlreadyVerified
+	}
+	if collection.ContractAddress == "" {
+		return nil, ErrNoContractAddress
+	}
+
+	err := checkValidVerificationApplication(request)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := storage.HasPendingCollectionVerification(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrVerificationPending
+	}
+
+	err = verifyVerificationSignature(VerificationMessage(collection, applicant), request.SignerAddress, request.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := entities.CollectionVerification{
+		CollectionID:     collection.ID,
+		ApplicantAddress: applicant,
+		Website:          request.Website,
+		TwitterLink:      request.TwitterLink,
+		DiscordLink:      request.DiscordLink,
+		TelegramLink:     request.TelegramLink,
+		InstagramLink:    request.InstagramLink,
+		TeamContact:      request.TeamContact,
+		Evidence:         request.Evidence,
+		ContractAddress:  collection.ContractAddress,
+		SignerAddress:    request.SignerAddress,
+		Signature:        request.Signature,
+		Status:           entities.VerificationPending,
+		Reasons:          []byte("[]"),
+		CreatedAt:        now,
+	}
+
+	runVerificationChecks(&verification, apiContractOwnerLookup(api), now)
+	if verification.OwnershipCheck == entities.VerificationCheckFailed {
+		return nil, ErrNotContractController
+	}
+
+	err = storage.AddCollectionVerification(&verification)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// GetCollectionVerifications lists the applications with a status, every one when it is empty, oldest first.
+func GetCollectionVerifications(status entities.VerificationStatus, offset int, limit int) (*CollectionVerifications, error) {
+	if status != "" && !isVerificationStatus(status) {
+		return nil, ErrUnknownVerificationStatus
+	}
+	if limit > MaxVerificationsLimit {
+		limit = MaxVerificationsLimit
+	}
+
+	total, err := storage.CountCollectionVerifications(status)
+	if err != nil {
+		return nil, err
+	}
+
+	verifications, err := storage.GetCollectionVerifications(status, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CollectionVerifications{Total: total, Verifications: verifications}, nil
+}
+
+// RefreshCollectionVerificationChecks runs the automated checks of an application again,
+// like when the chain could not be reached on application or the collection got flagged since.
+func RefreshCollectionVerificationChecks(id uint64, api string, now int64) (*entities.CollectionVerification, error) {
+	verification, err := storage.GetCollectionVerificationById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	runVerificationChecks(verification, apiContractOwnerLookup(api), now)
+	err = storage.UpdateCollectionVerificationChecks(verification)
+	if err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// ReviewCollectionVerification records the decision on a pending application, verifies the collection
+// when it is approved and notifies the applicant. It returns the application as it was before.
+// An application whose ownership check failed is only approved with an explicit override.
+func ReviewCollectionVerification(id uint64, reviewer string, request *ReviewVerificationRequest, now int64) (*entities.CollectionVerification, error) {
+	err := checkValidVerificationReview(request)
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := storage.GetCollectionVerificationById(id)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Status != entities.VerificationPending {
+		return nil, ErrVerificationAlreadyReviewed
+	}
+
+	err = checkVerificationApprovable(verification, request)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := request.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	reasonsJson, err := json.Marshal(reasons)
+	if err != nil {
+		return nil, err
+	}
+
+	err = storage.SetCollectionVerificationDecision(id, request.Decision, reasonsJson, request.Note, reviewer, now)
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrVerificationAlreadyReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Decision == entities.VerificationApproved {
+		err = storage.SetCollectionVerified(verification.CollectionID, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	Notify(verification.ApplicantAddress, entities.NotificationCollectionVerification,
+		verificationDecisionMessage(verification.Collection.Name, request.Decision),
+		verificationNotificationData{
+			VerificationId:    verification.ID,
+			CollectionTokenId: verification.Collection.CollectionTokenID,
+			Status:            request.Decision,
+			Reasons:           reasons,
+		})
+
+	return verification, nil
+}
+
+// runVerificationChecks fills the automated checks of an application.
+// A check that cannot be run is left unknown instead of failing the application.
+func runVerificationChecks(verification *entities.CollectionVerification, lookupOwner ContractOwnerLookup, now int64) {
+	verification.OwnershipCheck, verification.ContractOwner = contractOwnershipCheck(verification.ContractAddress, verification.SignerAddress, lookupOwner)
+
+	verification.DuplicateScore = 0
+	verification.DuplicateMatchedCollection = ""
+	verification.DuplicateFlagStatus = ""
+	flag, err := storage.GetCollectionDuplicateWarningFlag(verification.CollectionID)
+	if err == nil {
+		verification.DuplicateScore = flag.Score
+		verification.DuplicateMatchedCollection = flag.MatchedCollection.CollectionTokenID
+		verification.DuplicateFlagStatus = flag.Status
+	} else if err != gorm.ErrRecordNotFound {
+		zlog.Error("could not get duplicate flag", zap.Uint64("collection", verification.CollectionID), zap.Error(err))
+	}
+
+	verification.ChecksRunAt = now
+}
+
+// contractOwnershipCheck passes when the signer is the contract address itself, like a creator wallet,
+// or the owner of the contract on chain. It returns the owner found.
+func contractOwnershipCheck(contractAddress string, signer string, lookupOwner ContractOwnerLookup) (entities.VerificationCheck, string) {
+	if signer == contractAddress {
+		return entities.VerificationCheckPassed, contractAddress
+	}
+
+	owner, err := lookupOwner(contractAddress)
+	if err != nil {
+		zlog.Warn("could not get contract owner", zap.String("contract", contractAddress), zap.Error(err))
+		return entities.VerificationCheckUnknown, ""
+	}
+	if owner != signer {
+		return entities.VerificationCheckFailed, owner
+	}
+
+	return entities.VerificationCheckPassed, owner
+}
+
+func apiContractOwnerLookup(api string) ContractOwnerLookup {
+	return func(address string) (string, error) {
+		body, err := GetResponse(fmt.Sprintf("%s/accounts/%s", api, address))
+		if err != nil {
+			return "", err
+		}
+
+		var account struct {
+			OwnerAddress string `json:"ownerAddress"`
+		}
+		err = json.Unmarshal(body, &account)
+		if err != nil {
+			return "", err
+		}
+
+		return account.OwnerAddress, nil
+	}
+}
+
+func verifyVerificationSignature(message string, signer string, signature string) error {
+	address, err := data.NewAddressFromBech32String(signer)
+	if err != nil {
+		return ErrInvalidVerificationSignature
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidVerificationSignature
+	}
+
+	err = crypto.VerifySignature(address.AddressBytes(), crypto.ComputeElrondSignableMessage([]byte(message)), sig)
+	if err != nil {
+		return ErrInvalidVerificationSignature
+	}
+
+	return nil
+}
+
+func verificationDecisionMessage(collectionName string, decision entities.VerificationStatus) string {
+	if decision == entities.VerificationApproved {
+		return fmt.Sprintf("%s is now verified.", collectionName)
+	}
+
+	return fmt.Sprintf("The verification of %s was declined.", collectionName)
+}
+
+func checkValidVerificationApplication(request *VerificationApplicationRequest) error {
+	if request.TeamContact == "" {
+		return ErrMissingTeamContact
+	}
+
+	for _, field := range []string{request.Website, request.TwitterLink, request.DiscordLink, request.TelegramLink, request.InstagramLink, request.TeamContact, request.Evidence} {
+		if len(field) > MaxVerificationTextLength {
+			return ErrVerificationTextTooLong
+		}
+	}
+
+	return nil
+}
+
+func checkValidVerificationReview(request *ReviewVerificationRequest) error {
+	if request.Decision != entities.VerificationApproved && request.Decision != entities.VerificationRejected {
+		return ErrUnknownVerificationStatus
+	}
+	if request.Decision == entities.VerificationRejected && len(request.Reasons) == 0 {
+		return ErrVerificationReasonsRequired
+	}
+	if len(request.Reasons) > MaxVerificationReasons {
+		return ErrTooManyVerificationReasons
+	}
+
+	for _, reason := range request.Reasons {
+		if len(reason) > MaxVerificationTextLength {
+			return ErrVerificationTextTooLong
+		}
+	}
+	if len(request.Note) > MaxVerificationTextLength {
+		return ErrVerificationTextTooLong
+	}
+	if request.OverrideOwnershipCheck && request.Note == "" {
+		return ErrOverrideNoteRequired
+	}
+
+	return nil
+}
+
+// checkVerificationApprovable refuses to approve an application whose signer was found not to control the contract,
+// unless the reviewer overrides the ownership check.
+func checkVerificationApprovable(verification *entities.CollectionVerification, request *ReviewVerificationRequest) error {
+	if request.Decision != entities.VerificationApproved || request.OverrideOwnershipCheck {
+		return nil
+	}
+	if verification.OwnershipCheck == entities.VerificationCheckFailed {
+		return ErrOwnershipCheckFailed
+	}
+
+	return nil
+}
+
+func IsVerificationRequestErr(err error) bool {
+	return errors.Is(err, ErrNoContractAddress) ||
+		errors.Is(err, ErrMissingTeamContact) ||
+		errors.Is(err, ErrVerificationTextTooLong) ||
+		errors.Is(err, ErrInvalidVerificationSignature) ||
+		errors.Is(err, ErrUnknownVerificationStatus) ||
+		errors.Is(err, ErrVerificationReasonsRequired) ||
+		errors.Is(err, ErrTooManyVerificationReasons) ||
+		errors.Is(err, ErrOverrideNoteRequired)
+}
+
+func isVerificationStatus(status entities.VerificationStatus) bool {
+	switch status {
+	case entities.VerificationPending, entities.VerificationApproved, entities.VerificationRejected:
+		return true
+	default:
+		return false
+	}
+}
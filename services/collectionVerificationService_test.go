@@ -0,0 +1,104 @@
+package services
+
+import (
+	libed25519 "crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/crypto"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
+	"github.com/stretchr/testify/require"
+)
+
+func signVerificationMessage(sk libed25519.PrivateKey, message string) (string, string) {
+	address := data.NewAddressFromBytes(sk[libed25519.PublicKeySize:]).AddressAsBech32String()
+	sig, _ := crypto.SignPayload(sk, crypto.ComputeElrondSignableMessage([]byte(message)))
+
+	return address, hex.EncodeToString(sig)
+}
+
+func Test_VerifyVerificationSignature(t *testing.T) {
+	t.Parallel()
+
+	seed, _ := hex.DecodeString("4c6f8a7d0b1c2e3f405162738495a6b7c8d9eaf00112233445566778899aabbc")
+	collection := &entities.Collection{CollectionTokenID: "COLL-123456", ContractAddress: "erd1qqqqqqqqqqqqqpgq"}
+	message := VerificationMessage(collection, "erd1applicant")
+
+	signer, signature := signVerificationMessage(crypto.NewEdKey(seed), message)
+	require.Nil(t, verifyVerificationSignature(message, signer, signature))
+
+	// the signature does not carry over to another applicant
+	otherMessage := VerificationMessage(collection, "erd1someoneelse")
+	require.Equal(t, ErrInvalidVerificationSignature, verifyVerificationSignature(otherMessage, signer, signature))
+
+	require.Equal(t, ErrInvalidVerificationSignature, verifyVerificationSignature(message, signer, "not hex"))
+	require.Equal(t, ErrInvalidVerificationSignature, verifyVerificationSignature(message, "erd1notanaddress", signature))
+}
+
+func Test_ContractOwnershipCheck(t *testing.T) {
+	t.Parallel()
+
+	noLookup := func(address string) (string, error) {
+		t.Fatal("wallets need no lookup")
+		return "", nil
+	}
+	check, owner := contractOwnershipCheck("erd1creator", "erd1creator", noLookup)
+	require.Equal(t, entities.VerificationCheckPassed, check)
+	require.Equal(t, "erd1creator", owner)
+
+	ownedBy := func(owner string) ContractOwnerLookup {
+		return func(address string) (string, error) {
+			return owner, nil
+		}
+	}
+	check, _ = contractOwnershipCheck("erd1contract", "erd1owner", ownedBy("erd1owner"))
+	require.Equal(t, entities.VerificationCheckPassed, check)
+
+	check, owner = contractOwnershipCheck("erd1contract", "erd1impostor", ownedBy("erd1owner"))
+	require.Equal(t, entities.VerificationCheckFailed, check)
+	require.Equal(t, "erd1owner", owner)
+
+	unreachable := func(address string) (string, error) {
+		return "", errors.New("api down")
+	}
+	check, _ = contractOwnershipCheck("erd1contract", "erd1owner", unreachable)
+	require.Equal(t, entities.VerificationCheckUnknown, check)
+}
+
+func Test_CheckValidVerificationReview(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, checkValidVerificationReview(&ReviewVerificationRequest{Decision: entities.VerificationApproved}))
+	require.Nil(t, checkValidVerificationReview(&ReviewVerificationRequest{Decision: entities.VerificationRejected, Reasons: []string{"no team contact"}}))
+
+	require.Equal(t, ErrVerificationReasonsRequired, checkValidVerificationReview(&ReviewVerificationRequest{Decision: entities.VerificationRejected}))
+	require.Equal(t, ErrUnknownVerificationStatus, checkValidVerificationReview(&ReviewVerificationRequest{Decision: entities.VerificationPending}))
+	require.True(t, IsVerificationRequestErr(checkValidVerificationReview(&ReviewVerificationRequest{Decision: "maybe"})))
+	require.Equal(t, ErrOverrideNoteRequired, checkValidVerificationReview(&ReviewVerificationRequest{Decision: entities.VerificationApproved, OverrideOwnershipCheck: true}))
+}
+
+func Test_CheckVerificationApprovable(t *testing.T) {
+	t.Parallel()
+
+	failed := &entities.CollectionVerification{OwnershipCheck: entities.VerificationCheckFailed}
+	approve := &ReviewVerificationRequest{Decision: entities.VerificationApproved}
+	require.Equal(t, ErrOwnershipCheckFailed, checkVerificationApprovable(failed, approve))
+
+	override := &ReviewVerificationRequest{Decision: entities.VerificationApproved, OverrideOwnershipCheck: true, Note: "owner confirmed by the team"}
+	require.Nil(t, checkVerificationApprovable(failed, override))
+
+	reject := &ReviewVerificationRequest{Decision: entities.VerificationRejected, Reasons: []string{"not the owner"}}
+	require.Nil(t, checkVerificationApprovable(failed, reject))
+
+	unknown := &entities.CollectionVerification{OwnershipCheck: entities.VerificationCheckUnknown}
+	require.Nil(t, checkVerificationApprovable(unknown, approve))
+}
+
+func Test_CheckValidVerificationApplication(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, ErrMissingTeamContact, checkValidVerificationApplication(&VerificationApplicationRequest{Website: "https://youbei.io"}))
+	require.Nil(t, checkValidVerificationApplication(&VerificationApplicationRequest{TeamContact: "team@youbei.io"}))
+}
@@ -9,7 +9,8 @@ import (
 )
 
 var cacheCfg = config.CacheConfig{
-	Url: "redis://localhost:6379",
+	ReadUrl:  "redis://localhost:6379",
+	WriteUrl: "redis://localhost:6379",
 }
 
 func Test_UpdateDeposit(t *testing.T) {
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxDraftDataLen  = 64 * 1024
+	MaxDraftNameLen  = 50
+	emptyDraftData   = "{}"
+	defaultDraftTTL  = 72 * time.Hour
+	defaultDraftsCap = 20
+	defaultSweepTime = 30 * time.Minute
+)
+
+var (
+	ErrUnknownDraftType  = errors.New("unknown draft type")
+	ErrDraftNotOwned     = errors.New("draft belongs to another address")
+	ErrTooManyDrafts     = errors.New("too many drafts of this type")
+	ErrDraftDataTooLarge = errors.New("draft data too large")
+	ErrDraftNameTooLong  = errors.New("draft name too long")
+	ErrInvalidDraftData  = errors.New("invalid draft data")
+)
+
+const createCollectionDraftSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"collectionName": {"type": "string", "maxLength": 20},
+		"description": {"type": "string", "maxLength": 1000},
+		"tokenName": {"type": "string", "maxLength": 20},
+		"tokenTicker": {"type": "string", "maxLength": 10},
+		"royalties": {"type": "number", "minimum": 0, "maximum": 100},
+		"tokenNameBase": {"type": "string", "maxLength": 20},
+		"imageBaseLink": {"type": "string", "maxLength": 100},
+		"imageExt": {"type": "string", "maxLength": 10},
+		"metadataBaseLink": {"type": "string", "maxLength": 100},
+		"price": {"type": "string", "maxLength": 40},
+		"maxSupply": {"type": "integer", "minimum": 0},
+		"saleStart": {"type": "integer", "minimum": 0},
+		"flags": {"type": "array", "maxItems": 10, "items": {"type": "string", "maxLength": 25}},
+		"step": {"type": "integer", "minimum": 0}
+	}
+}`
+
+const bulkListingDraftSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"items": {
+			"type": "array",
+			"maxItems": 100,
+			"items": {
+				"type": "object",
+				"additionalProperties": false,
+				"required": ["tokenId", "nonce"],
+				"properties": {
+					"tokenId": {"type": "string", "maxLength": 17},
+					"nonce": {"type": "integer", "minimum": 0},
+					"price": {"type": "string", "maxLength": 40},
+					"mode": {"enum": ["fixed", "auction"]},
+					"deadline": {"type": "integer", "minimum": 0}
+				}
+			}
+		}
+	}
+}`
+
+const profileEditDraftSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "maxLength": 20},
+		"description": {"type": "string", "maxLength": 1000},
+		"website": {"type": "string", "maxLength": 100},
+		"twitterLink": {"type": "string", "maxLength": 100},
+		"instagramLink": {"type": "string", "maxLength": 100}
+	}
+}`
+
+// DraftTypeDefinition is a registered kind of draft. Drafts are saved partially filled,
+// so the schemas constrain the shape of the fields but require none at the top level.
+type DraftTypeDefinition struct {
+	Type   entities.DraftType `json:"type"`
+	Schema json.RawMessage    `json:"schema"`
+	// TTL overrides the configured expiry when not zero.
+	TTL time.Duration `json:"-"`
+
+	schema *utils.JsonSchema
+}
+
+type CreateDraftRequest struct {
+	Type     entities.DraftType `json:"type"`
+	Name     string             `json:"name"`
+	JsonData json.RawMessage    `json:"jsonData"`
+}
+
+type UpdateDraftRequest struct {
+	Version  uint64          `json:"version"`
+	Name     string          `json:"name"`
+	JsonData json.RawMessage `json:"jsonData"`
+}
+
+var (
+	draftsLock     sync.RWMutex
+	draftTypes     = map[entities.DraftType]*DraftTypeDefinition{}
+	draftsSettings = defaultDraftsSettings()
+)
+
+func init() {
+	mustRegisterDraftType(entities.DraftCreateCollection, createCollectionDraftSchema, 0)
+	mustRegisterDraftType(entities.DraftBulkListing, bulkListingDraftSchema, 24*time.Hour)
+	mustRegisterDraftType(entities.DraftProfileEdit, profileEditDraftSchema, 0)
+}
+
+func defaultDraftsSettings() config.DraftsConfig {
+	return config.DraftsConfig{
+		ExpiryHours:      uint64(defaultDraftTTL / time.Hour),
+		SweepMinutes:     uint64(defaultSweepTime / time.Minute),
+		MaxDraftsPerType: defaultDraftsCap,
+	}
+}
+
+// InitDrafts sets the draft expiry, sweep period and per type cap. Zero values keep the defaults.
+func InitDrafts(cfg config.DraftsConfig) {
+	draftsLock.Lock()
+	defer draftsLock.Unlock()
+
+	defaults := defaultDraftsSettings()
+	if cfg.ExpiryHours == 0 {
+		cfg.ExpiryHours = defaults.ExpiryHours
+	}
+	if cfg.SweepMinutes == 0 {
+		cfg.SweepMinutes = defaults.SweepMinutes
+	}
+	if cfg.MaxDraftsPerType == 0 {
+		cfg.MaxDraftsPerType = defaults.MaxDraftsPerType
+	}
+	draftsSettings = cfg
+}
+
+func getDraftsSettings() config.DraftsConfig {
+	draftsLock.RLock()
+	defer draftsLock.RUnlock()
+
+	return draftsSettings
+}
+
+// RegisterDraftType adds a kind of draft validated against the given json schema.
+func RegisterDraftType(draftType entities.DraftType, schema string, ttl time.Duration) error {
+	parsed, err := utils.ParseJsonSchema([]byte(schema))
+	if err != nil {
+		return fmt.Errorf("draft type %s: %w", draftType, err)
+	}
+
+	draftsLock.Lock()
+	defer draftsLock.Unlock()
+
+	draftTypes[draftType] = &DraftTypeDefinition{
+		Type:   draftType,
+		Schema: json.RawMessage(schema),
+		TTL:    ttl,
+		schema: parsed,
+	}
+	return nil
+}
+
+func mustRegisterDraftType(draftType entities.DraftType, schema string, ttl time.Duration) {
+	err := RegisterDraftType(draftType, schema, ttl)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func GetDraftTypes() []DraftTypeDefinition {
+	draftsLock.RLock()
+	defer draftsLock.RUnlock()
+
+	definitions := make([]DraftTypeDefinition, 0, len(draftTypes))
+	for _, definition := range draftTypes {
+		definitions = append(definitions, *definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Type < definitions[j].Type
+	})
+
+	return definitions
+}
+
+func getDraftType(draftType entities.DraftType) (*DraftTypeDefinition, error) {
+	draftsLock.RLock()
+	defer draftsLock.RUnlock()
+
+	definition, ok := draftTypes[draftType]
+	if !ok {
+		return nil, ErrUnknownDraftType
+	}
+
+	return definition, nil
+}
+
+// ValidateDraftData checks the size and schema of a draft document. An empty document stands for an empty object.
+func ValidateDraftData(draftType entities.DraftType, jsonData json.RawMessage) (datatypes.JSON, error) {
+	definition, err := getDraftType(draftType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jsonData) == 0 {
+		jsonData = json.RawMessage(emptyDraftData)
+	}
+	if len(jsonData) > MaxDraftDataLen {
+		return nil, ErrDraftDataTooLarge
+	}
+
+	err = definition.schema.Validate(jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDraftData, err.Error())
+	}
+
+	return datatypes.JSON(jsonData), nil
+}
+
+func CreateDraft(address string, request *CreateDraftRequest, now time.Time) (*entities.Draft, error) {
+	definition, err := getDraftType(request.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.Name) > MaxDraftNameLen {
+		return nil, ErrDraftNameTooLong
+	}
+
+	jsonData, err := ValidateDraftData(request.Type, request.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := storage.CountDraftsByAddressAndType(address, request.Type, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	if uint64(count) >= getDraftsSettings().MaxDraftsPerType {
+		return nil, ErrTooManyDrafts
+	}
+
+	draft := &entities.Draft{
+		Address:   address,
+		Type:      request.Type,
+		Name:      request.Name,
+		Version:   1,
+		JsonData:  jsonData,
+		ExpiresAt: draftExpiry(definition, now),
+		CreatedAt: now.Unix(),
+		UpdatedAt: now.Unix(),
+	}
+	err = storage.AddDraft(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// GetDraftForAddress returns a draft of the address, expired drafts waiting for the sweep are not found.
+func GetDraftForAddress(address string, id uint64, now time.Time) (*entities.Draft, error) {
+	draft, err := storage.GetDraftById(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Address != address {
+		return nil, ErrDraftNotOwned
+	}
+	if draft.ExpiresAt <= now.Unix() {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return draft, nil
+}
+
+func GetDraftsForAddress(address string, draftType entities.DraftType, now time.Time) ([]entities.Draft, error) {
+	if draftType != "" {
+		_, err := getDraftType(draftType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return storage.GetDraftsByAddress(address, draftType, now.Unix())
+}
+
+// UpdateDraft replaces the name and data of a draft and pushes back its expiry.
+// The request version must be the current one, otherwise storage.ErrDraftVersionConflict is returned.
+func UpdateDraft(address string, id uint64, request *UpdateDraftRequest, now time.Time) (*entities.Draft, error) {
+	draft, err := GetDraftForAddress(address, id, now)
+	if err != nil {
+		return nil, err
+	}
+	if request.Version != draft.Version {
+		return nil, storage.ErrDraftVersionConflict
+	}
+	if len(request.Name) > MaxDraftNameLen {
+		return nil, ErrDraftNameTooLong
+	}
+
+	jsonData, err := ValidateDraftData(draft.Type, request.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := getDraftType(draft.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Name = request.Name
+	draft.JsonData = jsonData
+	draft.ExpiresAt = draftExpiry(definition, now)
+	draft.UpdatedAt = now.Unix()
+	err = storage.UpdateDraft(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+func DeleteDraft(address string, id uint64, now time.Time) error {
+	draft, err := GetDraftForAddress(address, id, now)
+	if err != nil {
+		return err
+	}
+
+	return storage.DeleteDraft(draft.ID)
+}
+
+// StartDraftSweeper deletes the expired drafts every configured sweep period. It never returns.
+func StartDraftSweeper() {
+	for {
+		time.Sleep(time.Duration(getDraftsSettings().SweepMinutes) * time.Minute)
+
+		deleted, err := storage.DeleteExpiredDrafts(time.Now().Unix())
+		if err != nil {
+			log.Debug("could not delete expired drafts", "err", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Debug("deleted expired drafts", "count", deleted)
+		}
+	}
+}
+
+func draftExpiry(definition *DraftTypeDefinition, now time.Time) int64 {
+	ttl := definition.TTL
+	if ttl == 0 {
+		ttl = time.Duration(getDraftsSettings().ExpiryHours) * time.Hour
+	}
+
+	return now.Add(ttl).Unix()
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateDraftData(t *testing.T) {
+	data, err := ValidateDraftData(entities.DraftCreateCollection, nil)
+	require.Nil(t, err)
+	require.Equal(t, "{}", string(data))
+
+	_, err = ValidateDraftData(entities.DraftCreateCollection, json.RawMessage(`{"collectionName": "apes", "royalties": 5, "maxSupply": 1000}`))
+	require.Nil(t, err)
+
+	_, err = ValidateDraftData(entities.DraftCreateCollection, json.RawMessage(`{"royalties": 150}`))
+	require.True(t, errors.Is(err, ErrInvalidDraftData))
+
+	_, err = ValidateDraftData(entities.DraftBulkListing, json.RawMessage(`{"items": [{"tokenId": "APE-0a1b2c", "nonce": 3, "price": "1.5", "mode": "fixed"}]}`))
+	require.Nil(t, err)
+
+	_, err = ValidateDraftData(entities.DraftBulkListing, json.RawMessage(`{"items": [{"tokenId": "APE-0a1b2c"}]}`))
+	require.True(t, errors.Is(err, ErrInvalidDraftData))
+
+	_, err = ValidateDraftData(entities.DraftProfileEdit, json.RawMessage(`{"name": "ape", "email": "ape@ape.com"}`))
+	require.True(t, errors.Is(err, ErrInvalidDraftData))
+
+	_, err = ValidateDraftData("unknown", json.RawMessage(`{}`))
+	require.Equal(t, ErrUnknownDraftType, err)
+}
+
+func Test_DraftExpiry(t *testing.T) {
+	InitDrafts(config.DraftsConfig{ExpiryHours: 10})
+	defer InitDrafts(config.DraftsConfig{})
+
+	now := time.Unix(1000, 0)
+	profile, err := getDraftType(entities.DraftProfileEdit)
+	require.Nil(t, err)
+	require.Equal(t, int64(1000+10*3600), draftExpiry(profile, now))
+
+	listing, err := getDraftType(entities.DraftBulkListing)
+	require.Nil(t, err)
+	require.Equal(t, int64(1000+24*3600), draftExpiry(listing, now))
+
+	require.Len(t, GetDraftTypes(), 3)
+}
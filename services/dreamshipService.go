@@ -1,68 +1,35 @@
 package services
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/ENFT-DAO/youbei-api/cache"
-	"github.com/ENFT-DAO/youbei-api/config"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
 	"github.com/ENFT-DAO/youbei-api/storage"
-	"github.com/hashicorp/go-uuid"
 )
 
 const (
-	itemsBaseUrl						=	"https://api.dreamship.com/v1/items"
-	orderUrl							= 	"https://api.dreamship.com/v1/orders/"
-	orderStatusUrl						=	"https://api.dreamship.com/v1/orders/?reference_id=%s"
-	availableItemsUrl					=	"%s/%d/"
-	shippingUrl							=	"%s/%d/%s/"
-	usShippingUrl						=	"us-shipping-methods"
-	internationalShipping				=	"international-shipping-methods"
-
-	dreamshipItemsCacheKey				=	"dreamshipItems"
-	dreamshipShippingBaseCacheKey		=	"dreamshipShipping-%s"
-	dreamshipSubmitedOrderBaseCacheKey	=	"submitedOrder-%s"
-
-	dreamshipSubmitedOrderPeriod		=	48 * time.Hour
-	dreamshipItemsInfoExpirePeriod		=	6 * time.Hour
+	dreamshipItemsCacheKey             = "dreamshipItems"
+	dreamshipShippingBaseCacheKey      = "dreamshipShipping-%s-%d"
+	dreamshipSubmitedOrderBaseCacheKey = "submitedOrder-%s"
+
+	dreamshipSubmitedOrderPeriod   = 48 * time.Hour
+	dreamshipItemsInfoExpirePeriod = 6 * time.Hour
 )
 
-// 19 is item id of canvas in dreamship
-// To add more item, just add its id, can be find here https://api.dreamship.com/v1/items/
-var availableItem = [1]int64{19}
+var ErrPrintProductExists = errors.New("print product already in the catalog")
 
-func SetOrderHandler(cfg config.ExternalCredentialConfig, order entities.DreamshipOrderItems, walletAddress string) (entities.ItemWebhook, error) {
-	
-	response, err := SetOrder(cfg, order)
-	if err != nil {
-		return entities.ItemWebhook{}, err
-	}
-	amount, err := strconv.ParseFloat(response.Cost, 64)
-	if err != nil {
-		return entities.ItemWebhook{}, err
-	}
-	userOrder := entities.UserOrders {
-		UserAddress: walletAddress,
-		OrderStatus: "Submited",
-		Amount: amount,
-		CheckoutStatus: "Pending",
-		PaymentMethod: "EGLD",
-		OrderId: response.ReferenceId,
-	}
-	err = storage.AddOrUpdateOrderItem(userOrder)
-	if err != nil {
-		fmt.Println("Error raised!")
-	}
-	return response, err
+type PrintProductRequest struct {
+	ProviderItemId int64  `json:"providerItemId"`
+	Name           string `json:"name"`
+	Enabled        bool   `json:"enabled"`
 }
 
-func GetSubmitedOrdersStatusHandler(referenceId string, cfg config.ExternalCredentialConfig) (entities.ItemWebhook, error){
+func GetSubmitedOrdersStatusHandler(referenceId string) (entities.ItemWebhook, error) {
 	localCacher := cache.GetLocalCacher()
 	dreamshipSubmitedOrderCacheKey := fmt.Sprintf(dreamshipSubmitedOrderBaseCacheKey, referenceId)
 
@@ -70,89 +37,39 @@ func GetSubmitedOrdersStatusHandler(referenceId string, cfg config.ExternalCrede
 	if errRead == nil {
 		return orderValue.(entities.ItemWebhook), nil
 	}
-	order, err := GetSubmitedOrdersStatus(referenceId, cfg)
+
+	order, err := GetSubmitedOrdersStatus(referenceId)
 	if err != nil {
 		return entities.ItemWebhook{}, err
 	}
+
 	errSet := localCacher.SetWithTTLSync(dreamshipSubmitedOrderCacheKey, order, dreamshipSubmitedOrderPeriod)
 	if errSet != nil {
 		log.Debug("could not cache result", errSet)
 	}
-	return order, nil
-}
 
-func GetSubmitedOrdersStatus(referenceId string, cfg config.ExternalCredentialConfig) (entities.ItemWebhook, error) {
-	
-	url := fmt.Sprintf(orderStatusUrl, referenceId)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Add("Accept", "application/json")
-	bearer := fmt.Sprintf("Bearer %s", cfg.DreamshipAPIKey)
-	req.Header.Add("Authorization", bearer)
-
-	res, _ := http.DefaultClient.Do(req)
-	defer res.Body.Close()
-	body, _ := ioutil.ReadAll(res.Body)
-	var response entities.ItemWebhook
-	err := json.Unmarshal(body, &response)
-	if err != nil {
-		return response, nil
-	}
-	return response, nil
+	return order, nil
 }
 
-func DreamshipWebHook(order entities.ItemWebhook) error{
-	// Update user order
-	localCacher := cache.GetLocalCacher()
-	amount, err := strconv.ParseFloat(order.Cost, 64)
+func GetSubmitedOrdersStatus(referenceId string) (entities.ItemWebhook, error) {
+	provider, err := fulfillment.GetProviderOrErr()
 	if err != nil {
-		return err
-	}
-	// Save reference id in postgresql
-	userOrder := entities.UserOrders {
-		Amount: amount,
-		OrderStatus: order.Status,
-		CheckoutStatus: "Successful",
-		PaymentMethod: "Crypto",
-		OrderId: order.ReferenceId,
+		return entities.ItemWebhook{}, err
 	}
-	storage.AddOrUpdateOrderItem(userOrder)
 
-	// cache order result in Redis
-	dreamshipSubmitedOrderCacheKey := fmt.Sprintf(dreamshipSubmitedOrderBaseCacheKey, order.ReferenceId)
-	localCacher.Del(dreamshipSubmitedOrderCacheKey)
-	errSet := localCacher.SetWithTTLSync(dreamshipSubmitedOrderCacheKey, order, dreamshipSubmitedOrderPeriod)
-	if errSet != nil {
-		log.Debug("could not cache result", errSet)
-	}
-	return nil
+	return provider.GetOrder(context.Background(), referenceId)
 }
 
-func SetOrder(cfg config.ExternalCredentialConfig, order entities.DreamshipOrderItems) (entities.ItemWebhook, error) {
-	referenceId, err := uuid.GenerateUUID()
-	order.ReferenceId = referenceId
-	orderJson, err := json.Marshal(order)
-	var response entities.ItemWebhook
+func SetOrder(order entities.DreamshipOrderItems) (entities.ItemWebhook, error) {
+	provider, err := fulfillment.GetProviderOrErr()
 	if err != nil {
-		return response, err
+		return entities.ItemWebhook{}, err
 	}
-	req, _ := http.NewRequest("POST", orderUrl, bytes.NewBuffer(orderJson))
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-	bearer := fmt.Sprintf("Bearer %s", cfg.DreamshipAPIKey)
-	req.Header.Add("Authorization", bearer)
-
-	res, _ := http.DefaultClient.Do(req)
-	defer res.Body.Close()
-	body, _ := ioutil.ReadAll(res.Body)
 
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return response, nil
-	}
-	return response, nil
+	return provider.CreateOrder(context.Background(), order)
 }
 
-func GetAvailableVariantsHandler(cfg config.ExternalCredentialConfig) ([]entities.DreamshipItems, error) {
+func GetAvailableVariantsHandler() ([]entities.DreamshipItems, error) {
 	localCacher := cache.GetLocalCacher()
 
 	itemsVal, errRead := localCacher.Get(dreamshipItemsCacheKey)
@@ -160,10 +77,11 @@ func GetAvailableVariantsHandler(cfg config.ExternalCredentialConfig) ([]entitie
 		return itemsVal.([]entities.DreamshipItems), nil
 	}
 
-	items, err := GetAvailableVariants(cfg)
+	items, err := GetAvailableVariants()
 	if err != nil {
 		return items, err
 	}
+
 	errSet := localCacher.SetWithTTLSync(dreamshipItemsCacheKey, items, dreamshipItemsInfoExpirePeriod)
 	if errSet != nil {
 		log.Debug("could not cache result", errSet)
@@ -172,18 +90,20 @@ func GetAvailableVariantsHandler(cfg config.ExternalCredentialConfig) ([]entitie
 	return items, nil
 }
 
-func GetShipmentMethodsAndCostsHandler(cfg config.ExternalCredentialConfig, usOrInternational string, item int64) (map[string]entities.ShippingMethodResponse, error) {
+func GetShipmentMethodsAndCostsHandler(usOrInternational string, item int64) (map[string]entities.ShippingMethodResponse, error) {
 	localCacher := cache.GetLocalCacher()
-	dreamshipShippingCacheKey := fmt.Sprintf(dreamshipShippingBaseCacheKey, usOrInternational)
+	dreamshipShippingCacheKey := fmt.Sprintf(dreamshipShippingBaseCacheKey, usOrInternational, item)
+
 	itemsVal, errRead := localCacher.Get(dreamshipShippingCacheKey)
 	if errRead == nil {
 		return itemsVal.(map[string]entities.ShippingMethodResponse), nil
 	}
 
-	items, err := GetShipmentMethodsAndCosts(cfg, usOrInternational, item)
+	items, err := GetShipmentMethodsAndCosts(usOrInternational, item)
 	if err != nil {
 		return items, err
 	}
+
 	errSet := localCacher.SetWithTTLSync(dreamshipShippingCacheKey, items, dreamshipItemsInfoExpirePeriod)
 	if errSet != nil {
 		log.Debug("could not cache result", errSet)
@@ -192,50 +112,128 @@ func GetShipmentMethodsAndCostsHandler(cfg config.ExternalCredentialConfig, usOr
 	return items, nil
 }
 
-func GetAvailableVariants(cfg config.ExternalCredentialConfig) ([]entities.DreamshipItems, error) {
-	var availableItems []entities.DreamshipItems
-	for _, item := range availableItem{
-		url := fmt.Sprintf(availableItemsUrl, itemsBaseUrl, item)
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Add("Accept", "application/json")
-		bearer := fmt.Sprintf("Bearer %s", cfg.DreamshipAPIKey)
-		req.Header.Add("Authorization", bearer)
-		res, _ := http.DefaultClient.Do(req)
-		defer res.Body.Close()
-		body, _ := ioutil.ReadAll(res.Body)
-		var availItem entities.DreamshipItems
-		err := json.Unmarshal(body, &availItem)
+// GetAvailableVariants fetches the enabled products of the catalog from the provider.
+// A product the provider does not know anymore is skipped.
+func GetAvailableVariants() ([]entities.DreamshipItems, error) {
+	provider, err := fulfillment.GetProviderOrErr()
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := storage.GetPrintProducts(true)
+	if err != nil {
+		return nil, err
+	}
+
+	availableItems := make([]entities.DreamshipItems, 0, len(products))
+	for _, product := range products {
+		item, err := provider.GetItem(context.Background(), product.ProviderItemId)
+		if errors.Is(err, fulfillment.ErrItemNotFound) {
+			log.Warn("print product not found at provider", "item", product.ProviderItemId)
+			continue
+		}
 		if err != nil {
-			fmt.Println(err)
-			return []entities.DreamshipItems{}, err
+			return nil, err
 		}
-		availableItems = append(availableItems, availItem) 
+
+		availableItems = append(availableItems, item)
 	}
+
 	return availableItems, nil
 }
 
-func GetShipmentMethodsAndCosts(cfg config.ExternalCredentialConfig, usOrInternational string, item int64) (map[string]entities.ShippingMethodResponse, error) {
-	
-	url := fmt.Sprintf(shippingUrl, itemsBaseUrl, item, usShippingUrl)
-	if usOrInternational != "us"  {
-		url = fmt.Sprintf(shippingUrl, itemsBaseUrl, item, internationalShipping)
+func GetShipmentMethodsAndCosts(usOrInternational string, item int64) (map[string]entities.ShippingMethodResponse, error) {
+	provider, err := fulfillment.GetProviderOrErr()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetShippingMethods(context.Background(), usOrInternational, item)
+}
+
+func GetPrintCatalog() ([]entities.PrintProduct, error) {
+	return storage.GetPrintProducts(false)
+}
+
+// AddPrintProduct adds a provider item to the catalog, its name defaults to the provider one.
+func AddPrintProduct(request *PrintProductRequest) (*entities.PrintProduct, error) {
+	provider, err := fulfillment.GetProviderOrErr()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := provider.GetItem(context.Background(), request.ProviderItemId)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := storage.GetPrintProducts(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if product.ProviderItemId == request.ProviderItemId {
+			return nil, ErrPrintProductExists
+		}
+	}
+
+	name := request.Name
+	if name == "" {
+		name = item.Name
+	}
+
+	now := time.Now().Unix()
+	product := &entities.PrintProduct{
+		ProviderItemId: request.ProviderItemId,
+		Name:           name,
+		Enabled:        request.Enabled,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	err = storage.AddPrintProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidatePrintItems()
+	return product, nil
+}
+
+// UpdatePrintProduct renames or toggles a product, the provider item can not change.
+func UpdatePrintProduct(id uint64, request *PrintProductRequest) (*entities.PrintProduct, error) {
+	product, err := storage.GetPrintProductById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Name != "" {
+		product.Name = request.Name
 	}
+	product.Enabled = request.Enabled
+	product.UpdatedAt = time.Now().Unix()
 
-	
-	req, _ := http.NewRequest("GET", url, nil)
-	
-	req.Header.Add("Accept", "application/json")
-	bearer := fmt.Sprintf("Bearer %s", cfg.DreamshipAPIKey)
-	req.Header.Add("Authorization", bearer)
-	res, _ := http.DefaultClient.Do(req)
-	defer res.Body.Close()
-	body, _ := ioutil.ReadAll(res.Body)
-	
-	var m map[string]entities.ShippingMethodResponse
-	err := json.Unmarshal(body, &m)
+	err = storage.UpdatePrintProduct(product)
 	if err != nil {
-		return map[string]entities.ShippingMethodResponse{}, err
+		return nil, err
 	}
 
-	return m, nil
-}
\ No newline at end of file
+	invalidatePrintItems()
+	return product, nil
+}
+
+func DeletePrintProduct(id uint64) error {
+	err := storage.DeletePrintProduct(id)
+	if err != nil {
+		return err
+	}
+
+	invalidatePrintItems()
+	return nil
+}
+
+func invalidatePrintItems() {
+	err := cache.GetLocalCacher().Del(dreamshipItemsCacheKey)
+	if err != nil {
+		log.Debug("could not invalidate print items", "err", err)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/imagehash"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// a token copies a token of a verified collection when both of their hashes are this close
+	DuplicatePHashDistance = 10
+	DuplicateDHashDistance = 12
+
+	// a collection is flagged when this share of its hashed tokens copies a single verified collection,
+	// collections with fewer hashed tokens are not judged yet
+	DuplicateFlagScore     = 0.5
+	DuplicateFlagMinTokens = 5
+
+	MaxDuplicateFlagsLimit = 100
+)
+
+var ErrUnknownDuplicateFlagStatus = errors.New("unknown duplicate flag status")
+
+// DuplicateMatch is how much a collection copies one verified collection.
+type DuplicateMatch struct {
+	MatchedCollectionID uint64
+	ComparedTokens      uint64
+	MatchedTokens       uint64
+	Score               float64
+	Similarity          float64
+}
+
+type DuplicateFlags struct {
+	Total int64                              `json:"total"`
+	Flags []entities.CollectionDuplicateFlag `json:"flags"`
+}
+
+type ReviewDuplicateFlagRequest struct {
+	Status entities.DuplicateFlagStatus `json:"status"`
+	Note   string                       `json:"note"`
+}
+
+// referenceHashes are the hashes of the verified collections, indexed by pHash.
+type referenceHashes struct {
+	hashes []entities.TokenImageHash
+	index  *imagehash.Index
+}
+
+func newReferenceHashes(hashes []entities.TokenImageHash) *referenceHashes {
+	index := imagehash.NewIndex()
+	for i, hash := range hashes {
+		index.Add(uint64(hash.PHash), i)
+	}
+
+	return &referenceHashes{hashes: hashes, index: index}
+}
+
+// match scores the hashes of a collection against every verified collection it copies, best first.
+// A token counts once per verified collection, with its closest match.
+func (refs *referenceHashes) match(hashes []entities.TokenImageHash) []DuplicateMatch {
+	compared := uint64(0)
+	matched := map[uint64]uint64{}
+	similarities := map[uint64]float64{}
+
+	for _, hash := range hashes {
+		if hash.Failed {
+			continue
+		}
+		compared++
+
+		best := map[uint64]float64{}
+		refs.index.Search(uint64(hash.PHash), DuplicatePHashDistance, func(value int, distance int) {
+			ref := refs.hashes[value]
+			if ref.CollectionID == hash.CollectionID {
+				return
+			}
+
+			dDistance := imagehash.Distance(uint64(ref.DHash), uint64(hash.DHash))
+			if dDistance > DuplicateDHashDistance {
+				return
+			}
+
+			similarity := 1 - float64(distance+dDistance)/(2*imagehash.HashBits)
+			if similarity > best[ref.CollectionID] {
+				best[ref.CollectionID] = similarity
+			}
+		})
+
+		for collectionId, similarity := range best {
+			matched[collectionId]++
+			similarities[collectionId] += similarity
+		}
+	}
+
+	matches := make([]DuplicateMatch, 0, len(matched))
+	for collectionId, count := range matched {
+		matches = append(matches, DuplicateMatch{
+			MatchedCollectionID: collectionId,
+			ComparedTokens:      compared,
+			MatchedTokens:       count,
+			Score:               float64(count) / float64(compared),
+			Similarity:          similarities[collectionId] / float64(count),
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].MatchedCollectionID < matches[j].MatchedCollectionID
+	})
+
+	return matches
+}
+
+func isDuplicateMatch(match DuplicateMatch) bool {
+	return match.ComparedTokens >= DuplicateFlagMinTokens && match.Score >= DuplicateFlagScore
+}
+
+// DetectDuplicateCollections compares the hashed tokens of every unverified collection with the verified ones
+// and queues the collections that largely copy one for moderation. Known flags get their scores refreshed.
+func DetectDuplicateCollections() error {
+	verifiedIds, err := storage.GetVerifiedCollectionIds()
+	if err != nil {
+		return err
+	}
+
+	verifiedHashes, err := storage.GetTokenImageHashesByCollectionIds(verifiedIds)
+	if err != nil {
+		return err
+	}
+	if len(verifiedHashes) == 0 {
+		return nil
+	}
+	refs := newReferenceHashes(verifiedHashes)
+
+	candidateIds, err := storage.GetHashedUnverifiedCollectionIds()
+	if err != nil {
+		return err
+	}
+
+	for _, collectionId := range candidateIds {
+		hashes, err := storage.GetTokenImageHashesByCollectionIds([]uint64{collectionId})
+		if err != nil {
+			zlog.Error("could not get collection image hashes", zap.Uint64("collection", collectionId), zap.Error(err))
+			continue
+		}
+
+		for _, match := range refs.match(hashes) {
+			if !isDuplicateMatch(match) {
+				continue
+			}
+
+			err = storage.UpsertCollectionDuplicateFlag(&entities.CollectionDuplicateFlag{
+				CollectionID:        collectionId,
+				MatchedCollectionID: match.MatchedCollectionID,
+				ComparedTokens:      match.ComparedTokens,
+				MatchedTokens:       match.MatchedTokens,
+				Score:               match.Score,
+				Similarity:          match.Similarity,
+				Status:              entities.DuplicateFlagOpen,
+			})
+			if err != nil {
+				zlog.Error("could not flag duplicate collection", zap.Uint64("collection", collectionId), zap.Error(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetDuplicateFlags lists the moderation queue of duplicate collections, highest score first.
+func GetDuplicateFlags(status entities.DuplicateFlagStatus, offset int, limit int) (*DuplicateFlags, error) {
+	if status != "" && !isDuplicateFlagStatus(status) {
+		return nil, ErrUnknownDuplicateFlagStatus
+	}
+	if limit > MaxDuplicateFlagsLimit {
+		limit = MaxDuplicateFlagsLimit
+	}
+
+	total, err := storage.CountCollectionDuplicateFlags(status)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := storage.GetCollectionDuplicateFlags(status, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DuplicateFlags{Total: total, Flags: flags}, nil
+}
+
+// ReviewDuplicateFlag records the decision of a moderator. It returns the flag as it was before.
+func ReviewDuplicateFlag(flagId uint64, reviewer string, request *ReviewDuplicateFlagRequest, now int64) (*entities.CollectionDuplicateFlag, error) {
+	if !isDuplicateFlagStatus(request.Status) {
+		return nil, ErrUnknownDuplicateFlagStatus
+	}
+
+	flag, err := storage.GetCollectionDuplicateFlagById(flagId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = storage.SetCollectionDuplicateFlagReview(flagId, request.Status, reviewer, request.Note, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
+
+// GetCollectionDuplicateWarning is the badge of a collection flagged as a copy, nil when there is none.
+// Verified collections and dismissed flags get no badge.
+func GetCollectionDuplicateWarning(collection *entities.Collection) (*dtos.CollectionDuplicateWarning, error) {
+	if collection.IsVerified {
+		return nil, nil
+	}
+
+	flag, err := storage.GetCollectionDuplicateWarningFlag(collection.ID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.CollectionDuplicateWarning{
+		Status:                   string(flag.Status),
+		MatchedCollectionTokenId: flag.MatchedCollection.CollectionTokenID,
+		MatchedCollectionName:    flag.MatchedCollection.Name,
+		Score:                    flag.Score,
+		Similarity:               flag.Similarity,
+	}, nil
+}
+
+func isDuplicateFlagStatus(status entities.DuplicateFlagStatus) bool {
+	switch status {
+	case entities.DuplicateFlagOpen, entities.DuplicateFlagConfirmed, entities.DuplicateFlagDismissed:
+		return true
+	default:
+		return false
+	}
+}
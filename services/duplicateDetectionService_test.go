@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func imageHashes(collectionId uint64, pHashes ...uint64) []entities.TokenImageHash {
+	hashes := make([]entities.TokenImageHash, 0, len(pHashes))
+	for i, pHash := range pHashes {
+		hashes = append(hashes, entities.TokenImageHash{
+			TokenID:      collectionId*1000 + uint64(i),
+			CollectionID: collectionId,
+			PHash:        int64(pHash),
+			DHash:        int64(^pHash),
+		})
+	}
+
+	return hashes
+}
+
+func Test_ReferenceHashesMatchScoresPerVerifiedCollection(t *testing.T) {
+	t.Parallel()
+
+	original := imageHashes(1, 0x0F0F0F0F0F0F0F0F, 0x00FF00FF00FF00FF, 0x3333333333333333, 0x5555555555555555)
+	other := imageHashes(2, 0xFFFFFFFF00000000)
+	refs := newReferenceHashes(append(original, other...))
+
+	// three re-encoded copies of the original, one of them twice, and two unrelated images
+	copymint := imageHashes(3, 0x0F0F0F0F0F0F0F0E, 0x00FF00FF00FF00F7, 0x3333333333333331, 0x3333333333333333, 0x123456789ABCDEF0, 0x0000FFFF0000FFFF)
+	copymint[5].Failed = true
+
+	matches := refs.match(copymint)
+	require.Len(t, matches, 1)
+	require.Equal(t, uint64(1), matches[0].MatchedCollectionID)
+	require.Equal(t, uint64(5), matches[0].ComparedTokens)
+	require.Equal(t, uint64(4), matches[0].MatchedTokens)
+	require.InDelta(t, 0.8, matches[0].Score, 0.0001)
+	require.True(t, matches[0].Similarity > 0.95 && matches[0].Similarity <= 1)
+	require.True(t, isDuplicateMatch(matches[0]))
+}
+
+func Test_ReferenceHashesMatchNeedsBothHashes(t *testing.T) {
+	t.Parallel()
+
+	refs := newReferenceHashes(imageHashes(1, 0x0F0F0F0F0F0F0F0F))
+
+	// same pHash, but the gradients differ
+	candidate := imageHashes(2, 0x0F0F0F0F0F0F0F0F)
+	candidate[0].DHash = int64(0x0F0F0F0F0F0F0F0F)
+
+	require.Empty(t, refs.match(candidate))
+}
+
+func Test_ReferenceHashesMatchIgnoresOwnCollection(t *testing.T) {
+	t.Parallel()
+
+	hashes := imageHashes(1, 0x0F0F0F0F0F0F0F0F, 0x00FF00FF00FF00FF)
+	refs := newReferenceHashes(hashes)
+
+	require.Empty(t, refs.match(hashes))
+}
+
+func Test_IsDuplicateMatch(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, isDuplicateMatch(DuplicateMatch{ComparedTokens: DuplicateFlagMinTokens - 1, MatchedTokens: DuplicateFlagMinTokens - 1, Score: 1}))
+	require.False(t, isDuplicateMatch(DuplicateMatch{ComparedTokens: 10, MatchedTokens: 4, Score: 0.4}))
+	require.True(t, isDuplicateMatch(DuplicateMatch{ComparedTokens: 10, MatchedTokens: 5, Score: 0.5}))
+}
+
+func Test_GetCollectionDuplicateWarningVerifiedHasNone(t *testing.T) {
+	t.Parallel()
+
+	warning, err := GetCollectionDuplicateWarning(&entities.Collection{ID: 1, IsVerified: true})
+	require.Nil(t, err)
+	require.Nil(t, warning)
+}
+
+func Test_ReviewDuplicateFlagUnknownStatus(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReviewDuplicateFlag(1, "erd_moderator", &ReviewDuplicateFlagRequest{Status: "deleted"}, 0)
+	require.Equal(t, ErrUnknownDuplicateFlagStatus, err)
+
+	_, err = GetDuplicateFlags("deleted", 0, 10)
+	require.Equal(t, ErrUnknownDuplicateFlagStatus, err)
+}
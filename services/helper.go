@@ -11,6 +11,7 @@ import (
 	"math/rand"
 	"net/http"
 	urlp "net/url"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -209,11 +210,11 @@ func GetResponse(url string) ([]byte, error) {
 	return body, nil
 }
 
-func GetTransactionBC(hash string, api string) (entities.TransactionBC, error) {
+func GetTransactionBC(api string, hash string) (entities.TransactionBC, error) {
 
 	reqUrl := fmt.Sprintf("%s/transactions/%s",
-		hash,
-		api)
+		api,
+		hash)
 	body, err := GetResponse(reqUrl)
 	if err != nil {
 		zlog.Error(err.Error())
@@ -306,6 +307,32 @@ func TurnIntoBigFloat18Dec(num float64) *big.Float {
 	return bigNum
 }
 
+// convertNominalPrice turns a nominal price string into blockchain units (18 decimals).
+// The decimal string is parsed exactly, so 1.1 is 1100000000000000000 and not the nearest float.
+func convertNominalPrice(priceString string) (*big.Int, float64, error) {
+	errConvert := errors.New("couldn't convert price string to blockchain unit")
+	if strings.Contains(priceString, "/") {
+		return nil, 0, errConvert
+	}
+
+	ratPrice, ok := new(big.Rat).SetString(strings.TrimSpace(priceString))
+	if !ok || ratPrice.Sign() < 0 {
+		return nil, 0, errConvert
+	}
+
+	ratPrice.Mul(ratPrice, new(big.Rat).SetInt(big.NewInt(0).Exp(big.NewInt(10), big.NewInt(18), nil)))
+	if !ratPrice.IsInt() {
+		return nil, 0, errors.New("price has more than 18 decimals")
+	}
+
+	nominal, err := strconv.ParseFloat(strings.TrimSpace(priceString), 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ratPrice.Num(), nominal, nil
+}
+
 func TurnIntoBigInt8Dec(num int64) *big.Int {
 	bigNum := big.NewInt(num)
 	bigNum = bigNum.Mul(big.NewInt(10).Exp(big.NewInt(10), big.NewInt(8), nil), bigNum)
@@ -569,7 +596,21 @@ tokenLoop:
 			return nil, err
 		}
 	} else {
+		// a new token was most likely minted before it was indexed, so its mint comes from the chain
+		if dbToken.ID == 0 {
+			requestNewTokenHistory(token.Collection, token.Nonce)
+		}
 		return nil, err
 	}
 	return &token, nil
 }
+
+func requestNewTokenHistory(tokenId string, nonce uint64) {
+	token, err := storage.GetTokenByTokenIdAndNonce(tokenId, nonce)
+	if err != nil {
+		zlog.Error("could not get indexed token", zap.String("token", tokenId), zap.Uint64("nonce", nonce), zap.Error(err))
+		return
+	}
+
+	RequestTokenHistory(token.ID)
+}
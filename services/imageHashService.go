@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/imagehash"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// TokenImageHashBatch is how many tokens are hashed per run of the gatherer.
+	TokenImageHashBatch = 50
+
+	maxTokenImageBytes = 20 * 1024 * 1024
+	tokenImageTimeout  = 20 * time.Second
+
+	// maxTokenImageAttempts bounds the fetches of media that keeps failing, waiting tokenImageRetryDelay
+	// after the first failure and twice as long after each next one, up to maxTokenImageRetryDelay.
+	maxTokenImageAttempts   = 12
+	tokenImageRetryDelay    = 5 * time.Minute
+	maxTokenImageRetryDelay = 24 * time.Hour
+)
+
+var (
+	errTokenImageTooLarge    = fmt.Errorf("token image exceeds %d bytes", maxTokenImageBytes)
+	errTokenImageUnsupported = errors.New("token media is not a supported image")
+)
+
+var tokenImageClient = &http.Client{Timeout: tokenImageTimeout}
+
+// HashPendingTokenImages hashes the media of tokens indexed since the last run, or whose media changed.
+// Media that cannot be hashed, like videos, is recorded as failed so it is not fetched again.
+// Media that could not be fetched, on a timeout or a gateway error, is retried later with a backoff.
+func HashPendingTokenImages(limit int) (int, error) {
+	now := time.Now()
+	tokens, err := storage.GetTokensWithoutImageHash(now.Unix(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, token := range tokens {
+		hash := &entities.TokenImageHash{
+			TokenID:      token.ID,
+			CollectionID: token.CollectionID,
+			ImageLink:    token.ImageLink,
+		}
+
+		hashes, err := hashTokenImage(token.ImageLink)
+		if err != nil {
+			hash.Failed = true
+			hash.Error = err.Error()
+			if !isPermanentTokenImageErr(err) {
+				hash.Attempts = previousTokenImageAttempts(token) + 1
+				hash.RetryAt = tokenImageRetryAt(hash.Attempts, now)
+			}
+		} else {
+			hash.PHash = int64(hashes.PHash)
+			hash.DHash = int64(hashes.DHash)
+		}
+
+		err = storage.UpsertTokenImageHash(hash)
+		if err != nil {
+			zlog.Error("could not store token image hash", zap.Uint64("token", token.ID), zap.Error(err))
+		}
+	}
+
+	return len(tokens), nil
+}
+
+// previousTokenImageAttempts counts the failed fetches of the current media of the token.
+func previousTokenImageAttempts(token entities.Token) uint64 {
+	previous, err := storage.GetTokenImageHashByTokenId(token.ID)
+	if err != nil || previous.ImageLink != token.ImageLink {
+		return 0
+	}
+
+	return previous.Attempts
+}
+
+// tokenImageRetryAt is when to fetch the media again after its failed attempts, zero once they are used up.
+func tokenImageRetryAt(attempts uint64, now time.Time) int64 {
+	if attempts >= maxTokenImageAttempts {
+		return 0
+	}
+
+	delay := tokenImageRetryDelay
+	for index := uint64(1); index < attempts && delay < maxTokenImageRetryDelay; index++ {
+		delay *= 2
+	}
+	if delay > maxTokenImageRetryDelay {
+		delay = maxTokenImageRetryDelay
+	}
+
+	return now.Add(delay).Unix()
+}
+
+// isPermanentTokenImageErr tells whether the media itself cannot be hashed, fetching it again would fail the same way.
+func isPermanentTokenImageErr(err error) bool {
+	return errors.Is(err, errTokenImageUnsupported) ||
+		errors.Is(err, errTokenImageTooLarge)
+}
+
+func hashTokenImage(link string) (*imagehash.Hashes, error) {
+	resp, err := tokenImageClient.Get(ParseMetadataUrl(link))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnsupportedMediaType {
+		return nil, fmt.Errorf("%w: %s", errTokenImageUnsupported, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	if resp.ContentLength > maxTokenImageBytes {
+		return nil, errTokenImageTooLarge
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTokenImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTokenImageBytes {
+		return nil, errTokenImageTooLarge
+	}
+
+	hashes, err := imagehash.HashImageBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errTokenImageUnsupported, err)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HashTokenImageErrors(t *testing.T) {
+	status := http.StatusOK
+	body := []byte("not an image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	_, err := hashTokenImage(server.URL)
+	require.NotNil(t, err)
+	require.True(t, isPermanentTokenImageErr(err))
+
+	status = http.StatusBadGateway
+	_, err = hashTokenImage(server.URL)
+	require.NotNil(t, err)
+	require.False(t, isPermanentTokenImageErr(err))
+
+	status = http.StatusOK
+	buf := new(bytes.Buffer)
+	require.Nil(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 32, 32))))
+	body = buf.Bytes()
+	_, err = hashTokenImage(server.URL)
+	require.Nil(t, err)
+}
+
+func Test_TokenImageRetryAt(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	require.Equal(t, now.Add(tokenImageRetryDelay).Unix(), tokenImageRetryAt(1, now))
+	require.Equal(t, now.Add(4*tokenImageRetryDelay).Unix(), tokenImageRetryAt(3, now))
+	require.Equal(t, now.Add(maxTokenImageRetryDelay).Unix(), tokenImageRetryAt(maxTokenImageAttempts-1, now))
+	require.Equal(t, int64(0), tokenImageRetryAt(maxTokenImageAttempts, now))
+}
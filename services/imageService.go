@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -22,128 +23,148 @@ var (
 	ctx                 = context.Background()
 )
 
-func SetAccountProfileImage(accountAddress string, accountId uint64, image *string) (string, error) {
+func SetAccountProfileImage(accountAddress string, accountId uint64, image *string) (*cdn.ImageVariants, error) {
 	imageSize := getByteArrayLenOfBase64EncodedImage(image)
 	if imageSize == 0 {
-		return "", errorImageZeroLen
+		return nil, errorImageZeroLen
 	}
 	if imageSize > maxProfileImageSize {
-		return "", errorProfileTooBig
+		return nil, errorProfileTooBig
 	}
 
 	uploader, err := cdn.GetImageUploaderOrErr()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	base64ImageSize := getRawBase64ImageLength(image)
 	*image = (*image)[:len(*image)-(base64ImageSize%4)]
 
 	imgId := accountAddress + ProfileSuffix
-	imgUrl, err := uploader.UploadBase64(ctx, *image, imgId)
+	variants, err := uploader.UploadVariants(ctx, *image, imgId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = storage.UpdateAccountProfileWhereId(accountId, imgUrl)
+	variantsJson, err := json.Marshal(variants)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return imgUrl, nil
+	err = storage.UpdateAccountProfileWhereId(accountId, variants.Url, variantsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
 }
 
-func SetAccountCoverImage(accountAddress string, accountId uint64, image *string) (string, error) {
+func SetAccountCoverImage(accountAddress string, accountId uint64, image *string) (*cdn.ImageVariants, error) {
 	imageSize := getByteArrayLenOfBase64EncodedImage(image)
 	if imageSize == 0 {
-		return "", errorImageZeroLen
+		return nil, errorImageZeroLen
 	}
 	if imageSize > maxCoverImageSize {
-		return "", errorCoverTooBig
+		return nil, errorCoverTooBig
 	}
 
 	uploader, err := cdn.GetImageUploaderOrErr()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	base64ImageSize := getRawBase64ImageLength(image)
 	*image = (*image)[:len(*image)-(base64ImageSize%4)]
 
 	imgId := accountAddress + CoverSuffix
-	imgUrl, err := uploader.UploadBase64(ctx, *image, imgId)
+	variants, err := uploader.UploadVariants(ctx, *image, imgId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = storage.UpdateAccountCoverWhereId(accountId, imgUrl)
+	variantsJson, err := json.Marshal(variants)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return imgUrl, nil
+	err = storage.UpdateAccountCoverWhereId(accountId, variants.Url, variantsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
 }
 
-func SetCollectionCoverImage(tokenId string, collectionId uint64, image *string) (string, error) {
+func SetCollectionCoverImage(tokenId string, collectionId uint64, image *string) (*cdn.ImageVariants, error) {
 	imageSize := getByteArrayLenOfBase64EncodedImage(image)
 	if imageSize == 0 {
-		return "", errorImageZeroLen
+		return nil, errorImageZeroLen
 	}
 	if imageSize > maxCoverImageSize {
-		return "", errorCoverTooBig
+		return nil, errorCoverTooBig
 	}
 
 	uploader, err := cdn.GetImageUploaderOrErr()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	base64ImageSize := getRawBase64ImageLength(image)
 	*image = (*image)[:len(*image)-(base64ImageSize%4)]
 
 	imgId := tokenId + CoverSuffix
-	imgUrl, err := uploader.UploadBase64(ctx, *image, imgId)
+	variants, err := uploader.UploadVariants(ctx, *image, imgId)
+	if err != nil {
+		return nil, err
+	}
+
+	variantsJson, err := json.Marshal(variants)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = storage.UpdateCollectionCoverWhereId(collectionId, imgUrl)
+	err = storage.UpdateCollectionCoverWhereId(collectionId, variants.Url, variantsJson)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return imgUrl, nil
+	return variants, nil
 }
 
-func SetCollectionProfileImage(tokenId string, collectionId uint64, image *string) (string, error) {
+func SetCollectionProfileImage(tokenId string, collectionId uint64, image *string) (*cdn.ImageVariants, error) {
 	imageSize := getByteArrayLenOfBase64EncodedImage(image)
 	if imageSize == 0 {
-		return "", errorImageZeroLen
+		return nil, errorImageZeroLen
 	}
 	if imageSize > maxProfileImageSize {
-		return "", errorCoverTooBig
+		return nil, errorCoverTooBig
 	}
 
 	uploader, err := cdn.GetImageUploaderOrErr()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	base64ImageSize := getRawBase64ImageLength(image)
 	*image = (*image)[:len(*image)-(base64ImageSize%4)]
 
 	imgId := tokenId + ProfileSuffix
-	imgUrl, err := uploader.UploadBase64(ctx, *image, imgId)
+	variants, err := uploader.UploadVariants(ctx, *image, imgId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = storage.UpdateCollectionProfileWhereId(collectionId, imgUrl)
+	variantsJson, err := json.Marshal(variants)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return imgUrl, nil
+	err = storage.UpdateCollectionProfileWhereId(collectionId, variants.Url, variantsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
 }
 
 func getByteArrayLenOfBase64EncodedImage(image *string) int {
@@ -153,3 +174,129 @@ func getByteArrayLenOfBase64EncodedImage(image *string) int {
 func getRawBase64ImageLength(image *string) int {
 	return len(*image) - (strings.Index(*image, cdn.Base64Separator) + 1)
 }
+
+const (
+	ImageKindProfile = "profile"
+	ImageKindCover   = "cover"
+)
+
+var errorUnknownImageKind = errors.New("unknown image kind")
+
+type ImageUploadRequest struct {
+	Kind        string `json:"kind"`
+	ContentType string `json:"contentType"`
+}
+
+type ImageUploadCompleteRequest struct {
+	Kind string `json:"kind"`
+	Key  string `json:"key"`
+}
+
+// PresignAccountImageUpload hands out a url to upload an account image straight to the store.
+func PresignAccountImageUpload(accountAddress string, request ImageUploadRequest) (*cdn.PresignedUpload, error) {
+	return presignImageUpload(accountAddress, request)
+}
+
+// CompleteAccountImageUpload processes a direct upload and sets it as the account image.
+func CompleteAccountImageUpload(accountAddress string, accountId uint64, request ImageUploadCompleteRequest) (*cdn.ImageVariants, error) {
+	variants, variantsJson, err := completeImageUpload(accountAddress, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Kind == ImageKindProfile {
+		err = storage.UpdateAccountProfileWhereId(accountId, variants.Url, variantsJson)
+	} else {
+		err = storage.UpdateAccountCoverWhereId(accountId, variants.Url, variantsJson)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+// PresignCollectionImageUpload hands out a url to upload a collection image straight to the store.
+func PresignCollectionImageUpload(tokenId string, request ImageUploadRequest) (*cdn.PresignedUpload, error) {
+	return presignImageUpload(tokenId, request)
+}
+
+// CompleteCollectionImageUpload processes a direct upload and sets it as the collection image.
+func CompleteCollectionImageUpload(tokenId string, collectionId uint64, request ImageUploadCompleteRequest) (*cdn.ImageVariants, error) {
+	variants, variantsJson, err := completeImageUpload(tokenId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Kind == ImageKindProfile {
+		err = storage.UpdateCollectionProfileWhereId(collectionId, variants.Url, variantsJson)
+	} else {
+		err = storage.UpdateCollectionCoverWhereId(collectionId, variants.Url, variantsJson)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func presignImageUpload(owner string, request ImageUploadRequest) (*cdn.PresignedUpload, error) {
+	suffix, maxSize, err := imageKindTarget(request.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	uploader, err := cdn.GetDirectUploaderOrErr()
+	if err != nil {
+		return nil, err
+	}
+
+	return uploader.PresignUpload(ctx, owner+suffix, request.ContentType, int64(maxSize))
+}
+
+// completeImageUpload returns the variants of a direct upload along with their json, which is stored next to the image link.
+func completeImageUpload(owner string, request ImageUploadCompleteRequest) (*cdn.ImageVariants, []byte, error) {
+	suffix, maxSize, err := imageKindTarget(request.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uploader, err := cdn.GetDirectUploaderOrErr()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variants, err := uploader.CompleteUpload(ctx, request.Key, owner+suffix, int64(maxSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variantsJson, err := json.Marshal(variants)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return variants, variantsJson, nil
+}
+
+func imageKindTarget(kind string) (string, int, error) {
+	switch kind {
+	case ImageKindProfile:
+		return ProfileSuffix, maxProfileImageSize, nil
+	case ImageKindCover:
+		return CoverSuffix, maxCoverImageSize, nil
+	default:
+		return "", 0, errorUnknownImageKind
+	}
+}
+
+// IsImageUploadRequestErr tells whether a direct upload failed on what the client sent.
+func IsImageUploadRequestErr(err error) bool {
+	return errors.Is(err, errorUnknownImageKind) ||
+		errors.Is(err, cdn.ErrUnsupportedContentType) ||
+		errors.Is(err, cdn.ErrInvalidUploadKey) ||
+		errors.Is(err, cdn.ErrUploadTooLarge) ||
+		errors.Is(err, cdn.UnknownImgTypeErr) ||
+		errors.Is(err, cdn.ErrImageTooLarge) ||
+		errors.Is(err, cdn.ErrImageTooSmall)
+}
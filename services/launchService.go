@@ -0,0 +1,524 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
+)
+
+const (
+	LaunchStepIssue            = "issue"
+	LaunchStepDeploy           = "deploy"
+	LaunchStepSetRoles         = "setRoles"
+	LaunchStepChangeOwner      = "changeOwner"
+	LaunchStepSaleStart        = "saleStart"
+	LaunchStepCreateCollection = "createCollection"
+
+	MaxLaunchRoyalties = 100
+
+	launchIssueFunction       = "issueNonFungible"
+	launchDeployFunction      = "deployNFTTemplateContract"
+	launchSetRolesFunction    = "setSpecialRole"
+	launchChangeOwnerFunction = "changeOwner"
+	launchSaleStartFunction   = "updateSaleStart"
+)
+
+var (
+	ErrUnknownLaunchStep    = errors.New("unknown launch step")
+	ErrLaunchNotInProgress  = errors.New("launch is not in progress")
+	ErrLaunchStepNotReady   = errors.New("launch step dependencies are not completed")
+	ErrLaunchStepDone       = errors.New("launch step already completed")
+	ErrLaunchStepServerSide = errors.New("launch step is run by the server")
+
+	tokenTickerRegex = regexp.MustCompile("^[A-Z0-9]{3,10}$")
+)
+
+type launchStepDefinition struct {
+	Name      string
+	DependsOn []string
+	// OnChain steps are sent by the creator and detected from their transaction, the others are run by the server.
+	OnChain bool
+}
+
+// launchSteps lists the launch steps in the order they are shown to the creator.
+var launchSteps = []launchStepDefinition{
+	{Name: LaunchStepIssue, OnChain: true},
+	{Name: LaunchStepDeploy, DependsOn: []string{LaunchStepIssue}, OnChain: true},
+	{Name: LaunchStepSetRoles, DependsOn: []string{LaunchStepIssue, LaunchStepDeploy}, OnChain: true},
+	{Name: LaunchStepChangeOwner, DependsOn: []string{LaunchStepDeploy}, OnChain: true},
+	{Name: LaunchStepSaleStart, DependsOn: []string{LaunchStepDeploy}, OnChain: true},
+	{Name: LaunchStepCreateCollection, DependsOn: []string{LaunchStepSetRoles, LaunchStepChangeOwner, LaunchStepSaleStart}},
+}
+
+type CreateLaunchRequest struct {
+	CollectionName   string  `json:"collectionName"`
+	Description      string  `json:"description"`
+	TokenName        string  `json:"tokenName"`
+	TokenTicker      string  `json:"tokenTicker"`
+	Royalties        float64 `json:"royalties"`
+	TokenNameBase    string  `json:"tokenNameBase"`
+	ImageBaseLink    string  `json:"imageBaseLink"`
+	ImageExt         string  `json:"imageExt"`
+	MetadataBaseLink string  `json:"metadataBaseLink"`
+	Price            string  `json:"price"`
+	MaxSupply        uint64  `json:"maxSupply"`
+	SaleStart        uint64  `json:"saleStart"`
+}
+
+type SubmitLaunchStepRequest struct {
+	TxHash string `json:"txHash"`
+}
+
+type StuckLaunches struct {
+	Counts   []entities.LaunchStepCount  `json:"counts"`
+	Launches []entities.CollectionLaunch `json:"launches"`
+}
+
+// StartCollectionLaunch creates the launch of an address. A launch left in progress by the same address is abandoned.
+func StartCollectionLaunch(address string, request *CreateLaunchRequest) (*entities.CollectionLaunch, error) {
+	err := checkValidLaunchRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]entities.CollectionLaunchStep, len(launchSteps))
+	for index, definition := range launchSteps {
+		steps[index] = entities.CollectionLaunchStep{
+			Name:     definition.Name,
+			Position: uint64(index),
+			Status:   entities.LaunchStepPending,
+		}
+	}
+
+	launch := &entities.CollectionLaunch{
+		Address:          address,
+		Status:           entities.LaunchInProgress,
+		CurrentStep:      launchSteps[0].Name,
+		CollectionName:   request.CollectionName,
+		Description:      request.Description,
+		TokenName:        request.TokenName,
+		TokenTicker:      request.TokenTicker,
+		Royalties:        request.Royalties,
+		TokenNameBase:    request.TokenNameBase,
+		ImageBaseLink:    request.ImageBaseLink,
+		ImageExt:         request.ImageExt,
+		MetadataBaseLink: request.MetadataBaseLink,
+		Price:            request.Price,
+		MaxSupply:        request.MaxSupply,
+		SaleStart:        request.SaleStart,
+		Steps:            steps,
+	}
+
+	err = storage.AddCollectionLaunch(launch)
+	if err != nil {
+		return nil, err
+	}
+
+	return launch, nil
+}
+
+// GetLaunchStepReady returns the step if it can be sent now: the launch is in progress,
+// the step is not done yet and every step it depends on is completed.
+func GetLaunchStepReady(launch *entities.CollectionLaunch, stepName string) (*entities.CollectionLaunchStep, error) {
+	if launch.Status != entities.LaunchInProgress {
+		return nil, ErrLaunchNotInProgress
+	}
+
+	definition, ok := getLaunchStepDefinition(stepName)
+	if !ok {
+		return nil, ErrUnknownLaunchStep
+	}
+
+	step := findLaunchStep(launch, stepName)
+	if step == nil {
+		return nil, ErrUnknownLaunchStep
+	}
+	if step.Status == entities.LaunchStepCompleted {
+		return nil, ErrLaunchStepDone
+	}
+
+	for _, dependency := range definition.DependsOn {
+		dependencyStep := findLaunchStep(launch, dependency)
+		if dependencyStep == nil || dependencyStep.Status != entities.LaunchStepCompleted {
+			return nil, ErrLaunchStepNotReady
+		}
+	}
+
+	return step, nil
+}
+
+// SubmitLaunchStep records the transaction the creator sent for an on-chain step, then checks the chain right away.
+func SubmitLaunchStep(launch *entities.CollectionLaunch, stepName string, txHash string, api string, blockchainCfg config.BlockchainConfig) (*entities.CollectionLaunch, error) {
+	step, err := GetLaunchStepReady(launch, stepName)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, _ := getLaunchStepDefinition(stepName)
+	if !definition.OnChain {
+		return nil, ErrLaunchStepServerSide
+	}
+	if len(txHash) == 0 {
+		return nil, errors.New("empty tx hash")
+	}
+
+	step.TxHash = txHash
+	step.Status = entities.LaunchStepSubmitted
+	step.Error = ""
+	step.SubmittedAt = time.Now().UnixMilli()
+	err = storage.UpdateCollectionLaunchStep(step)
+	if err != nil {
+		return nil, err
+	}
+
+	return RefreshCollectionLaunch(launch, api, blockchainCfg)
+}
+
+// RefreshCollectionLaunch looks up the submitted transactions, keeps what they returned (token id, contract address)
+// and creates the collection once every on-chain step is done. It is safe to call on every read, so a launch can be resumed anytime.
+func RefreshCollectionLaunch(launch *entities.CollectionLaunch, api string, blockchainCfg config.BlockchainConfig) (*entities.CollectionLaunch, error) {
+	if launch.Status != entities.LaunchInProgress {
+		return launch, nil
+	}
+
+	for index := range launch.Steps {
+		step := &launch.Steps[index]
+		if step.Status != entities.LaunchStepSubmitted {
+			continue
+		}
+
+		tx, err := GetTransactionBC(api, step.TxHash)
+		if err != nil {
+			log.Debug("could not get launch transaction", "hash", step.TxHash, "err", err)
+			continue
+		}
+
+		changed, err := applyLaunchTransaction(launch, step, tx, blockchainCfg)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			continue
+		}
+
+		err = storage.UpdateCollectionLaunchStep(step)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := GetLaunchStepReady(launch, LaunchStepCreateCollection)
+	if err == nil {
+		createLaunchCollection(launch, blockchainCfg.ProxyUrl)
+	}
+
+	launch.CurrentStep = ""
+	for _, step := range launch.Steps {
+		if step.Status != entities.LaunchStepCompleted {
+			launch.CurrentStep = step.Name
+			break
+		}
+	}
+	if launch.CurrentStep == "" {
+		launch.Status = entities.LaunchCompleted
+	}
+
+	err = storage.UpdateCollectionLaunch(launch)
+	if err != nil {
+		return nil, err
+	}
+
+	return launch, nil
+}
+
+func AbandonCollectionLaunch(launch *entities.CollectionLaunch) error {
+	if launch.Status != entities.LaunchInProgress {
+		return ErrLaunchNotInProgress
+	}
+
+	launch.Status = entities.LaunchAbandoned
+	return storage.UpdateCollectionLaunch(launch)
+}
+
+// GetStuckLaunches shows on which step the launches idle for longer than idleFor are waiting.
+func GetStuckLaunches(idleFor time.Duration, offset int, limit int) (*StuckLaunches, error) {
+	before := time.Now().Add(-idleFor).UnixMilli()
+
+	counts, err := storage.GetStuckLaunchCounts(before)
+	if err != nil {
+		return nil, err
+	}
+
+	launches, err := storage.GetStuckCollectionLaunches(before, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StuckLaunches{Counts: counts, Launches: launches}, nil
+}
+
+func createLaunchCollection(launch *entities.CollectionLaunch, blockchainProxy string) {
+	step := findLaunchStep(launch, LaunchStepCreateCollection)
+
+	collection, err := storage.GetCollectionByTokenId(launch.TokenId)
+	if err != nil {
+		collection, err = CreateCollection(&CreateCollectionRequest{
+			UserAddress:             launch.Address,
+			Name:                    launch.CollectionName,
+			TokenId:                 launch.TokenId,
+			Description:             launch.Description,
+			ContractAddress:         launch.ContractAddress,
+			MintPricePerTokenString: launch.Price,
+			TokenBaseURI:            launch.ImageBaseLink,
+			MaxSupply:               launch.MaxSupply,
+			MetaDataBaseURI:         launch.MetadataBaseLink,
+			MintStartDate:           launch.SaleStart,
+		}, blockchainProxy)
+	}
+
+	if err != nil {
+		step.Status = entities.LaunchStepFailed
+		step.Error = err.Error()
+	} else {
+		launch.CollectionID = collection.ID
+		step.Status = entities.LaunchStepCompleted
+		step.Error = ""
+		step.CompletedAt = time.Now().UnixMilli()
+	}
+
+	err = storage.UpdateCollectionLaunchStep(step)
+	if err != nil {
+		log.Debug("could not update launch step", "err", err)
+	}
+}
+
+// applyLaunchTransaction moves a submitted step once its transaction reached a final state.
+// A transaction that is not the call the step asks for fails the step, so the right one can be submitted.
+func applyLaunchTransaction(
+	launch *entities.CollectionLaunch,
+	step *entities.CollectionLaunchStep,
+	tx entities.TransactionBC,
+	blockchainCfg config.BlockchainConfig,
+) (bool, error) {
+	if tx.Status == string(transaction.TxStatusPending) || tx.PendingResults {
+		return false, nil
+	}
+
+	if tx.Status != string(transaction.TxStatusSuccess) {
+		step.Status = entities.LaunchStepFailed
+		step.Error = fmt.Sprintf("transaction %s", tx.Status)
+		return true, nil
+	}
+
+	err := checkLaunchTransaction(launch, step.Name, tx, blockchainCfg)
+	if err != nil {
+		step.Status = entities.LaunchStepFailed
+		step.Error = err.Error()
+		return true, nil
+	}
+
+	switch step.Name {
+	case LaunchStepIssue:
+		tokenId, ok := getIssuedTokenId(tx)
+		if !ok {
+			return false, nil
+		}
+		if !strings.HasPrefix(tokenId, launch.TokenTicker+"-") {
+			step.Status = entities.LaunchStepFailed
+			step.Error = "issued token does not have the launch ticker"
+			return true, nil
+		}
+		launch.TokenId = tokenId
+	case LaunchStepDeploy:
+		contractAddress, ok := getDeployedContractAddress(tx)
+		if !ok {
+			return false, nil
+		}
+		launch.ContractAddress = contractAddress
+	}
+
+	step.Status = entities.LaunchStepCompleted
+	step.Error = ""
+	step.CompletedAt = time.Now().UnixMilli()
+	return true, nil
+}
+
+// checkLaunchTransaction makes sure the transaction is the call of the step: sent by the creator of the launch,
+// to the contract the step template targets, with the endpoint and the token and minter arguments of this launch.
+func checkLaunchTransaction(launch *entities.CollectionLaunch, stepName string, tx entities.TransactionBC, blockchainCfg config.BlockchainConfig) error {
+	if tx.Sender != launch.Address {
+		return errors.New("transaction was not sent by the launch address")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(tx.Data)
+	if err != nil {
+		return errors.New("could not decode transaction data")
+	}
+	args := strings.Split(string(decoded), "@")
+
+	var receiver, function string
+	var expectedArgs []string
+	switch stepName {
+	case LaunchStepIssue:
+		receiver, function = blockchainCfg.SystemSCAddress, launchIssueFunction
+		expectedArgs = []string{hex.EncodeToString([]byte(launch.TokenName)), hex.EncodeToString([]byte(launch.TokenTicker))}
+	case LaunchStepDeploy:
+		receiver, function = blockchainCfg.DeployerAddress, launchDeployFunction
+		expectedArgs = []string{hex.EncodeToString([]byte(launch.TokenId))}
+	case LaunchStepSetRoles:
+		contractAddressHex, err := getAddressHex(launch.ContractAddress)
+		if err != nil {
+			return err
+		}
+		receiver, function = blockchainCfg.SystemSCAddress, launchSetRolesFunction
+		expectedArgs = []string{hex.EncodeToString([]byte(launch.TokenId)), contractAddressHex}
+	case LaunchStepChangeOwner:
+		contractAddressHex, err := getAddressHex(launch.ContractAddress)
+		if err != nil {
+			return err
+		}
+		receiver, function = blockchainCfg.DeployerAddress, launchChangeOwnerFunction
+		expectedArgs = []string{contractAddressHex}
+	case LaunchStepSaleStart:
+		receiver, function = launch.ContractAddress, launchSaleStartFunction
+	default:
+		return ErrLaunchStepServerSide
+	}
+
+	if tx.Receiver != receiver {
+		return fmt.Errorf("transaction was not sent to %s", receiver)
+	}
+	if args[0] != function {
+		return fmt.Errorf("transaction does not call %s", function)
+	}
+	if len(args) < len(expectedArgs)+1 {
+		return fmt.Errorf("missing %s arguments", function)
+	}
+	for index, expected := range expectedArgs {
+		if !strings.EqualFold(args[index+1], expected) {
+			return fmt.Errorf("%s arguments do not match the launch", function)
+		}
+	}
+
+	return nil
+}
+
+func getAddressHex(bech32Address string) (string, error) {
+	address, err := data.NewAddressFromBech32String(bech32Address)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(address.AddressBytes()), nil
+}
+
+// getIssuedTokenId reads the token identifier from the issue callback, data looks like @ok@<hex token id>.
+func getIssuedTokenId(tx entities.TransactionBC) (string, bool) {
+	for _, args := range decodeResultsArgs(tx) {
+		if len(args) < 3 || (args[1] != hex.EncodeToString([]byte("ok")) && args[1] != "00") {
+			continue
+		}
+
+		tokenId, err := hex.DecodeString(args[2])
+		if err != nil || !strings.Contains(string(tokenId), "-") {
+			continue
+		}
+
+		return string(tokenId), true
+	}
+
+	return "", false
+}
+
+// getDeployedContractAddress reads the new minter address from the deployer result, data looks like @ok@<hex address>.
+func getDeployedContractAddress(tx entities.TransactionBC) (string, bool) {
+	for _, args := range decodeResultsArgs(tx) {
+		if len(args) < 3 {
+			continue
+		}
+
+		addressBytes, err := hex.DecodeString(args[2])
+		if err != nil || len(addressBytes) != 32 {
+			continue
+		}
+
+		return data.NewAddressFromBytes(addressBytes).AddressAsBech32String(), true
+	}
+
+	return "", false
+}
+
+func decodeResultsArgs(tx entities.TransactionBC) [][]string {
+	results := make([][]string, 0, len(tx.Results))
+	for _, result := range tx.Results {
+		decoded, err := base64.StdEncoding.DecodeString(result.Data)
+		if err != nil {
+			continue
+		}
+		results = append(results, strings.Split(string(decoded), "@"))
+	}
+
+	return results
+}
+
+func getLaunchStepDefinition(stepName string) (launchStepDefinition, bool) {
+	for _, definition := range launchSteps {
+		if definition.Name == stepName {
+			return definition, true
+		}
+	}
+
+	return launchStepDefinition{}, false
+}
+
+func findLaunchStep(launch *entities.CollectionLaunch, stepName string) *entities.CollectionLaunchStep {
+	for index := range launch.Steps {
+		if launch.Steps[index].Name == stepName {
+			return &launch.Steps[index]
+		}
+	}
+
+	return nil
+}
+
+func checkValidLaunchRequest(request *CreateLaunchRequest) error {
+	if len(request.CollectionName) == 0 || len(request.CollectionName) > MaxNameLen {
+		return fmt.Errorf("collection name must have between 1 and %d characters", MaxNameLen)
+	}
+	if len(request.Description) > MaxDescLen {
+		return errors.New("description too long")
+	}
+	if len(request.TokenName) == 0 || len(request.TokenName) > MaxNameLen {
+		return fmt.Errorf("token name must have between 1 and %d characters", MaxNameLen)
+	}
+	if !tokenTickerRegex.MatchString(request.TokenTicker) {
+		return errors.New("token ticker must have 3 to 10 upper case letters or digits")
+	}
+	if request.Royalties < 0 || request.Royalties > MaxLaunchRoyalties {
+		return errors.New("royalties must be between 0 and 100")
+	}
+	if len(request.ImageBaseLink) == 0 || len(request.MetadataBaseLink) == 0 {
+		return errors.New("image and metadata base links are required")
+	}
+	if request.MaxSupply == 0 {
+		return errors.New("max supply must be positive")
+	}
+
+	price, err := strconv.ParseFloat(request.Price, 64)
+	if err != nil || price < 0 {
+		return errors.New("invalid price")
+	}
+
+	return nil
+}
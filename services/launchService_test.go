@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetLaunchStepReady(t *testing.T) {
+	launch := &entities.CollectionLaunch{Status: entities.LaunchInProgress}
+	for index, definition := range launchSteps {
+		launch.Steps = append(launch.Steps, entities.CollectionLaunchStep{Name: definition.Name, Position: uint64(index), Status: entities.LaunchStepPending})
+	}
+
+	_, err := GetLaunchStepReady(launch, LaunchStepIssue)
+	require.Nil(t, err)
+
+	_, err = GetLaunchStepReady(launch, LaunchStepDeploy)
+	require.Equal(t, ErrLaunchStepNotReady, err)
+
+	launch.Steps[0].Status = entities.LaunchStepCompleted
+	_, err = GetLaunchStepReady(launch, LaunchStepDeploy)
+	require.Nil(t, err)
+
+	_, err = GetLaunchStepReady(launch, LaunchStepIssue)
+	require.Equal(t, ErrLaunchStepDone, err)
+
+	_, err = GetLaunchStepReady(launch, "unknown")
+	require.Equal(t, ErrUnknownLaunchStep, err)
+
+	launch.Status = entities.LaunchAbandoned
+	_, err = GetLaunchStepReady(launch, LaunchStepDeploy)
+	require.Equal(t, ErrLaunchNotInProgress, err)
+}
+
+var launchBlockchainCfg = config.BlockchainConfig{
+	DeployerAddress: "erd1qqqqqqqqqqqqqpgqupgxrdhphusx5crgvg454u9k4zqsp5mst9usqlrfyy",
+	SystemSCAddress: "erd1qqqqqqqqqqqqqqqpqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqzllls8a5w6u",
+}
+
+const (
+	launchCreatorAddress  = "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	launchContractAddress = "erd1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq6gq4hu"
+)
+
+func makeLaunchTx(receiver string, data string, results ...string) entities.TransactionBC {
+	tx := entities.TransactionBC{
+		Status:   "success",
+		Sender:   launchCreatorAddress,
+		Receiver: receiver,
+		Data:     base64.StdEncoding.EncodeToString([]byte(data)),
+	}
+	for _, result := range results {
+		tx.Results = append(tx.Results, entities.SCResult{Data: base64.StdEncoding.EncodeToString([]byte(result))})
+	}
+
+	return tx
+}
+
+func Test_ApplyLaunchTransaction(t *testing.T) {
+	launch := &entities.CollectionLaunch{Status: entities.LaunchInProgress, Address: launchCreatorAddress, TokenName: "Youbei", TokenTicker: "YOUBEI"}
+	issueStep := &entities.CollectionLaunchStep{Name: LaunchStepIssue, Status: entities.LaunchStepSubmitted}
+
+	issueTx := makeLaunchTx(
+		launchBlockchainCfg.SystemSCAddress,
+		"issueNonFungible@"+hex.EncodeToString([]byte("Youbei"))+"@"+hex.EncodeToString([]byte("YOUBEI")),
+		"@6f6b@"+hex.EncodeToString([]byte("YOUBEI-a1b2c3")),
+	)
+	changed, err := applyLaunchTransaction(launch, issueStep, issueTx, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.Equal(t, entities.LaunchStepCompleted, issueStep.Status)
+	require.Equal(t, "YOUBEI-a1b2c3", launch.TokenId)
+
+	deployStep := &entities.CollectionLaunchStep{Name: LaunchStepDeploy, Status: entities.LaunchStepSubmitted}
+	deployTx := makeLaunchTx(
+		launchBlockchainCfg.DeployerAddress,
+		"deployNFTTemplateContract@"+hex.EncodeToString([]byte("YOUBEI-a1b2c3"))+"@03e8",
+		"@6f6b@"+hex.EncodeToString(make([]byte, 32)),
+	)
+	changed, err = applyLaunchTransaction(launch, deployStep, deployTx, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.Equal(t, launchContractAddress, launch.ContractAddress)
+
+	setRolesStep := &entities.CollectionLaunchStep{Name: LaunchStepSetRoles, Status: entities.LaunchStepSubmitted}
+	setRolesTx := makeLaunchTx(
+		launchBlockchainCfg.SystemSCAddress,
+		"setSpecialRole@"+hex.EncodeToString([]byte("YOUBEI-a1b2c3"))+"@"+hex.EncodeToString(make([]byte, 32))+"@45534454526f6c654e4654437265617465",
+	)
+	changed, err = applyLaunchTransaction(launch, setRolesStep, setRolesTx, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.Equal(t, entities.LaunchStepCompleted, setRolesStep.Status)
+
+	pendingStep := &entities.CollectionLaunchStep{Name: LaunchStepSaleStart, Status: entities.LaunchStepSubmitted}
+	changed, err = applyLaunchTransaction(launch, pendingStep, entities.TransactionBC{Status: "pending"}, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.False(t, changed)
+
+	changed, err = applyLaunchTransaction(launch, pendingStep, entities.TransactionBC{Status: "fail"}, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.Equal(t, entities.LaunchStepFailed, pendingStep.Status)
+}
+
+func Test_ApplyLaunchTransactionRejectsOtherCalls(t *testing.T) {
+	launch := &entities.CollectionLaunch{
+		Status:          entities.LaunchInProgress,
+		Address:         launchCreatorAddress,
+		TokenName:       "Youbei",
+		TokenTicker:     "YOUBEI",
+		TokenId:         "YOUBEI-a1b2c3",
+		ContractAddress: launchContractAddress,
+	}
+	contractHex := hex.EncodeToString(make([]byte, 32))
+	changeOwnerData := "changeOwner@" + contractHex
+
+	txs := map[string]entities.TransactionBC{
+		"other sender": func() entities.TransactionBC {
+			tx := makeLaunchTx(launchBlockchainCfg.DeployerAddress, changeOwnerData)
+			tx.Sender = "erd1someoneelse"
+			return tx
+		}(),
+		"other receiver": makeLaunchTx(launchCreatorAddress, changeOwnerData),
+		"other function": makeLaunchTx(launchBlockchainCfg.DeployerAddress, "ESDTNFTTransfer@"+contractHex),
+		"other contract": makeLaunchTx(launchBlockchainCfg.DeployerAddress, "changeOwner@"+hex.EncodeToString(make([]byte, 31))+"01"),
+		"no arguments":   makeLaunchTx(launchBlockchainCfg.DeployerAddress, "changeOwner"),
+	}
+	for name, tx := range txs {
+		step := &entities.CollectionLaunchStep{Name: LaunchStepChangeOwner, Status: entities.LaunchStepSubmitted}
+		changed, err := applyLaunchTransaction(launch, step, tx, launchBlockchainCfg)
+		require.Nil(t, err, name)
+		require.True(t, changed, name)
+		require.Equal(t, entities.LaunchStepFailed, step.Status, name)
+	}
+
+	step := &entities.CollectionLaunchStep{Name: LaunchStepChangeOwner, Status: entities.LaunchStepSubmitted}
+	_, err := applyLaunchTransaction(launch, step, makeLaunchTx(launchBlockchainCfg.DeployerAddress, changeOwnerData), launchBlockchainCfg)
+	require.Nil(t, err)
+	require.Equal(t, entities.LaunchStepCompleted, step.Status)
+
+	otherTokenLaunch := *launch
+	otherTokenLaunch.TokenId = ""
+	issueStep := &entities.CollectionLaunchStep{Name: LaunchStepIssue, Status: entities.LaunchStepSubmitted}
+	issueTx := makeLaunchTx(
+		launchBlockchainCfg.SystemSCAddress,
+		"issueNonFungible@"+hex.EncodeToString([]byte("Youbei"))+"@"+hex.EncodeToString([]byte("YOUBEI")),
+		"@6f6b@"+hex.EncodeToString([]byte("OTHER-a1b2c3")),
+	)
+	_, err = applyLaunchTransaction(&otherTokenLaunch, issueStep, issueTx, launchBlockchainCfg)
+	require.Nil(t, err)
+	require.Equal(t, entities.LaunchStepFailed, issueStep.Status)
+	require.Empty(t, otherTokenLaunch.TokenId)
+}
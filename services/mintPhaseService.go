@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+)
+
+const MaxMintPhases = 10
+
+type MintPhaseRequest struct {
+	Name          string `json:"name"`
+	PriceString   string `json:"priceString"` // nominal, e.g. "0.5"
+	StartDate     uint64 `json:"startDate"`
+	EndDate       uint64 `json:"endDate"`
+	WalletCap     uint64 `json:"walletCap"`
+	WhitelistType uint64 `json:"whitelistType"`
+}
+
+type SetCollectionMintPhasesRequest struct {
+	Phases []MintPhaseRequest `json:"phases"`
+}
+
+// CollectionMintInfo adds the schedule and the indexed progress to the mint info. CurrentPhase is nil outside of a phase.
+type CollectionMintInfo struct {
+	MintInfo
+	UniqueMinters uint64                         `json:"uniqueMinters"`
+	Revenue       float64                        `json:"revenue"`
+	PhasesSold    []entities.MintPhaseSold       `json:"phasesSold"`
+	Phases        []entities.CollectionMintPhase `json:"phases"`
+	CurrentPhase  *entities.CollectionMintPhase  `json:"currentPhase"`
+	NextPhase     *entities.CollectionMintPhase  `json:"nextPhase"`
+}
+
+func GetCollectionMintPhases(collectionId uint64) ([]entities.CollectionMintPhase, error) {
+	return storage.GetMintPhasesByCollectionId(collectionId)
+}
+
+// SetCollectionMintPhases replaces the whole schedule of the collection.
+// The collection mint start and end dates follow the first and the last phase.
+func SetCollectionMintPhases(collection *entities.Collection, request *SetCollectionMintPhasesRequest) ([]entities.CollectionMintPhase, error) {
+	phases := make([]entities.CollectionMintPhase, len(request.Phases))
+	for index, phaseRequest := range request.Phases {
+		price, nominal, err := convertNominalPrice(phaseRequest.PriceString)
+		if err != nil {
+			return nil, fmt.Errorf("phase %d: %w", index, err)
+		}
+
+		phases[index] = entities.CollectionMintPhase{
+			Name:          phaseRequest.Name,
+			PriceString:   price.String(),
+			PriceNominal:  nominal,
+			StartDate:     phaseRequest.StartDate,
+			EndDate:       phaseRequest.EndDate,
+			WalletCap:     phaseRequest.WalletCap,
+			WhitelistType: phaseRequest.WhitelistType,
+		}
+	}
+
+	err := saveCollectionMintPhases(collection, phases)
+	if err != nil {
+		return nil, err
+	}
+
+	return phases, nil
+}
+
+// GetCollectionMintInfo returns the mint progress along with the phase open at the given unix time.
+// Collections the mint indexer has not seen yet fall back to the contract view.
+func GetCollectionMintInfo(collection *entities.Collection, now uint64) (*CollectionMintInfo, error) {
+	var info CollectionMintInfo
+
+	progress, err := storage.GetCollectionMintProgress(collection.ID)
+	if err == nil {
+		mintProgress, innerErr := makeMintProgress(collection, progress)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		info.MintInfo = MintInfo{MaxSupply: mintProgress.MaxSupply, TotalSold: mintProgress.Minted}
+		info.UniqueMinters = mintProgress.UniqueMinters
+		info.Revenue = mintProgress.Revenue
+		info.PhasesSold = mintProgress.PhasesSold
+	} else {
+		mintInfo, innerErr := GetMintInfoForContract(collection.ContractAddress)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		info.MintInfo = *mintInfo
+	}
+
+	phases, err := storage.GetMintPhasesByCollectionId(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Phases = phases
+	info.CurrentPhase, info.NextPhase = FindMintPhase(phases, now)
+	return &info, nil
+}
+
+// FindMintPhase returns the phase open at the given unix time and the first one starting after it.
+func FindMintPhase(phases []entities.CollectionMintPhase, now uint64) (*entities.CollectionMintPhase, *entities.CollectionMintPhase) {
+	var current, next *entities.CollectionMintPhase
+	for index := range phases {
+		phase := &phases[index]
+		if phase.StartDate > now {
+			if next == nil {
+				next = phase
+			}
+			continue
+		}
+		if phase.EndDate == 0 || phase.EndDate > now {
+			current = phase
+		}
+	}
+
+	return current, next
+}
+
+func ValidateMintPhases(phases []entities.CollectionMintPhase) error {
+	if len(phases) > MaxMintPhases {
+		return fmt.Errorf("a collection can have at most %d mint phases", MaxMintPhases)
+	}
+
+	for index, phase := range phases {
+		if len(phase.Name) == 0 || len(phase.Name) > MaxNameLen {
+			return fmt.Errorf("phase %d: name must have between 1 and %d characters", index, MaxNameLen)
+		}
+		if phase.StartDate == 0 {
+			return fmt.Errorf("phase %d: start date is required", index)
+		}
+		if phase.EndDate != 0 && phase.EndDate <= phase.StartDate {
+			return fmt.Errorf("phase %d: end date must be after the start date", index)
+		}
+		if phase.EndDate == 0 && index != len(phases)-1 {
+			return fmt.Errorf("phase %d: only the last phase can be left without end date", index)
+		}
+		if index > 0 && phase.StartDate < phases[index-1].EndDate {
+			return fmt.Errorf("phase %d: starts before the previous phase ends", index)
+		}
+
+		switch phase.WhitelistType {
+		case entities.Whitelist_type_none, entities.Whitelist_type_mint, entities.Whitelist_type_buy_mint:
+		default:
+			return fmt.Errorf("phase %d: whitelist type must allow minting", index)
+		}
+	}
+
+	return nil
+}
+
+func saveCollectionMintPhases(collection *entities.Collection, phases []entities.CollectionMintPhase) error {
+	err := ValidateMintPhases(phases)
+	if err != nil {
+		return err
+	}
+
+	for index := range phases {
+		phases[index].ID = 0
+		phases[index].CollectionID = collection.ID
+		phases[index].Position = uint64(index)
+	}
+
+	if len(phases) > 0 {
+		collection.MintStartDate = phases[0].StartDate
+		collection.MintEndDate = phases[len(phases)-1].EndDate
+	}
+
+	return storage.SaveCollectionMintPhases(collection, phases)
+}
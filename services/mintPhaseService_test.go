@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateMintPhases(t *testing.T) {
+	phases := []entities.CollectionMintPhase{
+		{Name: "OG", StartDate: 100, EndDate: 200, WalletCap: 2, WhitelistType: entities.Whitelist_type_mint},
+		{Name: "Whitelist", StartDate: 200, EndDate: 300, WalletCap: 5, WhitelistType: entities.Whitelist_type_buy_mint},
+		{Name: "Public", StartDate: 300},
+	}
+	require.Nil(t, ValidateMintPhases(phases))
+
+	overlapping := append([]entities.CollectionMintPhase{}, phases...)
+	overlapping[1].StartDate = 150
+	require.NotNil(t, ValidateMintPhases(overlapping))
+
+	openEnded := append([]entities.CollectionMintPhase{}, phases...)
+	openEnded[0].EndDate = 0
+	require.NotNil(t, ValidateMintPhases(openEnded))
+
+	buyOnly := append([]entities.CollectionMintPhase{}, phases...)
+	buyOnly[0].WhitelistType = entities.Whitelist_type_buy
+	require.NotNil(t, ValidateMintPhases(buyOnly))
+}
+
+func Test_FindMintPhase(t *testing.T) {
+	phases := []entities.CollectionMintPhase{
+		{Name: "OG", StartDate: 100, EndDate: 200},
+		{Name: "Public", StartDate: 250},
+	}
+
+	current, next := FindMintPhase(phases, 50)
+	require.Nil(t, current)
+	require.Equal(t, "OG", next.Name)
+
+	current, next = FindMintPhase(phases, 150)
+	require.Equal(t, "OG", current.Name)
+	require.Equal(t, "Public", next.Name)
+
+	current, next = FindMintPhase(phases, 220)
+	require.Nil(t, current)
+	require.Equal(t, "Public", next.Name)
+
+	current, next = FindMintPhase(phases, 1000)
+	require.Equal(t, "Public", current.Name)
+	require.Nil(t, next)
+}
+
+func Test_ConvertNominalPrice(t *testing.T) {
+	t.Parallel()
+
+	price, nominal, err := convertNominalPrice("1.1")
+	require.Nil(t, err)
+	require.Equal(t, "1100000000000000000", price.String())
+	require.Equal(t, 1.1, nominal)
+
+	price, _, err = convertNominalPrice("0.07")
+	require.Nil(t, err)
+	require.Equal(t, "70000000000000000", price.String())
+
+	price, _, err = convertNominalPrice("0.000000000000000001")
+	require.Nil(t, err)
+	require.Equal(t, "1", price.String())
+
+	_, _, err = convertNominalPrice("0.0000000000000000001")
+	require.NotNil(t, err)
+	_, _, err = convertNominalPrice("-1")
+	require.NotNil(t, err)
+	_, _, err = convertNominalPrice("1/3")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/cache"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/interaction"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/emurmotol/ethconv"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	MintProgressChannelFormat = "MintProgress:%s"
+
+	mintTokensFunctionName                   = "mintTokens"
+	mintTokensThroughMarketplaceFunctionName = "mintTokensThroughMarketplace"
+)
+
+type MintProgress struct {
+	CollectionId  string                   `json:"collectionId"`
+	MaxSupply     uint64                   `json:"maxSupply"`
+	Minted        uint64                   `json:"minted"`
+	UniqueMinters uint64                   `json:"uniqueMinters"`
+	Revenue       float64                  `json:"revenue"`
+	PhasesSold    []entities.MintPhaseSold `json:"phasesSold"`
+	UpdatedAt     int64                    `json:"updatedAt"`
+}
+
+// IndexMintTransactions stores the successful mints of a batch of minter contract transactions,
+// records the mint of the tokens already indexed and publishes the new progress. Indexing stops at the first pending transaction so it is picked up again next time.
+// The batch was read from the cursor, skipping the cursorTxs transactions already indexed at cursorTimestamp.
+func IndexMintTransactions(collection *entities.Collection, txs []entities.TransactionBC, cursorTimestamp uint64, cursorTxs uint64) (*MintProgress, error) {
+	phases, err := storage.GetMintPhasesByCollectionId(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastTimestamp uint64
+	var lastTimestampTxs uint64
+	mints := make([]entities.MintTransaction, 0, len(txs))
+	mintTxs := make([]entities.TransactionBC, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Status == string(transaction.TxStatusPending) || tx.PendingResults {
+			break
+		}
+		if tx.Timestamp != lastTimestamp {
+			lastTimestamp = tx.Timestamp
+			lastTimestampTxs = 0
+			if lastTimestamp == cursorTimestamp {
+				lastTimestampTxs = cursorTxs
+			}
+		}
+		lastTimestampTxs++
+
+		mint, ok := parseMintTransaction(collection.ID, phases, tx)
+		if ok {
+			mints = append(mints, mint)
+			mintTxs = append(mintTxs, tx)
+		}
+	}
+
+	if lastTimestamp == 0 {
+		return nil, nil
+	}
+
+	progress, err := storage.AddMintTransactions(collection.ID, mints, lastTimestamp, lastTimestampTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mints) == 0 {
+		return nil, nil
+	}
+
+	for _, tx := range mintTxs {
+		recordMintEvents(tx)
+	}
+
+	return publishMintProgress(collection, progress)
+}
+
+// ReconcileCollectionMintProgress reads max supply and total sold from the contract view and stores them in the progress.
+func ReconcileCollectionMintProgress(collection *entities.Collection) (*MintProgress, error) {
+	mintInfo, err := queryMintInfo(collection.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := storage.UpdateCollectionMintProgressFromChain(collection.ID, mintInfo.MaxSupply, mintInfo.TotalSold, time.Now().UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	return publishMintProgress(collection, progress)
+}
+
+func GetMintProgress(collection *entities.Collection) (*MintProgress, error) {
+	progress, err := storage.GetCollectionMintProgress(collection.ID)
+	if err == gorm.ErrRecordNotFound {
+		progress = &entities.CollectionMintProgress{CollectionID: collection.ID, MaxSupply: collection.MaxSupply}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return makeMintProgress(collection, progress)
+}
+
+// SubscribeMintProgress listens to the progress published by any instance for the collection.
+func SubscribeMintProgress(ctx context.Context, tokenId string) *redis.PubSub {
+	return cache.GetRedis().Subscribe(ctx, fmt.Sprintf(MintProgressChannelFormat, tokenId))
+}
+
+func publishMintProgress(collection *entities.Collection, progress *entities.CollectionMintProgress) (*MintProgress, error) {
+	mintProgress, err := makeMintProgress(collection, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(mintProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := fmt.Sprintf(MintProgressChannelFormat, collection.CollectionTokenID)
+	err = cache.GetRedis().Publish(cache.GetContext(), channel, payload).Err()
+	if err != nil {
+		log.Debug("could not publish mint progress", "err", err)
+	}
+
+	return mintProgress, nil
+}
+
+func makeMintProgress(collection *entities.Collection, progress *entities.CollectionMintProgress) (*MintProgress, error) {
+	phasesSold, err := storage.GetMintPhasesSold(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSupply := progress.MaxSupply
+	if maxSupply == 0 {
+		maxSupply = collection.MaxSupply
+	}
+
+	return &MintProgress{
+		CollectionId:  collection.CollectionTokenID,
+		MaxSupply:     maxSupply,
+		Minted:        progress.Minted,
+		UniqueMinters: progress.UniqueMinters,
+		Revenue:       progress.Revenue,
+		PhasesSold:    phasesSold,
+		UpdatedAt:     progress.UpdatedAt,
+	}, nil
+}
+
+func parseMintTransaction(collectionId uint64, phases []entities.CollectionMintPhase, tx entities.TransactionBC) (entities.MintTransaction, bool) {
+	if tx.Status != string(transaction.TxStatusSuccess) {
+		return entities.MintTransaction{}, false
+	}
+	if tx.Function != mintTokensFunctionName && tx.Function != mintTokensThroughMarketplaceFunctionName {
+		return entities.MintTransaction{}, false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(tx.Data)
+	if err != nil {
+		return entities.MintTransaction{}, false
+	}
+	args := strings.Split(string(data), "@")
+	if len(args) < 2 {
+		return entities.MintTransaction{}, false
+	}
+	count, err := strconv.ParseUint(args[1], 16, 64)
+	if err != nil || count == 0 {
+		return entities.MintTransaction{}, false
+	}
+
+	value := float64(0)
+	bigValue, ok := big.NewInt(0).SetString(tx.Value, 10)
+	if ok {
+		floatValue, innerErr := ethconv.FromWei(bigValue, ethconv.Ether)
+		if innerErr != nil {
+			log.Debug("could not convert mint value", "tx", tx.TxHash)
+		} else {
+			value, _ = floatValue.Float64()
+		}
+	}
+
+	// Minting through the marketplace may not be sent by the minter, who is whoever received the new tokens.
+	minter := tx.Sender
+	for _, transfer := range parseNftTransfers(tx.Results) {
+		if !transfer.ToContract {
+			minter = transfer.Receiver
+			break
+		}
+	}
+
+	mint := entities.MintTransaction{
+		TxHash:       tx.TxHash,
+		CollectionID: collectionId,
+		Minter:       minter,
+		Count:        count,
+		Value:        value,
+		Timestamp:    tx.Timestamp,
+	}
+
+	phase, _ := FindMintPhase(phases, tx.Timestamp)
+	if phase != nil {
+		mint.PhaseID = phase.ID
+	}
+
+	return mint, true
+}
+
+// nftTransfer is a token sent by a smart contract result of a transaction.
+type nftTransfer struct {
+	TokenId      string
+	Nonce        uint64
+	Sender       string
+	Receiver     string
+	FromContract bool
+	ToContract   bool
+}
+
+// parseNftTransfers reads the ESDTNFTTransfer and MultiESDTNFTTransfer results of a transaction.
+func parseNftTransfers(results []entities.SCResult) []nftTransfer {
+	var transfers []nftTransfer
+	for _, result := range results {
+		data, err := base64.StdEncoding.DecodeString(result.Data)
+		if err != nil {
+			continue
+		}
+
+		args := strings.Split(string(data), "@")
+		switch args[0] {
+		case "ESDTNFTTransfer":
+			if len(args) < 5 {
+				continue
+			}
+			transfer, ok := makeNftTransfer(result.Sender, args[1], args[2], args[4])
+			if ok {
+				transfers = append(transfers, transfer)
+			}
+		case "MultiESDTNFTTransfer":
+			if len(args) < 3 {
+				continue
+			}
+			count, err := strconv.ParseUint(args[2], 16, 64)
+			if err != nil {
+				continue
+			}
+			for index := uint64(0); index < count && len(args) >= int(6+index*3); index++ {
+				transfer, ok := makeNftTransfer(result.Sender, args[3+index*3], args[4+index*3], args[1])
+				if ok {
+					transfers = append(transfers, transfer)
+				}
+			}
+		}
+	}
+
+	return transfers
+}
+
+func makeNftTransfer(sender string, tokenIdHex string, nonceHex string, receiverHex string) (nftTransfer, bool) {
+	tokenId, err := hex.DecodeString(tokenIdHex)
+	if err != nil {
+		return nftTransfer{}, false
+	}
+
+	nonce, err := strconv.ParseUint(nonceHex, 16, 64)
+	if err != nil || nonce == 0 {
+		return nftTransfer{}, false
+	}
+
+	if len(receiverHex) != 64 {
+		return nftTransfer{}, false
+	}
+	receiver, err := ConvertHexToBehc32(receiverHex)
+	if err != nil {
+		return nftTransfer{}, false
+	}
+
+	// smart contract addresses start with 8 zero bytes
+	senderHex, _ := getAddressHex(sender)
+	return nftTransfer{
+		TokenId:      string(tokenId),
+		Nonce:        nonce,
+		Sender:       sender,
+		Receiver:     receiver,
+		FromContract: strings.HasPrefix(senderHex, "0000000000000000"),
+		ToContract:   strings.HasPrefix(receiverHex, "0000000000000000"),
+	}, true
+}
+
+func queryMintInfo(contractAddress string) (*MintInfo, error) {
+	bi := interaction.GetBlockchainInteractor()
+	if bi == nil {
+		return nil, errors.New("no blockchain interactor")
+	}
+
+	result, err := bi.DoVmQuery(contractAddress, MintInfoViewName, []string{})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 2 {
+		return nil, errors.New("unknown result len")
+	}
+
+	maxSupply := big.NewInt(0).SetBytes(result[0])
+	totalSold := big.NewInt(0).SetBytes(result[1])
+
+	return &MintInfo{
+		MaxSupply: maxSupply.Uint64(),
+		TotalSold: totalSold.Uint64(),
+	}, nil
+}
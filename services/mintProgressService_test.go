@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseMintTransaction(t *testing.T) {
+	phases := []entities.CollectionMintPhase{
+		{ID: 7, Name: "OG", StartDate: 100, EndDate: 200},
+	}
+
+	tx := entities.TransactionBC{
+		TxHash:    "hash",
+		Sender:    "erd_minter",
+		Data:      base64.StdEncoding.EncodeToString([]byte("mintTokens@03")),
+		Status:    "success",
+		Function:  "mintTokens",
+		Value:     "1500000000000000000",
+		Timestamp: 150,
+	}
+
+	mint, ok := parseMintTransaction(3, phases, tx)
+	require.True(t, ok)
+	require.Equal(t, uint64(3), mint.Count)
+	require.Equal(t, uint64(7), mint.PhaseID)
+	require.Equal(t, 1.5, mint.Value)
+	require.Equal(t, "erd_minter", mint.Minter)
+
+	tx.Timestamp = 300
+	mint, ok = parseMintTransaction(3, phases, tx)
+	require.True(t, ok)
+	require.Equal(t, uint64(0), mint.PhaseID)
+
+	tx.Status = "fail"
+	_, ok = parseMintTransaction(3, phases, tx)
+	require.False(t, ok)
+
+	tx.Status = "success"
+	tx.Function = "withdraw"
+	_, ok = parseMintTransaction(3, phases, tx)
+	require.False(t, ok)
+}
+
+func Test_ParseMintTransaction_ThroughMarketplace(t *testing.T) {
+	buyerHex := strings.Repeat("ab", 32)
+	marketplaceHex := strings.Repeat("00", 8) + strings.Repeat("cd", 24)
+	tokenHex := hex.EncodeToString([]byte("COL-abcdef"))
+	tx := entities.TransactionBC{
+		TxHash:    "hash",
+		Sender:    "erd_relayer",
+		Data:      base64.StdEncoding.EncodeToString([]byte("mintTokensThroughMarketplace@02")),
+		Status:    "success",
+		Function:  "mintTokensThroughMarketplace",
+		Value:     "0",
+		Timestamp: 150,
+		Results: []entities.SCResult{
+			{Data: base64.StdEncoding.EncodeToString([]byte("ESDTNFTTransfer@" + tokenHex + "@05@01@" + marketplaceHex))},
+			{Data: base64.StdEncoding.EncodeToString([]byte("MultiESDTNFTTransfer@" + buyerHex + "@02@" + tokenHex + "@05@01@" + tokenHex + "@06@01"))},
+		},
+	}
+
+	transfers := parseNftTransfers(tx.Results)
+	require.Len(t, transfers, 3)
+	require.True(t, transfers[0].ToContract)
+	require.Equal(t, "COL-abcdef", transfers[1].TokenId)
+	require.Equal(t, uint64(6), transfers[2].Nonce)
+
+	buyer, err := ConvertHexToBehc32(buyerHex)
+	require.Nil(t, err)
+	mint, ok := parseMintTransaction(3, nil, tx)
+	require.True(t, ok)
+	require.Equal(t, buyer, mint.Minter)
+	require.Equal(t, uint64(2), mint.Count)
+}
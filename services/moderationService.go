@@ -0,0 +1,389 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/stats/collstats"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxReportDetailsLength    = 1000
+	MaxModerationQueueLimit   = 100
+	MaxModerationReportsLimit = 100
+)
+
+var (
+	ErrUnknownReportTarget     = errors.New("unknown report target type")
+	ErrUnknownReportReason     = errors.New("unknown report reason")
+	ErrUnknownReportStatus     = errors.New("unknown report status")
+	ErrReportDetailsTooLong    = fmt.Errorf("report details exceed %d characters", MaxReportDetailsLength)
+	ErrReportAlreadyOpen       = errors.New("target already reported")
+	ErrInvalidModerationAction = errors.New("action does not apply to the target")
+	ErrAccountBanned           = errors.New("account is banned")
+)
+
+type CreateReportRequest struct {
+	TargetType entities.ReportTargetType `json:"targetType"`
+	TargetID   string                    `json:"targetId"`
+	Nonce      uint64                    `json:"nonce"`
+	Reason     entities.ReportReason     `json:"reason"`
+	Details    string                    `json:"details"`
+}
+
+type ModerationActionRequest struct {
+	TargetType entities.ReportTargetType `json:"targetType"`
+	TargetID   string                    `json:"targetId"`
+	Nonce      uint64                    `json:"nonce"`
+	Action     entities.ModerationAction `json:"action"`
+	Note       string                    `json:"note"`
+}
+
+// ModerationState is what moderators decided about a target. Only the flags of its type are set.
+type ModerationState struct {
+	IsHidden   *bool `json:"isHidden,omitempty"`
+	IsDelisted *bool `json:"isDelisted,omitempty"`
+	IsBanned   *bool `json:"isBanned,omitempty"`
+}
+
+type ModerationActionResult struct {
+	Before          ModerationState `json:"before"`
+	After           ModerationState `json:"after"`
+	ResolvedReports int64           `json:"resolvedReports"`
+
+	auditTargetType string
+	auditTargetId   string
+}
+
+// AuditTarget is the audit target type and id of the moderated token, collection or account.
+func (result *ModerationActionResult) AuditTarget() (string, string) {
+	return result.auditTargetType, result.auditTargetId
+}
+
+type ModerationQueueEntry struct {
+	TargetType      entities.ReportTargetType `json:"targetType"`
+	TargetID        string                    `json:"targetId"`
+	Nonce           uint64                    `json:"nonce"`
+	Reports         int64                     `json:"reports"`
+	Reasons         []string                  `json:"reasons"`
+	FirstReportedAt int64                     `json:"firstReportedAt"`
+	LastReportedAt  int64                     `json:"lastReportedAt"`
+}
+
+type ModerationQueue struct {
+	Total   int64                  `json:"total"`
+	Entries []ModerationQueueEntry `json:"entries"`
+}
+
+type ContentReports struct {
+	Total   int64                    `json:"total"`
+	Reports []entities.ContentReport `json:"reports"`
+}
+
+// CreateReport files the report of a user. A user has at most one open report per target,
+// and banned accounts cannot report.
+func CreateReport(reporter string, request *CreateReportRequest, now int64) (*entities.ContentReport, error) {
+	err := checkValidReportRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := storage.GetAccountByAddress(reporter)
+	if err == nil && account.IsBanned {
+		return nil, ErrAccountBanned
+	}
+
+	_, err = getModerationState(request.TargetType, request.TargetID, request.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := reportTargetNonce(request.TargetType, request.Nonce)
+	alreadyOpen, err := storage.HasOpenContentReport(reporter, request.TargetType, request.TargetID, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if alreadyOpen {
+		return nil, ErrReportAlreadyOpen
+	}
+
+	report := entities.ContentReport{
+		TargetType:      request.TargetType,
+		TargetID:        request.TargetID,
+		TargetNonce:     nonce,
+		Reason:          request.Reason,
+		Details:         request.Details,
+		ReporterAddress: reporter,
+		Status:          entities.ReportOpen,
+		CreatedAt:       now,
+	}
+
+	err = storage.AddContentReport(&report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// GetModerationQueue lists the reported targets waiting for moderation, the most reported first.
+func GetModerationQueue(targetType entities.ReportTargetType, offset int, limit int) (*ModerationQueue, error) {
+	if targetType != "" && !isReportTargetType(targetType) {
+		return nil, ErrUnknownReportTarget
+	}
+	if limit > MaxModerationQueueLimit {
+		limit = MaxModerationQueueLimit
+	}
+
+	total, err := storage.CountReportedTargets(targetType)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := storage.GetReportedTargets(targetType, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ModerationQueueEntry, len(targets))
+	for index, target := range targets {
+		entries[index] = ModerationQueueEntry{
+			TargetType:      target.TargetType,
+			TargetID:        target.TargetID,
+			Nonce:           target.TargetNonce,
+			Reports:         target.Reports,
+			Reasons:         strings.Split(target.Reasons, ","),
+			FirstReportedAt: target.FirstReportedAt,
+			LastReportedAt:  target.LastReportedAt,
+		}
+	}
+
+	return &ModerationQueue{Total: total, Entries: entries}, nil
+}
+
+// GetContentReports lists the reports matching the filter, newest first.
+// The target id filter also matches the nonce of the filter.
+func GetContentReports(filter *entities.ContentReport, offset int, limit int) (*ContentReports, error) {
+	if filter.TargetType != "" && !isReportTargetType(filter.TargetType) {
+		return nil, ErrUnknownReportTarget
+	}
+	if filter.Status != "" && !isReportStatus(filter.Status) {
+		return nil, ErrUnknownReportStatus
+	}
+	if limit > MaxModerationReportsLimit {
+		limit = MaxModerationReportsLimit
+	}
+
+	total, err := storage.CountContentReports(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := storage.GetContentReports(filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContentReports{Total: total, Reports: reports}, nil
+}
+
+// ApplyModerationAction hides, delists or bans a target, or reverts it, and closes its open reports.
+// Dismissing only closes the reports. Hidden collections leave the leaderboards right away.
+func ApplyModerationAction(moderator string, request *ModerationActionRequest, now int64) (*ModerationActionResult, error) {
+	if !isReportTargetType(request.TargetType) {
+		return nil, ErrUnknownReportTarget
+	}
+	if !isModerationActionFor(request.TargetType, request.Action) {
+		return nil, ErrInvalidModerationAction
+	}
+
+	target, err := getModerationState(request.TargetType, request.TargetID, request.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ModerationActionResult{Before: target.state(), auditTargetType: target.auditTargetType, auditTargetId: target.auditTargetId}
+	applyModerationAction(target, request.Action)
+	result.After = target.state()
+
+	if request.Action != entities.ModerationDismiss {
+		err = target.save()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if request.TargetType == entities.ReportTargetCollection && (request.Action == entities.ModerationHide || request.Action == entities.ModerationDelist) {
+		err = collstats.RemoveCollectionFromLeaderboards(request.TargetID)
+		if err != nil {
+			zlog.Error("could not remove collection from leaderboards", zap.String("collection", request.TargetID), zap.Error(err))
+		}
+	}
+
+	status := entities.ReportActioned
+	if request.Action == entities.ModerationDismiss {
+		status = entities.ReportDismissed
+	}
+
+	nonce := reportTargetNonce(request.TargetType, request.Nonce)
+	result.ResolvedReports, err = storage.ResolveContentReports(request.TargetType, request.TargetID, nonce, status, request.Action, moderator, request.Note, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func IsModerationRequestErr(err error) bool {
+	return errors.Is(err, ErrUnknownReportTarget) ||
+		errors.Is(err, ErrUnknownReportReason) ||
+		errors.Is(err, ErrUnknownReportStatus) ||
+		errors.Is(err, ErrReportDetailsTooLong) ||
+		errors.Is(err, ErrInvalidModerationAction)
+}
+
+// moderationTarget holds the moderation flags of a token, collection or account and how to store them.
+type moderationTarget struct {
+	targetType      entities.ReportTargetType
+	hidden          bool
+	delisted        bool
+	banned          bool
+	save            func() error
+	auditTargetType string
+	auditTargetId   string
+}
+
+func (target *moderationTarget) state() ModerationState {
+	hidden, delisted, banned := target.hidden, target.delisted, target.banned
+	if target.targetType == entities.ReportTargetAccount {
+		return ModerationState{IsBanned: &banned}
+	}
+
+	return ModerationState{IsHidden: &hidden, IsDelisted: &delisted}
+}
+
+func getModerationState(targetType entities.ReportTargetType, targetId string, nonce uint64) (*moderationTarget, error) {
+	target := &moderationTarget{targetType: targetType}
+
+	switch targetType {
+	case entities.ReportTargetToken:
+		token, err := storage.GetTokenByTokenIdAndNonce(targetId, nonce)
+		if err != nil {
+			return nil, err
+		}
+		target.hidden, target.delisted = token.IsHidden, token.IsDelisted
+		target.auditTargetType, target.auditTargetId = AuditTargetToken, strconv.FormatUint(token.ID, 10)
+		target.save = func() error {
+			return storage.SetTokenModeration(token.ID, target.hidden, target.delisted)
+		}
+	case entities.ReportTargetCollection:
+		collection, err := storage.GetCollectionByTokenId(targetId)
+		if err != nil {
+			return nil, err
+		}
+		target.hidden, target.delisted = collection.IsHidden, collection.IsDelisted
+		target.auditTargetType, target.auditTargetId = AuditTargetCollection, CollectionAuditTarget(collection)
+		target.save = func() error {
+			return storage.SetCollectionModeration(collection.ID, target.hidden, target.delisted)
+		}
+	case entities.ReportTargetAccount:
+		account, err := storage.GetAccountByAddress(targetId)
+		if err != nil {
+			return nil, err
+		}
+		target.banned = account.IsBanned
+		target.auditTargetType, target.auditTargetId = AuditTargetAccount, account.Address
+		target.save = func() error {
+			return storage.SetAccountBanned(account.ID, target.banned)
+		}
+	default:
+		return nil, ErrUnknownReportTarget
+	}
+
+	return target, nil
+}
+
+func applyModerationAction(target *moderationTarget, action entities.ModerationAction) {
+	switch action {
+	case entities.ModerationHide:
+		target.hidden = true
+	case entities.ModerationUnhide:
+		target.hidden = false
+	case entities.ModerationDelist:
+		target.delisted = true
+	case entities.ModerationRelist:
+		target.delisted = false
+	case entities.ModerationBan:
+		target.banned = true
+	case entities.ModerationUnban:
+		target.banned = false
+	}
+}
+
+func checkValidReportRequest(request *CreateReportRequest) error {
+	if !isReportTargetType(request.TargetType) {
+		return ErrUnknownReportTarget
+	}
+	if !isReportReason(request.Reason) {
+		return ErrUnknownReportReason
+	}
+	if len(request.Details) > MaxReportDetailsLength {
+		return ErrReportDetailsTooLong
+	}
+
+	return nil
+}
+
+// reportTargetNonce keeps the nonce of tokens only, so reports of other targets group together.
+func reportTargetNonce(targetType entities.ReportTargetType, nonce uint64) uint64 {
+	if targetType != entities.ReportTargetToken {
+		return 0
+	}
+
+	return nonce
+}
+
+func isModerationActionFor(targetType entities.ReportTargetType, action entities.ModerationAction) bool {
+	switch action {
+	case entities.ModerationDismiss:
+		return true
+	case entities.ModerationHide, entities.ModerationUnhide, entities.ModerationDelist, entities.ModerationRelist:
+		return targetType == entities.ReportTargetToken || targetType == entities.ReportTargetCollection
+	case entities.ModerationBan, entities.ModerationUnban:
+		return targetType == entities.ReportTargetAccount
+	default:
+		return false
+	}
+}
+
+func isReportTargetType(targetType entities.ReportTargetType) bool {
+	switch targetType {
+	case entities.ReportTargetToken, entities.ReportTargetCollection, entities.ReportTargetAccount:
+		return true
+	default:
+		return false
+	}
+}
+
+func isReportReason(reason entities.ReportReason) bool {
+	switch reason {
+	case entities.ReportReasonScam, entities.ReportReasonOffensive, entities.ReportReasonCopyright, entities.ReportReasonSpam, entities.ReportReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+func isReportStatus(status entities.ReportStatus) bool {
+	switch status {
+	case entities.ReportOpen, entities.ReportActioned, entities.ReportDismissed:
+		return true
+	default:
+		return false
+	}
+}
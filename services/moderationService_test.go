@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsModerationActionFor(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isModerationActionFor(entities.ReportTargetToken, entities.ModerationHide))
+	require.True(t, isModerationActionFor(entities.ReportTargetCollection, entities.ModerationDelist))
+	require.True(t, isModerationActionFor(entities.ReportTargetAccount, entities.ModerationBan))
+	require.True(t, isModerationActionFor(entities.ReportTargetAccount, entities.ModerationDismiss))
+
+	require.False(t, isModerationActionFor(entities.ReportTargetAccount, entities.ModerationHide))
+	require.False(t, isModerationActionFor(entities.ReportTargetToken, entities.ModerationBan))
+	require.False(t, isModerationActionFor(entities.ReportTargetCollection, "delete"))
+}
+
+func Test_ApplyModerationActionKeepsOtherFlags(t *testing.T) {
+	t.Parallel()
+
+	target := &moderationTarget{targetType: entities.ReportTargetCollection, delisted: true}
+	applyModerationAction(target, entities.ModerationHide)
+	state := target.state()
+	require.True(t, *state.IsHidden)
+	require.True(t, *state.IsDelisted)
+	require.Nil(t, state.IsBanned)
+
+	applyModerationAction(target, entities.ModerationRelist)
+	require.True(t, target.hidden)
+	require.False(t, target.delisted)
+
+	account := &moderationTarget{targetType: entities.ReportTargetAccount}
+	applyModerationAction(account, entities.ModerationBan)
+	state = account.state()
+	require.True(t, *state.IsBanned)
+	require.Nil(t, state.IsHidden)
+}
+
+func Test_CheckValidReportRequest(t *testing.T) {
+	t.Parallel()
+
+	request := CreateReportRequest{TargetType: entities.ReportTargetCollection, TargetID: "COLL-123456", Reason: entities.ReportReasonScam}
+	require.Nil(t, checkValidReportRequest(&request))
+
+	request.TargetType = "page"
+	require.Equal(t, ErrUnknownReportTarget, checkValidReportRequest(&request))
+
+	request.TargetType = entities.ReportTargetToken
+	request.Reason = "boring"
+	require.Equal(t, ErrUnknownReportReason, checkValidReportRequest(&request))
+
+	request.Reason = entities.ReportReasonOther
+	request.Details = strings.Repeat("a", MaxReportDetailsLength+1)
+	require.Equal(t, ErrReportDetailsTooLong, checkValidReportRequest(&request))
+	require.True(t, IsModerationRequestErr(checkValidReportRequest(&request)))
+}
+
+func Test_ReportTargetNonceOnlyForTokens(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, uint64(7), reportTargetNonce(entities.ReportTargetToken, 7))
+	require.Equal(t, uint64(0), reportTargetNonce(entities.ReportTargetCollection, 7))
+	require.Equal(t, uint64(0), reportTargetNonce(entities.ReportTargetAccount, 7))
+}
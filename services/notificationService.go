@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"go.uber.org/zap"
+)
+
+const MaxNotificationsLimit = 100
+
+type Notifications struct {
+	Total         int64                   `json:"total"`
+	Unread        int64                   `json:"unread"`
+	Notifications []entities.Notification `json:"notifications"`
+}
+
+type MarkNotificationsReadRequest struct {
+	Ids []uint64 `json:"ids"`
+}
+
+// Notify leaves a notification for the owner of the address. Failures are only logged,
+// a notification is never worth failing the action it reports.
+func Notify(address string, kind string, message string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		zlog.Error("could not marshal notification data", zap.String("kind", kind), zap.Error(err))
+		return
+	}
+
+	err = storage.AddNotification(&entities.Notification{
+		Address:   address,
+		Kind:      kind,
+		Message:   message,
+		Data:      payload,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		zlog.Error("could not store notification", zap.String("address", address), zap.String("kind", kind), zap.Error(err))
+	}
+}
+
+// GetNotifications lists the notifications of an address, newest first, with how many are unread.
+func GetNotifications(address string, offset int, limit int) (*Notifications, error) {
+	if limit > MaxNotificationsLimit {
+		limit = MaxNotificationsLimit
+	}
+
+	total, err := storage.CountNotificationsByAddress(address, false)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := storage.CountNotificationsByAddress(address, true)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := storage.GetNotificationsByAddress(address, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifications{Total: total, Unread: unread, Notifications: notifications}, nil
+}
+
+// MarkNotificationsRead marks the listed notifications of the address as read, or all of them when none is listed.
+func MarkNotificationsRead(address string, request *MarkNotificationsReadRequest) (int64, error) {
+	return storage.MarkNotificationsRead(address, request.Ids)
+}
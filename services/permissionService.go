@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
+	"gorm.io/gorm"
+)
+
+type Permission string
+
+const (
+	// global permissions
+	PermissionManageRoles      Permission = "roles:manage"
+	PermissionCollectionsAdmin Permission = "collections:admin"
+	PermissionContentModerate  Permission = "content:moderate"
+	PermissionLaunchesSupport  Permission = "launches:support"
+	PermissionAuditRead        Permission = "audit:read"
+	PermissionPrintRoyalties   Permission = "print:royalties"
+	PermissionPrintCatalog     Permission = "print:catalog"
+	PermissionVerifications    Permission = "collections:verify"
+
+	// collection scoped permissions, granted by a role on the collection of the path or globally
+	PermissionCollectionEdit      Permission = "collection:edit"
+	PermissionCollectionMint      Permission = "collection:mint"
+	PermissionCollectionWhitelist Permission = "collection:whitelist"
+	PermissionCollectionRoles     Permission = "collection:roles"
+)
+
+var (
+	ErrUnknownAccountRole    = errors.New("unknown account role")
+	ErrUnknownCollectionRole = errors.New("unknown collection role")
+	ErrCreatorRoleImmutable  = errors.New("the collection creator is always an owner")
+)
+
+// globalRolePermissions maps the account roles to what they can do on any collection. Admins can do everything.
+var globalRolePermissions = map[entities.AccountRole][]Permission{
+	entities.RoleModerator: {PermissionContentModerate, PermissionCollectionEdit, PermissionVerifications},
+	entities.RoleSupport:   {PermissionLaunchesSupport},
+	entities.RoleUser:      {},
+}
+
+var collectionRolePermissions = map[entities.CollectionRoleType][]Permission{
+	entities.CollectionRoleOwner:            {PermissionCollectionEdit, PermissionCollectionMint, PermissionCollectionWhitelist, PermissionCollectionRoles},
+	entities.CollectionRoleEditor:           {PermissionCollectionEdit},
+	entities.CollectionRoleWhitelistManager: {PermissionCollectionWhitelist},
+}
+
+// AccountRoles is what the jwt carries about an address: its global role and its role on each collection.
+type AccountRoles struct {
+	Role        entities.AccountRole
+	Collections map[uint64]entities.CollectionRoleType
+}
+
+type SetCollectionRoleRequest struct {
+	Address string                      `json:"address"`
+	Role    entities.CollectionRoleType `json:"role"`
+}
+
+type SetAccountRoleRequest struct {
+	Role entities.AccountRole `json:"role"`
+}
+
+func IsCollectionPermission(permission Permission) bool {
+	for _, permissions := range collectionRolePermissions {
+		if containsPermission(permissions, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GlobalRoleHasPermission(role entities.AccountRole, permission Permission) bool {
+	if role == entities.RoleAdmin {
+		return true
+	}
+
+	return containsPermission(globalRolePermissions[role], permission)
+}
+
+func CollectionRoleHasPermission(role entities.CollectionRoleType, permission Permission) bool {
+	return containsPermission(collectionRolePermissions[role], permission)
+}
+
+// ResolveAccountRoles loads the roles of an address for its jwt, creating the account on first login.
+// Collections created by the address are owned.
+func ResolveAccountRoles(address string) (*AccountRoles, error) {
+	account, err := GetOrCreateAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := &AccountRoles{
+		Role:        account.Role,
+		Collections: map[uint64]entities.CollectionRoleType{},
+	}
+
+	collectionRoles, err := storage.GetCollectionRolesByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, collectionRole := range collectionRoles {
+		roles.Collections[collectionRole.CollectionID] = collectionRole.Role
+	}
+
+	createdIds, err := storage.GetCollectionIdsByCreatorAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range createdIds {
+		roles.Collections[id] = entities.CollectionRoleOwner
+	}
+
+	return roles, nil
+}
+
+// GetCollectionRoleOf returns the current role of an address on a collection, or an empty role.
+func GetCollectionRoleOf(collection *entities.Collection, address string) (entities.CollectionRoleType, error) {
+	creator, err := storage.GetAccountById(collection.CreatorID)
+	if err != nil {
+		return "", err
+	}
+	if creator.Address == address {
+		return entities.CollectionRoleOwner, nil
+	}
+
+	role, err := storage.GetCollectionRole(collection.ID, address)
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return role.Role, nil
+}
+
+func GetCollectionRoles(collection *entities.Collection) ([]entities.CollectionRole, error) {
+	return storage.GetCollectionRoles(collection.ID)
+}
+
+// SetCollectionRole grants a role on the collection. Replacing a role signs the address out,
+// so its jwt does not keep the permissions of the previous one.
+func SetCollectionRole(collection *entities.Collection, grantedBy string, request *SetCollectionRoleRequest) (*entities.CollectionRole, error) {
+	if _, ok := collectionRolePermissions[request.Role]; !ok {
+		return nil, ErrUnknownCollectionRole
+	}
+
+	_, err := data.NewAddressFromBech32String(request.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := GetCollectionRoleOf(collection, request.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	creator, err := storage.GetAccountById(collection.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	if creator.Address == request.Address {
+		return nil, ErrCreatorRoleImmutable
+	}
+
+	role := &entities.CollectionRole{
+		CollectionID: collection.ID,
+		Address:      request.Address,
+		Role:         request.Role,
+		GrantedBy:    grantedBy,
+		CreatedAt:    time.Now().Unix(),
+	}
+	err = storage.SetCollectionRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != "" && previous != request.Role {
+		RevokeAllAuthSessions(request.Address)
+	}
+
+	return role, nil
+}
+
+// RemoveCollectionRole takes the role of the address away and signs it out.
+func RemoveCollectionRole(collection *entities.Collection, address string) error {
+	err := storage.DeleteCollectionRole(collection.ID, address)
+	if err != nil {
+		return err
+	}
+
+	RevokeAllAuthSessions(address)
+	return nil
+}
+
+// SetAccountRole changes the global role of an address and signs it out.
+func SetAccountRole(address string, request *SetAccountRoleRequest) error {
+	if _, ok := globalRolePermissions[request.Role]; !ok && request.Role != entities.RoleAdmin {
+		return ErrUnknownAccountRole
+	}
+
+	err := storage.UpdateAccountRole(address, request.Role)
+	if err != nil {
+		return err
+	}
+
+	RevokeAllAuthSessions(address)
+	return nil
+}
+
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
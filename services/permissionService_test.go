@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GlobalRolePermissions(t *testing.T) {
+	require.True(t, GlobalRoleHasPermission(entities.RoleAdmin, PermissionManageRoles))
+	require.True(t, GlobalRoleHasPermission(entities.RoleAdmin, PermissionCollectionMint))
+	require.True(t, GlobalRoleHasPermission(entities.RoleModerator, PermissionContentModerate))
+	require.True(t, GlobalRoleHasPermission(entities.RoleModerator, PermissionCollectionEdit))
+	require.True(t, GlobalRoleHasPermission(entities.RoleModerator, PermissionVerifications))
+	require.False(t, GlobalRoleHasPermission(entities.RoleModerator, PermissionManageRoles))
+	require.True(t, GlobalRoleHasPermission(entities.RoleSupport, PermissionLaunchesSupport))
+	require.False(t, GlobalRoleHasPermission(entities.RoleSupport, PermissionCollectionEdit))
+	require.False(t, GlobalRoleHasPermission(entities.RoleUser, PermissionCollectionEdit))
+	require.False(t, GlobalRoleHasPermission("", PermissionCollectionEdit))
+}
+
+func Test_CollectionRolePermissions(t *testing.T) {
+	require.True(t, CollectionRoleHasPermission(entities.CollectionRoleOwner, PermissionCollectionRoles))
+	require.True(t, CollectionRoleHasPermission(entities.CollectionRoleEditor, PermissionCollectionEdit))
+	require.False(t, CollectionRoleHasPermission(entities.CollectionRoleEditor, PermissionCollectionMint))
+	require.True(t, CollectionRoleHasPermission(entities.CollectionRoleWhitelistManager, PermissionCollectionWhitelist))
+	require.False(t, CollectionRoleHasPermission(entities.CollectionRoleWhitelistManager, PermissionCollectionEdit))
+	require.False(t, CollectionRoleHasPermission("", PermissionCollectionEdit))
+
+	require.True(t, IsCollectionPermission(PermissionCollectionWhitelist))
+	require.False(t, IsCollectionPermission(PermissionCollectionsAdmin))
+}
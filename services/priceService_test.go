@@ -9,7 +9,7 @@ import (
 )
 
 func TestGetEGLDPrice(t *testing.T) {
-	cache.InitCacher(config.CacheConfig{Url: "redis://localhost:6379"})
+	cache.InitCacher(config.CacheConfig{ReadUrl: "redis://localhost:6379", WriteUrl: "redis://localhost:6379"})
 
 	price, err := GetEGLDPrice()
 	require.Nil(t, err)
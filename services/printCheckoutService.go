@@ -0,0 +1,394 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPrintQuoteExpirySecs = 600
+	printPaymentDataFormat      = "printCheckout:%d"
+	printReferenceIdFormat      = "youbei-print-checkout-%d"
+	// printAmountDecimals is the precision of the EGLD quote, it is rounded up so the provider cost is always covered.
+	printAmountDecimals = 6
+)
+
+var (
+	ErrPrintCheckoutNotOwned    = errors.New("print checkout belongs to another address")
+	ErrPrintPaymentPending      = errors.New("payment transaction is not final yet")
+	ErrPrintPaymentFailed       = errors.New("payment transaction failed")
+	ErrPrintPaymentSender       = errors.New("payment was not sent by the checkout address")
+	ErrPrintPaymentReceiver     = errors.New("payment was not sent to the payment address")
+	ErrPrintPaymentAmount       = errors.New("payment amount is lower than the quote")
+	ErrPrintPaymentData         = errors.New("payment does not reference the checkout")
+	ErrPrintPaymentLate         = errors.New("payment was sent after the quote expired")
+	ErrPrintPaymentTxUsed       = errors.New("payment transaction already used")
+	ErrPrintPaymentNotAvailable = errors.New("print payments are not configured")
+	ErrUnknownPrintVariant      = errors.New("unknown print item variant")
+	ErrInvalidPrintQuantity     = errors.New("invalid print quantity")
+	ErrUnknownShippingMethod    = errors.New("shipping method not available for this country")
+	ErrEmptyPrintOrder          = errors.New("empty print order")
+	ErrPrintOrderNotAccepted    = errors.New("order was not accepted by the fulfillment provider")
+)
+
+type SubmitPrintPaymentRequest struct {
+	TxHash string `json:"txHash"`
+}
+
+// PrintQuote is the provider cost of an order in USD.
+type PrintQuote struct {
+	CostUsd     float64
+	ShippingUsd float64
+}
+
+// PrintPaymentData is the data field the payment transaction of a checkout must carry.
+func PrintPaymentData(checkoutId uint64) string {
+	return fmt.Sprintf(printPaymentDataFormat, checkoutId)
+}
+
+// CreatePrintCheckout checks the buyer owns the printed tokens, quotes the order in EGLD at the current price,
+// adding the creator royalties, and locks the quote for the configured window.
+// Nothing is sent to the fulfillment provider before the payment is verified.
+func CreatePrintCheckout(printCfg config.PrintConfig, api string, marketplaceAddress string, address string, request *PrintOrderRequest, now time.Time) (*entities.PrintCheckout, error) {
+	if printCfg.PaymentAddress == "" {
+		return nil, ErrPrintPaymentNotAvailable
+	}
+
+	order, tokens, err := BuildPrintOrder(api, marketplaceAddress, address, request)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := GetAvailableVariantsHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := quotePrintOrder(order, items, func(region string, itemId int64) (map[string]entities.ShippingMethodResponse, error) {
+		return GetShipmentMethodsAndCostsHandler(region, itemId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	royalties, err := printRoyaltyShares(order, items, tokens, printCfg.CreatorRoyaltyPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	royaltyUsd := 0.0
+	for _, royalty := range royalties {
+		royaltyUsd += royalty.Usd
+	}
+
+	egldPrice, err := GetEGLDPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	amount, amountNominal, err := egldAmountForUsd(quote.CostUsd+quote.ShippingUsd+royaltyUsd, egldPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	orderJson, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	royaltiesJson, err := json.Marshal(royalties)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout := &entities.PrintCheckout{
+		Address:         address,
+		Status:          entities.PrintCheckoutQuoted,
+		Order:           orderJson,
+		CostUsd:         quote.CostUsd,
+		ShippingUsd:     quote.ShippingUsd,
+		RoyaltyUsd:      royaltyUsd,
+		Royalties:       royaltiesJson,
+		EgldPrice:       egldPrice,
+		AmountNominal:   amountNominal,
+		Amount:          amount.String(),
+		PaymentReceiver: printCfg.PaymentAddress,
+		QuoteExpiresAt:  now.Add(printQuoteTTL(printCfg)).Unix(),
+		CreatedAt:       now.Unix(),
+		UpdatedAt:       now.Unix(),
+	}
+	err = storage.AddPrintCheckout(checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkout, nil
+}
+
+func GetPrintCheckoutForAddress(address string, id uint64) (*entities.PrintCheckout, error) {
+	checkout, err := storage.GetPrintCheckoutById(id)
+	if err != nil {
+		return nil, err
+	}
+	if checkout.Address != address {
+		return nil, ErrPrintCheckoutNotOwned
+	}
+
+	return checkout, nil
+}
+
+// SubmitPrintPayment verifies the payment transaction of a quoted checkout and then forwards the order.
+// A paid checkout the provider did not accept is forwarded again, so the call can be retried.
+// The provider order is referenced by the checkout id, so a retry never places a second order.
+func SubmitPrintPayment(api string, checkout *entities.PrintCheckout, txHash string, now time.Time) (*entities.PrintCheckout, error) {
+	if checkout.Status == entities.PrintCheckoutQuoted {
+		if txHash == "" {
+			return nil, errors.New("empty tx hash")
+		}
+
+		used, err := storage.GetPrintCheckoutByPaymentTxHash(txHash)
+		if err == nil && used.ID != checkout.ID {
+			return nil, ErrPrintPaymentTxUsed
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+
+		tx, err := GetTransactionBC(api, txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		err = VerifyPrintPayment(checkout, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		paid, err := storage.SetPrintCheckoutPaid(checkout.ID, txHash, now.Unix())
+		if err != nil {
+			return nil, err
+		}
+		if !paid {
+			return nil, ErrPrintPaymentTxUsed
+		}
+
+		checkout.Status = entities.PrintCheckoutPaid
+		checkout.PaymentTxHash = txHash
+		recordPrintRoyalties(checkout, now)
+	}
+
+	if checkout.Status != entities.PrintCheckoutPaid {
+		return checkout, nil
+	}
+
+	return forwardPrintCheckout(checkout.ID, now)
+}
+
+// VerifyPrintPayment checks a final transaction pays the checkout quote: sender, receiver, amount,
+// the checkout reference in its data and a timestamp within the quote window.
+func VerifyPrintPayment(checkout *entities.PrintCheckout, tx entities.TransactionBC) error {
+	if tx.Status == string(transaction.TxStatusPending) || tx.PendingResults || tx.Status == "" {
+		return ErrPrintPaymentPending
+	}
+	if tx.Status != string(transaction.TxStatusSuccess) {
+		return ErrPrintPaymentFailed
+	}
+	if tx.Sender != checkout.Address {
+		return ErrPrintPaymentSender
+	}
+	if tx.Receiver != checkout.PaymentReceiver {
+		return ErrPrintPaymentReceiver
+	}
+
+	value, ok := big.NewInt(0).SetString(tx.Value, 10)
+	if !ok {
+		return ErrPrintPaymentAmount
+	}
+	amount, ok := big.NewInt(0).SetString(checkout.Amount, 10)
+	if !ok || value.Cmp(amount) < 0 {
+		return ErrPrintPaymentAmount
+	}
+
+	data, err := base64.StdEncoding.DecodeString(tx.Data)
+	if err != nil || string(data) != PrintPaymentData(checkout.ID) {
+		return ErrPrintPaymentData
+	}
+
+	if int64(tx.Timestamp) > checkout.QuoteExpiresAt {
+		return ErrPrintPaymentLate
+	}
+
+	return nil
+}
+
+// PrintCheckoutReferenceId is the reference id of the provider order of a checkout.
+func PrintCheckoutReferenceId(checkoutId uint64) string {
+	return fmt.Sprintf(printReferenceIdFormat, checkoutId)
+}
+
+// forwardPrintCheckout creates the provider order of a paid checkout, unless an earlier attempt already did
+// and only failed to record it. The checkout row stays locked meanwhile, so concurrent calls do not both create it.
+func forwardPrintCheckout(checkoutId uint64, now time.Time) (*entities.PrintCheckout, error) {
+	return storage.ForwardPaidPrintCheckout(checkoutId, func(checkout *entities.PrintCheckout) (*entities.PrintOrder, error) {
+		var order entities.DreamshipOrderItems
+		err := json.Unmarshal(checkout.Order, &order)
+		if err != nil {
+			return nil, err
+		}
+		order.ReferenceId = PrintCheckoutReferenceId(checkout.ID)
+
+		response, err := getOrCreatePrintOrder(order)
+		if err == nil && response.ReferenceId == "" {
+			err = ErrPrintOrderNotAccepted
+		}
+		if err != nil {
+			checkout.Error = err.Error()
+			checkout.UpdatedAt = now.Unix()
+			return nil, err
+		}
+
+		amount, err := strconv.ParseFloat(response.Cost, 64)
+		if err != nil {
+			amount = checkout.CostUsd + checkout.ShippingUsd
+		}
+
+		printOrder, err := NewPrintOrder(checkout, order, response, amount, now)
+		if err != nil {
+			return nil, err
+		}
+
+		checkout.Status = entities.PrintCheckoutSubmitted
+		checkout.ProviderOrderId = response.ReferenceId
+		checkout.Error = ""
+		checkout.UpdatedAt = now.Unix()
+
+		return printOrder, nil
+	})
+}
+
+// getOrCreatePrintOrder returns the provider order with the reference id of the order, creating it only when the provider does not know it.
+func getOrCreatePrintOrder(order entities.DreamshipOrderItems) (entities.ItemWebhook, error) {
+	existing, err := GetSubmitedOrdersStatus(order.ReferenceId)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, fulfillment.ErrOrderNotFound) {
+		return entities.ItemWebhook{}, err
+	}
+
+	return SetOrder(order)
+}
+
+// quotePrintOrder adds up the variant costs of the line items and the most expensive shipping of their items,
+// shipping being charged once per order by the provider.
+func quotePrintOrder(order entities.DreamshipOrderItems, items []entities.DreamshipItems, shipping func(region string, itemId int64) (map[string]entities.ShippingMethodResponse, error)) (*PrintQuote, error) {
+	if len(order.LineItems) == 0 {
+		return nil, ErrEmptyPrintOrder
+	}
+
+	quote := &PrintQuote{}
+	itemIds := map[int64]bool{}
+	for _, lineItem := range order.LineItems {
+		if lineItem.Quantity <= 0 {
+			return nil, ErrInvalidPrintQuantity
+		}
+
+		itemId, variant, ok := findPrintVariant(items, lineItem.ItemVariant)
+		if !ok {
+			return nil, ErrUnknownPrintVariant
+		}
+
+		quote.CostUsd += variant.Cost * float64(lineItem.Quantity)
+		itemIds[itemId] = true
+	}
+
+	region := fulfillment.InternationalShippingRegion
+	if strings.EqualFold(order.Address.Country, "US") {
+		region = fulfillment.USShippingRegion
+	}
+
+	for itemId := range itemIds {
+		methods, err := shipping(region, itemId)
+		if err != nil {
+			return nil, err
+		}
+
+		cost, ok := findShippingCost(methods, order.Address.Country, order.ShippingMethod)
+		if !ok {
+			return nil, ErrUnknownShippingMethod
+		}
+		quote.ShippingUsd = math.Max(quote.ShippingUsd, cost)
+	}
+
+	return quote, nil
+}
+
+func findPrintVariant(items []entities.DreamshipItems, variantId int64) (int64, entities.ItemVariants, bool) {
+	for _, item := range items {
+		for _, variant := range item.ItemVariants {
+			if variant.Id == variantId {
+				return item.Id, variant, true
+			}
+		}
+	}
+
+	return 0, entities.ItemVariants{}, false
+}
+
+func findShippingCost(zones map[string]entities.ShippingMethodResponse, country string, method string) (float64, bool) {
+	for key, zone := range zones {
+		if !strings.EqualFold(key, country) && !strings.EqualFold(zone.Code, country) {
+			continue
+		}
+
+		for _, shippingMethod := range zone.Methods {
+			if shippingMethod.Method == method {
+				return shippingMethod.Cost, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// egldAmountForUsd converts a USD amount at the given EGLD price, rounded up to printAmountDecimals.
+func egldAmountForUsd(usd float64, egldPrice float64) (*big.Int, float64, error) {
+	if egldPrice <= 0 {
+		return nil, 0, errors.New("invalid EGLD price")
+	}
+
+	precision := math.Pow10(printAmountDecimals)
+	return denominatePrintAmount(math.Ceil(usd/egldPrice*precision) / precision)
+}
+
+func denominatePrintAmount(nominal float64) (*big.Int, float64, error) {
+	amount, _, err := convertNominalPrice(strconv.FormatFloat(nominal, 'f', printAmountDecimals, 64))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return amount, nominal, nil
+}
+
+func printQuoteTTL(printCfg config.PrintConfig) time.Duration {
+	secs := printCfg.QuoteExpirySecs
+	if secs == 0 {
+		secs = DefaultPrintQuoteExpirySecs
+	}
+
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func testPrintItems() []entities.DreamshipItems {
+	return []entities.DreamshipItems{{
+		Id: 19,
+		ItemVariants: []entities.ItemVariants{
+			{Id: 1001, Cost: 20},
+			{Id: 1002, Cost: 35.5},
+		},
+	}}
+}
+
+func testPrintShipping(region string, itemId int64) (map[string]entities.ShippingMethodResponse, error) {
+	return map[string]entities.ShippingMethodResponse{
+		"US": {Code: "US", Methods: []entities.ShippingMethod{{Method: "standard", Cost: 7}, {Method: "express", Cost: 15}}},
+		"FR": {Code: "FR", Methods: []entities.ShippingMethod{{Method: "standard", Cost: 12}}},
+	}, nil
+}
+
+func Test_QuotePrintOrder(t *testing.T) {
+	order := entities.DreamshipOrderItems{
+		ShippingMethod: "express",
+		Address:        entities.Address{Country: "US"},
+		LineItems: []entities.LineItem{
+			{ItemVariant: 1001, Quantity: 2},
+			{ItemVariant: 1002, Quantity: 1},
+		},
+	}
+
+	quote, err := quotePrintOrder(order, testPrintItems(), testPrintShipping)
+	require.Nil(t, err)
+	require.Equal(t, 75.5, quote.CostUsd)
+	require.Equal(t, float64(15), quote.ShippingUsd)
+
+	order.Address.Country = "FR"
+	_, err = quotePrintOrder(order, testPrintItems(), testPrintShipping)
+	require.Equal(t, ErrUnknownShippingMethod, err)
+
+	order.LineItems[0].ItemVariant = 9999
+	_, err = quotePrintOrder(order, testPrintItems(), testPrintShipping)
+	require.Equal(t, ErrUnknownPrintVariant, err)
+
+	order.LineItems[0] = entities.LineItem{ItemVariant: 1001, Quantity: 0}
+	_, err = quotePrintOrder(order, testPrintItems(), testPrintShipping)
+	require.Equal(t, ErrInvalidPrintQuantity, err)
+}
+
+func Test_EgldAmountForUsdRoundsUp(t *testing.T) {
+	amount, nominal, err := egldAmountForUsd(100, 30)
+	require.Nil(t, err)
+	require.Equal(t, 3.333334, nominal)
+	require.Equal(t, 1, amount.Cmp(big.NewInt(3333333000000000000)))
+
+	_, _, err = egldAmountForUsd(100, 0)
+	require.NotNil(t, err)
+}
+
+func Test_VerifyPrintPayment(t *testing.T) {
+	checkout := &entities.PrintCheckout{
+		ID:              7,
+		Address:         "erd_buyer",
+		Amount:          "2000",
+		PaymentReceiver: "erd_treasury",
+		QuoteExpiresAt:  1000,
+	}
+	valid := entities.TransactionBC{
+		Sender:    "erd_buyer",
+		Receiver:  "erd_treasury",
+		Value:     "2000",
+		Status:    "success",
+		Data:      base64.StdEncoding.EncodeToString([]byte(PrintPaymentData(7))),
+		Timestamp: 990,
+	}
+	require.Nil(t, VerifyPrintPayment(checkout, valid))
+
+	cases := map[error]func(tx *entities.TransactionBC){
+		ErrPrintPaymentPending:  func(tx *entities.TransactionBC) { tx.Status = "pending" },
+		ErrPrintPaymentFailed:   func(tx *entities.TransactionBC) { tx.Status = "fail" },
+		ErrPrintPaymentSender:   func(tx *entities.TransactionBC) { tx.Sender = "erd_other" },
+		ErrPrintPaymentReceiver: func(tx *entities.TransactionBC) { tx.Receiver = "erd_other" },
+		ErrPrintPaymentAmount:   func(tx *entities.TransactionBC) { tx.Value = "1999" },
+		ErrPrintPaymentData: func(tx *entities.TransactionBC) {
+			tx.Data = base64.StdEncoding.EncodeToString([]byte(PrintPaymentData(8)))
+		},
+		ErrPrintPaymentLate: func(tx *entities.TransactionBC) { tx.Timestamp = 1001 },
+	}
+	for expected, change := range cases {
+		tx := valid
+		change(&tx)
+		require.Equal(t, expected, VerifyPrintPayment(checkout, tx))
+	}
+
+	tx := valid
+	tx.PendingResults = true
+	require.Equal(t, ErrPrintPaymentPending, VerifyPrintPayment(checkout, tx))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxPrintOrderItems      = 20
+	defaultPrintAreaKey     = "front"
+	printImageFileTypeStart = "image/"
+)
+
+var (
+	ErrPrintTokenNotFound = errors.New("token to print not found")
+	ErrPrintTokenNotOwned = errors.New("token to print is not owned by the buyer")
+	ErrPrintTokenNoImage  = errors.New("token to print has no image")
+	ErrTooManyPrintItems  = fmt.Errorf("too many print items, max is %d", MaxPrintOrderItems)
+)
+
+// PrintOrderRequest is a print order as the buyer sends it. Items reference tokens, the printed image is
+// resolved from the token media so only owned artwork can be printed.
+type PrintOrderRequest struct {
+	ShippingMethod string                  `json:"shippingMethod"`
+	Address        entities.Address        `json:"address"`
+	Items          []PrintOrderItemRequest `json:"items"`
+}
+
+type PrintOrderItemRequest struct {
+	TokenId     string `json:"tokenId"`
+	Nonce       uint64 `json:"nonce"`
+	ItemVariant int64  `json:"itemVariant"`
+	Quantity    int64  `json:"quantity"`
+	// PrintArea defaults to front.
+	PrintArea string `json:"printArea"`
+}
+
+// PrintToken is a token the buyer owns and the image printed for it.
+type PrintToken struct {
+	Identifier     string `json:"identifier"`
+	CollectionId   uint64 `json:"collectionId"`
+	CreatorAddress string `json:"creatorAddress"`
+	ImageUrl       string `json:"imageUrl"`
+}
+
+type printTokenMedia struct {
+	Url         string `json:"url"`
+	OriginalUrl string `json:"originalUrl"`
+	FileType    string `json:"fileType"`
+}
+
+// BuildPrintOrder checks every token of the request is owned by the buyer and builds the provider order
+// printing their images. Line items keep the token identifier as reference.
+func BuildPrintOrder(api string, marketplaceAddress string, buyer string, request *PrintOrderRequest) (entities.DreamshipOrderItems, []PrintToken, error) {
+	order := entities.DreamshipOrderItems{
+		ShippingMethod: request.ShippingMethod,
+		Address:        request.Address,
+	}
+	if len(request.Items) == 0 {
+		return order, nil, ErrEmptyPrintOrder
+	}
+	if len(request.Items) > MaxPrintOrderItems {
+		return order, nil, ErrTooManyPrintItems
+	}
+
+	tokens := make([]PrintToken, 0, len(request.Items))
+	for _, item := range request.Items {
+		printToken, err := ResolvePrintToken(api, marketplaceAddress, buyer, item.TokenId, item.Nonce)
+		if err != nil {
+			return order, nil, err
+		}
+
+		printArea := item.PrintArea
+		if printArea == "" {
+			printArea = defaultPrintAreaKey
+		}
+
+		order.LineItems = append(order.LineItems, entities.LineItem{
+			PrintAreas:  []entities.PrintArea{{Key: printArea, Url: printToken.ImageUrl}},
+			ReferenceId: printToken.Identifier,
+			Quantity:    item.Quantity,
+			ItemVariant: item.ItemVariant,
+		})
+		tokens = append(tokens, *printToken)
+	}
+
+	return order, tokens, nil
+}
+
+// ResolvePrintToken checks the buyer owns the token, in the database and then on chain, and returns the image to print.
+func ResolvePrintToken(api string, marketplaceAddress string, buyer string, tokenId string, nonce uint64) (*PrintToken, error) {
+	token, err := storage.GetTokenByTokenIdAndNonce(tokenId, nonce)
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrPrintTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.Owner.Address != buyer {
+		return nil, ErrPrintTokenNotOwned
+	}
+
+	identifier := fmt.Sprintf("%s-%s", token.TokenID, token.NonceStr)
+	response, err := GetResponse(fmt.Sprintf("%s/nfts/%s", api, identifier))
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenBC entities.TokenBC
+	err = json.Unmarshal(response, &tokenBC)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPrintTokenOwner(token, tokenBC, buyer, marketplaceAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	imageUrl := printImageUrl(token, tokenBC)
+	if imageUrl == "" {
+		return nil, ErrPrintTokenNoImage
+	}
+
+	collection, err := storage.GetCollectionById(token.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	creator, err := storage.GetAccountById(collection.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrintToken{
+		Identifier:     identifier,
+		CollectionId:   collection.ID,
+		CreatorAddress: creator.Address,
+		ImageUrl:       imageUrl,
+	}, nil
+}
+
+// checkPrintTokenOwner double checks the owner on chain. A listed token is held by the marketplace contract.
+func checkPrintTokenOwner(token *entities.Token, tokenBC entities.TokenBC, buyer string, marketplaceAddress string) error {
+	if tokenBC.Owner == buyer {
+		return nil
+	}
+	if token.OnSale && marketplaceAddress != "" && tokenBC.Owner == marketplaceAddress {
+		return nil
+	}
+
+	return ErrPrintTokenNotOwned
+}
+
+// printImageUrl prefers the original image of the token media, the indexed image link otherwise.
+func printImageUrl(token *entities.Token, tokenBC entities.TokenBC) string {
+	var medias []printTokenMedia
+	mediaJson, err := json.Marshal(tokenBC.Media)
+	if err == nil {
+		_ = json.Unmarshal(mediaJson, &medias)
+	}
+
+	for _, media := range medias {
+		if !strings.HasPrefix(media.FileType, printImageFileTypeStart) {
+			continue
+		}
+		if media.OriginalUrl != "" {
+			return media.OriginalUrl
+		}
+		if media.Url != "" {
+			return media.Url
+		}
+	}
+
+	return token.ImageLink
+}
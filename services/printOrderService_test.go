@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckPrintTokenOwner(t *testing.T) {
+	token := &entities.Token{}
+
+	err := checkPrintTokenOwner(token, entities.TokenBC{Owner: "erd_buyer"}, "erd_buyer", "erd_market")
+	require.Nil(t, err)
+
+	err = checkPrintTokenOwner(token, entities.TokenBC{Owner: "erd_market"}, "erd_buyer", "erd_market")
+	require.Equal(t, ErrPrintTokenNotOwned, err)
+
+	token.OnSale = true
+	err = checkPrintTokenOwner(token, entities.TokenBC{Owner: "erd_market"}, "erd_buyer", "erd_market")
+	require.Nil(t, err)
+
+	err = checkPrintTokenOwner(token, entities.TokenBC{Owner: "erd_other"}, "erd_buyer", "erd_market")
+	require.Equal(t, ErrPrintTokenNotOwned, err)
+}
+
+func Test_PrintImageUrl(t *testing.T) {
+	token := &entities.Token{ImageLink: "https://index/image.png"}
+
+	tokenBC := entities.TokenBC{Media: []interface{}{
+		map[string]interface{}{"url": "https://media/video.mp4", "fileType": "video/mp4"},
+		map[string]interface{}{"url": "https://media/thumb.png", "originalUrl": "https://ipfs/image.png", "fileType": "image/png"},
+	}}
+	require.Equal(t, "https://ipfs/image.png", printImageUrl(token, tokenBC))
+
+	tokenBC.Media = []interface{}{map[string]interface{}{"url": "https://media/image.jpg", "fileType": "image/jpeg"}}
+	require.Equal(t, "https://media/image.jpg", printImageUrl(token, tokenBC))
+
+	tokenBC.Media = nil
+	require.Equal(t, "https://index/image.png", printImageUrl(token, tokenBC))
+}
+
+func Test_BuildPrintOrderLimits(t *testing.T) {
+	_, _, err := BuildPrintOrder("", "", "erd_buyer", &PrintOrderRequest{})
+	require.Equal(t, ErrEmptyPrintOrder, err)
+
+	request := &PrintOrderRequest{Items: make([]PrintOrderItemRequest, MaxPrintOrderItems+1)}
+	_, _, err = BuildPrintOrder("", "", "erd_buyer", request)
+	require.Equal(t, ErrTooManyPrintItems, err)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/fulfillment"
+	"github.com/ENFT-DAO/youbei-api/storage"
+)
+
+// printOrderTransitions lists where an order can go from each status. Delivered, cancelled and
+// rejected orders are final, a webhook trying to move them is only recorded.
+var printOrderTransitions = map[entities.PrintOrderStatus][]entities.PrintOrderStatus{
+	entities.PrintOrderSubmitted:  {entities.PrintOrderProduction, entities.PrintOrderShipped, entities.PrintOrderCancelled, entities.PrintOrderRejected},
+	entities.PrintOrderProduction: {entities.PrintOrderShipped, entities.PrintOrderCancelled, entities.PrintOrderRejected},
+	entities.PrintOrderShipped:    {entities.PrintOrderDelivered},
+}
+
+// providerPrintOrderStatuses maps the provider order statuses to ours.
+var providerPrintOrderStatuses = map[string]entities.PrintOrderStatus{
+	"pending":             entities.PrintOrderSubmitted,
+	"unfulfilled":         entities.PrintOrderSubmitted,
+	"processing":          entities.PrintOrderProduction,
+	"production":          entities.PrintOrderProduction,
+	"in_production":       entities.PrintOrderProduction,
+	"partially_fulfilled": entities.PrintOrderProduction,
+	"fulfilled":           entities.PrintOrderShipped,
+	"shipped":             entities.PrintOrderShipped,
+	"delivered":           entities.PrintOrderDelivered,
+	"cancelled":           entities.PrintOrderCancelled,
+	"canceled":            entities.PrintOrderCancelled,
+	"rejected":            entities.PrintOrderRejected,
+	"failed":              entities.PrintOrderRejected,
+}
+
+type PrintOrders struct {
+	Total  int64                 `json:"total"`
+	Orders []entities.PrintOrder `json:"orders"`
+}
+
+func CanTransitionPrintOrder(from entities.PrintOrderStatus, to entities.PrintOrderStatus) bool {
+	for _, status := range printOrderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func PrintOrderStatusFromProvider(status string) (entities.PrintOrderStatus, bool) {
+	orderStatus, ok := providerPrintOrderStatuses[strings.ToLower(status)]
+	return orderStatus, ok
+}
+
+// NewPrintOrder builds the order of a checkout the provider accepted, one item per printed line.
+func NewPrintOrder(checkout *entities.PrintCheckout, order entities.DreamshipOrderItems, accepted entities.ItemWebhook, costUsd float64, now time.Time) (*entities.PrintOrder, error) {
+	shippingAddress, err := json.Marshal(order.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]entities.PrintOrderItem, 0, len(order.LineItems))
+	for _, lineItem := range order.LineItems {
+		item := entities.PrintOrderItem{
+			TokenIdentifier: lineItem.ReferenceId,
+			ItemVariant:     lineItem.ItemVariant,
+			Quantity:        lineItem.Quantity,
+		}
+		if len(lineItem.PrintAreas) > 0 {
+			item.PrintArea = lineItem.PrintAreas[0].Key
+			item.ImageUrl = lineItem.PrintAreas[0].Url
+		}
+		items = append(items, item)
+	}
+
+	return &entities.PrintOrder{
+		CheckoutID:      checkout.ID,
+		Address:         checkout.Address,
+		ReferenceId:     accepted.ReferenceId,
+		ProviderOrderId: accepted.Id,
+		Status:          entities.PrintOrderSubmitted,
+		ProviderStatus:  accepted.Status,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingAddress: shippingAddress,
+		Tracking:        []byte("[]"),
+		CostUsd:         costUsd,
+		Items:           items,
+		History: []entities.PrintOrderStatusChange{{
+			To:             entities.PrintOrderSubmitted,
+			ProviderStatus: accepted.Status,
+			CreatedAt:      now.Unix(),
+		}},
+		CreatedAt: now.Unix(),
+		UpdatedAt: now.Unix(),
+	}, nil
+}
+
+// HandlePrintOrderWebhook authenticates a provider webhook and applies it to its order once,
+// a webhook delivered again is acknowledged without changes. It returns whether the order was updated.
+func HandlePrintOrderWebhook(header http.Header, body []byte, now time.Time) (bool, error) {
+	provider, err := fulfillment.GetProviderOrErr()
+	if err != nil {
+		return false, err
+	}
+
+	webhook, err := provider.ParseWebhook(header, body)
+	if err != nil {
+		return false, err
+	}
+
+	hash := sha256.Sum256(body)
+	event := &entities.PrintOrderWebhookEvent{
+		EventKey:    hex.EncodeToString(hash[:]),
+		ReferenceId: webhook.ReferenceId,
+		ReceivedAt:  now.Unix(),
+	}
+
+	return storage.ApplyPrintOrderWebhook(event, func(order *entities.PrintOrder) *entities.PrintOrderStatusChange {
+		return applyPrintOrderWebhook(order, webhook, now)
+	})
+}
+
+// applyPrintOrderWebhook copies the provider status and tracking to the order and moves it
+// to the matching status when the transition is allowed.
+func applyPrintOrderWebhook(order *entities.PrintOrder, webhook entities.ItemWebhook, now time.Time) *entities.PrintOrderStatusChange {
+	order.ProviderStatus = webhook.Status
+	order.UpdatedAt = now.Unix()
+	if webhook.Id != 0 {
+		order.ProviderOrderId = webhook.Id
+	}
+
+	trackings := printOrderTrackings(webhook)
+	if len(trackings) > 0 {
+		tracking, err := json.Marshal(trackings)
+		if err == nil {
+			order.Tracking = tracking
+		}
+	}
+
+	status, ok := PrintOrderStatusFromProvider(webhook.Status)
+	if !ok || status == order.Status {
+		return nil
+	}
+	if !CanTransitionPrintOrder(order.Status, status) {
+		log.Debug("ignored print order transition", "order", order.ID, "from", order.Status, "to", status)
+		return nil
+	}
+
+	change := &entities.PrintOrderStatusChange{
+		OrderID:        order.ID,
+		From:           order.Status,
+		To:             status,
+		ProviderStatus: webhook.Status,
+		CreatedAt:      now.Unix(),
+	}
+	order.Status = status
+
+	return change
+}
+
+func printOrderTrackings(webhook entities.ItemWebhook) []entities.Tracking {
+	var trackings []entities.Tracking
+	for _, orderFulfillment := range webhook.Fulfillments {
+		trackings = append(trackings, orderFulfillment.Trackings...)
+	}
+
+	return trackings
+}
+
+func GetPrintOrders(address string, offset int, limit int) (*PrintOrders, error) {
+	total, err := storage.CountPrintOrdersByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := storage.GetPrintOrdersByAddress(address, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrintOrders{Total: total, Orders: orders}, nil
+}
+
+func GetPrintOrder(address string, id uint64) (*entities.PrintOrder, error) {
+	return storage.GetPrintOrderForAddress(address, id)
+}
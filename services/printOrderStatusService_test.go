@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CanTransitionPrintOrder(t *testing.T) {
+	require.True(t, CanTransitionPrintOrder(entities.PrintOrderSubmitted, entities.PrintOrderProduction))
+	require.True(t, CanTransitionPrintOrder(entities.PrintOrderProduction, entities.PrintOrderShipped))
+	require.True(t, CanTransitionPrintOrder(entities.PrintOrderShipped, entities.PrintOrderDelivered))
+	require.False(t, CanTransitionPrintOrder(entities.PrintOrderShipped, entities.PrintOrderProduction))
+	require.False(t, CanTransitionPrintOrder(entities.PrintOrderDelivered, entities.PrintOrderCancelled))
+	require.False(t, CanTransitionPrintOrder(entities.PrintOrderCancelled, entities.PrintOrderShipped))
+}
+
+func Test_ApplyPrintOrderWebhook(t *testing.T) {
+	now := time.Unix(1000, 0)
+	order := &entities.PrintOrder{ID: 4, Status: entities.PrintOrderSubmitted}
+
+	change := applyPrintOrderWebhook(order, entities.ItemWebhook{Id: 77, Status: "in_production"}, now)
+	require.NotNil(t, change)
+	require.Equal(t, entities.PrintOrderSubmitted, change.From)
+	require.Equal(t, entities.PrintOrderProduction, change.To)
+	require.Equal(t, entities.PrintOrderProduction, order.Status)
+	require.Equal(t, uint64(77), order.ProviderOrderId)
+
+	shipped := entities.ItemWebhook{
+		Status: "shipped",
+		Fulfillments: []entities.Fulfillments{{
+			Trackings: []entities.Tracking{{TrackingNumber: "1Z", Carrier: "UPS", CarrierUrl: "https://ups/1Z"}},
+		}},
+	}
+	change = applyPrintOrderWebhook(order, shipped, now)
+	require.NotNil(t, change)
+	require.Equal(t, entities.PrintOrderShipped, order.Status)
+
+	var trackings []entities.Tracking
+	require.Nil(t, json.Unmarshal(order.Tracking, &trackings))
+	require.Equal(t, "https://ups/1Z", trackings[0].CarrierUrl)
+
+	change = applyPrintOrderWebhook(order, entities.ItemWebhook{Status: "production"}, now)
+	require.Nil(t, change)
+	require.Equal(t, entities.PrintOrderShipped, order.Status)
+	require.Equal(t, "production", order.ProviderStatus)
+
+	change = applyPrintOrderWebhook(order, entities.ItemWebhook{Status: "on_hold"}, now)
+	require.Nil(t, change)
+	require.Equal(t, entities.PrintOrderShipped, order.Status)
+}
+
+func Test_NewPrintOrder(t *testing.T) {
+	checkout := &entities.PrintCheckout{ID: 9, Address: "erd_buyer"}
+	order := entities.DreamshipOrderItems{
+		ShippingMethod: "standard",
+		Address:        entities.Address{Country: "FR"},
+		LineItems: []entities.LineItem{{
+			ReferenceId: "TKN-aaaa-01",
+			ItemVariant: 1001,
+			Quantity:    2,
+			PrintAreas:  []entities.PrintArea{{Key: "front", Url: "https://ipfs/image.png"}},
+		}},
+	}
+
+	printOrder, err := NewPrintOrder(checkout, order, entities.ItemWebhook{Id: 5, ReferenceId: "ref", Status: "pending"}, 47, time.Unix(1000, 0))
+	require.Nil(t, err)
+	require.Equal(t, "ref", printOrder.ReferenceId)
+	require.Equal(t, entities.PrintOrderSubmitted, printOrder.Status)
+	require.Equal(t, "TKN-aaaa-01", printOrder.Items[0].TokenIdentifier)
+	require.Equal(t, "https://ipfs/image.png", printOrder.Items[0].ImageUrl)
+	require.Len(t, printOrder.History, 1)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"go.uber.org/zap"
+)
+
+var ErrPrintRoyaltyPaid = errors.New("print royalty already paid")
+
+// PrintRoyaltyShare is the royalty a checkout owes to the creator of one printed collection.
+type PrintRoyaltyShare struct {
+	CollectionId   uint64  `json:"collectionId"`
+	CreatorAddress string  `json:"creatorAddress"`
+	Usd            float64 `json:"usd"`
+}
+
+type MarkPrintRoyaltyPaidRequest struct {
+	TxHash string `json:"txHash"`
+}
+
+func GetCreatorPrintRoyalties(creatorAddress string, status entities.PrintRoyaltyStatus, offset int, limit int) ([]entities.PrintRoyalty, error) {
+	return storage.GetPrintRoyaltiesByCreator(creatorAddress, status, offset, limit)
+}
+
+// MarkPrintRoyaltyPaid verifies the payout transaction sent from the print payment address to the creator
+// and closes the royalty.
+func MarkPrintRoyaltyPaid(printCfg config.PrintConfig, api string, royaltyId uint64, txHash string, now time.Time) (*entities.PrintRoyalty, error) {
+	if txHash == "" {
+		return nil, errors.New("empty tx hash")
+	}
+
+	royalty, err := storage.GetPrintRoyaltyById(royaltyId)
+	if err != nil {
+		return nil, err
+	}
+	if royalty.Status != entities.PrintRoyaltyOwed {
+		return nil, ErrPrintRoyaltyPaid
+	}
+
+	tx, err := GetTransactionBC(api, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = VerifyPrintRoyaltyPayout(royalty, printCfg.PaymentAddress, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	paid, err := storage.SetPrintRoyaltyPaid(royalty.ID, txHash, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	if !paid {
+		return nil, ErrPrintRoyaltyPaid
+	}
+
+	royalty.Status = entities.PrintRoyaltyPaid
+	royalty.PaidTxHash = txHash
+	royalty.PaidAt = now.Unix()
+	return royalty, nil
+}
+
+// VerifyPrintRoyaltyPayout checks a final transaction pays the royalty amount to its creator from the payment address.
+func VerifyPrintRoyaltyPayout(royalty *entities.PrintRoyalty, paymentAddress string, tx entities.TransactionBC) error {
+	if tx.Status == string(transaction.TxStatusPending) || tx.PendingResults || tx.Status == "" {
+		return ErrPrintPaymentPending
+	}
+	if tx.Status != string(transaction.TxStatusSuccess) {
+		return ErrPrintPaymentFailed
+	}
+	if tx.Sender != paymentAddress {
+		return ErrPrintPaymentSender
+	}
+	if tx.Receiver != royalty.CreatorAddress {
+		return ErrPrintPaymentReceiver
+	}
+
+	value, ok := big.NewInt(0).SetString(tx.Value, 10)
+	if !ok {
+		return ErrPrintPaymentAmount
+	}
+	amount, ok := big.NewInt(0).SetString(royalty.Amount, 10)
+	if !ok || value.Cmp(amount) < 0 {
+		return ErrPrintPaymentAmount
+	}
+
+	return nil
+}
+
+// printRoyaltyShares computes the royalty of every printed collection, a percent of the variant cost of its line items.
+// Line items and tokens share their index.
+func printRoyaltyShares(order entities.DreamshipOrderItems, items []entities.DreamshipItems, tokens []PrintToken, percent float64) ([]PrintRoyaltyShare, error) {
+	shares := []PrintRoyaltyShare{}
+	if percent <= 0 {
+		return shares, nil
+	}
+	if len(tokens) != len(order.LineItems) {
+		return nil, errors.New("print tokens do not match the line items")
+	}
+
+	indexes := map[uint64]int{}
+	for index, lineItem := range order.LineItems {
+		_, variant, ok := findPrintVariant(items, lineItem.ItemVariant)
+		if !ok {
+			return nil, ErrUnknownPrintVariant
+		}
+
+		token := tokens[index]
+		usd := variant.Cost * float64(lineItem.Quantity) * percent / 100
+		shareIndex, ok := indexes[token.CollectionId]
+		if !ok {
+			shareIndex = len(shares)
+			indexes[token.CollectionId] = shareIndex
+			shares = append(shares, PrintRoyaltyShare{CollectionId: token.CollectionId, CreatorAddress: token.CreatorAddress})
+		}
+		shares[shareIndex].Usd += usd
+	}
+
+	for index := range shares {
+		shares[index].Usd = math.Round(shares[index].Usd*100) / 100
+	}
+
+	return shares, nil
+}
+
+// printRoyaltiesOf converts the royalty shares of a paid checkout to EGLD at its quote price, rounded down.
+func printRoyaltiesOf(checkout *entities.PrintCheckout, now time.Time) ([]entities.PrintRoyalty, error) {
+	var shares []PrintRoyaltyShare
+	if len(checkout.Royalties) == 0 {
+		return nil, nil
+	}
+
+	err := json.Unmarshal(checkout.Royalties, &shares)
+	if err != nil {
+		return nil, err
+	}
+	if checkout.EgldPrice <= 0 {
+		return nil, errors.New("invalid EGLD price")
+	}
+
+	precision := math.Pow10(printAmountDecimals)
+	royalties := make([]entities.PrintRoyalty, 0, len(shares))
+	for _, share := range shares {
+		amount, nominal, err := denominatePrintAmount(math.Floor(share.Usd/checkout.EgldPrice*precision) / precision)
+		if err != nil {
+			return nil, err
+		}
+
+		royalties = append(royalties, entities.PrintRoyalty{
+			CheckoutID:     checkout.ID,
+			CollectionID:   share.CollectionId,
+			CreatorAddress: share.CreatorAddress,
+			Usd:            share.Usd,
+			AmountNominal:  nominal,
+			Amount:         amount.String(),
+			Status:         entities.PrintRoyaltyOwed,
+			CreatedAt:      now.Unix(),
+		})
+	}
+
+	return royalties, nil
+}
+
+// recordPrintRoyalties adds the royalties of a checkout once its payment is recorded.
+// Failures are logged, the payment itself stays valid.
+func recordPrintRoyalties(checkout *entities.PrintCheckout, now time.Time) {
+	royalties, err := printRoyaltiesOf(checkout, now)
+	if err == nil {
+		err = storage.AddPrintRoyalties(royalties)
+	}
+	if err != nil {
+		zlog.Error("could not record print royalties", zap.Uint64("checkout", checkout.ID), zap.Error(err))
+	}
+}
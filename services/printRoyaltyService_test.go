@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PrintRoyaltyShares(t *testing.T) {
+	order := entities.DreamshipOrderItems{
+		LineItems: []entities.LineItem{
+			{ItemVariant: 1001, Quantity: 2},
+			{ItemVariant: 1002, Quantity: 1},
+			{ItemVariant: 1001, Quantity: 1},
+		},
+	}
+	tokens := []PrintToken{
+		{CollectionId: 1, CreatorAddress: "erd_creator1"},
+		{CollectionId: 2, CreatorAddress: "erd_creator2"},
+		{CollectionId: 1, CreatorAddress: "erd_creator1"},
+	}
+
+	shares, err := printRoyaltyShares(order, testPrintItems(), tokens, 10)
+	require.Nil(t, err)
+	require.Equal(t, []PrintRoyaltyShare{
+		{CollectionId: 1, CreatorAddress: "erd_creator1", Usd: 6},
+		{CollectionId: 2, CreatorAddress: "erd_creator2", Usd: 3.55},
+	}, shares)
+
+	shares, err = printRoyaltyShares(order, testPrintItems(), tokens, 0)
+	require.Nil(t, err)
+	require.Empty(t, shares)
+
+	_, err = printRoyaltyShares(order, testPrintItems(), tokens[:1], 10)
+	require.NotNil(t, err)
+}
+
+func Test_PrintRoyaltiesOfRoundsDown(t *testing.T) {
+	royaltiesJson, err := json.Marshal([]PrintRoyaltyShare{{CollectionId: 1, CreatorAddress: "erd_creator", Usd: 10}})
+	require.Nil(t, err)
+
+	checkout := &entities.PrintCheckout{ID: 3, EgldPrice: 30, Royalties: royaltiesJson}
+	royalties, err := printRoyaltiesOf(checkout, time.Unix(100, 0))
+	require.Nil(t, err)
+	require.Len(t, royalties, 1)
+	require.Equal(t, 0.333333, royalties[0].AmountNominal)
+	require.Equal(t, "333333000000000000", royalties[0].Amount)
+	require.Equal(t, entities.PrintRoyaltyOwed, royalties[0].Status)
+	require.Equal(t, uint64(3), royalties[0].CheckoutID)
+}
+
+func Test_VerifyPrintRoyaltyPayout(t *testing.T) {
+	royalty := &entities.PrintRoyalty{CreatorAddress: "erd_creator", Amount: "1000"}
+	valid := entities.TransactionBC{Sender: "erd_treasury", Receiver: "erd_creator", Value: "1000", Status: "success"}
+
+	require.Nil(t, VerifyPrintRoyaltyPayout(royalty, "erd_treasury", valid))
+
+	tx := valid
+	tx.Status = "pending"
+	require.Equal(t, ErrPrintPaymentPending, VerifyPrintRoyaltyPayout(royalty, "erd_treasury", tx))
+
+	tx = valid
+	tx.Sender = "erd_other"
+	require.Equal(t, ErrPrintPaymentSender, VerifyPrintRoyaltyPayout(royalty, "erd_treasury", tx))
+
+	tx = valid
+	tx.Receiver = "erd_other"
+	require.Equal(t, ErrPrintPaymentReceiver, VerifyPrintRoyaltyPayout(royalty, "erd_treasury", tx))
+
+	tx = valid
+	tx.Value = "999"
+	require.Equal(t, ErrPrintPaymentAmount, VerifyPrintRoyaltyPayout(royalty, "erd_treasury", tx))
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"go.uber.org/zap"
+)
+
+const tokenHistoryPageSize = 50
+
+// after is inclusive, so the transactions already indexed at the cursor timestamp are skipped with from.
+var getTokenHistoryTransactionsAPI = "%s/nfts/%s/transactions?after=%d&from=%d&size=%d&order=asc&withScResults=true"
+
+// ProvenanceEntry is one change of state in the history of a token.
+type ProvenanceEntry struct {
+	Kind          entities.TokenEventKind `json:"kind"`
+	TxHash        string                  `json:"txHash"`
+	Timestamp     uint64                  `json:"timestamp"`
+	FromAddress   string                  `json:"fromAddress"`
+	ToAddress     string                  `json:"toAddress"`
+	AmountNominal float64                 `json:"amountNominal"`
+	AmountString  string                  `json:"amountString"`
+}
+
+type TokenProvenance struct {
+	Total   int64             `json:"total"`
+	Entries []ProvenanceEntry `json:"entries"`
+}
+
+// RecordTokenEvent keeps a change of state of a token for its provenance. Failures are only logged,
+// the provenance is never worth failing the change it records.
+func RecordTokenEvent(event entities.TokenEvent) {
+	event.CreatedAt = time.Now().Unix()
+	err := storage.AddTokenEvent(&event)
+	if err != nil {
+		zlog.Error("could not store token event",
+			zap.Uint64("token", event.TokenID),
+			zap.String("kind", string(event.Kind)),
+			zap.String("tx_hash", event.TxHash),
+			zap.Error(err))
+	}
+}
+
+// GetTokenProvenance lists the changes of state of a token, oldest first.
+// Transactions, offers and bids from before the events were recorded fill in the history.
+func GetTokenProvenance(token *entities.Token, offset int, limit int) (*TokenProvenance, error) {
+	events, err := storage.GetTokenEventsByTokenId(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := storage.GetTransactionsWithAccountsByTokenId(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := storage.GetOffersForTokenId(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bids, err := storage.GetBidsForTokenId(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := buildTokenProvenance(events, transactions, offers, bids)
+	provenance := TokenProvenance{
+		Total:   int64(len(entries)),
+		Entries: []ProvenanceEntry{},
+	}
+	if offset < len(entries) {
+		end := offset + limit
+		if end > len(entries) {
+			end = len(entries)
+		}
+		provenance.Entries = entries[offset:end]
+	}
+
+	return &provenance, nil
+}
+
+// buildTokenProvenance merges the recorded events with the transactions, offers and bids of a token.
+// Those are only kept when no event was recorded for their transaction.
+// A listing of a token that is already listed is a price change.
+func buildTokenProvenance(
+	events []entities.TokenEvent,
+	transactions []entities.Transaction,
+	offers []entities.Offer,
+	bids []entities.Bid,
+) []ProvenanceEntry {
+	recorded := map[string]bool{}
+	entries := make([]ProvenanceEntry, 0, len(events)+len(transactions)+len(offers)+len(bids))
+	for _, event := range events {
+		if event.TxHash != "" {
+			recorded[event.TxHash] = true
+		}
+		entries = append(entries, ProvenanceEntry{
+			Kind:          event.Kind,
+			TxHash:        event.TxHash,
+			Timestamp:     event.Timestamp,
+			FromAddress:   event.FromAddress,
+			ToAddress:     event.ToAddress,
+			AmountNominal: event.AmountNominal,
+			AmountString:  event.AmountString,
+		})
+	}
+
+	for _, transaction := range transactions {
+		kind, ok := transactionEventKind(transaction.Type)
+		if !ok || recorded[transaction.Hash] {
+			continue
+		}
+
+		entry := ProvenanceEntry{
+			Kind:          kind,
+			TxHash:        transaction.Hash,
+			Timestamp:     transaction.Timestamp,
+			FromAddress:   transaction.Seller.Address,
+			AmountNominal: transaction.PriceNominal,
+		}
+		if kind == entities.TokenEventSale {
+			entry.ToAddress = transaction.Buyer.Address
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, offer := range offers {
+		if recorded[offer.TxHash] {
+			continue
+		}
+
+		entries = append(entries, ProvenanceEntry{
+			Kind:          entities.TokenEventOffer,
+			TxHash:        offer.TxHash,
+			Timestamp:     offer.Timestamp,
+			FromAddress:   offer.OfferorAddress,
+			AmountNominal: offer.AmountNominal,
+			AmountString:  offer.AmountString,
+		})
+	}
+
+	for _, bid := range bids {
+		if recorded[bid.TxHash] {
+			continue
+		}
+
+		entries = append(entries, ProvenanceEntry{
+			Kind:          entities.TokenEventBid,
+			TxHash:        bid.TxHash,
+			Timestamp:     bid.Timestamp,
+			FromAddress:   bid.BidderAddress,
+			AmountNominal: bid.BidAmountNominal,
+			AmountString:  bid.BidAmountString,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+
+	listed := false
+	for index := range entries {
+		switch entries[index].Kind {
+		case entities.TokenEventListing:
+			if listed {
+				entries[index].Kind = entities.TokenEventPriceChange
+			}
+			listed = true
+		case entities.TokenEventDelisting,
+			entities.TokenEventAuctionStart,
+			entities.TokenEventAuctionEnd,
+			entities.TokenEventSale,
+			entities.TokenEventTransfer:
+			listed = false
+		}
+	}
+
+	return entries
+}
+
+// RequestTokenHistory queues the token for the history indexer, which reads its new transactions.
+// Failures are only logged, like the events they would record.
+func RequestTokenHistory(tokenId uint64) {
+	err := storage.RequestTokenHistory(tokenId, time.Now().UnixMilli())
+	if err != nil {
+		zlog.Error("could not request token history", zap.Uint64("token", tokenId), zap.Error(err))
+	}
+}
+
+// IndexTokenHistory records the mint and the transfers between wallets of a token from one page of its
+// transactions on chain, after its cursor. Indexing stops at the first pending transaction so it is picked up again.
+// Transfers to and from contracts are left to the marketplace indexer, which knows what they stand for.
+func IndexTokenHistory(cursor *entities.TokenHistoryCursor, api string) error {
+	token, err := storage.GetTokenById(cursor.TokenID)
+	if err != nil {
+		return err
+	}
+
+	identifier := token.TokenID + "-" + token.NonceStr
+	url := fmt.Sprintf(getTokenHistoryTransactionsAPI, api, identifier, cursor.LastTimestamp, cursor.LastTimestampTxs, tokenHistoryPageSize)
+	res, err := GetResponse(url)
+	if err != nil {
+		return err
+	}
+
+	var txs []entities.TransactionBC
+	err = json.Unmarshal(res, &txs)
+	if err != nil {
+		return err
+	}
+
+	done := len(txs) < tokenHistoryPageSize
+	for _, tx := range txs {
+		if tx.Status == string(transaction.TxStatusPending) || tx.PendingResults {
+			done = false
+			break
+		}
+		if tx.Timestamp != cursor.LastTimestamp {
+			cursor.LastTimestamp = tx.Timestamp
+			cursor.LastTimestampTxs = 0
+		}
+		cursor.LastTimestampTxs++
+
+		for _, event := range parseTokenHistoryEvents(token, tx) {
+			RecordTokenEvent(event)
+		}
+	}
+
+	return storage.UpdateTokenHistoryCursor(cursor, done)
+}
+
+// recordMintEvents records the mint of every token of a mint transaction that is already indexed.
+// The others get theirs from the history indexer once they are.
+func recordMintEvents(tx entities.TransactionBC) {
+	for _, transfer := range parseNftTransfers(tx.Results) {
+		if transfer.ToContract {
+			continue
+		}
+
+		token, err := storage.GetTokenByTokenIdAndNonce(transfer.TokenId, transfer.Nonce)
+		if err != nil {
+			continue
+		}
+
+		for _, event := range parseTokenHistoryEvents(token, tx) {
+			RecordTokenEvent(event)
+		}
+	}
+}
+
+// parseTokenHistoryEvents reads the mint or the transfers between wallets of the token in a transaction.
+// The token is minted to whoever receives it from a mint, as minting through the marketplace
+// may not be sent by the minter.
+func parseTokenHistoryEvents(token *entities.Token, tx entities.TransactionBC) []entities.TokenEvent {
+	if tx.Status != string(transaction.TxStatusSuccess) || tx.PendingResults {
+		return nil
+	}
+
+	isMint := tx.Function == mintTokensFunctionName || tx.Function == mintTokensThroughMarketplaceFunctionName
+	// a transfer sent from a wallet is the data of the transaction itself
+	results := append([]entities.SCResult{{Sender: tx.Sender, Data: tx.Data}}, tx.Results...)
+
+	var events []entities.TokenEvent
+	for _, transfer := range parseNftTransfers(results) {
+		if transfer.TokenId != token.TokenID || transfer.Nonce != token.Nonce || transfer.ToContract {
+			continue
+		}
+
+		event := entities.TokenEvent{
+			TokenID:     token.ID,
+			Kind:        entities.TokenEventTransfer,
+			TxHash:      tx.TxHash,
+			Timestamp:   tx.Timestamp,
+			FromAddress: transfer.Sender,
+			ToAddress:   transfer.Receiver,
+		}
+		if isMint {
+			event.Kind = entities.TokenEventMint
+			event.FromAddress = ""
+		} else if transfer.FromContract {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func transactionEventKind(txType entities.TxType) (entities.TokenEventKind, bool) {
+	switch txType {
+	case entities.ListToken:
+		return entities.TokenEventListing, true
+	case entities.WithdrawToken:
+		return entities.TokenEventDelisting, true
+	case entities.AuctionToken:
+		return entities.TokenEventAuctionStart, true
+	case entities.BuyToken:
+		return entities.TokenEventSale, true
+	case entities.TxStake:
+		return entities.TokenEventStake, true
+	}
+
+	return "", false
+}
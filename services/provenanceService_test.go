@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BuildTokenProvenanceMergesHistoryInOrder(t *testing.T) {
+	t.Parallel()
+
+	events := []entities.TokenEvent{
+		{Kind: entities.TokenEventMint, TxHash: "mint", Timestamp: 2, ToAddress: "erd_minter"},
+		{Kind: entities.TokenEventListing, TxHash: "list", Timestamp: 10, FromAddress: "erd_seller", AmountNominal: 2},
+		{Kind: entities.TokenEventListing, TxHash: "relist", Timestamp: 20, FromAddress: "erd_seller", AmountNominal: 1.5},
+		{Kind: entities.TokenEventSale, TxHash: "buy", Timestamp: 40, FromAddress: "erd_seller", ToAddress: "erd_buyer", AmountNominal: 1.5},
+		{Kind: entities.TokenEventListing, TxHash: "resell", Timestamp: 60, FromAddress: "erd_buyer", AmountNominal: 3},
+	}
+	transactions := []entities.Transaction{
+		{Type: entities.ListToken, Hash: "list", Timestamp: 10, PriceNominal: 2, Seller: entities.Account{Address: "erd_seller"}},
+		{Type: entities.BuyToken, Hash: "buy", Timestamp: 40, PriceNominal: 1.5, Seller: entities.Account{Address: "erd_seller"}},
+		{Type: entities.ListToken, Hash: "old", Timestamp: 5, PriceNominal: 4, Seller: entities.Account{Address: "erd_seller"}},
+		{Type: entities.None, Hash: "none", Timestamp: 6},
+	}
+	offers := []entities.Offer{{TxHash: "offer", Timestamp: 30, OfferorAddress: "erd_offeror", AmountNominal: 1}}
+	bids := []entities.Bid{{TxHash: "bid", Timestamp: 35, BidderAddress: "erd_bidder", BidAmountNominal: 1.2}}
+
+	entries := buildTokenProvenance(events, transactions, offers, bids)
+
+	kinds := make([]entities.TokenEventKind, len(entries))
+	hashes := make([]string, len(entries))
+	for index, entry := range entries {
+		kinds[index] = entry.Kind
+		hashes[index] = entry.TxHash
+	}
+	require.Equal(t, []string{"mint", "old", "list", "relist", "offer", "bid", "buy", "resell"}, hashes)
+	require.Equal(t, []entities.TokenEventKind{
+		entities.TokenEventMint,
+		entities.TokenEventListing,
+		entities.TokenEventPriceChange,
+		entities.TokenEventPriceChange,
+		entities.TokenEventOffer,
+		entities.TokenEventBid,
+		entities.TokenEventSale,
+		entities.TokenEventListing,
+	}, kinds)
+	require.Equal(t, "erd_minter", entries[0].ToAddress)
+	require.Equal(t, "erd_buyer", entries[6].ToAddress)
+	require.Equal(t, "erd_offeror", entries[4].FromAddress)
+}
+
+func Test_BuildTokenProvenanceLegacySaleHasBuyer(t *testing.T) {
+	t.Parallel()
+
+	transactions := []entities.Transaction{
+		{Type: entities.AuctionToken, Hash: "auction", Timestamp: 10, Seller: entities.Account{Address: "erd_seller"}},
+		{Type: entities.BuyToken, Hash: "end", Timestamp: 20, PriceNominal: 5,
+			Seller: entities.Account{Address: "erd_seller"}, Buyer: entities.Account{Address: "erd_winner"}},
+		{Type: entities.WithdrawToken, Hash: "withdraw", Timestamp: 30, Seller: entities.Account{Address: "erd_winner"}},
+	}
+
+	entries := buildTokenProvenance(nil, transactions, nil, nil)
+
+	require.Len(t, entries, 3)
+	require.Equal(t, entities.TokenEventAuctionStart, entries[0].Kind)
+	require.Equal(t, entities.TokenEventSale, entries[1].Kind)
+	require.Equal(t, "erd_seller", entries[1].FromAddress)
+	require.Equal(t, "erd_winner", entries[1].ToAddress)
+	require.Equal(t, entities.TokenEventDelisting, entries[2].Kind)
+	require.Empty(t, entries[2].ToAddress)
+}
+
+func Test_ParseTokenHistoryEvents(t *testing.T) {
+	t.Parallel()
+
+	tokenHex := hex.EncodeToString([]byte("COL-abcdef"))
+	minterHex := strings.Repeat("11", 32)
+	holderHex := strings.Repeat("22", 32)
+	marketplaceHex := "0000000000000000" + strings.Repeat("33", 24)
+	minter, err := ConvertHexToBehc32(minterHex)
+	require.Nil(t, err)
+	holder, err := ConvertHexToBehc32(holderHex)
+	require.Nil(t, err)
+	token := &entities.Token{ID: 9, TokenID: "COL-abcdef", Nonce: 5}
+
+	mintTx := entities.TransactionBC{
+		TxHash:    "mint",
+		Sender:    minter,
+		Data:      base64.StdEncoding.EncodeToString([]byte("mintTokens@02")),
+		Status:    "success",
+		Function:  "mintTokens",
+		Timestamp: 100,
+		Results: []entities.SCResult{
+			{Data: base64.StdEncoding.EncodeToString([]byte("ESDTNFTTransfer@" + tokenHex + "@05@01@" + minterHex))},
+			{Data: base64.StdEncoding.EncodeToString([]byte("ESDTNFTTransfer@" + tokenHex + "@06@01@" + minterHex))},
+		},
+	}
+	events := parseTokenHistoryEvents(token, mintTx)
+	require.Equal(t, []entities.TokenEvent{
+		{TokenID: 9, Kind: entities.TokenEventMint, TxHash: "mint", Timestamp: 100, ToAddress: minter},
+	}, events)
+
+	transferTx := entities.TransactionBC{
+		TxHash:    "transfer",
+		Sender:    minter,
+		Receiver:  minter,
+		Data:      base64.StdEncoding.EncodeToString([]byte("ESDTNFTTransfer@" + tokenHex + "@05@01@" + holderHex)),
+		Status:    "success",
+		Function:  "ESDTNFTTransfer",
+		Timestamp: 200,
+	}
+	events = parseTokenHistoryEvents(token, transferTx)
+	require.Equal(t, []entities.TokenEvent{
+		{TokenID: 9, Kind: entities.TokenEventTransfer, TxHash: "transfer", Timestamp: 200, FromAddress: minter, ToAddress: holder},
+	}, events)
+
+	transferTx.Status = "fail"
+	require.Empty(t, parseTokenHistoryEvents(token, transferTx))
+
+	listTx := transferTx
+	listTx.Status = "success"
+	listTx.Data = base64.StdEncoding.EncodeToString([]byte("ESDTNFTTransfer@" + tokenHex + "@05@01@" + marketplaceHex + "@" + hex.EncodeToString([]byte("putNftForSale"))))
+	require.Empty(t, parseTokenHistoryEvents(token, listTx))
+}
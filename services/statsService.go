@@ -1,10 +1,14 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/ENFT-DAO/youbei-api/cache"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
 )
 
 func GetAllTransactionsWithPagination(args GetAllTransactionsWithPaginationArgs) ([]entities.TransactionDetail, int64, error) {
@@ -51,3 +55,28 @@ func GetAllTransactionsWithPagination(args GetAllTransactionsWithPaginationArgs)
 
 	return transactions, total, nil
 }
+
+// GetVolumeHistory reads the volume rollups of the periods between from and to.
+// A zero collectionId returns the totals of the whole marketplace.
+func GetVolumeHistory(period entities.RollupPeriod, from time.Time, to time.Time, collectionId uint64) ([]entities.AggregatedVolumeRollup, error) {
+	keyOf := utils.DayKey
+	switch period {
+	case entities.RollupDay:
+	case entities.RollupWeek:
+		keyOf = utils.WeekKey
+	case entities.RollupMonth:
+		keyOf = utils.MonthKey
+	default:
+		return nil, errors.New("invalid rollup period")
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("invalid date range")
+	}
+
+	if collectionId == 0 {
+		return storage.GetMarketVolumeRollups(period, keyOf(from), keyOf(to))
+	}
+
+	return storage.GetCollectionVolumeRollups(collectionId, period, keyOf(from), keyOf(to))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/ENFT-DAO/youbei-api/stats/collstats"
 	"github.com/ENFT-DAO/youbei-api/storage"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 	"github.com/boltdb/bolt"
 )
@@ -30,6 +31,11 @@ var (
 	TokenSearchExpirePeriod   = 5 * time.Minute
 )
 
+const (
+	stakeNFTFunctionName   = "stakeAddressNFT"
+	unstakeNFTFunctionName = "unstakeAddressNFT"
+)
+
 type ListTokenRequest struct {
 	TxHash        string  `json:"txHash"`
 	UserAddress   string  `json:"walletAddress"`
@@ -442,6 +448,7 @@ func WithdrawToken(args WithdrawTokenArgs) {
 		log.Debug("could not update token", "err", err)
 		return
 	}
+	refreshCollectionFloorPrice(token.CollectionID)
 
 	err = storage.DeleteOffersForTokenId(token.ID)
 	if err != nil {
@@ -611,6 +618,7 @@ func ListToken(args ListTokenArgs, blockchainProxy string, marketplaceAddress st
 		log.Debug("could not create or update token", "err", innerErr)
 		return
 	}
+	refreshCollectionFloorPrice(token.CollectionID)
 
 	// Indexer is safer till later review
 	// transaction := entities.Transaction{
@@ -627,7 +635,7 @@ func ListToken(args ListTokenArgs, blockchainProxy string, marketplaceAddress st
 	// AddTransaction(&transaction)
 }
 
-func StakeToken(args StakeTokenArgs, blockchainProxy string, marketplaceAddress string) {
+func StakeToken(args StakeTokenArgs, blockchainApi string, stakingAddress string) {
 
 	var err error
 
@@ -662,6 +670,28 @@ func StakeToken(args StakeTokenArgs, blockchainProxy string, marketplaceAddress
 		return
 	}
 
+	tx, err := GetTransactionBC(blockchainApi, args.TxHash)
+	if err != nil {
+		log.Debug("could not get stake transaction", "tx", args.TxHash, "err", err)
+		return
+	}
+	if !isStakeTransaction(args, tx, stakingAddress) {
+		log.Debug("stake transaction does not match the request", "tx", args.TxHash)
+		return
+	}
+
+	stakeKind := entities.TokenEventStake
+	if !args.OnStake {
+		stakeKind = entities.TokenEventUnstake
+	}
+	RecordTokenEvent(entities.TokenEvent{
+		TokenID:     token.ID,
+		Kind:        stakeKind,
+		TxHash:      tx.TxHash,
+		Timestamp:   tx.Timestamp,
+		FromAddress: tx.Sender,
+	})
+
 	// Indexer is safer till later review
 	// transaction := entities.Transaction{
 	// 	Hash:         args.TxHash,
@@ -677,6 +707,34 @@ func StakeToken(args StakeTokenArgs, blockchainProxy string, marketplaceAddress
 	// AddTransaction(&transaction)
 }
 
+// isStakeTransaction checks the transaction is a successful stake, or unstake, of the token by its owner.
+func isStakeTransaction(args StakeTokenArgs, tx entities.TransactionBC, stakingAddress string) bool {
+	if tx.Status != string(transaction.TxStatusSuccess) || tx.Sender != args.OwnerAddress || tx.Receiver != stakingAddress {
+		return false
+	}
+
+	function := stakeNFTFunctionName
+	if !args.OnStake {
+		function = unstakeNFTFunctionName
+	}
+
+	data, err := base64.StdEncoding.DecodeString(tx.Data)
+	if err != nil {
+		return false
+	}
+	txArgs := strings.Split(string(data), "@")
+	if len(txArgs) < 3 || txArgs[0] != function {
+		return false
+	}
+	tokenId, err := hex.DecodeString(txArgs[1])
+	if err != nil || string(tokenId) != args.TokenId {
+		return false
+	}
+	nonce, err := strconv.ParseUint(txArgs[2], 16, 64)
+
+	return err == nil && nonce == args.Nonce
+}
+
 func getTokenBC(tokenName string, tokenHexNonce string, blockchainApi string) (entities.TokenBC, error) {
 	//var resp ProxyTokenResponse
 	var token entities.TokenBC
@@ -811,6 +869,7 @@ func BuyToken(args BuyTokenArgs) {
 		log.Debug("could not update token", "err", err)
 		return
 	}
+	refreshCollectionFloorPrice(token.CollectionID)
 	//indexer is safer till later review TODO
 
 	// transaction := entities.Transaction{
@@ -905,6 +964,7 @@ func StartAuction(args StartAuctionArgs, blockchainProxy string, marketplaceAddr
 		log.Debug("could not create or update token", "err", innerErr)
 		return nil, err
 	}
+	refreshCollectionFloorPrice(token.CollectionID)
 
 	transaction := entities.Transaction{
 		Hash:         args.TxHash,
@@ -961,6 +1021,7 @@ func EndAuction(args EndAuctionArgs) {
 		log.Debug("could not update token", "err", err)
 		return
 	}
+	refreshCollectionFloorPrice(token.CollectionID)
 
 	err = storage.DeleteOffersForTokenId(token.ID)
 	if err != nil {
@@ -986,6 +1047,13 @@ func EndAuction(args EndAuctionArgs) {
 	AddTransaction(&transaction)
 }
 
+func refreshCollectionFloorPrice(collectionId uint64) {
+	err := collstats.UpdateFloorPriceForCollection(collectionId)
+	if err != nil {
+		log.Debug("could not refresh collection floor price", "err", err)
+	}
+}
+
 func GetExtendedTokenData(tokenId string, nonce uint64) (*dtos.ExtendedTokenDto, error) {
 	token, err := storage.GetTokenByTokenIdAndNonce(tokenId, nonce)
 	if err != nil {
@@ -1669,6 +1737,8 @@ func ChangeTokenOwner(tokenIdentifier string, nonceStr string, newOwner string,
 		if err != nil {
 			return err
 		}
+
+		RequestTokenHistory(token.ID)
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -27,10 +28,10 @@ func Test_ListToken(t *testing.T) {
 	connectToDb()
 
 	collection := entities.Collection{
-		Name:        "col",
-		TokenID:     "",
-		Description: "",
-		CreatorID:   0,
+		Name:              "col",
+		CollectionTokenID: "",
+		Description:       "",
+		CreatorID:         0,
 	}
 	_ = storage.AddCollection(&collection)
 
@@ -59,7 +60,7 @@ func Test_ListToken(t *testing.T) {
 		TokenID:      "tokenId",
 		Nonce:        13,
 		PriceNominal: 1_000,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      ownerAccount.ID,
 		CollectionID: token.CollectionID,
 	}
@@ -70,10 +71,10 @@ func Test_SellToken(t *testing.T) {
 	connectToDb()
 
 	collection := entities.Collection{
-		Name:        "col",
-		TokenID:     "",
-		Description: "",
-		CreatorID:   0,
+		Name:              "col",
+		CollectionTokenID: "",
+		Description:       "",
+		CreatorID:         0,
 	}
 	_ = storage.AddCollection(&collection)
 
@@ -116,7 +117,7 @@ func Test_SellToken(t *testing.T) {
 		TokenID:      "tokenId",
 		Nonce:        13,
 		PriceNominal: 1_000,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      0,
 		CollectionID: token.CollectionID,
 	}
@@ -127,10 +128,10 @@ func Test_WithdrawToken(t *testing.T) {
 	connectToDb()
 
 	collection := entities.Collection{
-		Name:        "col",
-		TokenID:     "",
-		Description: "",
-		CreatorID:   0,
+		Name:              "col",
+		CollectionTokenID: "",
+		Description:       "",
+		CreatorID:         0,
 	}
 	_ = storage.AddCollection(&collection)
 
@@ -168,7 +169,7 @@ func Test_WithdrawToken(t *testing.T) {
 		TokenID:      "tokenId",
 		Nonce:        13,
 		PriceNominal: 1_000,
-		Status:       entities.List,
+		Status:       entities.ListToken,
 		OwnerID:      0,
 		CollectionID: token.CollectionID,
 	}
@@ -318,7 +319,7 @@ func Test_StartAuction(t *testing.T) {
 	require.Equal(t, nonce, token.Nonce)
 	require.Equal(t, "abcdef", token.ImageLink)
 	require.Equal(t, "1000000000000000000", token.PriceString)
-	require.Equal(t, entities.TokenStatus(entities.Auction), token.Status)
+	require.Equal(t, entities.AuctionToken, token.Status)
 	require.Equal(t, owner.ID, token.OwnerID)
 }
 
@@ -347,7 +348,7 @@ func Test_StartAuctionEndAuction(t *testing.T) {
 	require.Equal(t, nonce, token.Nonce)
 	require.Equal(t, "abcdef", token.ImageLink)
 	require.Equal(t, "1000000000000000000", token.PriceString)
-	require.Equal(t, entities.TokenStatus(entities.Auction), token.Status)
+	require.Equal(t, entities.AuctionToken, token.Status)
 	require.Equal(t, owner.ID, token.OwnerID)
 
 	EndAuction(EndAuctionArgs{
@@ -361,7 +362,7 @@ func Test_StartAuctionEndAuction(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, uint64(0), tokenAfterEnd.OwnerID)
 	require.Equal(t, 4722.366, tokenAfterEnd.LastBuyPriceNominal)
-	require.Equal(t, entities.TokenStatus(entities.None), tokenAfterEnd.Status)
+	require.Equal(t, entities.None, tokenAfterEnd.Status)
 }
 
 func Test_GetMetadata(t *testing.T) {
@@ -455,3 +456,31 @@ func Test_RefreshMetadata(t *testing.T) {
 
 	require.Equal(t, tokenAttrs, readTokenAttrs)
 }
+
+func Test_IsStakeTransaction(t *testing.T) {
+	stakingAddress := "erd1qqqqqqqqqqqqqpgqm4dmwyxc5fsj49z3jcu9h08azjrcf60kt9uspxs483"
+	args := StakeTokenArgs{OwnerAddress: "erd_owner", TokenId: "COL-abcdef", Nonce: 10, OnStake: true}
+	tx := entities.TransactionBC{
+		TxHash:   "stake",
+		Sender:   "erd_owner",
+		Receiver: stakingAddress,
+		Data:     base64.StdEncoding.EncodeToString([]byte("stakeAddressNFT@434f4c2d616263646566@0a")),
+		Status:   "success",
+	}
+	require.True(t, isStakeTransaction(args, tx, stakingAddress))
+
+	args.OnStake = false
+	require.False(t, isStakeTransaction(args, tx, stakingAddress))
+
+	args.OnStake = true
+	args.Nonce = 11
+	require.False(t, isStakeTransaction(args, tx, stakingAddress))
+
+	args.Nonce = 10
+	args.OwnerAddress = "erd_other"
+	require.False(t, isStakeTransaction(args, tx, stakingAddress))
+
+	args.OwnerAddress = "erd_owner"
+	tx.Status = "fail"
+	require.False(t, isStakeTransaction(args, tx, stakingAddress))
+}
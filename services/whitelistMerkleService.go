@@ -0,0 +1,153 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ENFT-DAO/youbei-api/crypto"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
+)
+
+type WhitelistMerkleProof struct {
+	Root    string   `json:"root"`
+	Version uint64   `json:"version"`
+	Address string   `json:"address"`
+	Amount  uint64   `json:"amount"`
+	Leaf    string   `json:"leaf"`
+	Proof   []string `json:"proof"`
+}
+
+// BuildWhitelistMerkleSnapshot freezes the mint allocations of a collection into a new tree version.
+// The amounts of an address are summed over the mint and buy-mint entries, addresses are sorted so the root is reproducible.
+func BuildWhitelistMerkleSnapshot(collectionId uint64) (*entities.WhitelistMerkleRoot, error) {
+	whitelists, err := storage.GetWhitelistsByCollectionID(collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[string]uint64)
+	for _, whitelist := range whitelists {
+		if whitelist.Type != entities.Whitelist_type_mint && whitelist.Type != entities.Whitelist_type_buy_mint {
+			continue
+		}
+		if whitelist.Amount == 0 {
+			continue
+		}
+		amounts[whitelist.Address] += whitelist.Amount
+	}
+
+	addresses := make([]string, 0, len(amounts))
+	for address := range amounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	leaves := make([]entities.WhitelistMerkleLeaf, len(addresses))
+	for index, address := range addresses {
+		leaves[index] = entities.WhitelistMerkleLeaf{
+			Position: uint64(index),
+			Address:  address,
+			Amount:   amounts[address],
+		}
+	}
+
+	tree, err := makeWhitelistMerkleTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	root := entities.WhitelistMerkleRoot{
+		CollectionID: collectionId,
+		Root:         hex.EncodeToString(tree.Root()),
+		LeavesCount:  uint64(len(leaves)),
+	}
+
+	err = storage.AddWhitelistMerkleSnapshot(&root, leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	return &root, nil
+}
+
+// GetWhitelistMerkleRoot returns the given snapshot version, or the latest one when version is 0.
+func GetWhitelistMerkleRoot(collectionId uint64, version uint64) (*entities.WhitelistMerkleRoot, error) {
+	if version == 0 {
+		return storage.GetLatestWhitelistMerkleRoot(collectionId)
+	}
+
+	return storage.GetWhitelistMerkleRoot(collectionId, version)
+}
+
+func GetWhitelistMerkleProof(collectionId uint64, version uint64, address string) (*WhitelistMerkleProof, error) {
+	root, err := GetWhitelistMerkleRoot(collectionId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := storage.GetWhitelistMerkleLeaves(root.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	position := sort.Search(len(leaves), func(i int) bool {
+		return leaves[i].Address >= address
+	})
+	if position == len(leaves) || leaves[position].Address != address {
+		return nil, crypto.ErrMerkleLeafNotFound
+	}
+
+	tree, err := makeWhitelistMerkleTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := tree.Proof(position)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := computeWhitelistLeaf(leaves[position])
+	if err != nil {
+		return nil, err
+	}
+
+	proofHex := make([]string, len(proof))
+	for index, hash := range proof {
+		proofHex[index] = hex.EncodeToString(hash)
+	}
+
+	return &WhitelistMerkleProof{
+		Root:    root.Root,
+		Version: root.Version,
+		Address: address,
+		Amount:  leaves[position].Amount,
+		Leaf:    hex.EncodeToString(leaf),
+		Proof:   proofHex,
+	}, nil
+}
+
+func makeWhitelistMerkleTree(leaves []entities.WhitelistMerkleLeaf) (*crypto.MerkleTree, error) {
+	hashes := make([][]byte, len(leaves))
+	for index, leaf := range leaves {
+		hash, err := computeWhitelistLeaf(leaf)
+		if err != nil {
+			return nil, err
+		}
+		hashes[index] = hash
+	}
+
+	return crypto.NewMerkleTree(hashes)
+}
+
+func computeWhitelistLeaf(leaf entities.WhitelistMerkleLeaf) ([]byte, error) {
+	address, err := data.NewAddressFromBech32String(leaf.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid whitelist address %s: %w", leaf.Address, err)
+	}
+
+	return crypto.ComputeWhitelistLeaf(address.AddressBytes(), leaf.Amount), nil
+}
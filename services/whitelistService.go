@@ -1,71 +1,305 @@
 package services
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/interaction"
 	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
 	"github.com/ElrondNetwork/elrond-sdk-erdgo/data"
 )
 
+const (
+	WhitelistFormatCsv  = "csv"
+	WhitelistFormatJson = "json"
+
+	MaxWhitelistImportEntries = 10000
+	// every entry is a vm query, a page has to fit in a request
+	MaxWhitelistReconcileEntries = 100
+)
+
 type SetWhitelistRequest struct {
-	CollectionID uint64 `json:"collectionId"`
-	Address      string `json:"address"`
-	Amount       uint64 `json:"amount"`
-	Type         uint64 `json:"type"`
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+	Type    uint64 `json:"type"`
+}
+
+type WhitelistReconciliation struct {
+	Address       string `json:"address"`
+	Amount        uint64 `json:"amount"`
+	ContractLimit uint64 `json:"contractLimit"`
+	Matches       bool   `json:"matches"`
+}
+
+// WhitelistReconciliationPage is one page of a reconciliation, Next is the cursor of the following page
+// and stays 0 on the last one.
+type WhitelistReconciliationPage struct {
+	Entries []WhitelistReconciliation `json:"entries"`
+	Next    uint64                    `json:"next"`
+}
+
+var whitelistCsvHeader = []string{"address", "amount", "type"}
+
+func GetCollectionWhitelist(collectionId uint64) ([]entities.Whitelist, error) {
+	return storage.GetWhitelistsByCollectionID(collectionId)
+}
+
+func GetWhitelistForAddress(collectionId uint64, address string) ([]entities.Whitelist, error) {
+	return storage.GetWhitelistsByAddressAndCollectionID(address, collectionId)
+}
+
+// GetWhitelistAuditState maps address:type to the amount of the allocations of one address,
+// or of the whole collection when the address is empty, so that audit diffs list the changed allocations.
+func GetWhitelistAuditState(collectionId uint64, address string) (map[string]uint64, error) {
+	var whitelists []entities.Whitelist
+	var err error
+	if address == "" {
+		whitelists, err = storage.GetWhitelistsByCollectionID(collectionId)
+	} else {
+		whitelists, err = storage.GetWhitelistsByAddressAndCollectionID(address, collectionId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]uint64, len(whitelists))
+	for _, whitelist := range whitelists {
+		state[fmt.Sprintf("%s:%d", whitelist.Address, whitelist.Type)] = whitelist.Amount
+	}
+
+	return state, nil
+}
+
+func SetCollectionWhitelistEntry(collectionId uint64, request *SetWhitelistRequest) (*entities.Whitelist, error) {
+	whitelist, err := makeWhitelistEntry(collectionId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	whitelists := []entities.Whitelist{whitelist}
+	err = storage.AddOrUpdateWhitelists(whitelists)
+	if err != nil {
+		return nil, err
+	}
+
+	return &whitelists[0], nil
+}
+
+func DeleteCollectionWhitelistEntry(collectionId uint64, address string, whitelistType uint64) error {
+	return storage.DeleteWhitelist(collectionId, address, whitelistType)
+}
+
+// ImportCollectionWhitelist reads address, amount and type entries from a csv (header optional)
+// or a json array and stores them. Nothing is stored if any entry is invalid.
+func ImportCollectionWhitelist(collectionId uint64, format string, reader io.Reader) ([]entities.Whitelist, error) {
+	var requests []SetWhitelistRequest
+	var err error
+
+	switch format {
+	case WhitelistFormatCsv:
+		requests, err = readWhitelistCsv(reader)
+	case WhitelistFormatJson:
+		err = json.NewDecoder(reader).Decode(&requests)
+	default:
+		return nil, errors.New("unsupported whitelist format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requests) > MaxWhitelistImportEntries {
+		return nil, fmt.Errorf("too many whitelist entries, max is %d", MaxWhitelistImportEntries)
+	}
+
+	whitelists := make([]entities.Whitelist, 0, len(requests))
+	for index := range requests {
+		whitelist, innerErr := makeWhitelistEntry(collectionId, &requests[index])
+		if innerErr != nil {
+			return nil, fmt.Errorf("entry %d: %w", index+1, innerErr)
+		}
+		whitelists = append(whitelists, whitelist)
+	}
+
+	err = storage.AddOrUpdateWhitelists(whitelists)
+	if err != nil {
+		return nil, err
+	}
+
+	return whitelists, nil
 }
 
-func GetWhitelist(address string) (*entities.Whitelist, error) {
-	whitelist, err := storage.GetWhitelistByAddress(address)
+func ExportCollectionWhitelistCsv(collectionId uint64) (*bytes.Buffer, error) {
+	whitelists, err := storage.GetWhitelistsByCollectionID(collectionId)
+	if err != nil {
+		return nil, err
+	}
 
+	csvWrapper, err := utils.NewCsvWrapper()
 	if err != nil {
 		return nil, err
 	}
+	defer csvWrapper.Close()
 
-	return whitelist, nil
+	records := [][]string{whitelistCsvHeader}
+	for _, whitelist := range whitelists {
+		records = append(records, []string{
+			whitelist.Address,
+			strconv.FormatUint(whitelist.Amount, 10),
+			strconv.FormatUint(whitelist.Type, 10),
+		})
+	}
+
+	err = csvWrapper.WriteBulkRecord(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return csvWrapper.GetBuffer(), nil
 }
 
-func CreateWhitelist(address string, request *SetWhitelistRequest) (*entities.Whitelist, error) {
+// ReconcileCollectionWhitelist compares a page of the buy allocations, after the given cursor,
+// with the contract's getBuyLimit. With apply set, mismatching entries take the amount of the contract,
+// which is what buyers get, and entries the contract gives nothing are deleted.
+func ReconcileCollectionWhitelist(collection *entities.Collection, after uint64, limit int, apply bool) (*WhitelistReconciliationPage, error) {
+	if collection.ContractAddress == "" {
+		return nil, errors.New("collection has no contract")
+	}
+	if limit <= 0 || limit > MaxWhitelistReconcileEntries {
+		limit = MaxWhitelistReconcileEntries
+	}
 
-	whitelist := entities.Whitelist{
+	buyTypes := []uint64{entities.Whitelist_type_buy, entities.Whitelist_type_buy_mint}
+	whitelists, err := storage.GetWhitelistsByCollectionIDAndTypesAfter(collection.ID, buyTypes, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := WhitelistReconciliationPage{Entries: []WhitelistReconciliation{}}
+	for _, whitelist := range whitelists {
+		contractLimit, innerErr := getWhitelistLimitByAddress(collection.ContractAddress, whitelist.Address)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		entry := WhitelistReconciliation{
+			Address:       whitelist.Address,
+			Amount:        whitelist.Amount,
+			ContractLimit: contractLimit,
+			Matches:       whitelist.Amount == contractLimit,
+		}
+		if !entry.Matches && apply {
+			if contractLimit == 0 {
+				innerErr = storage.DeleteWhitelist(collection.ID, whitelist.Address, whitelist.Type)
+			} else {
+				innerErr = storage.UpdateWhitelistAmount(collection.ID, whitelist.Address, whitelist.Type, contractLimit)
+			}
+			if innerErr != nil {
+				return nil, innerErr
+			}
+		}
+
+		page.Entries = append(page.Entries, entry)
+	}
+
+	if len(whitelists) == limit {
+		page.Next = whitelists[len(whitelists)-1].ID
+	}
+
+	return &page, nil
+}
+
+func ParseWhitelistType(value string) (uint64, error) {
+	whitelistType, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return whitelistType, checkWhitelistType(whitelistType)
+}
+
+func makeWhitelistEntry(collectionId uint64, request *SetWhitelistRequest) (entities.Whitelist, error) {
+	address := strings.TrimSpace(request.Address)
+	_, err := data.NewAddressFromBech32String(address)
+	if err != nil {
+		return entities.Whitelist{}, errors.New("invalid address")
+	}
+
+	err = checkWhitelistType(request.Type)
+	if err != nil {
+		return entities.Whitelist{}, err
+	}
+
+	if request.Amount == 0 {
+		return entities.Whitelist{}, errors.New("amount must be positive, delete the entry instead")
+	}
+
+	return entities.Whitelist{
+		CollectionID: collectionId,
 		Address:      address,
-		CollectionID: request.CollectionID,
 		Amount:       request.Amount,
 		Type:         request.Type,
-		CreatedAt:    uint64(time.Now().Unix()),
-		ModifiedAt:   uint64(time.Now().Unix()),
+	}, nil
+}
+
+func checkWhitelistType(whitelistType uint64) error {
+	switch whitelistType {
+	case entities.Whitelist_type_buy, entities.Whitelist_type_mint, entities.Whitelist_type_buy_mint:
+		return nil
+	default:
+		return errors.New("invalid whitelist type")
 	}
+}
 
-	err := storage.AddWhitelist(&whitelist)
+func readWhitelistCsv(reader io.Reader) ([]SetWhitelistRequest, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = len(whitelistCsvHeader)
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
 	if err != nil {
 		return nil, err
 	}
 
-	return &whitelist, err
-}
+	if len(records) > 0 && strings.EqualFold(records[0][0], whitelistCsvHeader[0]) {
+		records = records[1:]
+	}
 
-func UpdateWhitelist(whitelist *entities.Whitelist, request *SetWhitelistRequest) error {
-	whitelist.CollectionID = request.CollectionID
-	whitelist.Address = request.Address
-	whitelist.Amount = request.Amount
-	whitelist.Type = request.Type
-	whitelist.ModifiedAt = uint64(time.Now().Unix())
+	requests := make([]SetWhitelistRequest, 0, len(records))
+	for index, record := range records {
+		amount, innerErr := strconv.ParseUint(record[1], 10, 64)
+		if innerErr != nil {
+			return nil, fmt.Errorf("entry %d: invalid amount", index+1)
+		}
 
-	return storage.UpdateWhitelist(whitelist)
-}
+		whitelistType, innerErr := strconv.ParseUint(record[2], 10, 64)
+		if innerErr != nil {
+			return nil, fmt.Errorf("entry %d: invalid type", index+1)
+		}
+
+		requests = append(requests, SetWhitelistRequest{
+			Address: record[0],
+			Amount:  amount,
+			Type:    whitelistType,
+		})
+	}
 
-func UpdateWhitelistAmountByAddress(amount uint64, address string) error {
-	return storage.UpdateWhitelistAmountByAddress(amount, address)
+	return requests, nil
 }
 
 func getWhitelistLimitByAddress(contractAddress string, userAddress string) (uint64, error) {
 
 	bi := interaction.GetBlockchainInteractor()
 
-	addressDecoded, err := data.NewAddressFromBech32String(contractAddress)
+	addressDecoded, err := data.NewAddressFromBech32String(userAddress)
 	if err != nil {
 		return 0, err
 	}
@@ -73,7 +307,10 @@ func getWhitelistLimitByAddress(contractAddress string, userAddress string) (uin
 	userAddressHex := hex.EncodeToString(addressDecoded.AddressBytes())
 
 	result, err := bi.DoVmQuery(contractAddress, "getBuyLimit", []string{userAddressHex})
-	if err != nil || len(result) == 0 {
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 || len(result[0]) == 0 {
 		return 0, nil
 	}
 
@@ -3,19 +3,17 @@ package aggregator
 import (
 	"fmt"
 	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/stats/trending"
 	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
-	"sort"
-	"strconv"
 	"sync"
 	"time"
 )
 
 const (
-	StartProjectThreshold    = "2022-01-01 00:00:00"
-	StartProjectThresholdInt = 2022010100
-	MaxOverComputeThreshold  = 12
-	MaxRunnerCount           = 2
+	MaxOverComputeThreshold = 12
+	MaxRunnerCount          = 3
 )
 
 // MARK: manager
@@ -60,9 +58,10 @@ func GetManager() *manager {
 }
 
 func (m *manager) Start() {
-	// Start hourly aggregator
-	go m.aggregatedVolumePerHourRunner()
-	go m.aggregatedVolumePerCollectionPerHourRunner()
+	// Start hourly aggregator and rollups
+	go m.volumeRunner()
+	go m.collectionSnapshotRunner()
+	go m.trendingScoreRunner()
 }
 
 func (m *manager) Stop() {
@@ -71,9 +70,7 @@ func (m *manager) Stop() {
 	}
 }
 
-func (m *manager) aggregatedVolumePerHourRunner() {
-	//m.getAggregatedVolumePerHour()
-
+func (m *manager) volumeRunner() {
 	ticker := time.NewTicker(5 * time.Minute)
 	for {
 		select {
@@ -81,94 +78,24 @@ func (m *manager) aggregatedVolumePerHourRunner() {
 			ticker.Stop()
 			return
 		case <-ticker.C:
-			m.getAggregatedVolumePerHour()
+			m.reaggregateRecentVolume()
 		}
 	}
 }
 
-func (m *manager) getAggregatedVolumePerHour() {
-	index := 0
-	subtractIndex := 0
-	for {
-		currentTime := time.Now().UTC().Add(-1 * time.Duration(subtractIndex) * time.Hour)
-		currentTimeStr := fmt.Sprintf("%04d-%02d-%02d %02d:00:00", currentTime.Year(), currentTime.Month(), currentTime.Day(), currentTime.Hour())
-
-		oneHourBeforeTime := currentTime.Add(-1 * time.Hour)
-		oneHourBeforeTimeStr := fmt.Sprintf("%04d-%02d-%02d %02d:00:00", oneHourBeforeTime.Year(), oneHourBeforeTime.Month(), oneHourBeforeTime.Day(), oneHourBeforeTime.Hour())
-
-		intHourStr := fmt.Sprintf("%4d%02d%02d%02d", oneHourBeforeTime.Year(), oneHourBeforeTime.Month(), oneHourBeforeTime.Day(), oneHourBeforeTime.Hour())
-		intHour, _ := strconv.ParseInt(intHourStr, 10, 64)
-
-		if index < MaxOverComputeThreshold {
-			buyVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.BuyToken)
-			if err != nil {
-				break
-			}
-			listVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.ListToken)
-			if err != nil {
-				break
-			}
-			withdrawVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.WithdrawToken)
-			if err != nil {
-				break
-			}
-
-			newRecord := entities.AggregatedVolumePerHour{
-				Hour:           intHour,
-				BuyVolume:      buyVolume,
-				ListVolume:     listVolume,
-				WithdrawVolume: withdrawVolume,
-			}
-			err2 := storage.AddOrUpdateAggregatedVolumePerHour(&newRecord)
-			if err2 != nil {
-				logInstance.Debug(fmt.Sprintf("cannot insert row for %d", intHour))
-				break
-			}
-
-			index += 1
-		} else {
-			if intHour > StartProjectThresholdInt {
-				_, err := storage.GetOneAggregatedVolumePerHour(intHour)
-				if err != nil {
-					// insert a new one
-					buyVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.BuyToken)
-					if err != nil {
-						break
-					}
-					listVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.ListToken)
-					if err != nil {
-						break
-					}
-					withdrawVolume, err := storage.GetAggregatedTradedVolumeHourly(oneHourBeforeTimeStr, currentTimeStr, entities.WithdrawToken)
-					if err != nil {
-						break
-					}
+// reaggregateRecentVolume rebuilds the last hours, the current one included, so late indexed
+// transactions still land in their bucket.
+func (m *manager) reaggregateRecentVolume() {
+	to := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	from := to.Add(-time.Duration(MaxOverComputeThreshold) * time.Hour)
 
-					newRecord := entities.AggregatedVolumePerHour{
-						Hour:           intHour,
-						BuyVolume:      buyVolume,
-						ListVolume:     listVolume,
-						WithdrawVolume: withdrawVolume,
-					}
-					err2 := storage.AddOrUpdateAggregatedVolumePerHour(&newRecord)
-					if err2 != nil {
-						logInstance.Debug(fmt.Sprintf("cannot insert row for %d", intHour))
-					}
-				} else {
-					return
-				}
-			} else {
-				return
-			}
-		}
-		subtractIndex++
-		time.Sleep(50 * time.Millisecond)
+	err := Reaggregate(from, to)
+	if err != nil {
+		logInstance.Debug("cannot reaggregate volume", "err", err)
 	}
 }
 
-func (m *manager) aggregatedVolumePerCollectionPerHourRunner() {
-	//m.getAggregatedVolumePerCollectionPerHour()
-
+func (m *manager) collectionSnapshotRunner() {
 	ticker := time.NewTicker(5 * time.Minute)
 	for {
 		select {
@@ -176,173 +103,79 @@ func (m *manager) aggregatedVolumePerCollectionPerHourRunner() {
 			ticker.Stop()
 			return
 		case <-ticker.C:
-			m.getAggregatedVolumePerCollectionPerHour()
+			m.snapshotFloorPricePerCollection()
+			m.snapshotOwnersPerCollection()
 		}
 	}
 }
 
-func (m *manager) getAggregatedVolumePerCollectionPerHour() {
-	index := 0
-	subtractIndex := 0
-
-	// Get all collectionIds from db
+// snapshotFloorPricePerCollection writes the current floor of every collection into the current hour.
+// The row keeps being overwritten during the hour, so it ends up holding the last floor seen in it.
+func (m *manager) snapshotFloorPricePerCollection() {
 	collections, err := storage.GetAllCollections()
 	if err != nil {
 		return
 	}
 
-	collectionIds := []uint64{}
-	for _, item := range collections {
-		collectionIds = append(collectionIds, item.ID)
+	floors, err := storage.GetMinListPricePerCollection()
+	if err != nil {
+		return
 	}
 
-	for {
-		currentTime := time.Now().UTC().Add(-1 * time.Duration(subtractIndex) * time.Hour)
-		currentTimeStr := fmt.Sprintf("%04d-%02d-%02d %02d:00:00", currentTime.Year(), currentTime.Month(), currentTime.Day(), currentTime.Hour())
+	floorsByCollection := make(map[uint64]entities.CollectionFloorPricePerHour)
+	for _, item := range floors {
+		floorsByCollection[item.CollectionId] = item
+	}
 
-		oneHourBeforeTime := currentTime.Add(-1 * time.Hour)
-		oneHourBeforeTimeStr := fmt.Sprintf("%04d-%02d-%02d %02d:00:00", oneHourBeforeTime.Year(), oneHourBeforeTime.Month(), oneHourBeforeTime.Day(), oneHourBeforeTime.Hour())
+	intHour := utils.HourKey(time.Now())
+	for _, collection := range collections {
+		floor := floorsByCollection[collection.ID]
+		newRecord := entities.CollectionFloorPricePerHour{
+			Hour:         intHour,
+			CollectionId: collection.ID,
+			FloorPrice:   floor.FloorPrice,
+			ListedCount:  floor.ListedCount,
+		}
+		err2 := storage.AddOrUpdateCollectionFloorPricePerHour(&newRecord)
+		if err2 != nil {
+			logInstance.Debug(fmt.Sprintf("cannot insert floor row for %d and collection %d", intHour, collection.ID))
+		}
+	}
+}
 
-		intHourStr := fmt.Sprintf("%4d%02d%02d%02d", oneHourBeforeTime.Year(), oneHourBeforeTime.Month(), oneHourBeforeTime.Day(), oneHourBeforeTime.Hour())
-		intHour, _ := strconv.ParseInt(intHourStr, 10, 64)
+// snapshotOwnersPerCollection writes the current owner count of every collection into the current hour.
+func (m *manager) snapshotOwnersPerCollection() {
+	owners, err := storage.GetOwnersCountPerCollection()
+	if err != nil {
+		return
+	}
 
-		type tempVolumeStruct struct {
-			BuyVolume      float64
-			ListVolume     float64
-			WithdrawVolume float64
+	intHour := utils.HourKey(time.Now())
+	for _, item := range owners {
+		newRecord := entities.CollectionOwnersPerHour{
+			Hour:         intHour,
+			CollectionId: item.CollectionId,
+			OwnersTotal:  item.OwnersTotal,
 		}
+		err2 := storage.AddOrUpdateCollectionOwnersPerHour(&newRecord)
+		if err2 != nil {
+			logInstance.Debug(fmt.Sprintf("cannot insert owners row for %d and collection %d", intHour, item.CollectionId))
+		}
+	}
+}
 
-		if index < MaxOverComputeThreshold {
-			records, err := storage.GetAggregatedTradedVolumePerCollectionHourly(oneHourBeforeTimeStr, currentTimeStr)
+func (m *manager) trendingScoreRunner() {
+	ticker := time.NewTicker(5 * time.Minute)
+	for {
+		select {
+		case <-m.controlChannels[2]:
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			err := trending.ComputeScores()
 			if err != nil {
-				break
-			}
-
-			tempList := make(map[uint64]tempVolumeStruct)
-			tempIds := []uint64{}
-
-			for _, item := range records {
-				p := tempList[item.CollectionId]
-				switch item.Type {
-				case string(entities.BuyToken):
-					p.BuyVolume = item.Total
-				case string(entities.ListToken):
-					p.ListVolume = item.Total
-				case string(entities.WithdrawToken):
-					p.WithdrawVolume = item.Total
-				}
-				tempList[item.CollectionId] = p
-				tempIds = append(tempIds, item.CollectionId)
-			}
-
-			for key, item := range tempList {
-				newRecord := entities.AggregatedVolumePerCollectionPerHour{
-					Hour:           intHour,
-					BuyVolume:      item.BuyVolume,
-					ListVolume:     item.ListVolume,
-					WithdrawVolume: item.WithdrawVolume,
-					CollectionId:   key,
-				}
-				err2 := storage.AddOrUpdateAggregatedVolumePerCollectionPerHour(&newRecord)
-				if err2 != nil {
-					logInstance.Debug(fmt.Sprintf("cannot insert row for %d and collection %d", intHour, key))
-				}
-			}
-
-			// Get collections that does not exist here
-			for _, id := range collectionIds {
-				indexT := sort.Search(len(tempIds), func(i int) bool {
-					return id == tempIds[i]
-				})
-
-				if indexT <= 0 || indexT > len(tempIds) {
-					newRecord := entities.AggregatedVolumePerCollectionPerHour{
-						Hour:           intHour,
-						BuyVolume:      0.0,
-						ListVolume:     0.0,
-						WithdrawVolume: 0.0,
-						CollectionId:   id,
-					}
-					err2 := storage.AddOrUpdateAggregatedVolumePerCollectionPerHour(&newRecord)
-					if err2 != nil {
-						logInstance.Debug(fmt.Sprintf("cannot insert row for %d and collection %d", intHour, id))
-					}
-				}
-			}
-
-			index += 1
-		} else {
-			if intHour > StartProjectThresholdInt {
-				fetchedRecords, _ := storage.GetOneAggregatedVolumePerCollectionPerHour(intHour)
-
-				if len(fetchedRecords) == 0 {
-					records, err := storage.GetAggregatedTradedVolumePerCollectionHourly(oneHourBeforeTimeStr, currentTimeStr)
-					if err != nil {
-						break
-					}
-
-					tempList := make(map[uint64]tempVolumeStruct)
-					tempIds := []uint64{}
-
-					for _, item := range records {
-						p := tempList[item.CollectionId]
-						switch item.Type {
-						case string(entities.BuyToken):
-							p.BuyVolume = item.Total
-						case string(entities.ListToken):
-							p.ListVolume = item.Total
-						case string(entities.WithdrawToken):
-							p.WithdrawVolume = item.Total
-						}
-						tempList[item.CollectionId] = p
-						tempIds = append(tempIds, item.CollectionId)
-					}
-
-					for key, item := range tempList {
-						newRecord := entities.AggregatedVolumePerCollectionPerHour{
-							Hour:           intHour,
-							BuyVolume:      item.BuyVolume,
-							ListVolume:     item.ListVolume,
-							WithdrawVolume: item.WithdrawVolume,
-							CollectionId:   key,
-						}
-						err2 := storage.AddOrUpdateAggregatedVolumePerCollectionPerHour(&newRecord)
-						if err2 != nil {
-							logInstance.Debug(fmt.Sprintf("cannot insert row for %d and collection %d", intHour, key))
-							break
-						}
-					}
-
-					// Get collections that does not exist here
-					for _, id := range collectionIds {
-						indexT := sort.Search(len(tempIds), func(i int) bool {
-							return id == tempIds[i]
-						})
-
-						if indexT <= 0 || indexT > len(tempIds) {
-							newRecord := entities.AggregatedVolumePerCollectionPerHour{
-								Hour:           intHour,
-								BuyVolume:      0.0,
-								ListVolume:     0.0,
-								WithdrawVolume: 0.0,
-								CollectionId:   id,
-							}
-							err2 := storage.AddOrUpdateAggregatedVolumePerCollectionPerHour(&newRecord)
-							if err2 != nil {
-								logInstance.Debug(fmt.Sprintf("cannot insert row for %d and collection %d", intHour, id))
-							}
-						}
-					}
-
-				} else {
-					return
-				}
-			} else {
-				return
+				logInstance.Debug("cannot compute trending scores", "err", err)
 			}
 		}
-
-		subtractIndex++
-		time.Sleep(50 * time.Millisecond)
 	}
 }
@@ -0,0 +1,76 @@
+package aggregator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
+)
+
+const BackfillChunk = 7 * 24 * time.Hour
+
+var rollupPeriods = []entities.RollupPeriod{entities.RollupDay, entities.RollupWeek, entities.RollupMonth}
+
+// Reaggregate rebuilds the hourly buckets of [from, to) and every rollup period touching them.
+// Both ends are truncated to the hour. It is safe to run again over the same window.
+func Reaggregate(from, to time.Time) error {
+	from = from.UTC().Truncate(time.Hour)
+	to = to.UTC().Truncate(time.Hour)
+	if !to.After(from) {
+		return errors.New("empty aggregation window")
+	}
+
+	err := storage.ReaggregateVolumePerHour(from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range rollupPeriods {
+		err = storage.RollupVolume(period, periodKeys(period, from, to))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Backfill runs Reaggregate over [from, to) chunk by chunk, to keep each transaction small.
+func Backfill(from, to time.Time) error {
+	for chunkStart := from; chunkStart.Before(to); chunkStart = chunkStart.Add(BackfillChunk) {
+		chunkEnd := chunkStart.Add(BackfillChunk)
+		if chunkEnd.After(to) {
+			chunkEnd = to
+		}
+
+		err := Reaggregate(chunkStart, chunkEnd)
+		if err != nil {
+			return err
+		}
+		logInstance.Info("backfilled", "from", chunkStart.Format(time.RFC3339), "to", chunkEnd.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func periodKeys(period entities.RollupPeriod, from, to time.Time) []int64 {
+	keyOf := utils.DayKey
+	switch period {
+	case entities.RollupWeek:
+		keyOf = utils.WeekKey
+	case entities.RollupMonth:
+		keyOf = utils.MonthKey
+	}
+
+	var keys []int64
+	for hour := from; hour.Before(to); hour = hour.Add(time.Hour) {
+		key := keyOf(hour)
+		if len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,18 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PeriodKeys(t *testing.T) {
+	from := time.Date(2022, time.February, 27, 22, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.March, 1, 2, 0, 0, 0, time.UTC)
+
+	require.Equal(t, []int64{20220227, 20220228, 20220301}, periodKeys(entities.RollupDay, from, to))
+	require.Equal(t, []int64{20220221, 20220228}, periodKeys(entities.RollupWeek, from, to))
+	require.Equal(t, []int64{202202, 202203}, periodKeys(entities.RollupMonth, from, to))
+}
@@ -228,7 +228,16 @@ func setStatisticsRaw(tokenId string) (*dtos.CollectionStatistics, error) {
 		return nil, err
 	}
 
-	err = updateLeaderboardTables(tokenId, cacheStats)
+	moderated, err := storage.IsCollectionModeratedByTokenId(tokenId)
+	if err != nil {
+		log.Debug("could not check if collection is moderated", err)
+	}
+
+	if moderated {
+		err = RemoveCollectionFromLeaderboards(tokenId)
+	} else {
+		err = updateLeaderboardTables(tokenId, cacheStats)
+	}
 	if err != nil {
 		log.Debug("could not update leaderboard table")
 	}
@@ -0,0 +1,63 @@
+package collstats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ENFT-DAO/youbei-api/cache"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/go-redis/redis/v8"
+)
+
+// UpdateFloorPriceForCollection recomputes the floor from the active listings and pushes it
+// to the floor leaderboard and the cached statistics, without waiting for a full stats refresh.
+func UpdateFloorPriceForCollection(collectionId uint64) error {
+	collection, err := storage.GetCollectionById(collectionId)
+	if err != nil {
+		return err
+	}
+
+	floorPrice, err := storage.GetMinListPriceForTokensWithCollectionId(collectionId)
+	if err != nil {
+		return err
+	}
+
+	tokenId := collection.CollectionTokenID
+	err = setFloorPriceLeaderboardEntry(tokenId, floorPrice)
+	if err != nil {
+		log.Debug("could not update floor price leaderboard", "err", err)
+	}
+
+	cacheStats, err := getStatisticsRaw(tokenId)
+	if err != nil {
+		// nothing cached yet, the next stats computation will pick up the floor
+		return nil
+	}
+
+	cacheStats.FloorPrice = floorPrice
+	bytes, err := json.Marshal(cacheStats)
+	if err != nil {
+		return err
+	}
+
+	redisCache := cache.GetRedis()
+	redisCtx := cache.GetContext()
+	redisKey := fmt.Sprintf(redisCollectionStatsKeyFormat, tokenId)
+	return redisCache.Set(redisCtx, redisKey, bytes, 0).Err()
+}
+
+// setFloorPriceLeaderboardEntry keeps collections without listings out of the floor table,
+// otherwise they would all rank as the cheapest collections.
+func setFloorPriceLeaderboardEntry(tokenId string, floorPrice float64) error {
+	redisCache := cache.GetRedis()
+	redisCtx := cache.GetContext()
+
+	if floorPrice == 0 {
+		return redisCache.ZRem(redisCtx, FloorPrice, tokenId).Err()
+	}
+
+	return redisCache.ZAdd(redisCtx, FloorPrice, &redis.Z{
+		Score:  floorPrice,
+		Member: tokenId,
+	}).Err()
+}
@@ -44,10 +44,7 @@ func updateLeaderboardTables(tokenId string, stats *dtos.CollectionStatistics) e
 		log.Debug("sorted set add failed")
 	}
 
-	_, err = redisCache.ZAdd(redisCtx, FloorPrice, &redis.Z{
-		Score:  stats.FloorPrice,
-		Member: tokenId,
-	}).Result()
+	err = setFloorPriceLeaderboardEntry(tokenId, stats.FloorPrice)
 	if err != nil {
 		log.Debug("sorted set add failed")
 	}
@@ -63,6 +60,21 @@ func updateLeaderboardTables(tokenId string, stats *dtos.CollectionStatistics) e
 	return nil
 }
 
+// RemoveCollectionFromLeaderboards takes a collection out of every leaderboard, like when moderators hide it.
+func RemoveCollectionFromLeaderboards(tokenId string) error {
+	redisCache := cache.GetRedis()
+	redisCtx := cache.GetContext()
+
+	for _, table := range []string{ItemsTotal, OwnersTotal, FloorPrice, VolumeTraded} {
+		_, err := redisCache.ZRem(redisCtx, table, tokenId).Result()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GetLeaderboardEntries(table string, start int, stop int, rev bool) ([]LeaderboardEntry, error) {
 	redisCache := cache.GetRedis()
 	redisCtx := cache.GetContext()
@@ -0,0 +1,245 @@
+package collstats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/cache"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
+)
+
+const (
+	Window1h  = "1h"
+	Window24h = "24h"
+	Window7d  = "7d"
+	Window30d = "30d"
+	WindowAll = "all"
+
+	SalesCount   = "salesCount"
+	FloorChange  = "floorChange"
+	OwnersChange = "ownersChange"
+
+	windowRankingsCacheKeyFormat = "WindowRankings:%s"
+	windowRankingsExpirePeriod   = 5 * time.Minute
+)
+
+var windowDurations = map[string]time.Duration{
+	Window1h:  time.Hour,
+	Window24h: 24 * time.Hour,
+	Window7d:  7 * 24 * time.Hour,
+	Window30d: 30 * 24 * time.Hour,
+	WindowAll: 0,
+}
+
+type WindowRankingEntry struct {
+	CollectionId          string  `json:"collectionId"`
+	CollectionName        string  `json:"collectionName"`
+	Volume                float64 `json:"volume"`
+	SalesCount            uint64  `json:"salesCount"`
+	FloorPrice            float64 `json:"floorPrice"`
+	FloorChangePercentage float64 `json:"floorChangePercentage"`
+	OwnersTotal           uint64  `json:"ownersTotal"`
+	OwnersChange          int64   `json:"ownersChange"`
+}
+
+func IsValidRankingWindow(window string) bool {
+	_, ok := windowDurations[window]
+	return ok
+}
+
+// GetWindowRankingEntries ranks the collections over a time window. The numbers only come from
+// the hourly warehouse tables, so the same window always gives the same ranking for a given hour.
+func GetWindowRankingEntries(window string, criteria string, start int, stop int, rev bool) ([]WindowRankingEntry, error) {
+	err := testWindowCriteria(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := getWindowRankings(window)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		left, right := windowCriteriaValue(entries[i], criteria), windowCriteriaValue(entries[j], criteria)
+		if left == right {
+			return entries[i].CollectionId < entries[j].CollectionId
+		}
+		if rev {
+			return left > right
+		}
+		return left < right
+	})
+
+	if start >= len(entries) {
+		return []WindowRankingEntry{}, nil
+	}
+	if stop >= len(entries) {
+		stop = len(entries) - 1
+	}
+
+	return entries[start : stop+1], nil
+}
+
+func getWindowRankings(window string) ([]WindowRankingEntry, error) {
+	var byteArray []byte
+	var entries []WindowRankingEntry
+
+	cacheKey := fmt.Sprintf(windowRankingsCacheKeyFormat, window)
+	err := cache.GetCacher().Get(cacheKey, &byteArray)
+	if err == nil {
+		err = json.Unmarshal(byteArray, &entries)
+		return entries, err
+	}
+
+	entries, err = ComputeWindowRankings(window)
+	if err != nil {
+		return nil, err
+	}
+
+	byteArray, err = json.Marshal(entries)
+	if err == nil {
+		err = cache.GetCacher().Set(cacheKey, byteArray, windowRankingsExpirePeriod)
+		if err != nil {
+			log.Debug("could not set cache", "err", err)
+		}
+	}
+
+	return entries, nil
+}
+
+// ComputeWindowRankings builds one entry per collection for the given window, leaving out the hidden and delisted ones.
+// Volume and sales cover the completed hours of the window, while floor and owner changes
+// compare the first snapshot inside the window with the latest one.
+func ComputeWindowRankings(window string) ([]WindowRankingEntry, error) {
+	duration, ok := windowDurations[window]
+	if !ok {
+		return nil, errors.New("not a valid ranking window")
+	}
+
+	now := time.Now()
+	fromHour := int64(0)
+	if duration > 0 {
+		fromHour = utils.HourKey(now.Add(-duration))
+	}
+	toHour := utils.HourKey(now)
+
+	allCollections, err := storage.GetAllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	collections := allCollections[:0]
+	for _, collection := range allCollections {
+		if !collection.IsHidden && !collection.IsDelisted {
+			collections = append(collections, collection)
+		}
+	}
+
+	volumes, err := storage.GetCollectionVolumesInHourRange(fromHour, toHour)
+	if err != nil {
+		return nil, err
+	}
+
+	firstFloors, err := storage.GetFirstCollectionFloorPricesSince(fromHour)
+	if err != nil {
+		return nil, err
+	}
+
+	latestFloors, err := storage.GetLatestCollectionFloorPrices()
+	if err != nil {
+		return nil, err
+	}
+
+	firstOwners, err := storage.GetFirstCollectionOwnersSince(fromHour)
+	if err != nil {
+		return nil, err
+	}
+
+	latestOwners, err := storage.GetLatestCollectionOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uint64]*WindowRankingEntry, len(collections))
+	result := make([]WindowRankingEntry, len(collections))
+	for index, collection := range collections {
+		result[index] = WindowRankingEntry{
+			CollectionId:   collection.CollectionTokenID,
+			CollectionName: collection.Name,
+		}
+		entries[collection.ID] = &result[index]
+	}
+
+	for _, item := range volumes {
+		if entry, ok := entries[item.CollectionId]; ok {
+			entry.Volume = item.Volume
+			entry.SalesCount = item.SalesCount
+		}
+	}
+
+	startFloors := make(map[uint64]float64, len(firstFloors))
+	for _, item := range firstFloors {
+		startFloors[item.CollectionId] = item.FloorPrice
+	}
+	for _, item := range latestFloors {
+		if entry, ok := entries[item.CollectionId]; ok {
+			entry.FloorPrice = item.FloorPrice
+			entry.FloorChangePercentage = percentageChange(startFloors[item.CollectionId], item.FloorPrice)
+		}
+	}
+
+	startOwners := make(map[uint64]uint64, len(firstOwners))
+	for _, item := range firstOwners {
+		startOwners[item.CollectionId] = item.OwnersTotal
+	}
+	for _, item := range latestOwners {
+		if entry, ok := entries[item.CollectionId]; ok {
+			entry.OwnersTotal = item.OwnersTotal
+			if startCount, found := startOwners[item.CollectionId]; found {
+				entry.OwnersChange = int64(item.OwnersTotal) - int64(startCount)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// percentageChange is 0 when there was no floor to compare against.
+func percentageChange(from, to float64) float64 {
+	if from == 0 || to == 0 {
+		return 0
+	}
+
+	return (to - from) / from * 100
+}
+
+func windowCriteriaValue(entry WindowRankingEntry, criteria string) float64 {
+	switch criteria {
+	case SalesCount:
+		return float64(entry.SalesCount)
+	case FloorPrice:
+		return entry.FloorPrice
+	case FloorChange:
+		return entry.FloorChangePercentage
+	case OwnersTotal:
+		return float64(entry.OwnersTotal)
+	case OwnersChange:
+		return float64(entry.OwnersChange)
+	default:
+		return entry.Volume
+	}
+}
+
+func testWindowCriteria(criteria string) error {
+	switch criteria {
+	case VolumeTraded, SalesCount, FloorPrice, FloorChange, OwnersTotal, OwnersChange:
+		return nil
+	default:
+		return errors.New("not a valid window ranking criteria")
+	}
+}
@@ -0,0 +1,38 @@
+package gatherer
+
+import (
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/services"
+	"go.uber.org/zap"
+)
+
+const (
+	ImageHashDurationSeconds          = 5
+	DuplicateDetectionDurationMinutes = 30
+)
+
+// syncImageHashRunner hashes the media of newly indexed tokens and periodically looks for
+// collections copying a verified one.
+func syncImageHashRunner(cha chan bool) {
+	hashTicker := time.NewTicker(ImageHashDurationSeconds * time.Second)
+	detectTicker := time.NewTicker(DuplicateDetectionDurationMinutes * time.Minute)
+	for {
+		select {
+		case <-cha:
+			hashTicker.Stop()
+			detectTicker.Stop()
+			return
+		case <-hashTicker.C:
+			_, err := services.HashPendingTokenImages(services.TokenImageHashBatch)
+			if err != nil {
+				zlog.Error("could not hash token images", zap.Error(err))
+			}
+		case <-detectTicker.C:
+			err := services.DetectDuplicateCollections()
+			if err != nil {
+				zlog.Error("could not detect duplicate collections", zap.Error(err))
+			}
+		}
+	}
+}
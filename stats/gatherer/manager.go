@@ -5,7 +5,7 @@ import (
 )
 
 const (
-	MaxRunnerCount = 1
+	MaxRunnerCount = 2
 )
 
 // MARK: manager
@@ -47,6 +47,7 @@ func GetManager() *manager {
 func (m *manager) Start(blockchainAPI string) {
 	// Start hourly aggregator
 	go syncRarityRunner(m.controlChannels[0], blockchainAPI)
+	go syncImageHashRunner(m.controlChannels[1])
 }
 
 func (m *manager) Stop() {
@@ -2,22 +2,27 @@ package stats
 
 import (
 	"encoding/json"
-	"fmt"
+	"time"
 
 	"github.com/ENFT-DAO/youbei-api/data/dtos"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/ENFT-DAO/youbei-api/storage"
 )
 
+const (
+	AttributeVolumeWindow = 7 * 24 * time.Hour
+)
+
 type CollectionMetadata struct {
-	NumItems  uint64
-	Owners    map[uint64]bool
-	AttrStats []dtos.AttributeStat
+	NumItems    uint64
+	OwnersTotal uint64
+	AttrStats   []dtos.AttributeStat
 }
 
 func ComputeStatisticsForCollection(collectionId uint64) (*dtos.CollectionStatistics, error) {
 	var stats dtos.CollectionStatistics
 
-	minPrice, err := storage.GetMinBuyPriceForTransactionsWithCollectionId(collectionId)
+	floorPrice, err := storage.GetMinListPriceForTokensWithCollectionId(collectionId)
 	if err != nil {
 		return nil, err
 	}
@@ -34,8 +39,8 @@ func ComputeStatisticsForCollection(collectionId uint64) (*dtos.CollectionStatis
 
 	stats = dtos.CollectionStatistics{
 		ItemsTotal:   collectionMetadata.NumItems,
-		OwnersTotal:  uint64(len(collectionMetadata.Owners)),
-		FloorPrice:   minPrice,
+		OwnersTotal:  collectionMetadata.OwnersTotal,
+		FloorPrice:   floorPrice,
 		VolumeTraded: sumPrice,
 		AttrStats:    collectionMetadata.AttrStats,
 	}
@@ -44,83 +49,89 @@ func ComputeStatisticsForCollection(collectionId uint64) (*dtos.CollectionStatis
 }
 
 func ComputeCollectionMetadata(collectionId uint64) (*CollectionMetadata, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("Recovered in f", r)
-		}
-	}()
-	offset := 0
-	limit := 1_000
-	numItems := 0
-	ownersIDs := make(map[uint64]bool)
-	var globalAttrs []dtos.AttributeStat
-	for {
-		tokens, innerErr := storage.GetListedTokensByCollectionIdWithOffsetLimit(collectionId, offset, limit)
-		if innerErr != nil {
-			return nil, innerErr
-		}
-		if len(tokens) == 0 {
-			break
-		}
+	numItems, err := storage.CountTokensByCollectionId(collectionId)
+	if err != nil {
+		return nil, err
+	}
 
-		numItems = numItems + len(tokens)
-		for _, token := range tokens {
-			tokenAttrs := []map[string]interface{}{}
-			ownersIDs[token.OwnerID] = true
-			innerErr = json.Unmarshal(token.Attributes, &tokenAttrs)
-			if innerErr != nil {
-				continue
-			}
-			if len(tokenAttrs) == 0 {
-				continue
-			}
-			for _, obj := range tokenAttrs {
-				attributeFound := false
-				for index, globalAttr := range globalAttrs {
-
-					if _, ok := obj["trait_type"]; !ok {
-						for k, v := range obj {
-							if globalAttr.TraitType == k && globalAttr.Value == v {
-								attributeFound = true
-								globalAttrs[index].Total++
-							}
-						}
-					} else {
-						if globalAttr.TraitType == obj["trait_type"] && globalAttr.Value == obj["value"] {
-							attributeFound = true
-							globalAttrs[index].Total++
-						}
-					}
-				}
-
-				if !attributeFound {
-					if _, ok := obj["trait_type"]; !ok {
-						for k, v := range obj {
-							globalAttrs = append(globalAttrs, dtos.AttributeStat{
-								TraitType: k,
-								Value:     v,
-								Total:     1,
-							})
-						}
-					} else {
-						globalAttrs = append(globalAttrs, dtos.AttributeStat{
-							TraitType: obj["trait_type"].(string),
-							Value:     obj["value"],
-							Total:     1,
-						})
-
-					}
-				}
-			}
-		}
+	ownersTotal, err := storage.CountUniqueOwnersByCollectionId(collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := storage.GetAttributeSuppliesByCollectionId(collectionId)
+	if err != nil {
+		return nil, err
+	}
 
-		offset += limit
+	attrStats := make([]dtos.AttributeStat, len(aggregates))
+	for index, aggregate := range aggregates {
+		attrStats[index] = dtos.AttributeStat{
+			TraitType: aggregate.TraitType,
+			Value:     decodeAttributeValue(aggregate.Value),
+			Total:     aggregate.Supply,
+		}
 	}
 
 	result := CollectionMetadata{
-		NumItems:  uint64(numItems),
-		Owners:    ownersIDs,
-		AttrStats: globalAttrs,
+		NumItems:    numItems,
+		OwnersTotal: ownersTotal,
+		AttrStats:   attrStats,
 	}
 	return &result, nil
 }
+
+func ComputeAttributeAnalyticsForCollection(collectionId uint64) (*dtos.CollectionAttributeAnalytics, error) {
+	itemsTotal, err := storage.CountTokensByCollectionId(collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := storage.GetAttributeAggregatesByCollectionId(collectionId, volumeWindowStart())
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]dtos.AttributeAnalytics, len(aggregates))
+	for index, aggregate := range aggregates {
+		attributes[index] = makeAttributeAnalytics(aggregate, itemsTotal)
+	}
+
+	result := dtos.CollectionAttributeAnalytics{
+		ItemsTotal: itemsTotal,
+		Attributes: attributes,
+	}
+	return &result, nil
+}
+
+func makeAttributeAnalytics(aggregate entities.AttributeAggregate, itemsTotal uint64) dtos.AttributeAnalytics {
+	supplyPercentage := float64(0)
+	if itemsTotal > 0 {
+		supplyPercentage = float64(aggregate.Supply) * 100 / float64(itemsTotal)
+	}
+
+	return dtos.AttributeAnalytics{
+		TraitType:        aggregate.TraitType,
+		Value:            decodeAttributeValue(aggregate.Value),
+		Supply:           aggregate.Supply,
+		SupplyPercentage: supplyPercentage,
+		Listed:           aggregate.Listed,
+		FloorPrice:       aggregate.FloorPrice,
+		LastSalePrice:    aggregate.LastSalePrice,
+		Volume7d:         aggregate.Volume,
+	}
+}
+
+func decodeAttributeValue(raw string) interface{} {
+	var value interface{}
+	err := json.Unmarshal([]byte(raw), &value)
+	if err != nil {
+		return raw
+	}
+
+	return value
+}
+
+func volumeWindowStart() uint64 {
+	return uint64(time.Now().Add(-AttributeVolumeWindow).Unix())
+}
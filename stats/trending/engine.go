@@ -0,0 +1,159 @@
+package trending
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/config"
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/storage"
+	"github.com/ENFT-DAO/youbei-api/utils"
+	logger "github.com/ElrondNetwork/elrond-go-logger"
+)
+
+var (
+	log = logger.GetOrCreate("trending")
+
+	lock     sync.RWMutex
+	settings = defaultSettings()
+)
+
+func defaultSettings() config.TrendingConfig {
+	return config.TrendingConfig{
+		WindowHours:           24,
+		HalfLifeHours:         6,
+		SalesVelocityWeight:   0.4,
+		UniqueBuyersWeight:    0.3,
+		FloorMovementWeight:   0.2,
+		ListingActivityWeight: 0.1,
+	}
+}
+
+// Init sets the scoring weights. A zero window or half life keeps the default one.
+func Init(cfg config.TrendingConfig) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	defaults := defaultSettings()
+	if cfg.WindowHours == 0 {
+		cfg.WindowHours = defaults.WindowHours
+	}
+	if cfg.HalfLifeHours <= 0 {
+		cfg.HalfLifeHours = defaults.HalfLifeHours
+	}
+	settings = cfg
+}
+
+func getSettings() config.TrendingConfig {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return settings
+}
+
+// ComputeScores rescores every collection with recent activity and replaces the stored scores.
+func ComputeScores() error {
+	cfg := getSettings()
+	now := time.Now()
+	window := time.Duration(cfg.WindowHours) * time.Hour
+
+	activity, err := storage.GetDecayedTransactionActivity(
+		uint64(now.Add(-window).Unix()),
+		uint64(now.Unix()),
+		cfg.HalfLifeHours*time.Hour.Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	firstFloors, err := storage.GetFirstCollectionFloorPricesSince(utils.HourKey(now.Add(-window)))
+	if err != nil {
+		return err
+	}
+
+	latestFloors, err := storage.GetLatestCollectionFloorPrices()
+	if err != nil {
+		return err
+	}
+
+	scores := computeScores(cfg, activity, floorMovements(firstFloors, latestFloors))
+	err = storage.ReplaceCollectionTrendingScores(scores)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("trending scores computed", "collections", len(scores))
+	return nil
+}
+
+// floorMovements returns the relative floor rise of each collection over the window.
+// Falling floors count as no movement, they should not push a collection up.
+func floorMovements(first, latest []entities.CollectionFloorPricePerHour) map[uint64]float64 {
+	startFloors := make(map[uint64]float64, len(first))
+	for _, item := range first {
+		startFloors[item.CollectionId] = item.FloorPrice
+	}
+
+	movements := make(map[uint64]float64)
+	for _, item := range latest {
+		start := startFloors[item.CollectionId]
+		if start == 0 || item.FloorPrice <= start {
+			continue
+		}
+		movements[item.CollectionId] = (item.FloorPrice - start) / start
+	}
+
+	return movements
+}
+
+// computeScores normalizes every signal by its maximum across collections, so the weights
+// decide how much each one counts no matter its scale.
+func computeScores(cfg config.TrendingConfig, activity []entities.CollectionTrendingActivity, movements map[uint64]float64) []entities.CollectionTrendingScore {
+	scoresById := make(map[uint64]*entities.CollectionTrendingScore)
+	scoreFor := func(collectionId uint64) *entities.CollectionTrendingScore {
+		score, ok := scoresById[collectionId]
+		if !ok {
+			score = &entities.CollectionTrendingScore{CollectionId: collectionId}
+			scoresById[collectionId] = score
+		}
+		return score
+	}
+
+	for _, item := range activity {
+		score := scoreFor(item.CollectionId)
+		score.SalesVelocity = item.Sales
+		score.UniqueBuyers = item.UniqueBuyers
+		score.ListingActivity = item.Listings
+	}
+	for collectionId, movement := range movements {
+		scoreFor(collectionId).FloorMovement = movement
+	}
+
+	maxSales, maxBuyers, maxFloor, maxListings := 0.0, 0.0, 0.0, 0.0
+	for _, score := range scoresById {
+		maxSales = math.Max(maxSales, score.SalesVelocity)
+		maxBuyers = math.Max(maxBuyers, score.UniqueBuyers)
+		maxFloor = math.Max(maxFloor, score.FloorMovement)
+		maxListings = math.Max(maxListings, score.ListingActivity)
+	}
+
+	scores := make([]entities.CollectionTrendingScore, 0, len(scoresById))
+	for _, score := range scoresById {
+		score.Score = cfg.SalesVelocityWeight*normalize(score.SalesVelocity, maxSales) +
+			cfg.UniqueBuyersWeight*normalize(score.UniqueBuyers, maxBuyers) +
+			cfg.FloorMovementWeight*normalize(score.FloorMovement, maxFloor) +
+			cfg.ListingActivityWeight*normalize(score.ListingActivity, maxListings)
+		scores = append(scores, *score)
+	}
+
+	return scores
+}
+
+func normalize(value, max float64) float64 {
+	if max == 0 {
+		return 0
+	}
+
+	return value / max
+}
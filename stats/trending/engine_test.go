@@ -0,0 +1,46 @@
+package trending
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComputeScores(t *testing.T) {
+	cfg := defaultSettings()
+
+	activity := []entities.CollectionTrendingActivity{
+		{CollectionId: 1, Sales: 10, Listings: 2, UniqueBuyers: 5},
+		{CollectionId: 2, Sales: 5, Listings: 4, UniqueBuyers: 5},
+	}
+	movements := map[uint64]float64{3: 0.5}
+
+	scores := computeScores(cfg, activity, movements)
+	require.Len(t, scores, 3)
+
+	byId := make(map[uint64]entities.CollectionTrendingScore)
+	for _, score := range scores {
+		byId[score.CollectionId] = score
+	}
+
+	require.InDelta(t, 0.4+0.3+0.1*0.5, byId[1].Score, 1e-9)
+	require.InDelta(t, 0.4*0.5+0.3+0.1, byId[2].Score, 1e-9)
+	require.InDelta(t, 0.2, byId[3].Score, 1e-9)
+}
+
+func Test_FloorMovements(t *testing.T) {
+	first := []entities.CollectionFloorPricePerHour{
+		{CollectionId: 1, FloorPrice: 2},
+		{CollectionId: 2, FloorPrice: 2},
+		{CollectionId: 3, FloorPrice: 0},
+	}
+	latest := []entities.CollectionFloorPricePerHour{
+		{CollectionId: 1, FloorPrice: 3},
+		{CollectionId: 2, FloorPrice: 1},
+		{CollectionId: 3, FloorPrice: 1},
+	}
+
+	movements := floorMovements(first, latest)
+	require.Equal(t, map[uint64]float64{1: 0.5}, movements)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
@@ -60,13 +61,16 @@ func UpdateAccountProfileWhereName(name string, account entities.Account) error
 	return nil
 }
 
-func UpdateAccountProfileWhereId(accountId uint64, link string) error {
+func UpdateAccountProfileWhereId(accountId uint64, link string, variants datatypes.JSON) error {
 	database, err := GetDBOrError()
 	if err != nil {
 		return err
 	}
 
-	tx := database.Table("accounts").Where("id = ?", accountId).Update("profile_image_link", link)
+	tx := database.Table("accounts").Where("id = ?", accountId).Updates(map[string]interface{}{
+		"profile_image_link":     link,
+		"profile_image_variants": variants,
+	})
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -76,13 +80,16 @@ func UpdateAccountProfileWhereId(accountId uint64, link string) error {
 	return nil
 }
 
-func UpdateAccountCoverWhereId(accountId uint64, link string) error {
+func UpdateAccountCoverWhereId(accountId uint64, link string, variants datatypes.JSON) error {
 	database, err := GetDBOrError()
 	if err != nil {
 		return err
 	}
 
-	tx := database.Table("accounts").Where("id = ?", accountId).Update("cover_image_link", link)
+	tx := database.Table("accounts").Where("id = ?", accountId).Updates(map[string]interface{}{
+		"cover_image_link":     link,
+		"cover_image_variants": variants,
+	})
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -134,6 +141,23 @@ func GetAccountByAddress(address string) (*entities.Account, error) {
 	return &account, nil
 }
 
+func SetAccountBanned(accountId uint64, banned bool) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.Account{}).Where("id = ?", accountId).Update("is_banned", banned)
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 func GetAccountsWithNameAlikeWithLimit(name string, limit int) ([]entities.Account, error) {
 	var accounts []entities.Account
 
@@ -142,7 +166,7 @@ func GetAccountsWithNameAlikeWithLimit(name string, limit int) ([]entities.Accou
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Where("name ILIKE ?", name).Find(&accounts)
+	txRead := database.Limit(limit).Where("name ILIKE ? AND is_banned = false", name).Find(&accounts)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -188,3 +212,19 @@ func GetAccountsCount() (int64, error) {
 
 	return total, nil
 }
+
+func UpdateAccountRole(address string, role entities.AccountRole) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	tx := database.Table("accounts").Where("address = ?", address).Update("role", role)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 )
 
 func Test_AddAccount(t *testing.T) {
@@ -76,9 +77,30 @@ func Test_AddSecondAccount(t *testing.T) {
 	require.Equal(t, count, int64(2))
 }
 
+func Test_UpdateAccountProfileWhereId(t *testing.T) {
+	connectToTestDb()
+
+	account := defaultAccount()
+	account.Address = "erd_profile_variants"
+	account.Name = "profile_variants"
+	err := AddAccount(&account)
+	require.Nil(t, err)
+
+	variants := datatypes.JSON(`{"hash":"0123456789abcdef","url":"https://cdn/full.png","variants":[]}`)
+	err = UpdateAccountProfileWhereId(account.ID, "https://cdn/full.png", variants)
+	require.Nil(t, err)
+
+	accountRead, err := GetAccountById(account.ID)
+	require.Nil(t, err)
+	require.Equal(t, "https://cdn/full.png", accountRead.ProfileImageLink)
+	require.JSONEq(t, string(variants), string(accountRead.ProfileImageVariants))
+}
+
 func defaultAccount() entities.Account {
 	return entities.Account{
-		Address: "erd123",
-		Name:    "default",
+		Address:              "erd123",
+		Name:                 "default",
+		ProfileImageVariants: datatypes.JSON("null"),
+		CoverImageVariants:   datatypes.JSON("null"),
 	}
 }
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows an audit log query. Empty fields and zero times are not filtered on.
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetId   string
+	From       int64
+	To         int64
+}
+
+func AddAuditLog(auditLog *entities.AuditLog) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(auditLog).Error
+}
+
+// GetAuditLogs returns the matching entries, newest first. A negative limit returns every entry.
+func GetAuditLogs(filter AuditLogFilter, offset int, limit int) ([]entities.AuditLog, error) {
+	var auditLogs []entities.AuditLog
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := filterAuditLogs(database, filter).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&auditLogs)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return auditLogs, nil
+}
+
+func CountAuditLogs(filter AuditLogFilter) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := filterAuditLogs(database.Model(&entities.AuditLog{}), filter).Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+func filterAuditLogs(database *gorm.DB, filter AuditLogFilter) *gorm.DB {
+	if filter.Actor != "" {
+		database = database.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		database = database.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		database = database.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		database = database.Where("target_id = ?", filter.TargetId)
+	}
+	if filter.From != 0 {
+		database = database.Where("created_at >= ?", filter.From)
+	}
+	if filter.To != 0 {
+		database = database.Where("created_at < ?", filter.To)
+	}
+
+	return database
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func Test_GetAuditLogsFiltered(t *testing.T) {
+	connectToTestDb()
+
+	for index, action := range []string{"collection.adminSection", "collection.staking", "collection.adminSection"} {
+		err := AddAuditLog(&entities.AuditLog{
+			Actor:      "erd_auditor",
+			Action:     action,
+			TargetType: "collection",
+			TargetId:   "777",
+			Diff:       datatypes.JSON(`{}`),
+			RequestId:  "request",
+			CreatedAt:  int64(1000 + index),
+		})
+		require.Nil(t, err)
+	}
+
+	filter := AuditLogFilter{Actor: "erd_auditor", Action: "collection.adminSection", TargetId: "777"}
+	count, err := CountAuditLogs(filter)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), count)
+
+	logs, err := GetAuditLogs(filter, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, int64(1002), logs[0].CreatedAt)
+
+	filter.From = 1001
+	logs, err = GetAuditLogs(filter, 0, -1)
+	require.Nil(t, err)
+	require.Len(t, logs, 1)
+}
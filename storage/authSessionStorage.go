@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+func AddAuthSession(session *entities.AuthSession) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(session).Error
+}
+
+func GetAuthSessionById(id uint64) (*entities.AuthSession, error) {
+	var session entities.AuthSession
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&session, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &session, nil
+}
+
+// GetActiveAuthSessions returns the sessions of an address that are neither revoked nor expired, most recently used first.
+func GetActiveAuthSessions(address string, now int64) ([]entities.AuthSession, error) {
+	var sessions []entities.AuthSession
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Where("address = ? AND revoked_at = 0 AND expires_at > ?", address, now).
+		Order("last_used_at desc").
+		Find(&sessions)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return sessions, nil
+}
+
+// RotateAuthSessionRefreshHash replaces the refresh hash only if it is still oldHash and the session is not revoked.
+// It returns false when another request rotated or revoked the session first.
+func RotateAuthSessionRefreshHash(id uint64, oldHash string, newHash string, lastUsedAt int64, expiresAt int64) (bool, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txUpdate := database.Model(&entities.AuthSession{}).
+		Where("id = ? AND refresh_hash = ? AND revoked_at = 0", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_hash": newHash,
+			"last_used_at": lastUsedAt,
+			"expires_at":   expiresAt,
+		})
+	if txUpdate.Error != nil {
+		return false, txUpdate.Error
+	}
+
+	return txUpdate.RowsAffected == 1, nil
+}
+
+// RevokeAuthSessions revokes the given sessions of an address, or all of them when no id is given.
+// It returns the ids it revoked.
+func RevokeAuthSessions(address string, ids []uint64, now int64) ([]uint64, error) {
+	var revoked []uint64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&entities.AuthSession{}).Where("address = ? AND revoked_at = 0", address)
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+
+		txRead := query.Pluck("id", &revoked)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+		if len(revoked) == 0 {
+			return nil
+		}
+
+		return tx.Model(&entities.AuthSession{}).
+			Where("id IN ?", revoked).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
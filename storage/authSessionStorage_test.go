@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotateAndRevokeAuthSessions(t *testing.T) {
+	connectToTestDb()
+
+	phone := entities.AuthSession{Address: "erd_session", RefreshHash: "hash_1", ExpiresAt: 100}
+	laptop := entities.AuthSession{Address: "erd_session", RefreshHash: "hash_a", ExpiresAt: 100}
+	require.Nil(t, AddAuthSession(&phone))
+	require.Nil(t, AddAuthSession(&laptop))
+
+	rotated, err := RotateAuthSessionRefreshHash(phone.ID, "hash_1", "hash_2", 10, 200)
+	require.Nil(t, err)
+	require.True(t, rotated)
+
+	rotated, err = RotateAuthSessionRefreshHash(phone.ID, "hash_1", "hash_3", 10, 200)
+	require.Nil(t, err)
+	require.False(t, rotated)
+
+	revoked, err := RevokeAuthSessions("erd_session", []uint64{phone.ID}, 20)
+	require.Nil(t, err)
+	require.Equal(t, []uint64{phone.ID}, revoked)
+
+	sessions, err := GetActiveAuthSessions("erd_session", 50)
+	require.Nil(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, laptop.ID, sessions[0].ID)
+
+	revoked, err = RevokeAuthSessions("erd_session", nil, 30)
+	require.Nil(t, err)
+	require.Equal(t, []uint64{laptop.ID}, revoked)
+}
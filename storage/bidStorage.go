@@ -57,3 +57,19 @@ func GetBidsForTokenWithOffsetLimit(tokenId uint64, offset int, limit int) ([]en
 
 	return bids, nil
 }
+
+func GetBidsForTokenId(tokenId uint64) ([]entities.Bid, error) {
+	var bids []entities.Bid
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Order("timestamp asc").Find(&bids, "token_id = ?", tokenId)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return bids, nil
+}
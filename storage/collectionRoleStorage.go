@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetCollectionRoles(collectionId uint64) ([]entities.CollectionRole, error) {
+	var roles []entities.CollectionRole
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("collection_id = ?", collectionId).Order("id asc").Find(&roles)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return roles, nil
+}
+
+func GetCollectionRolesByAddress(address string) ([]entities.CollectionRole, error) {
+	var roles []entities.CollectionRole
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("address = ?", address).Find(&roles)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return roles, nil
+}
+
+func GetCollectionRole(collectionId uint64, address string) (*entities.CollectionRole, error) {
+	var role entities.CollectionRole
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&role, "collection_id = ? AND address = ?", collectionId, address)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &role, nil
+}
+
+// SetCollectionRole adds the role or replaces the one the address already has on the collection.
+func SetCollectionRole(role *entities.CollectionRole) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txCreate := database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "collection_id"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by"}),
+	}).Create(role)
+	return txCreate.Error
+}
+
+func DeleteCollectionRole(collectionId uint64, address string) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txDelete := database.Where("collection_id = ? AND address = ?", collectionId, address).Delete(&entities.CollectionRole{})
+	if txDelete.Error != nil {
+		return txDelete.Error
+	}
+	if txDelete.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func GetCollectionIdsByCreatorAddress(address string) ([]uint64, error) {
+	var ids []uint64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.Collection{}).
+		Joins("JOIN accounts ON accounts.id = collections.creator_id").
+		Where("accounts.address = ?", address).
+		Pluck("collections.id", &ids)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return ids, nil
+}
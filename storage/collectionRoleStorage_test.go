@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func Test_SetCollectionRoleReplaces(t *testing.T) {
+	connectToTestDb()
+
+	collection := defaultCollection()
+	err := AddCollection(&collection)
+	require.Nil(t, err)
+
+	err = SetCollectionRole(&entities.CollectionRole{
+		CollectionID: collection.ID,
+		Address:      "erd_role_holder",
+		Role:         entities.CollectionRoleEditor,
+		GrantedBy:    "erd_creator",
+	})
+	require.Nil(t, err)
+
+	err = SetCollectionRole(&entities.CollectionRole{
+		CollectionID: collection.ID,
+		Address:      "erd_role_holder",
+		Role:         entities.CollectionRoleWhitelistManager,
+		GrantedBy:    "erd_creator",
+	})
+	require.Nil(t, err)
+
+	roles, err := GetCollectionRoles(collection.ID)
+	require.Nil(t, err)
+	require.Len(t, roles, 1)
+	require.Equal(t, entities.CollectionRoleWhitelistManager, roles[0].Role)
+
+	err = DeleteCollectionRole(collection.ID, "erd_role_holder")
+	require.Nil(t, err)
+
+	_, err = GetCollectionRole(collection.ID, "erd_role_holder")
+	require.Equal(t, gorm.ErrRecordNotFound, err)
+}
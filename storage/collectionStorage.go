@@ -1,12 +1,17 @@
 package storage
 
 import (
+	"fmt"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 )
 
+// visibleCollectionsQuery leaves out the collections hidden or delisted by moderators from public listings.
+const visibleCollectionsQuery = "collections.is_hidden = false AND collections.is_delisted = false"
+
 func AddCollection(collection *entities.Collection) error {
 	database, err := GetDBOrError()
 	if err != nil {
@@ -56,13 +61,16 @@ func UpdateCollection(collection *entities.Collection) error {
 	return nil
 }
 
-func UpdateCollectionProfileWhereId(collectionId uint64, link string) error {
+func UpdateCollectionProfileWhereId(collectionId uint64, link string, variants datatypes.JSON) error {
 	database, err := GetDBOrError()
 	if err != nil {
 		return err
 	}
 
-	tx := database.Table("collections").Where("id = ?", collectionId).Update("profile_image_link", link)
+	tx := database.Table("collections").Where("id = ?", collectionId).Updates(map[string]interface{}{
+		"profile_image_link":     link,
+		"profile_image_variants": variants,
+	})
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -72,13 +80,16 @@ func UpdateCollectionProfileWhereId(collectionId uint64, link string) error {
 	return nil
 }
 
-func UpdateCollectionCoverWhereId(collectionId uint64, link string) error {
+func UpdateCollectionCoverWhereId(collectionId uint64, link string, variants datatypes.JSON) error {
 	database, err := GetDBOrError()
 	if err != nil {
 		return err
 	}
 
-	tx := database.Table("collections").Where("id = ?", collectionId).Update("cover_image_link", link)
+	tx := database.Table("collections").Where("id = ?", collectionId).Updates(map[string]interface{}{
+		"cover_image_link":     link,
+		"cover_image_variants": variants,
+	})
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -213,7 +224,7 @@ func GetCollectionsWithOffsetLimit(offset int, limit int, flags []string) ([]ent
 		return nil, err
 	}
 
-	txRead := database.Offset(offset).Limit(limit)
+	txRead := database.Offset(offset).Limit(limit).Where(visibleCollectionsQuery)
 	for _, flag := range flags {
 		txRead.Where(datatypes.JSONQuery("flags").HasKey(flag))
 	}
@@ -275,6 +286,63 @@ func GetAllCollectionAccounts() ([]entities.CollectionAccount, error) {
 }
 */
 
+// SetCollectionModeration hides or delists a collection, or brings it back.
+func SetCollectionModeration(collectionId uint64, hidden bool, delisted bool) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.Collection{}).
+		Where("id = ?", collectionId).
+		Updates(map[string]interface{}{"is_hidden": hidden, "is_delisted": delisted})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func SetCollectionVerified(collectionId uint64, verified bool) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.Collection{}).Where("id = ?", collectionId).Update("is_verified", verified)
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// IsCollectionModeratedByTokenId tells whether a collection is hidden or delisted, so kept out of public rankings.
+func IsCollectionModeratedByTokenId(tokenId string) (bool, error) {
+	var moderated []bool
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txRead := database.Model(&entities.Collection{}).Where("collection_token_id = ?", tokenId).Pluck("is_hidden OR is_delisted", &moderated)
+	if txRead.Error != nil {
+		return false, txRead.Error
+	}
+	if len(moderated) == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+
+	return moderated[0], nil
+}
+
 func GetCollectionsWithNameAlikeWithLimit(name string, limit int) ([]entities.Collection, error) {
 	var collections []entities.Collection
 
@@ -283,7 +351,7 @@ func GetCollectionsWithNameAlikeWithLimit(name string, limit int) ([]entities.Co
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Order("is_verified desc").Where("name ILIKE ?", name).Find(&collections)
+	txRead := database.Limit(limit).Order("is_verified desc").Where("name ILIKE ? AND is_hidden = false", name).Find(&collections)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -299,7 +367,7 @@ func GetCollectionsByCreatorIdWithOffsetLimit(creatorId uint64, offset int, limi
 		return nil, err
 	}
 
-	txRead := database.Offset(offset).Limit(limit).Find(&collections, "creator_id = ?", creatorId)
+	txRead := database.Offset(offset).Limit(limit).Where(visibleCollectionsQuery).Find(&collections, "creator_id = ?", creatorId)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -316,7 +384,7 @@ func GetCollectionsVerified(limit int) ([]entities.Collection, error) {
 	}
 
 	//is_verifed and create_at in desc in order (most recent first)
-	txRead := database.Limit(limit).Order("created_at desc").Find(&collections, "is_verified = true AND profile_image_link <> ''")
+	txRead := database.Limit(limit).Order("created_at desc").Where(visibleCollectionsQuery).Find(&collections, "is_verified = true AND profile_image_link <> ''")
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -338,6 +406,7 @@ func GetCollectionsVerifiedByAddress(address string, limit int) ([]entities.Coll
 		Joins("inner join accounts on accounts.id=collections.creator_id").
 		Order("collections.created_at desc").
 		Where("collections.is_verified = true AND collections.profile_image_link <> '' AND accounts.address=?", address).
+		Where(visibleCollectionsQuery).
 		Find(&collections)
 	if txRead.Error != nil {
 		return nil, txRead.Error
@@ -354,7 +423,7 @@ func GetCollectionsNoteworthy(limit int) ([]entities.Collection, error) {
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Order("priority desc").Find(&collections, "type = 2 AND profile_image_link <> ''")
+	txRead := database.Limit(limit).Order("priority desc").Where(visibleCollectionsQuery).Find(&collections, "type = 2 AND profile_image_link <> ''")
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -362,6 +431,8 @@ func GetCollectionsNoteworthy(limit int) ([]entities.Collection, error) {
 	return collections, nil
 }
 
+// GetCollectionsTrending orders collections by their trending score, pinned collections first.
+// Suppressed and moderated collections are left out, as are verified and noteworthy ones which have their own sections.
 func GetCollectionsTrending(limit int) ([]entities.Collection, error) {
 	var collections []entities.Collection
 
@@ -370,17 +441,18 @@ func GetCollectionsTrending(limit int) ([]entities.Collection, error) {
 		return nil, err
 	}
 
-	/*
-		SELECT *
-		FROM public.collections
-		where is_verified <> true AND type <> 2 AND profile_image_link <> ''
-		ORDER BY created_at DESC
-	*/
-
-	//Do not include in verified and noteworthy accounts
-	//right now, we are just getting the recently added collection
-	//TODO: determine the metrics for
-	txRead := database.Limit(limit).Order("created_at desc").Find(&collections, "is_verified <> true AND type <> 2 AND profile_image_link <> ''")
+	txRead := database.
+		Select("collections.*").
+		Joins("LEFT JOIN collection_trending_scores ON collection_trending_scores.collection_id = collections.id").
+		Joins("LEFT JOIN collection_trending_overrides ON collection_trending_overrides.collection_id = collections.id").
+		Where(visibleCollectionsQuery).
+		Where("collection_trending_overrides.mode IS NULL OR collection_trending_overrides.mode <> ?", entities.TrendingSuppress).
+		Where("collection_trending_overrides.mode = ? OR (collections.is_verified <> true AND collections.type <> 2 AND collections.profile_image_link <> '')", entities.TrendingPin).
+		Order(fmt.Sprintf("CASE WHEN collection_trending_overrides.mode = '%s' THEN 0 ELSE 1 END", entities.TrendingPin)).
+		Order("collection_trending_scores.score DESC NULLS LAST").
+		Order("collections.created_at desc").
+		Limit(limit).
+		Find(&collections)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -113,9 +113,9 @@ func Test_CollectionWithNameILike(t *testing.T) {
 
 func defaultCollection() entities.Collection {
 	return entities.Collection{
-		Name:      "default",
-		TokenID:   "my_token",
-		CreatorID: 0,
+		Name:              "default",
+		CollectionTokenID: "my_token",
+		CreatorID:         0,
 	}
 }
 
@@ -129,14 +129,14 @@ func addCollectionsWithPriority() {
 func collectionsWithPriority() []entities.Collection {
 	return []entities.Collection{
 		{
-			Name:     "first_coll",
-			TokenID:  "first_token_id",
-			Priority: 100,
+			Name:              "first_coll",
+			CollectionTokenID: "first_token_id",
+			Priority:          100,
 		},
 		{
-			Name:     "second_coll",
-			TokenID:  "second_token_id",
-			Priority: 50,
+			Name:              "second_coll",
+			CollectionTokenID: "second_token_id",
+			Priority:          50,
 		},
 	}
 }
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func AddCollectionVerification(verification *entities.CollectionVerification) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txCreate := database.Create(verification)
+	if txCreate.Error != nil {
+		return txCreate.Error
+	}
+	if txCreate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func GetCollectionVerificationById(id uint64) (*entities.CollectionVerification, error) {
+	var verification entities.CollectionVerification
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Collection").Find(&verification, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &verification, nil
+}
+
+// GetLatestCollectionVerification returns the last application filed for the collection.
+func GetLatestCollectionVerification(collectionId uint64) (*entities.CollectionVerification, error) {
+	var verification entities.CollectionVerification
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("collection_id = ?", collectionId).Order("id desc").Limit(1).Find(&verification)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &verification, nil
+}
+
+func HasPendingCollectionVerification(collectionId uint64) (bool, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txRead := database.Model(&entities.CollectionVerification{}).
+		Where("collection_id = ? AND status = ?", collectionId, entities.VerificationPending).
+		Count(&count)
+	if txRead.Error != nil {
+		return false, txRead.Error
+	}
+
+	return count > 0, nil
+}
+
+// GetCollectionVerifications returns the applications with a status, or every application when it is empty, oldest first.
+func GetCollectionVerifications(status entities.VerificationStatus, offset int, limit int) ([]entities.CollectionVerification, error) {
+	var verifications []entities.CollectionVerification
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := filterCollectionVerifications(database, status).
+		Preload("Collection").
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&verifications)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return verifications, nil
+}
+
+func CountCollectionVerifications(status entities.VerificationStatus) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := filterCollectionVerifications(database.Model(&entities.CollectionVerification{}), status).Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// UpdateCollectionVerificationChecks stores the results of the automated checks of an application.
+func UpdateCollectionVerificationChecks(verification *entities.CollectionVerification) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.CollectionVerification{}).
+		Where("id = ?", verification.ID).
+		Updates(map[string]interface{}{
+			"contract_owner":               verification.ContractOwner,
+			"ownership_check":              verification.OwnershipCheck,
+			"duplicate_score":              verification.DuplicateScore,
+			"duplicate_matched_collection": verification.DuplicateMatchedCollection,
+			"duplicate_flag_status":        verification.DuplicateFlagStatus,
+			"checks_run_at":                verification.ChecksRunAt,
+		})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetCollectionVerificationDecision records the decision on a pending application.
+// An application already decided is not found.
+func SetCollectionVerificationDecision(id uint64, status entities.VerificationStatus, reasons datatypes.JSON, note string, reviewedBy string, reviewedAt int64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.CollectionVerification{}).
+		Where("id = ? AND status = ?", id, entities.VerificationPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reasons":     reasons,
+			"review_note": note,
+			"reviewed_by": reviewedBy,
+			"reviewed_at": reviewedAt,
+		})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func filterCollectionVerifications(query *gorm.DB, status entities.VerificationStatus) *gorm.DB {
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	return query
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CollectionVerificationIsDecidedOnce(t *testing.T) {
+	connectToTestDb()
+
+	collection := entities.Collection{Name: "applicant", CollectionTokenID: "APPL-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	require.Nil(t, AddCollection(&collection))
+
+	verification := entities.CollectionVerification{
+		CollectionID:     collection.ID,
+		ApplicantAddress: "erd_applicant",
+		TeamContact:      "team@youbei.io",
+		OwnershipCheck:   entities.VerificationCheckPassed,
+		Status:           entities.VerificationPending,
+		Reasons:          []byte("[]"),
+	}
+	require.Nil(t, AddCollectionVerification(&verification))
+
+	pending, err := HasPendingCollectionVerification(collection.ID)
+	require.Nil(t, err)
+	require.True(t, pending)
+
+	err = SetCollectionVerificationDecision(verification.ID, entities.VerificationRejected, []byte(`["no website"]`), "", "erd_reviewer", 100)
+	require.Nil(t, err)
+
+	err = SetCollectionVerificationDecision(verification.ID, entities.VerificationApproved, []byte("[]"), "", "erd_reviewer", 200)
+	require.NotNil(t, err)
+
+	latest, err := GetLatestCollectionVerification(collection.ID)
+	require.Nil(t, err)
+	require.Equal(t, entities.VerificationRejected, latest.Status)
+	require.JSONEq(t, `["no website"]`, string(latest.Reasons))
+
+	pending, err = HasPendingCollectionVerification(collection.ID)
+	require.Nil(t, err)
+	require.False(t, pending)
+}
+
+func Test_MarkNotificationsRead(t *testing.T) {
+	connectToTestDb()
+
+	address := "erd_notified" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	for i := 0; i < 3; i++ {
+		require.Nil(t, AddNotification(&entities.Notification{Address: address, Kind: entities.NotificationCollectionVerification, Data: []byte("{}")}))
+	}
+
+	notifications, err := GetNotificationsByAddress(address, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, notifications, 3)
+
+	marked, err := MarkNotificationsRead(address, []uint64{notifications[0].ID})
+	require.Nil(t, err)
+	require.Equal(t, int64(1), marked)
+
+	unread, err := CountNotificationsByAddress(address, true)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), unread)
+
+	marked, err = MarkNotificationsRead(address, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), marked)
+}
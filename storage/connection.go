@@ -56,6 +56,11 @@ func TryMigrate() error {
 		zlog.Error("account migration", zap.Error(err))
 	}
 
+	err = db.AutoMigrate(&entities.AuthSession{})
+	if err != nil {
+		zlog.Error("AuthSession migration", zap.Error(err))
+	}
+
 	err = db.AutoMigrate(&entities.Token{})
 	if err != nil {
 		zlog.Error("Token migration", zap.Error(err))
@@ -71,6 +76,61 @@ func TryMigrate() error {
 		zlog.Error("Collection migration", zap.Error(err))
 	}
 
+	err = db.AutoMigrate(&entities.CollectionRole{})
+	if err != nil {
+		zlog.Error("CollectionRole migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.AuditLog{})
+	if err != nil {
+		zlog.Error("AuditLog migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintCheckout{})
+	if err != nil {
+		zlog.Error("PrintCheckout migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintRoyalty{})
+	if err != nil {
+		zlog.Error("PrintRoyalty migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintOrder{})
+	if err != nil {
+		zlog.Error("PrintOrder migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintOrderItem{})
+	if err != nil {
+		zlog.Error("PrintOrderItem migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintOrderStatusChange{})
+	if err != nil {
+		zlog.Error("PrintOrderStatusChange migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.PrintOrderWebhookEvent{})
+	if err != nil {
+		zlog.Error("PrintOrderWebhookEvent migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.CollectionMintPhase{})
+	if err != nil {
+		zlog.Error("CollectionMintPhase migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.MintTransaction{})
+	if err != nil {
+		zlog.Error("MintTransaction migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.CollectionMintProgress{})
+	if err != nil {
+		zlog.Error("CollectionMintProgress migration", zap.Error(err))
+	}
+
 	err = db.AutoMigrate(&entities.Offer{})
 	if err != nil {
 		zlog.Error("Offer migration", zap.Error(err))
@@ -81,16 +141,50 @@ func TryMigrate() error {
 		zlog.Error("Bid migration", zap.Error(err))
 	}
 
+	// allocations used to be stored without a unique index, keep the first of each so it can be built
+	if db.Migrator().HasTable(&entities.Whitelist{}) {
+		err = db.Exec("DELETE FROM whitelists a USING whitelists b " +
+			"WHERE a.collection_id = b.collection_id AND a.address = b.address AND a.type = b.type AND a.id > b.id").Error
+		if err != nil {
+			zlog.Error("Whitelist duplicates cleanup", zap.Error(err))
+		}
+	}
+
 	err = db.AutoMigrate(&entities.Whitelist{})
 	if err != nil {
 		zlog.Error("Whitelist migration", zap.Error(err))
 	}
 
+	err = db.AutoMigrate(&entities.WhitelistMerkleRoot{})
+	if err != nil {
+		zlog.Error("WhitelistMerkleRoot migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.WhitelistMerkleLeaf{})
+	if err != nil {
+		zlog.Error("WhitelistMerkleLeaf migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.CollectionLaunch{})
+	if err != nil {
+		zlog.Error("CollectionLaunch migration", zap.Error(err))
+	}
+
+	err = db.AutoMigrate(&entities.CollectionLaunchStep{})
+	if err != nil {
+		zlog.Error("CollectionLaunchStep migration", zap.Error(err))
+	}
+
 	err = db.AutoMigrate(&entities.SessionState{})
 	if err != nil {
 		zlog.Error("SessionState migration", zap.Error(err))
 	}
 
+	err = db.AutoMigrate(&entities.Draft{})
+	if err != nil {
+		zlog.Error("Draft migration", zap.Error(err))
+	}
+
 	err = db.AutoMigrate(&entities.MarketPlaceStat{})
 	if err != nil {
 		zlog.Error("MarketPlaceStat migration", zap.Error(err))
@@ -116,11 +210,77 @@ func TryMigrate() error {
 		return err
 	}
 
+	err = db.AutoMigrate(&entities.CollectionFloorPricePerHour{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.CollectionOwnersPerHour{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.AggregatedVolumeRollup{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.CollectionTrendingScore{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.CollectionTrendingOverride{})
+	if err != nil {
+		return err
+	}
+
 	err = db.AutoMigrate(&entities.UserOrders{})
 	if err != nil {
 		return err
 	}
-	return nil
+
+	err = db.AutoMigrate(&entities.PrintProduct{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.TokenImageHash{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.CollectionDuplicateFlag{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.ContentReport{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.CollectionVerification{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.Notification{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.TokenEvent{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&entities.TokenHistoryCursor{})
+	if err != nil {
+		return err
+	}
+
+	return seedPrintProducts()
 }
 
 func GetDB() *gorm.DB {
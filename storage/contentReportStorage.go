@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+func AddContentReport(report *entities.ContentReport) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txCreate := database.Create(report)
+	if txCreate.Error != nil {
+		return txCreate.Error
+	}
+	if txCreate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// HasOpenContentReport tells whether the reporter already has an open report of the target.
+func HasOpenContentReport(reporter string, targetType entities.ReportTargetType, targetId string, targetNonce uint64) (bool, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txRead := database.Model(&entities.ContentReport{}).
+		Where("reporter_address = ? AND status = ?", reporter, entities.ReportOpen).
+		Where("target_type = ? AND target_id = ? AND target_nonce = ?", targetType, targetId, targetNonce).
+		Count(&count)
+	if txRead.Error != nil {
+		return false, txRead.Error
+	}
+
+	return count > 0, nil
+}
+
+// GetContentReports returns the reports matching the non empty criteria, newest first.
+func GetContentReports(filter *entities.ContentReport, offset int, limit int) ([]entities.ContentReport, error) {
+	var reports []entities.ContentReport
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := filterContentReports(database, filter).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&reports)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return reports, nil
+}
+
+func CountContentReports(filter *entities.ContentReport) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := filterContentReports(database.Model(&entities.ContentReport{}), filter).Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// GetReportedTargets groups the open reports by target, the most reported first.
+// An empty target type returns the targets of every type.
+func GetReportedTargets(targetType entities.ReportTargetType, offset int, limit int) ([]entities.ReportedTarget, error) {
+	var targets []entities.ReportedTarget
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := reportedTargetsQuery(database, targetType).
+		Order("reports desc, last_reported_at desc").
+		Offset(offset).
+		Limit(limit).
+		Scan(&targets)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return targets, nil
+}
+
+func CountReportedTargets(targetType entities.ReportTargetType) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := database.Table("(?) as targets", reportedTargetsQuery(database, targetType)).Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// ResolveContentReports closes the open reports of a target with the action taken on it.
+func ResolveContentReports(targetType entities.ReportTargetType, targetId string, targetNonce uint64, status entities.ReportStatus, action entities.ModerationAction, resolvedBy string, note string, resolvedAt int64) (int64, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txUpdate := database.Model(&entities.ContentReport{}).
+		Where("status = ?", entities.ReportOpen).
+		Where("target_type = ? AND target_id = ? AND target_nonce = ?", targetType, targetId, targetNonce).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"action":          action,
+			"resolved_by":     resolvedBy,
+			"resolution_note": note,
+			"resolved_at":     resolvedAt,
+		})
+	if txUpdate.Error != nil {
+		return 0, txUpdate.Error
+	}
+
+	return txUpdate.RowsAffected, nil
+}
+
+func reportedTargetsQuery(database *gorm.DB, targetType entities.ReportTargetType) *gorm.DB {
+	query := database.Model(&entities.ContentReport{}).
+		Select("target_type, target_id, target_nonce, count(*) as reports, string_agg(distinct reason, ',') as reasons, "+
+			"min(created_at) as first_reported_at, max(created_at) as last_reported_at").
+		Where("status = ?", entities.ReportOpen).
+		Group("target_type, target_id, target_nonce")
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	return query
+}
+
+func filterContentReports(query *gorm.DB, filter *entities.ContentReport) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ? AND target_nonce = ?", filter.TargetID, filter.TargetNonce)
+	}
+
+	return query
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContentReportsGroupAndResolvePerTarget(t *testing.T) {
+	connectToTestDb()
+
+	collectionId := "SCAM-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	for index, reason := range []entities.ReportReason{entities.ReportReasonScam, entities.ReportReasonScam, entities.ReportReasonCopyright} {
+		err := AddContentReport(&entities.ContentReport{
+			TargetType:      entities.ReportTargetCollection,
+			TargetID:        collectionId,
+			Reason:          reason,
+			ReporterAddress: "erd_reporter" + strconv.Itoa(index),
+			Status:          entities.ReportOpen,
+			CreatedAt:       int64(100 + index),
+		})
+		require.Nil(t, err)
+	}
+
+	open, err := HasOpenContentReport("erd_reporter0", entities.ReportTargetCollection, collectionId, 0)
+	require.Nil(t, err)
+	require.True(t, open)
+
+	targets, err := GetReportedTargets(entities.ReportTargetCollection, 0, 1000)
+	require.Nil(t, err)
+
+	var target *entities.ReportedTarget
+	for index := range targets {
+		if targets[index].TargetID == collectionId {
+			target = &targets[index]
+		}
+	}
+	require.NotNil(t, target)
+	require.Equal(t, int64(3), target.Reports)
+	require.Equal(t, "copyright,scam", target.Reasons)
+	require.Equal(t, int64(100), target.FirstReportedAt)
+	require.Equal(t, int64(102), target.LastReportedAt)
+
+	resolved, err := ResolveContentReports(entities.ReportTargetCollection, collectionId, 0, entities.ReportActioned, entities.ModerationHide, "erd_moderator", "", 200)
+	require.Nil(t, err)
+	require.Equal(t, int64(3), resolved)
+
+	count, err := CountContentReports(&entities.ContentReport{Status: entities.ReportOpen, TargetType: entities.ReportTargetCollection, TargetID: collectionId})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), count)
+}
+
+func Test_ModeratedContentIsLeftOutOfListings(t *testing.T) {
+	connectToTestDb()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	visible := entities.Collection{Name: "visible", CollectionTokenID: "SHOWN-" + suffix, Type: 2, ProfileImageLink: "shown.png", Priority: 1000000}
+	require.Nil(t, AddCollection(&visible))
+	delisted := entities.Collection{Name: "delisted", CollectionTokenID: "DELIST-" + suffix, Type: 2, ProfileImageLink: "delisted.png", Priority: 1000000}
+	require.Nil(t, AddCollection(&delisted))
+	require.Nil(t, SetCollectionModeration(delisted.ID, false, true))
+
+	noteworthy, err := GetCollectionsNoteworthy(1000)
+	require.Nil(t, err)
+	ids := map[uint64]bool{}
+	for _, collection := range noteworthy {
+		ids[collection.ID] = true
+	}
+	require.True(t, ids[visible.ID])
+	require.False(t, ids[delisted.ID])
+
+	shown := entities.Token{TokenID: "SHOWN-" + suffix, Nonce: 1, CollectionID: visible.ID}
+	require.Nil(t, AddToken(&shown))
+	hidden := entities.Token{TokenID: "SHOWN-" + suffix, Nonce: 2, CollectionID: visible.ID}
+	require.Nil(t, AddToken(&hidden))
+	require.Nil(t, SetTokenModeration(hidden.ID, true, false))
+
+	tokens, err := GetTokensByCollectionIdWithOffsetLimit(visible.ID, 0, 10, nil, nil, false, false, entities.QueryFilter{})
+	require.Nil(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, shown.ID, tokens[0].ID)
+}
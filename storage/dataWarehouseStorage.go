@@ -2,7 +2,12 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/ENFT-DAO/youbei-api/utils"
 	"gorm.io/gorm"
 )
 
@@ -164,7 +169,7 @@ func GetAggregatedTradedVolumePerCollectionHourly(fromDate, toDate string) ([]en
 	var records []entities.GroupAggregatedVolumePerCollection
 
 	txRead := database.Table("transactions").
-		Select("transactions.type as type, transactions.collection_id as collectionId, sum(transactions.price_nominal) as total").
+		Select("transactions.type as type, transactions.collection_id as collection_id, sum(transactions.price_nominal) as total, count(*) as count").
 		Where("date_trunc('hour', to_timestamp(transactions.timestamp))>=? and date_trunc('hour', to_timestamp(transactions.timestamp))<?", fromDate, toDate).
 		Group("transactions.type").
 		Group("transactions.collection_id").
@@ -176,3 +181,320 @@ func GetAggregatedTradedVolumePerCollectionHourly(fromDate, toDate string) ([]en
 
 	return records, nil
 }
+
+func AddOrUpdateCollectionFloorPricePerHour(record *entities.CollectionFloorPricePerHour) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	recordCount := int64(0)
+	err = database.Model(&entities.CollectionFloorPricePerHour{}).
+		Where("hour=? and collection_id=?", record.Hour, record.CollectionId).
+		Count(&recordCount).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if recordCount == 0 {
+		txCreate := database.Create(record)
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	} else {
+		txCreate := database.Model(record).
+			Where("hour=? and collection_id=?", record.Hour, record.CollectionId).
+			Updates(map[string]interface{}{
+				"floor_price":  record.FloorPrice,
+				"listed_count": record.ListedCount,
+			})
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+func GetCollectionFloorPriceHistory(collectionId uint64, fromHour int64) ([]entities.CollectionFloorPricePerHour, error) {
+	var records []entities.CollectionFloorPricePerHour
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Where("collection_id=? and hour>=?", collectionId, fromHour).
+		Order("hour asc").
+		Find(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+func AddOrUpdateCollectionOwnersPerHour(record *entities.CollectionOwnersPerHour) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	recordCount := int64(0)
+	err = database.Model(&entities.CollectionOwnersPerHour{}).
+		Where("hour=? and collection_id=?", record.Hour, record.CollectionId).
+		Count(&recordCount).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if recordCount == 0 {
+		txCreate := database.Create(record)
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	} else {
+		txCreate := database.Model(record).
+			Where("hour=? and collection_id=?", record.Hour, record.CollectionId).
+			Updates(map[string]interface{}{
+				"owners_total": record.OwnersTotal,
+			})
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+// GetCollectionVolumesInHourRange sums the hourly buy volume and sales of every collection
+// over the hours in [fromHour, toHour).
+func GetCollectionVolumesInHourRange(fromHour, toHour int64) ([]entities.CollectionWindowVolume, error) {
+	var records []entities.CollectionWindowVolume
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.AggregatedVolumePerCollectionPerHour{}).
+		Select("collection_id, sum(buy_volume) as volume, sum(buy_count) as sales_count").
+		Where("hour>=? and hour<?", fromHour, toHour).
+		Group("collection_id").
+		Scan(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+// GetFirstCollectionFloorPricesSince returns, per collection, the oldest floor snapshot not older than fromHour.
+func GetFirstCollectionFloorPricesSince(fromHour int64) ([]entities.CollectionFloorPricePerHour, error) {
+	var records []entities.CollectionFloorPricePerHour
+	err := scanEdgeSnapshots(&entities.CollectionFloorPricePerHour{}, fromHour, "asc", &records)
+	return records, err
+}
+
+// GetLatestCollectionFloorPrices returns the newest floor snapshot of every collection.
+func GetLatestCollectionFloorPrices() ([]entities.CollectionFloorPricePerHour, error) {
+	var records []entities.CollectionFloorPricePerHour
+	err := scanEdgeSnapshots(&entities.CollectionFloorPricePerHour{}, 0, "desc", &records)
+	return records, err
+}
+
+// GetFirstCollectionOwnersSince returns, per collection, the oldest owners snapshot not older than fromHour.
+func GetFirstCollectionOwnersSince(fromHour int64) ([]entities.CollectionOwnersPerHour, error) {
+	var records []entities.CollectionOwnersPerHour
+	err := scanEdgeSnapshots(&entities.CollectionOwnersPerHour{}, fromHour, "asc", &records)
+	return records, err
+}
+
+// GetLatestCollectionOwners returns the newest owners snapshot of every collection.
+func GetLatestCollectionOwners() ([]entities.CollectionOwnersPerHour, error) {
+	var records []entities.CollectionOwnersPerHour
+	err := scanEdgeSnapshots(&entities.CollectionOwnersPerHour{}, 0, "desc", &records)
+	return records, err
+}
+
+func scanEdgeSnapshots(model interface{}, fromHour int64, hourOrder string, dest interface{}) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txRead := database.Model(model).
+		Select("DISTINCT ON (collection_id) *").
+		Where("hour>=?", fromHour).
+		Order(fmt.Sprintf("collection_id, hour %s", hourOrder)).
+		Find(dest)
+
+	return txRead.Error
+}
+
+var rollupPeriodKeyExpressions = map[entities.RollupPeriod]string{
+	entities.RollupDay:   "hour / 100",
+	entities.RollupWeek:  "to_char(date_trunc('week', to_date((hour / 100)::text, 'YYYYMMDD')), 'YYYYMMDD')::bigint",
+	entities.RollupMonth: "hour / 10000",
+}
+
+// ReaggregateVolumePerHour rebuilds the hourly buckets of [from, to) from the transactions table.
+// The window is cleared first, so running it again after a reindex leaves no stale rows behind.
+func ReaggregateVolumePerHour(from, to time.Time) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	fromHour, toHour := utils.HourKey(from), utils.HourKey(to)
+	return database.Transaction(func(tx *gorm.DB) error {
+		txDelete := tx.Where("hour>=? and hour<?", fromHour, toHour).Delete(&entities.AggregatedVolumePerCollectionPerHour{})
+		if txDelete.Error != nil {
+			return txDelete.Error
+		}
+
+		txDelete = tx.Where("hour>=? and hour<?", fromHour, toHour).Delete(&entities.AggregatedVolumePerHour{})
+		if txDelete.Error != nil {
+			return txDelete.Error
+		}
+
+		txInsert := tx.Exec(`
+			INSERT INTO aggregated_volume_per_collection_per_hours (hour, collection_id, buy_volume, list_volume, withdraw_volume, buy_count)
+			SELECT to_char(to_timestamp(timestamp) AT TIME ZONE 'UTC', 'YYYYMMDDHH24')::bigint AS hour,
+				collection_id,
+				COALESCE(SUM(price_nominal) FILTER (WHERE type = ?), 0),
+				COALESCE(SUM(price_nominal) FILTER (WHERE type = ?), 0),
+				COALESCE(SUM(price_nominal) FILTER (WHERE type = ?), 0),
+				COUNT(*) FILTER (WHERE type = ?)
+			FROM transactions
+			WHERE timestamp >= ? AND timestamp < ?
+			GROUP BY 1, collection_id`,
+			entities.BuyToken, entities.ListToken, entities.WithdrawToken, entities.BuyToken,
+			from.Unix(), to.Unix())
+		if txInsert.Error != nil {
+			return txInsert.Error
+		}
+
+		txInsert = tx.Exec(`
+			INSERT INTO aggregated_volume_per_hours (hour, buy_volume, list_volume, withdraw_volume)
+			SELECT hour, SUM(buy_volume), SUM(list_volume), SUM(withdraw_volume)
+			FROM aggregated_volume_per_collection_per_hours
+			WHERE hour >= ? AND hour < ?
+			GROUP BY hour`,
+			fromHour, toHour)
+		return txInsert.Error
+	})
+}
+
+// RollupVolume recomputes the given periods from the hourly collection buckets.
+func RollupVolume(period entities.RollupPeriod, periodKeys []int64) error {
+	keyExpression, ok := rollupPeriodKeyExpressions[period]
+	if !ok {
+		return errors.New("unknown rollup period")
+	}
+	if len(periodKeys) == 0 {
+		return nil
+	}
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		txDelete := tx.Where("period=? and period_key IN ?", period, periodKeys).Delete(&entities.AggregatedVolumeRollup{})
+		if txDelete.Error != nil {
+			return txDelete.Error
+		}
+
+		txInsert := tx.Exec(fmt.Sprintf(`
+			INSERT INTO aggregated_volume_rollups (period, period_key, collection_id, buy_volume, list_volume, withdraw_volume, buy_count)
+			SELECT ?, %[1]s, collection_id, SUM(buy_volume), SUM(list_volume), SUM(withdraw_volume), SUM(buy_count)
+			FROM aggregated_volume_per_collection_per_hours
+			WHERE %[1]s IN ?
+			GROUP BY %[1]s, collection_id`, keyExpression),
+			period, periodKeys)
+		return txInsert.Error
+	})
+}
+
+// GetMarketVolumeRollups sums the rollups of all collections for every period key in [fromKey, toKey].
+func GetMarketVolumeRollups(period entities.RollupPeriod, fromKey, toKey int64) ([]entities.AggregatedVolumeRollup, error) {
+	var records []entities.AggregatedVolumeRollup
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.AggregatedVolumeRollup{}).
+		Select("period, period_key, sum(buy_volume) as buy_volume, sum(list_volume) as list_volume, sum(withdraw_volume) as withdraw_volume, sum(buy_count) as buy_count").
+		Where("period=? and period_key>=? and period_key<=?", period, fromKey, toKey).
+		Group("period, period_key").
+		Order("period_key asc").
+		Scan(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+func GetCollectionVolumeRollups(collectionId uint64, period entities.RollupPeriod, fromKey, toKey int64) ([]entities.AggregatedVolumeRollup, error) {
+	var records []entities.AggregatedVolumeRollup
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Where("collection_id=? and period=? and period_key>=? and period_key<=?", collectionId, period, fromKey, toKey).
+		Order("period_key asc").
+		Find(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+// GetMarketVolumeTotal adds up every monthly rollup.
+func GetMarketVolumeTotal() (entities.AggregatedVolumeRollup, error) {
+	var record entities.AggregatedVolumeRollup
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return record, err
+	}
+
+	txRead := database.Model(&entities.AggregatedVolumeRollup{}).
+		Select("coalesce(sum(buy_volume), 0) as buy_volume, coalesce(sum(list_volume), 0) as list_volume, coalesce(sum(withdraw_volume), 0) as withdraw_volume, coalesce(sum(buy_count), 0) as buy_count").
+		Where("period=?", entities.RollupMonth).
+		Scan(&record)
+
+	if txRead.Error != nil {
+		return record, txRead.Error
+	}
+
+	return record, nil
+}
@@ -42,3 +42,84 @@ func defaultAggregatedVolumePerHour() entities.AggregatedVolumePerHour {
 		WithdrawVolume: 3.2,
 	}
 }
+
+func Test_CollectionFloorPriceHistory(t *testing.T) {
+	connectToTestDb()
+
+	record := entities.CollectionFloorPricePerHour{
+		Hour:         2022051415,
+		CollectionId: 1,
+		FloorPrice:   1.5,
+		ListedCount:  3,
+	}
+	err := AddOrUpdateCollectionFloorPricePerHour(&record)
+	require.Nil(t, err)
+
+	record.FloorPrice = 0
+	record.ListedCount = 0
+	err = AddOrUpdateCollectionFloorPricePerHour(&record)
+	require.Nil(t, err)
+
+	history, err := GetCollectionFloorPriceHistory(1, 2022051400)
+	require.Nil(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, float64(0), history[0].FloorPrice)
+	require.Equal(t, uint64(0), history[0].ListedCount)
+}
+
+func Test_GetCollectionVolumesInHourRange(t *testing.T) {
+	connectToTestDb()
+
+	for _, hour := range []int64{2022051413, 2022051414, 2022051415} {
+		record := entities.AggregatedVolumePerCollectionPerHour{
+			Hour:         hour,
+			CollectionId: 7,
+			BuyVolume:    2,
+			BuyCount:     1,
+		}
+		err := AddOrUpdateAggregatedVolumePerCollectionPerHour(&record)
+		require.Nil(t, err)
+	}
+
+	volumes, err := GetCollectionVolumesInHourRange(2022051414, 2022051416)
+	require.Nil(t, err)
+
+	found := false
+	for _, item := range volumes {
+		if item.CollectionId == 7 {
+			found = true
+			require.Equal(t, float64(4), item.Volume)
+			require.Equal(t, uint64(2), item.SalesCount)
+		}
+	}
+	require.True(t, found)
+}
+
+func Test_RollupVolume(t *testing.T) {
+	connectToTestDb()
+
+	for _, hour := range []int64{2022030100, 2022030123, 2022030200} {
+		record := entities.AggregatedVolumePerCollectionPerHour{
+			Hour:         hour,
+			CollectionId: 9,
+			BuyVolume:    1.5,
+			BuyCount:     1,
+		}
+		err := AddOrUpdateAggregatedVolumePerCollectionPerHour(&record)
+		require.Nil(t, err)
+	}
+
+	err := RollupVolume(entities.RollupDay, []int64{20220301, 20220302})
+	require.Nil(t, err)
+
+	// running it twice must not double count
+	err = RollupVolume(entities.RollupDay, []int64{20220301, 20220302})
+	require.Nil(t, err)
+
+	rollups, err := GetCollectionVolumeRollups(9, entities.RollupDay, 20220301, 20220302)
+	require.Nil(t, err)
+	require.Len(t, rollups, 2)
+	require.Equal(t, float64(3), rollups[0].BuyVolume)
+	require.Equal(t, uint64(2), rollups[0].BuyCount)
+	require.Equal(t, float64(1.5), rollups[1].BuyVolume)
+}
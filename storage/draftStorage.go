@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+var ErrDraftVersionConflict = errors.New("draft was modified by another request")
+
+func AddDraft(draft *entities.Draft) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(draft).Error
+}
+
+func GetDraftById(id uint64) (*entities.Draft, error) {
+	var draft entities.Draft
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&draft, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &draft, nil
+}
+
+// GetDraftsByAddress returns the drafts of an address not expired at now, newest first.
+// An empty draftType returns the drafts of every type.
+func GetDraftsByAddress(address string, draftType entities.DraftType, now int64) ([]entities.Draft, error) {
+	var drafts []entities.Draft
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.Where("address = ? AND expires_at > ?", address, now)
+	if draftType != "" {
+		query = query.Where("type = ?", draftType)
+	}
+
+	txRead := query.Order("updated_at desc").Find(&drafts)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return drafts, nil
+}
+
+func CountDraftsByAddressAndType(address string, draftType entities.DraftType, now int64) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := database.Model(&entities.Draft{}).
+		Where("address = ? AND type = ? AND expires_at > ?", address, draftType, now).
+		Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// UpdateDraft saves the draft only if the stored version is still the one the draft was read with,
+// then bumps the version. A stale version yields ErrDraftVersionConflict.
+func UpdateDraft(draft *entities.Draft) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.Draft{}).
+		Where("id = ? AND version = ?", draft.ID, draft.Version).
+		Updates(map[string]interface{}{
+			"name":       draft.Name,
+			"json_data":  draft.JsonData,
+			"expires_at": draft.ExpiresAt,
+			"updated_at": draft.UpdatedAt,
+			"version":    draft.Version + 1,
+		})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return ErrDraftVersionConflict
+	}
+
+	draft.Version++
+	return nil
+}
+
+func DeleteDraft(id uint64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txDelete := database.Delete(&entities.Draft{}, id)
+	if txDelete.Error != nil {
+		return txDelete.Error
+	}
+	if txDelete.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func DeleteExpiredDrafts(now int64) (int64, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txDelete := database.Where("expires_at <= ?", now).Delete(&entities.Draft{})
+	if txDelete.Error != nil {
+		return 0, txDelete.Error
+	}
+
+	return txDelete.RowsAffected, nil
+}
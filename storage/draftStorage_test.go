@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func Test_UpdateDraftVersion(t *testing.T) {
+	connectToTestDb()
+
+	draft := entities.Draft{
+		Address:   "erd_drafter",
+		Type:      entities.DraftProfileEdit,
+		Version:   1,
+		JsonData:  datatypes.JSON(`{"name":"first"}`),
+		ExpiresAt: 100,
+	}
+	err := AddDraft(&draft)
+	require.Nil(t, err)
+
+	stale := draft
+	draft.JsonData = datatypes.JSON(`{"name":"second"}`)
+	err = UpdateDraft(&draft)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), draft.Version)
+
+	stale.JsonData = datatypes.JSON(`{"name":"stale"}`)
+	err = UpdateDraft(&stale)
+	require.Equal(t, ErrDraftVersionConflict, err)
+
+	stored, err := GetDraftById(draft.ID)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"name":"second"}`, string(stored.JsonData))
+
+	drafts, err := GetDraftsByAddress("erd_drafter", entities.DraftProfileEdit, 50)
+	require.Nil(t, err)
+	require.Len(t, drafts, 1)
+
+	deleted, err := DeleteExpiredDrafts(100)
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = GetDraftById(draft.ID)
+	require.NotNil(t, err)
+}
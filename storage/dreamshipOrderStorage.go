@@ -1,57 +0,0 @@
-package storage
-
-import (
-	"fmt"
-
-	"github.com/ENFT-DAO/youbei-api/data/entities"
-)
-
-func AddOrUpdateOrderItem(orderItem entities.UserOrders) error{
-	db, err := GetDBOrError()
-	fmt.Println("Database Connected!")
-	if err != nil {
-		return err
-	}
-	result := db.Model(&entities.UserOrders{}).
-				Where("order_id = ?", orderItem.OrderId).
-				Updates(entities.UserOrders {
-					Amount: orderItem.Amount,
-					OrderStatus: orderItem.OrderStatus,
-					CheckoutStatus: orderItem.CheckoutStatus,
-					PaymentMethod: orderItem.PaymentMethod,
-					OrderId: orderItem.OrderId,
-				})
-	fmt.Println(result.RowsAffected)
-	if result.RowsAffected == 0{
-			db.Create(&orderItem)
-	}
-	return nil
-}
-
-func RetrievesUserOrders(userAddress string) ([]entities.UserOrders, error) {
-	db, err := GetDBOrError()
-	if err != nil {
-		return nil, err
-	}
-	var result []entities.UserOrders
-	txRead := db.Model(&entities.UserOrders{}).Where("user_address = ?", userAddress).Find(&result)
-	if txRead.Error != nil {
-		fmt.Println(txRead.Error)
-		return nil, txRead.Error
-	}
-	return result, nil
-}
-
-func RetrievesAnOrders(userAddress string, orderId string) ([]entities.UserOrders, error) {
-	db, err := GetDBOrError()
-	if err != nil {
-		return nil, err
-	}
-	var result []entities.UserOrders
-	txRead := db.Model(&entities.UserOrders{}).Where("user_address = ? AND order_id = ?", userAddress, orderId).Find(&result)
-	if txRead.Error != nil {
-		fmt.Println(txRead.Error)
-		return nil, txRead.Error
-	}
-	return result, nil
-}
\ No newline at end of file
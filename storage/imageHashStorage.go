@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertTokenImageHash stores the hashes of a token, replacing the ones of its previous media.
+func UpsertTokenImageHash(hash *entities.TokenImageHash) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"collection_id", "image_link", "p_hash", "d_hash", "failed", "error", "attempts", "retry_at", "updated_at"}),
+	}).Create(hash).Error
+}
+
+func GetTokenImageHashByTokenId(tokenId uint64) (*entities.TokenImageHash, error) {
+	var hash entities.TokenImageHash
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("token_id = ?", tokenId).Find(&hash)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &hash, nil
+}
+
+// GetTokensWithoutImageHash returns tokens never hashed, whose media changed since or whose fetch is due for a retry
+// at the unix time now, newest first.
+func GetTokensWithoutImageHash(now int64, limit int) ([]entities.Token, error) {
+	var tokens []entities.Token
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Select("tokens.*").
+		Joins("LEFT JOIN token_image_hashes ON token_image_hashes.token_id = tokens.id").
+		Where("tokens.image_link <> '' AND (token_image_hashes.id IS NULL OR token_image_hashes.image_link <> tokens.image_link OR "+
+			"(token_image_hashes.failed = true AND token_image_hashes.retry_at > 0 AND token_image_hashes.retry_at <= ?))", now).
+		Order("tokens.id desc").
+		Limit(limit).
+		Find(&tokens)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return tokens, nil
+}
+
+// GetTokenImageHashesByCollectionIds returns the successful hashes of the collections.
+func GetTokenImageHashesByCollectionIds(collectionIds []uint64) ([]entities.TokenImageHash, error) {
+	var hashes []entities.TokenImageHash
+
+	if len(collectionIds) == 0 {
+		return hashes, nil
+	}
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("collection_id IN ? AND failed = false", collectionIds).Find(&hashes)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return hashes, nil
+}
+
+func GetVerifiedCollectionIds() ([]uint64, error) {
+	var ids []uint64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.Collection{}).Where("is_verified = true").Pluck("id", &ids)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return ids, nil
+}
+
+// GetHashedUnverifiedCollectionIds returns the collections that are not verified and have hashed tokens.
+func GetHashedUnverifiedCollectionIds() ([]uint64, error) {
+	var ids []uint64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.TokenImageHash{}).
+		Distinct("token_image_hashes.collection_id").
+		Joins("JOIN collections ON collections.id = token_image_hashes.collection_id").
+		Where("collections.is_verified = false AND token_image_hashes.failed = false").
+		Pluck("token_image_hashes.collection_id", &ids)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return ids, nil
+}
+
+// UpsertCollectionDuplicateFlag records the latest scores of a flag. A flag already reviewed keeps its status.
+func UpsertCollectionDuplicateFlag(flag *entities.CollectionDuplicateFlag) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "collection_id"}, {Name: "matched_collection_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"compared_tokens", "matched_tokens", "score", "similarity", "updated_at"}),
+	}).Create(flag).Error
+}
+
+func GetCollectionDuplicateFlagById(id uint64) (*entities.CollectionDuplicateFlag, error) {
+	var flag entities.CollectionDuplicateFlag
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Collection").Preload("MatchedCollection").Find(&flag, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &flag, nil
+}
+
+// GetCollectionDuplicateFlags returns the flags with a status, or every flag when it is empty, highest score first.
+func GetCollectionDuplicateFlags(status entities.DuplicateFlagStatus, offset int, limit int) ([]entities.CollectionDuplicateFlag, error) {
+	var flags []entities.CollectionDuplicateFlag
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := filterDuplicateFlags(database, status).
+		Preload("Collection").
+		Preload("MatchedCollection").
+		Order("score desc, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&flags)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return flags, nil
+}
+
+func CountCollectionDuplicateFlags(status entities.DuplicateFlagStatus) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := filterDuplicateFlags(database.Model(&entities.CollectionDuplicateFlag{}), status).Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// GetCollectionDuplicateWarningFlag returns the highest scoring flag of a collection that is not dismissed.
+func GetCollectionDuplicateWarningFlag(collectionId uint64) (*entities.CollectionDuplicateFlag, error) {
+	var flag entities.CollectionDuplicateFlag
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Preload("MatchedCollection").
+		Where("collection_id = ? AND status <> ?", collectionId, entities.DuplicateFlagDismissed).
+		Order("score desc").
+		Limit(1).
+		Find(&flag)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &flag, nil
+}
+
+func SetCollectionDuplicateFlagReview(id uint64, status entities.DuplicateFlagStatus, reviewedBy string, note string, reviewedAt int64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.CollectionDuplicateFlag{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewedBy,
+			"review_note": note,
+			"reviewed_at": reviewedAt,
+		})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func filterDuplicateFlags(query *gorm.DB, status entities.DuplicateFlagStatus) *gorm.DB {
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	return query
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UpsertTokenImageHashReplacesMedia(t *testing.T) {
+	connectToTestDb()
+
+	tokenId := uint64(time.Now().UnixNano())
+	err := UpsertTokenImageHash(&entities.TokenImageHash{TokenID: tokenId, CollectionID: 1, ImageLink: "first.png", PHash: 1, DHash: 2})
+	require.Nil(t, err)
+
+	err = UpsertTokenImageHash(&entities.TokenImageHash{TokenID: tokenId, CollectionID: 1, ImageLink: "second.png", Failed: true, Error: "unknown format"})
+	require.Nil(t, err)
+
+	hash, err := GetTokenImageHashByTokenId(tokenId)
+	require.Nil(t, err)
+	require.Equal(t, "second.png", hash.ImageLink)
+	require.True(t, hash.Failed)
+}
+
+func Test_UpsertCollectionDuplicateFlagKeepsReview(t *testing.T) {
+	connectToTestDb()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	original := entities.Collection{Name: "original", CollectionTokenID: "ORIG-" + suffix, IsVerified: true}
+	require.Nil(t, AddCollection(&original))
+	copymint := entities.Collection{Name: "copy", CollectionTokenID: "COPY-" + suffix}
+	require.Nil(t, AddCollection(&copymint))
+
+	err := UpsertCollectionDuplicateFlag(&entities.CollectionDuplicateFlag{
+		CollectionID:        copymint.ID,
+		MatchedCollectionID: original.ID,
+		ComparedTokens:      10,
+		MatchedTokens:       8,
+		Score:               0.8,
+		Status:              entities.DuplicateFlagOpen,
+	})
+	require.Nil(t, err)
+
+	warning, err := GetCollectionDuplicateWarningFlag(copymint.ID)
+	require.Nil(t, err)
+	require.Equal(t, original.CollectionTokenID, warning.MatchedCollection.CollectionTokenID)
+
+	err = SetCollectionDuplicateFlagReview(warning.ID, entities.DuplicateFlagDismissed, "erd_moderator", "licensed derivative", 100)
+	require.Nil(t, err)
+
+	err = UpsertCollectionDuplicateFlag(&entities.CollectionDuplicateFlag{
+		CollectionID:        copymint.ID,
+		MatchedCollectionID: original.ID,
+		ComparedTokens:      20,
+		MatchedTokens:       18,
+		Score:               0.9,
+		Status:              entities.DuplicateFlagOpen,
+	})
+	require.Nil(t, err)
+
+	flag, err := GetCollectionDuplicateFlagById(warning.ID)
+	require.Nil(t, err)
+	require.Equal(t, entities.DuplicateFlagDismissed, flag.Status)
+	require.Equal(t, uint64(18), flag.MatchedTokens)
+
+	_, err = GetCollectionDuplicateWarningFlag(copymint.ID)
+	require.NotNil(t, err)
+}
+
+func Test_GetTokensWithoutImageHashRetriesDueFetches(t *testing.T) {
+	connectToTestDb()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	due := entities.Token{TokenID: "RETRY-" + suffix, Nonce: 1, ImageLink: "due.png"}
+	require.Nil(t, AddToken(&due))
+	later := entities.Token{TokenID: "RETRY-" + suffix, Nonce: 2, ImageLink: "later.png"}
+	require.Nil(t, AddToken(&later))
+	unsupported := entities.Token{TokenID: "RETRY-" + suffix, Nonce: 3, ImageLink: "video.mp4"}
+	require.Nil(t, AddToken(&unsupported))
+
+	require.Nil(t, UpsertTokenImageHash(&entities.TokenImageHash{TokenID: due.ID, ImageLink: "due.png", Failed: true, Attempts: 1, RetryAt: 100}))
+	require.Nil(t, UpsertTokenImageHash(&entities.TokenImageHash{TokenID: later.ID, ImageLink: "later.png", Failed: true, Attempts: 1, RetryAt: 300}))
+	require.Nil(t, UpsertTokenImageHash(&entities.TokenImageHash{TokenID: unsupported.ID, ImageLink: "video.mp4", Failed: true}))
+
+	tokens, err := GetTokensWithoutImageHash(200, 1000)
+	require.Nil(t, err)
+
+	pending := map[uint64]bool{}
+	for _, token := range tokens {
+		pending[token.ID] = true
+	}
+	require.True(t, pending[due.ID])
+	require.False(t, pending[later.ID])
+	require.False(t, pending[unsupported.ID])
+}
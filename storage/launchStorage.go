@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddCollectionLaunch stores a new launch with its steps and abandons the launches the address left in progress.
+func AddCollectionLaunch(launch *entities.CollectionLaunch) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		txUpdate := tx.Model(&entities.CollectionLaunch{}).
+			Where("address = ? AND status = ?", launch.Address, entities.LaunchInProgress).
+			Update("status", entities.LaunchAbandoned)
+		if txUpdate.Error != nil {
+			return txUpdate.Error
+		}
+
+		return tx.Create(launch).Error
+	})
+}
+
+func GetCollectionLaunchById(id uint64) (*entities.CollectionLaunch, error) {
+	var launch entities.CollectionLaunch
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Steps", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position asc")
+	}).Find(&launch, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &launch, nil
+}
+
+func GetActiveCollectionLaunch(address string) (*entities.CollectionLaunch, error) {
+	var launch entities.CollectionLaunch
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Steps", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position asc")
+	}).Where("address = ? AND status = ?", address, entities.LaunchInProgress).
+		Order("id desc").
+		Limit(1).
+		Find(&launch)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &launch, nil
+}
+
+func UpdateCollectionLaunch(launch *entities.CollectionLaunch) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Omit(clause.Associations).Save(launch)
+	return txUpdate.Error
+}
+
+func UpdateCollectionLaunchStep(step *entities.CollectionLaunchStep) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Save(step)
+	return txUpdate.Error
+}
+
+// GetStuckLaunchCounts groups the launches still in progress and untouched since before by the step they wait on.
+func GetStuckLaunchCounts(before int64) ([]entities.LaunchStepCount, error) {
+	var counts []entities.LaunchStepCount
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.CollectionLaunch{}).
+		Select("current_step, COUNT(*) AS count").
+		Where("status = ? AND updated_at < ?", entities.LaunchInProgress, before).
+		Group("current_step").
+		Order("count desc").
+		Scan(&counts)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return counts, nil
+}
+
+func GetStuckCollectionLaunches(before int64, offset int, limit int) ([]entities.CollectionLaunch, error) {
+	var launches []entities.CollectionLaunch
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Steps", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position asc")
+	}).Where("status = ? AND updated_at < ?", entities.LaunchInProgress, before).
+		Order("updated_at asc").
+		Offset(offset).
+		Limit(limit).
+		Find(&launches)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return launches, nil
+}
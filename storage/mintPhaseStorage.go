@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetMintPhasesByCollectionId(collectionId uint64) ([]entities.CollectionMintPhase, error) {
+	var phases []entities.CollectionMintPhase
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("collection_id = ?", collectionId).Order("position asc").Find(&phases)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return phases, nil
+}
+
+// SaveCollectionMintPhases updates the schedule of a collection in place, phase by position, so the phases
+// keep their ids and the mints already credited to them. Phases past the new schedule are deleted.
+// The summary dates of the collection are saved in the same transaction.
+func SaveCollectionMintPhases(collection *entities.Collection, phases []entities.CollectionMintPhase) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		var current []entities.CollectionMintPhase
+		txRead := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("collection_id = ?", collection.ID).
+			Find(&current)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+
+		idsByPosition := map[uint64]uint64{}
+		for _, phase := range current {
+			idsByPosition[phase.Position] = phase.ID
+		}
+
+		for index := range phases {
+			id, ok := idsByPosition[phases[index].Position]
+			if !ok {
+				txCreate := tx.Create(&phases[index])
+				if txCreate.Error != nil {
+					return txCreate.Error
+				}
+				continue
+			}
+
+			phases[index].ID = id
+			txUpdate := tx.Model(&phases[index]).Select(
+				"name", "price_string", "price_nominal", "start_date", "end_date", "wallet_cap", "whitelist_type",
+			).Updates(&phases[index])
+			if txUpdate.Error != nil {
+				return txUpdate.Error
+			}
+		}
+
+		txDelete := tx.Where("collection_id = ? AND position >= ?", collection.ID, len(phases)).
+			Delete(&entities.CollectionMintPhase{})
+		if txDelete.Error != nil {
+			return txDelete.Error
+		}
+
+		txUpdate := tx.Model(collection).Updates(map[string]interface{}{
+			"mint_start_date": collection.MintStartDate,
+			"mint_end_date":   collection.MintEndDate,
+		})
+		return txUpdate.Error
+	})
+}
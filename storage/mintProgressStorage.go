@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mintTotals struct {
+	Minted        uint64
+	UniqueMinters uint64
+	Revenue       float64
+}
+
+func GetCollectionMintProgress(collectionId uint64) (*entities.CollectionMintProgress, error) {
+	var progress entities.CollectionMintProgress
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&progress, "collection_id = ?", collectionId)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &progress, nil
+}
+
+// AddMintTransactions stores the mints once per tx hash, moves the indexer cursor and refreshes the progress totals.
+// The cursor is the timestamp of the last indexed transaction and how many transactions were indexed at it.
+func AddMintTransactions(collectionId uint64, mints []entities.MintTransaction, lastTimestamp uint64, lastTimestampTxs uint64) (*entities.CollectionMintProgress, error) {
+	var progress entities.CollectionMintProgress
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if len(mints) > 0 {
+			txCreate := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mints)
+			if txCreate.Error != nil {
+				return txCreate.Error
+			}
+		}
+
+		txRead := tx.Find(&progress, "collection_id = ?", collectionId)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+
+		progress.CollectionID = collectionId
+		if lastTimestamp > progress.LastTimestamp ||
+			(lastTimestamp == progress.LastTimestamp && lastTimestampTxs > progress.LastTimestampTxs) {
+			progress.LastTimestamp = lastTimestamp
+			progress.LastTimestampTxs = lastTimestampTxs
+		}
+
+		return saveMintProgress(tx, &progress)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// UpdateCollectionMintProgressFromChain stores the max supply and total sold read from the contract view.
+func UpdateCollectionMintProgressFromChain(collectionId uint64, maxSupply uint64, chainMinted uint64, reconciledAt int64) (*entities.CollectionMintProgress, error) {
+	var progress entities.CollectionMintProgress
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		txRead := tx.Find(&progress, "collection_id = ?", collectionId)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+
+		progress.CollectionID = collectionId
+		progress.MaxSupply = maxSupply
+		progress.ChainMinted = chainMinted
+		progress.ReconciledAt = reconciledAt
+
+		return saveMintProgress(tx, &progress)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+func GetMintPhasesSold(collectionId uint64) ([]entities.MintPhaseSold, error) {
+	var sold []entities.MintPhaseSold
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Model(&entities.MintTransaction{}).
+		Select("phase_id, SUM(count) AS sold").
+		Where("collection_id = ?", collectionId).
+		Group("phase_id").
+		Order("phase_id asc").
+		Scan(&sold)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return sold, nil
+}
+
+func saveMintProgress(tx *gorm.DB, progress *entities.CollectionMintProgress) error {
+	var totals mintTotals
+	txRead := tx.Model(&entities.MintTransaction{}).
+		Select("COALESCE(SUM(count), 0) AS minted, COUNT(DISTINCT minter) AS unique_minters, COALESCE(SUM(value), 0) AS revenue").
+		Where("collection_id = ?", progress.CollectionID).
+		Scan(&totals)
+	if txRead.Error != nil {
+		return txRead.Error
+	}
+
+	progress.Minted = totals.Minted
+	if progress.ChainMinted > progress.Minted {
+		progress.Minted = progress.ChainMinted
+	}
+	progress.UniqueMinters = totals.UniqueMinters
+	progress.Revenue = totals.Revenue
+
+	return tx.Save(progress).Error
+}
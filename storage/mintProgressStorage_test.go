@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddMintTransactions(t *testing.T) {
+	connectToTestDb()
+
+	mints := []entities.MintTransaction{
+		{TxHash: "mint_hash_1", CollectionID: 31, PhaseID: 1, Minter: "erd_a", Count: 2, Value: 1, Timestamp: 10},
+		{TxHash: "mint_hash_2", CollectionID: 31, PhaseID: 2, Minter: "erd_b", Count: 1, Value: 0.5, Timestamp: 20},
+	}
+	progress, err := AddMintTransactions(31, mints, 20, 1)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), progress.Minted)
+	require.Equal(t, uint64(2), progress.UniqueMinters)
+	require.Equal(t, uint64(1), progress.LastTimestampTxs)
+
+	again := []entities.MintTransaction{
+		{TxHash: "mint_hash_2", CollectionID: 31, PhaseID: 2, Minter: "erd_b", Count: 1, Value: 0.5, Timestamp: 20},
+	}
+	progress, err = AddMintTransactions(31, again, 20, 2)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), progress.Minted)
+	require.Equal(t, uint64(2), progress.LastTimestampTxs)
+
+	progress, err = UpdateCollectionMintProgressFromChain(31, 100, 5, 1)
+	require.Nil(t, err)
+	require.Equal(t, uint64(5), progress.Minted)
+	require.Equal(t, uint64(20), progress.LastTimestamp)
+
+	sold, err := GetMintPhasesSold(31)
+	require.Nil(t, err)
+	require.Len(t, sold, 2)
+	require.Equal(t, uint64(2), sold[0].Sold)
+}
+
+func Test_SaveCollectionMintPhasesKeepsIds(t *testing.T) {
+	connectToTestDb()
+
+	collection := defaultCollection()
+	err := AddCollection(&collection)
+	require.Nil(t, err)
+
+	phases := []entities.CollectionMintPhase{
+		{CollectionID: collection.ID, Position: 0, Name: "OG", StartDate: 10, EndDate: 20},
+		{CollectionID: collection.ID, Position: 1, Name: "Public", StartDate: 20},
+	}
+	err = SaveCollectionMintPhases(&collection, phases)
+	require.Nil(t, err)
+	firstId := phases[0].ID
+
+	moved := []entities.CollectionMintPhase{
+		{CollectionID: collection.ID, Position: 0, Name: "OG", StartDate: 15, EndDate: 20},
+	}
+	err = SaveCollectionMintPhases(&collection, moved)
+	require.Nil(t, err)
+	require.Equal(t, firstId, moved[0].ID)
+
+	saved, err := GetMintPhasesByCollectionId(collection.ID)
+	require.Nil(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, firstId, saved[0].ID)
+	require.Equal(t, uint64(15), saved[0].StartDate)
+}
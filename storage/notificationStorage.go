@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+func AddNotification(notification *entities.Notification) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txCreate := database.Create(notification)
+	if txCreate.Error != nil {
+		return txCreate.Error
+	}
+	if txCreate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetNotificationsByAddress returns the notifications of an address, newest first.
+func GetNotificationsByAddress(address string, offset int, limit int) ([]entities.Notification, error) {
+	var notifications []entities.Notification
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.
+		Where("address = ?", address).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&notifications)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return notifications, nil
+}
+
+func CountNotificationsByAddress(address string, unreadOnly bool) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	query := database.Model(&entities.Notification{}).Where("address = ?", address)
+	if unreadOnly {
+		query = query.Where("is_read = false")
+	}
+
+	txRead := query.Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return count, nil
+}
+
+// MarkNotificationsRead marks the notifications of an address as read, all of them when ids is empty.
+func MarkNotificationsRead(address string, ids []uint64) (int64, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	query := database.Model(&entities.Notification{}).Where("address = ? AND is_read = false", address)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	txUpdate := query.Update("is_read", true)
+	if txUpdate.Error != nil {
+		return 0, txUpdate.Error
+	}
+
+	return txUpdate.RowsAffected, nil
+}
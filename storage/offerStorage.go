@@ -76,3 +76,19 @@ func GetOffersForTokenWithOffsetLimit(tokenId uint64, offset int, limit int) ([]
 
 	return offer, nil
 }
+
+func GetOffersForTokenId(tokenId uint64) ([]entities.Offer, error) {
+	var offers []entities.Offer
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Order("timestamp asc").Find(&offers, "token_id = ?", tokenId)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return offers, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+func AddPrintCheckout(checkout *entities.PrintCheckout) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(checkout).Error
+}
+
+func GetPrintCheckoutById(id uint64) (*entities.PrintCheckout, error) {
+	var checkout entities.PrintCheckout
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&checkout, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &checkout, nil
+}
+
+func GetPrintCheckoutByPaymentTxHash(txHash string) (*entities.PrintCheckout, error) {
+	var checkout entities.PrintCheckout
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&checkout, "payment_tx_hash = ?", txHash)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &checkout, nil
+}
+
+// SetPrintCheckoutPaid records the verified payment of a quoted checkout.
+// It returns false when the checkout was already paid by another request.
+func SetPrintCheckoutPaid(id uint64, txHash string, updatedAt int64) (bool, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txUpdate := database.Model(&entities.PrintCheckout{}).
+		Where("id = ? AND status = ?", id, entities.PrintCheckoutQuoted).
+		Updates(map[string]interface{}{
+			"status":          entities.PrintCheckoutPaid,
+			"payment_tx_hash": txHash,
+			"updated_at":      updatedAt,
+		})
+	if txUpdate.Error != nil {
+		return false, txUpdate.Error
+	}
+
+	return txUpdate.RowsAffected == 1, nil
+}
+
+func UpdatePrintCheckout(checkout *entities.PrintCheckout) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Save(checkout).Error
+}
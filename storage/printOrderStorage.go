@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddSubmittedPrintOrder saves the checkout accepted by the provider together with its order,
+// items and first history entry.
+func AddSubmittedPrintOrder(checkout *entities.PrintCheckout, order *entities.PrintOrder) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(checkout).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(order).Error
+	})
+}
+
+// ForwardPaidPrintCheckout runs forward on the paid checkout while its row is locked, so only one request
+// forwards it at a time. The checkout is saved with the order forward returns, or alone when forward fails.
+// A checkout that is no longer paid, because another request submitted it, is returned without calling forward.
+func ForwardPaidPrintCheckout(id uint64, forward func(checkout *entities.PrintCheckout) (*entities.PrintOrder, error)) (*entities.PrintCheckout, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	var checkout entities.PrintCheckout
+	var forwardErr error
+	err = database.Transaction(func(tx *gorm.DB) error {
+		txRead := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&checkout, id)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+		if txRead.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if checkout.Status != entities.PrintCheckoutPaid {
+			return nil
+		}
+
+		var order *entities.PrintOrder
+		order, forwardErr = forward(&checkout)
+		err := tx.Save(&checkout).Error
+		if err != nil || forwardErr != nil {
+			return err
+		}
+
+		return tx.Create(order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if forwardErr != nil {
+		return nil, forwardErr
+	}
+
+	return &checkout, nil
+}
+
+func GetPrintOrderForAddress(address string, id uint64) (*entities.PrintOrder, error) {
+	var order entities.PrintOrder
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Items").
+		Preload("History", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("id asc")
+		}).
+		Where("address = ?", address).
+		Find(&order, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &order, nil
+}
+
+func GetPrintOrdersByAddress(address string, offset int, limit int) ([]entities.PrintOrder, error) {
+	var orders []entities.PrintOrder
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Items").
+		Where("address = ?", address).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&orders)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return orders, nil
+}
+
+func CountPrintOrdersByAddress(address string) (int64, error) {
+	var count int64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txCount := database.Model(&entities.PrintOrder{}).Where("address = ?", address).Count(&count)
+	if txCount.Error != nil {
+		return 0, txCount.Error
+	}
+
+	return count, nil
+}
+
+// ApplyPrintOrderWebhook records the webhook event and applies it to its order in one transaction.
+// It returns false without calling apply when the event was already processed. An unknown order
+// rolls the event back, so the provider retry is processed once the order exists.
+func ApplyPrintOrderWebhook(event *entities.PrintOrderWebhookEvent, apply func(order *entities.PrintOrder) *entities.PrintOrderStatusChange) (bool, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	applied := false
+	err = database.Transaction(func(tx *gorm.DB) error {
+		txCreate := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return nil
+		}
+
+		var order entities.PrintOrder
+		txRead := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&order, "reference_id = ?", event.ReferenceId)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+		if txRead.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		change := apply(&order)
+		err := tx.Omit(clause.Associations).Save(&order).Error
+		if err != nil {
+			return err
+		}
+
+		if change != nil {
+			change.OrderID = order.ID
+			err = tx.Create(change).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		applied = true
+		return nil
+	})
+
+	return applied, err
+}
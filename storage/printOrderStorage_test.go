@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApplyPrintOrderWebhookOnce(t *testing.T) {
+	connectToTestDb()
+
+	checkout := &entities.PrintCheckout{Address: "erd_print_buyer", Status: entities.PrintCheckoutSubmitted}
+	err := AddPrintCheckout(checkout)
+	require.Nil(t, err)
+
+	order := &entities.PrintOrder{
+		CheckoutID:  checkout.ID,
+		Address:     "erd_print_buyer",
+		ReferenceId: "print-ref-storage",
+		Status:      entities.PrintOrderSubmitted,
+		Items:       []entities.PrintOrderItem{{TokenIdentifier: "TKN-aaaa-01", Quantity: 1}},
+		History:     []entities.PrintOrderStatusChange{{To: entities.PrintOrderSubmitted}},
+	}
+	err = AddSubmittedPrintOrder(checkout, order)
+	require.Nil(t, err)
+
+	apply := func(order *entities.PrintOrder) *entities.PrintOrderStatusChange {
+		order.Status = entities.PrintOrderShipped
+		return &entities.PrintOrderStatusChange{From: entities.PrintOrderSubmitted, To: entities.PrintOrderShipped}
+	}
+	event := entities.PrintOrderWebhookEvent{EventKey: "print-event-storage", ReferenceId: "print-ref-storage"}
+
+	applied, err := ApplyPrintOrderWebhook(&event, apply)
+	require.Nil(t, err)
+	require.True(t, applied)
+
+	duplicate := entities.PrintOrderWebhookEvent{EventKey: "print-event-storage", ReferenceId: "print-ref-storage"}
+	applied, err = ApplyPrintOrderWebhook(&duplicate, apply)
+	require.Nil(t, err)
+	require.False(t, applied)
+
+	stored, err := GetPrintOrderForAddress("erd_print_buyer", order.ID)
+	require.Nil(t, err)
+	require.Equal(t, entities.PrintOrderShipped, stored.Status)
+	require.Len(t, stored.Items, 1)
+	require.Len(t, stored.History, 2)
+}
+
+func Test_ForwardPaidPrintCheckoutOnce(t *testing.T) {
+	connectToTestDb()
+
+	checkout := &entities.PrintCheckout{Address: "erd_print_forward", Status: entities.PrintCheckoutPaid}
+	err := AddPrintCheckout(checkout)
+	require.Nil(t, err)
+
+	forwards := 0
+	forward := func(locked *entities.PrintCheckout) (*entities.PrintOrder, error) {
+		forwards++
+		locked.Status = entities.PrintCheckoutSubmitted
+		return &entities.PrintOrder{
+			CheckoutID:  locked.ID,
+			Address:     locked.Address,
+			ReferenceId: "print-ref-forward",
+			Status:      entities.PrintOrderSubmitted,
+		}, nil
+	}
+
+	forwarded, err := ForwardPaidPrintCheckout(checkout.ID, forward)
+	require.Nil(t, err)
+	require.Equal(t, entities.PrintCheckoutSubmitted, forwarded.Status)
+
+	forwarded, err = ForwardPaidPrintCheckout(checkout.ID, forward)
+	require.Nil(t, err)
+	require.Equal(t, entities.PrintCheckoutSubmitted, forwarded.Status)
+	require.Equal(t, 1, forwards)
+}
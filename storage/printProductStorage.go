@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultPrintProducts were the only items offered before the catalog existed.
+// 19 is the canvas item of dreamship.
+var defaultPrintProducts = []entities.PrintProduct{
+	{ProviderItemId: 19, Name: "Canvas", Enabled: true},
+}
+
+func AddPrintProduct(product *entities.PrintProduct) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(product).Error
+}
+
+func UpdatePrintProduct(product *entities.PrintProduct) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Save(product).Error
+}
+
+func DeletePrintProduct(id uint64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txDelete := database.Delete(&entities.PrintProduct{}, id)
+	if txDelete.Error != nil {
+		return txDelete.Error
+	}
+	if txDelete.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func GetPrintProductById(id uint64) (*entities.PrintProduct, error) {
+	var product entities.PrintProduct
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&product, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &product, nil
+}
+
+func GetPrintProducts(enabledOnly bool) ([]entities.PrintProduct, error) {
+	var products []entities.PrintProduct
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.Order("id asc")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	txRead := query.Find(&products)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return products, nil
+}
+
+// seedPrintProducts adds the default products to an empty catalog.
+func seedPrintProducts() error {
+	var count int64
+
+	txCount := db.Model(&entities.PrintProduct{}).Count(&count)
+	if txCount.Error != nil {
+		return txCount.Error
+	}
+	if count > 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	products := make([]entities.PrintProduct, len(defaultPrintProducts))
+	for index, product := range defaultPrintProducts {
+		product.CreatedAt = now
+		product.UpdatedAt = now
+		products[index] = product
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&products).Error
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetPrintProductsEnabledOnly(t *testing.T) {
+	connectToTestDb()
+
+	enabled := &entities.PrintProduct{ProviderItemId: 90001, Name: "Poster", Enabled: true}
+	err := AddPrintProduct(enabled)
+	require.Nil(t, err)
+
+	disabled := &entities.PrintProduct{ProviderItemId: 90002, Name: "Mug"}
+	err = AddPrintProduct(disabled)
+	require.Nil(t, err)
+
+	products, err := GetPrintProducts(true)
+	require.Nil(t, err)
+	require.Contains(t, products, *enabled)
+	require.NotContains(t, products, *disabled)
+
+	err = DeletePrintProduct(disabled.ID)
+	require.Nil(t, err)
+
+	_, err = GetPrintProductById(disabled.ID)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+func AddPrintRoyalties(royalties []entities.PrintRoyalty) error {
+	if len(royalties) == 0 {
+		return nil
+	}
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Create(&royalties).Error
+}
+
+func GetPrintRoyaltyById(id uint64) (*entities.PrintRoyalty, error) {
+	var royalty entities.PrintRoyalty
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&royalty, id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &royalty, nil
+}
+
+// GetPrintRoyaltiesByCreator returns the royalties of a creator, newest first. An empty status returns all of them.
+func GetPrintRoyaltiesByCreator(creatorAddress string, status entities.PrintRoyaltyStatus, offset int, limit int) ([]entities.PrintRoyalty, error) {
+	var royalties []entities.PrintRoyalty
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.Where("creator_address = ?", creatorAddress)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	txRead := query.Order("id desc").Offset(offset).Limit(limit).Find(&royalties)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return royalties, nil
+}
+
+// SetPrintRoyaltyPaid records the payout of an owed royalty.
+// It returns false when the royalty was already paid by another request.
+func SetPrintRoyaltyPaid(id uint64, txHash string, paidAt int64) (bool, error) {
+	database, err := GetDBOrError()
+	if err != nil {
+		return false, err
+	}
+
+	txUpdate := database.Model(&entities.PrintRoyalty{}).
+		Where("id = ? AND status = ?", id, entities.PrintRoyaltyOwed).
+		Updates(map[string]interface{}{
+			"status":       entities.PrintRoyaltyPaid,
+			"paid_tx_hash": txHash,
+			"paid_at":      paidAt,
+		})
+	if txUpdate.Error != nil {
+		return false, txUpdate.Error
+	}
+
+	return txUpdate.RowsAffected == 1, nil
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetPrintRoyaltyPaidOnce(t *testing.T) {
+	connectToTestDb()
+
+	err := AddPrintRoyalties([]entities.PrintRoyalty{
+		{CheckoutID: 1, CollectionID: 2, CreatorAddress: "erd_print_creator", Amount: "1000", Status: entities.PrintRoyaltyOwed},
+		{CheckoutID: 1, CollectionID: 3, CreatorAddress: "erd_print_creator", Amount: "2000", Status: entities.PrintRoyaltyOwed},
+	})
+	require.Nil(t, err)
+
+	owed, err := GetPrintRoyaltiesByCreator("erd_print_creator", entities.PrintRoyaltyOwed, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, owed, 2)
+
+	paid, err := SetPrintRoyaltyPaid(owed[0].ID, "royalty_tx", 100)
+	require.Nil(t, err)
+	require.True(t, paid)
+
+	paid, err = SetPrintRoyaltyPaid(owed[0].ID, "royalty_tx", 100)
+	require.Nil(t, err)
+	require.False(t, paid)
+
+	owed, err = GetPrintRoyaltiesByCreator("erd_print_creator", entities.PrintRoyaltyOwed, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, owed, 1)
+}
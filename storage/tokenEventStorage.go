@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddTokenEvent stores the event unless it was already recorded, as the indexers
+// may go over the same transaction more than once.
+func AddTokenEvent(event *entities.TokenEvent) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
+
+// GetTokenEventsByTokenId returns every event of the token, oldest first.
+func GetTokenEventsByTokenId(tokenId uint64) ([]entities.TokenEvent, error) {
+	var events []entities.TokenEvent
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("token_id = ?", tokenId).Order("timestamp asc, id asc").Find(&events)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return events, nil
+}
+
+// RequestTokenHistory marks the token for the history indexer, keeping its cursor.
+func RequestTokenHistory(tokenId uint64, requestedAt int64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	cursor := entities.TokenHistoryCursor{TokenID: tokenId, Pending: true, RequestedAt: requestedAt}
+	return database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pending", "requested_at", "updated_at"}),
+	}).Create(&cursor).Error
+}
+
+// GetPendingTokenHistoryCursors returns the cursors of the tokens waiting for the history indexer, oldest request first.
+func GetPendingTokenHistoryCursors(limit int) ([]entities.TokenHistoryCursor, error) {
+	var cursors []entities.TokenHistoryCursor
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("pending = ?", true).Order("requested_at asc").Limit(limit).Find(&cursors)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return cursors, nil
+}
+
+// UpdateTokenHistoryCursor moves the cursor. With done set the token stops being pending,
+// unless it was requested again after the cursor was read.
+func UpdateTokenHistoryCursor(cursor *entities.TokenHistoryCursor, done bool) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"last_timestamp":     cursor.LastTimestamp,
+		"last_timestamp_txs": cursor.LastTimestampTxs,
+	}
+	if done {
+		updates["pending"] = gorm.Expr("requested_at <> ?", cursor.RequestedAt)
+	}
+
+	return database.Model(&entities.TokenHistoryCursor{}).
+		Where("token_id = ?", cursor.TokenID).
+		Updates(updates).
+		Error
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddTokenEventIsRecordedOnce(t *testing.T) {
+	connectToTestDb()
+
+	tokenId := uint64(time.Now().UnixNano())
+	event := entities.TokenEvent{TokenID: tokenId, Kind: entities.TokenEventListing, TxHash: "list", Timestamp: 20, FromAddress: "erd_seller"}
+	require.Nil(t, AddTokenEvent(&event))
+
+	again := event
+	again.ID = 0
+	require.Nil(t, AddTokenEvent(&again))
+	require.Nil(t, AddTokenEvent(&entities.TokenEvent{TokenID: tokenId, Kind: entities.TokenEventBid, TxHash: "bid", Timestamp: 10}))
+
+	events, err := GetTokenEventsByTokenId(tokenId)
+	require.Nil(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, entities.TokenEventBid, events[0].Kind)
+	require.Equal(t, entities.TokenEventListing, events[1].Kind)
+}
+
+func Test_UpdateTokenHistoryCursorKeepsNewRequests(t *testing.T) {
+	connectToTestDb()
+
+	tokenId := uint64(time.Now().UnixNano())
+	require.Nil(t, RequestTokenHistory(tokenId, 1))
+
+	cursor := getTokenHistoryCursor(t, tokenId)
+	require.True(t, cursor.Pending)
+
+	// requested again while the first page was read
+	require.Nil(t, RequestTokenHistory(tokenId, 2))
+	cursor.LastTimestamp = 30
+	cursor.LastTimestampTxs = 2
+	require.Nil(t, UpdateTokenHistoryCursor(&cursor, true))
+
+	cursor = getTokenHistoryCursor(t, tokenId)
+	require.True(t, cursor.Pending)
+	require.Equal(t, uint64(30), cursor.LastTimestamp)
+
+	require.Nil(t, UpdateTokenHistoryCursor(&cursor, true))
+	cursor = getTokenHistoryCursor(t, tokenId)
+	require.False(t, cursor.Pending)
+	require.Equal(t, uint64(2), cursor.LastTimestampTxs)
+}
+
+func getTokenHistoryCursor(t *testing.T, tokenId uint64) entities.TokenHistoryCursor {
+	var cursor entities.TokenHistoryCursor
+	txRead := GetDB().Where("token_id = ?", tokenId).First(&cursor)
+	require.Nil(t, txRead.Error)
+
+	return cursor
+}
@@ -11,6 +11,16 @@ import (
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 )
 
+const (
+	// explorerVisibleTokensQuery leaves out the tokens hidden or delisted by moderators, and the ones of such collections.
+	// It expects the collections to be joined.
+	explorerVisibleTokensQuery = "tokens.is_hidden = false AND tokens.is_delisted = false AND collections.is_hidden = false AND collections.is_delisted = false"
+	// searchableTokensQuery leaves out the hidden tokens and the ones of hidden collections.
+	searchableTokensQuery = "is_hidden = false AND collection_id NOT IN (SELECT id FROM collections WHERE is_hidden = true)"
+	// visibleTokensQuery is explorerVisibleTokensQuery for queries without the collections joined.
+	visibleTokensQuery = "is_hidden = false AND is_delisted = false AND collection_id NOT IN (SELECT id FROM collections WHERE is_hidden = true OR is_delisted = true)"
+)
+
 func AddToken(token *entities.Token) error {
 
 	database, err := GetDBOrError()
@@ -117,6 +127,27 @@ func UpdateToken(token *entities.Token) error {
 
 	return nil
 }
+
+// SetTokenModeration hides or delists a token, or brings it back.
+func SetTokenModeration(tokenId uint64, hidden bool, delisted bool) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txUpdate := database.Model(&entities.Token{}).
+		Where("id = ?", tokenId).
+		Updates(map[string]interface{}{"is_hidden": hidden, "is_delisted": delisted})
+	if txUpdate.Error != nil {
+		return txUpdate.Error
+	}
+	if txUpdate.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 func UpdateTokenWhere(token *entities.Token, toUpdate map[string]interface{}, where string, args ...interface{}) error {
 	database, err := GetDBOrError()
 	if err != nil {
@@ -330,7 +361,7 @@ func GetTokensByCollectionIdWithOffsetLimit(
 		return nil, err
 	}
 
-	txRead := database.Offset(offset).Limit(limit)
+	txRead := database.Offset(offset).Limit(limit).Where(visibleTokensQuery)
 	for k, v := range attributesFilters {
 
 		txRead.Where(fmt.Sprintf(`attributes @> '[{"trait_type":"%s","value":"%s"}]'`, k, v))
@@ -381,7 +412,7 @@ func GetListedTokensByCollectionIdWithOffsetLimit(collectionId uint64, offset in
 		return nil, err
 	}
 
-	txRead := database.Offset(offset).Limit(limit).Find(&tokens, "collection_id = ?", collectionId)
+	txRead := database.Offset(offset).Limit(limit).Where(visibleTokensQuery).Find(&tokens, "collection_id = ?", collectionId)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -423,6 +454,196 @@ func CountUniqueOwnersWithListedTokensByCollectionId(collectionId uint64) (uint6
 	return uint64(count), nil
 }
 
+func CountTokensByCollectionId(collectionId uint64) (uint64, error) {
+	count := int64(0)
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := database.Model(&entities.Token{}).Where("collection_id = ?", collectionId)
+	txRead.Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return uint64(count), nil
+}
+
+func CountUniqueOwnersByCollectionId(collectionId uint64) (uint64, error) {
+	count := int64(0)
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return 0, err
+	}
+
+	txRead := database.Model(&entities.Token{}).Where("collection_id = ?", collectionId)
+	txRead.Distinct("owner_id").Count(&count)
+	if txRead.Error != nil {
+		return 0, txRead.Error
+	}
+
+	return uint64(count), nil
+}
+
+// tokenAttributesQuery lists the trait values of every token of a collection, one row per token and trait.
+const tokenAttributesQuery = `
+	SELECT tokens.id AS token_id, tokens.status, tokens.price_nominal, attr.trait_type, attr.value
+	FROM tokens
+	CROSS JOIN LATERAL jsonb_array_elements(
+		CASE WHEN jsonb_typeof(tokens.attributes) = 'array' THEN tokens.attributes ELSE '[]'::jsonb END
+	) AS elem
+	CROSS JOIN LATERAL (
+		SELECT elem->>'trait_type' AS trait_type, (elem->'value')::text AS value
+		WHERE jsonb_typeof(elem) = 'object' AND jsonb_exists(elem, 'trait_type')
+		UNION ALL
+		SELECT kv.key AS trait_type, kv.value::text AS value
+		FROM jsonb_each(CASE WHEN jsonb_typeof(elem) = 'object' THEN elem ELSE '{}'::jsonb END) AS kv
+		WHERE NOT jsonb_exists(elem, 'trait_type')
+	) AS attr
+	WHERE tokens.collection_id = ?
+`
+
+// GetAttributeSuppliesByCollectionId counts the tokens of a collection per trait value,
+// without the listing and sale figures of GetAttributeAggregatesByCollectionId.
+func GetAttributeSuppliesByCollectionId(collectionId uint64) ([]entities.AttributeAggregate, error) {
+	var records []entities.AttributeAggregate
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+WITH attrs AS (` + tokenAttributesQuery + `)
+SELECT attrs.trait_type, attrs.value, COUNT(*) AS supply
+FROM attrs
+GROUP BY attrs.trait_type, attrs.value
+ORDER BY attrs.trait_type ASC, supply DESC, attrs.value ASC`
+
+	txRead := database.Raw(query, collectionId).Scan(&records)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+func GetMinListPriceForTokensWithCollectionId(collectionId uint64) (float64, error) {
+	var price float64
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return float64(0), err
+	}
+
+	nullFloat := sql.NullFloat64{}
+	txRead := database.Select("MIN(price_nominal)").
+		Where("status = ? AND collection_id = ?", entities.ListToken, collectionId).
+		Table("tokens").
+		Find(&nullFloat)
+
+	if txRead.Error != nil {
+		return float64(0), txRead.Error
+	}
+
+	if nullFloat.Valid {
+		price = nullFloat.Float64
+	}
+
+	return price, nil
+}
+
+func GetMinListPricePerCollection() ([]entities.CollectionFloorPricePerHour, error) {
+	var records []entities.CollectionFloorPricePerHour
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Table("tokens").
+		Select("tokens.collection_id as collection_id, MIN(tokens.price_nominal) as floor_price, COUNT(*) as listed_count").
+		Where("tokens.status = ?", entities.ListToken).
+		Group("tokens.collection_id").
+		Scan(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+func GetOwnersCountPerCollection() ([]entities.CollectionOwnersPerHour, error) {
+	var records []entities.CollectionOwnersPerHour
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Table("tokens").
+		Select("tokens.collection_id as collection_id, COUNT(DISTINCT tokens.owner_id) as owners_total").
+		Group("tokens.collection_id").
+		Scan(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+// GetAttributeAggregatesByCollectionId groups every token of a collection by its trait values.
+// Attributes are expected as an array of {"trait_type", "value"} objects; array items without
+// a trait_type are read as plain key/value pairs. Volume only counts sales after volumeSince.
+func GetAttributeAggregatesByCollectionId(collectionId uint64, volumeSince uint64) ([]entities.AttributeAggregate, error) {
+	var records []entities.AttributeAggregate
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+WITH attrs AS (` + tokenAttributesQuery + `),
+sales AS (
+	SELECT attrs.trait_type, attrs.value,
+		(array_agg(transactions.price_nominal ORDER BY transactions.timestamp DESC))[1] AS last_sale_price,
+		COALESCE(SUM(transactions.price_nominal) FILTER (WHERE transactions.timestamp >= ?), 0) AS volume
+	FROM attrs
+	INNER JOIN transactions ON transactions.token_id = attrs.token_id AND transactions.type = ?
+	GROUP BY attrs.trait_type, attrs.value
+)
+SELECT attrs.trait_type, attrs.value,
+	COUNT(*) AS supply,
+	COUNT(*) FILTER (WHERE attrs.status IN (?, ?)) AS listed,
+	COALESCE(MIN(attrs.price_nominal) FILTER (WHERE attrs.status = ?), 0) AS floor_price,
+	COALESCE(MAX(sales.last_sale_price), 0) AS last_sale_price,
+	COALESCE(MAX(sales.volume), 0) AS volume
+FROM attrs
+LEFT JOIN sales ON sales.trait_type = attrs.trait_type AND sales.value = attrs.value
+GROUP BY attrs.trait_type, attrs.value
+ORDER BY attrs.trait_type ASC, supply DESC, attrs.value ASC`
+
+	txRead := database.Raw(query,
+		collectionId,
+		volumeSince,
+		entities.BuyToken,
+		entities.ListToken,
+		entities.AuctionToken,
+		entities.ListToken,
+	).Scan(&records)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
 func GetTokensWithOffsetLimit(
 	offset int,
 	limit int,
@@ -435,7 +656,7 @@ func GetTokensWithOffsetLimit(
 		return nil, err
 	}
 
-	txRead := database.Offset(offset).Limit(limit)
+	txRead := database.Offset(offset).Limit(limit).Where(visibleTokensQuery)
 	if len(sortRules) == 2 {
 		query := fmt.Sprintf("%s %s", sortRules["criteria"], sortRules["mode"])
 		txRead.Order(query)
@@ -457,7 +678,7 @@ func GetTokensWithTokenIdAlikeWithLimit(tokenId string, limit int) ([]entities.T
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Find(&tokens)
+	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Where(searchableTokensQuery).Find(&tokens)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -473,7 +694,7 @@ func GetTokensListedWithTokenIdAlikeWithLimit(tokenId string, limit int) ([]enti
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Where("status is not NULL and status != '' and status != 'None'").Find(&tokens)
+	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Where("status is not NULL and status != '' and status != 'None'").Where(searchableTokensQuery).Find(&tokens)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -489,7 +710,7 @@ func GetTokensUnlistedWithTokenIdAlikeWithLimit(tokenId string, limit int) ([]en
 		return nil, err
 	}
 
-	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Where("status is NULL or status = '' or status = 'None'").Find(&tokens)
+	txRead := database.Limit(limit).Where("token_id ILIKE ?", tokenId).Where("status is NULL or status = '' or status = 'None'").Where(searchableTokensQuery).Find(&tokens)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -616,6 +837,7 @@ func GetAllTokens(lastTimestamp int64, currentPage, requestedPage, pageSize int,
 		Joins("inner join collections on collections.id=tokens.collection_id ").
 		Preload("Collection").
 		Order(order).
+		Where(explorerVisibleTokensQuery).
 		Where(query, filter.Values...).
 		Where(collectionFilter.Query, collectionFilter.Values...)
 
@@ -658,6 +880,7 @@ func GetTokensCountWithCriteria(filter *entities.QueryFilter, collectionFilter *
 	//if isVerified {
 	txRead = database.Table("tokens").
 		Joins("inner join collections on tokens.collection_id=collections.id").
+		Where(explorerVisibleTokensQuery).
 		Where(filter.Query, filter.Values...).
 		Where(collectionFilter.Query, collectionFilter.Values...)
 
@@ -700,6 +923,7 @@ func GetTokensPriceBoundary(filter *entities.QueryFilter, collectionFilter *enti
 	txRead := database.Table("tokens").
 		Joins("inner join collections on tokens.collection_id=collections.id").
 		Select("min(tokens.price_nominal) as min, max(tokens.price_nominal) as max").
+		Where(explorerVisibleTokensQuery).
 		Where(filter.Query, filter.Values...).
 		Where(collectionFilter.Query, collectionFilter.Values...)
 
@@ -268,6 +268,65 @@ func Test_GetAllTokens(t *testing.T) {
 
 }
 
+func Test_GetAttributeAggregatesByCollectionId(t *testing.T) {
+	connectToTestDb()
+
+	collection := defaultCollection()
+	err := AddCollection(&collection)
+	require.Nil(t, err)
+
+	listed := defaultToken()
+	listed.TokenID = "attr_token_" + strconv.Itoa(int(time.Now().UnixNano()))
+	listed.CollectionID = collection.ID
+	listed.PriceNominal = 5
+	listed.Attributes = datatypes.JSON(`[{"trait_type": "hair", "value": "red"}, {"trait_type": "level", "value": 2}]`)
+	err = AddToken(&listed)
+	require.Nil(t, err)
+
+	unlisted := defaultToken()
+	unlisted.TokenID = listed.TokenID + "_b"
+	unlisted.CollectionID = collection.ID
+	unlisted.Status = entities.None
+	unlisted.Attributes = datatypes.JSON(`[{"trait_type": "hair", "value": "red"}]`)
+	err = AddToken(&unlisted)
+	require.Nil(t, err)
+
+	sale := defaultTransaction()
+	sale.Hash = listed.TokenID
+	sale.Type = entities.BuyToken
+	sale.TokenID = unlisted.ID
+	sale.CollectionID = collection.ID
+	sale.PriceNominal = 3
+	sale.Timestamp = uint64(time.Now().Unix())
+	err = AddTransaction(&sale)
+	require.Nil(t, err)
+
+	records, err := GetAttributeAggregatesByCollectionId(collection.ID, uint64(time.Now().Add(-time.Hour).Unix()))
+	require.Nil(t, err)
+	require.Equal(t, []entities.AttributeAggregate{{
+		TraitType:     "hair",
+		Value:         `"red"`,
+		Supply:        2,
+		Listed:        1,
+		FloorPrice:    5,
+		LastSalePrice: 3,
+		Volume:        3,
+	}, {
+		TraitType:  "level",
+		Value:      "2",
+		Supply:     1,
+		Listed:     1,
+		FloorPrice: 5,
+	}}, records)
+
+	supplies, err := GetAttributeSuppliesByCollectionId(collection.ID)
+	require.Nil(t, err)
+	require.Equal(t, []entities.AttributeAggregate{
+		{TraitType: "hair", Value: `"red"`, Supply: 2},
+		{TraitType: "level", Value: "2", Supply: 1},
+	}, supplies)
+}
+
 func defaultToken() entities.Token {
 	return entities.Token{
 		TokenID:      "my_token",
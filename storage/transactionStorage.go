@@ -152,6 +152,23 @@ func GetTransactionsByTokenId(id uint64) ([]entities.Transaction, error) {
 	return transactions, nil
 }
 
+// GetTransactionsWithAccountsByTokenId returns every transaction of the token with its seller and buyer, oldest first.
+func GetTransactionsWithAccountsByTokenId(id uint64) ([]entities.Transaction, error) {
+	var transactions []entities.Transaction
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Preload("Seller").Preload("Buyer").Order("timestamp asc").Find(&transactions, "token_id = ?", id)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return transactions, nil
+}
+
 func GetTransactionsByTokenIdWithOffsetLimit(id uint64, offset int, limit int) ([]entities.Transaction, error) {
 	var transactions []entities.Transaction
 
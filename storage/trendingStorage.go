@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+// GetDecayedTransactionActivity weighs every sale and listing made in [since, now] by 0.5^(age/halfLife).
+// Unique buyers count each buyer once per collection, with the weight of their latest purchase.
+func GetDecayedTransactionActivity(since uint64, now uint64, halfLifeSeconds float64) ([]entities.CollectionTrendingActivity, error) {
+	var records []entities.CollectionTrendingActivity
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Raw(`
+		WITH weighted AS (
+			SELECT collection_id, type, buyer_id, POWER(0.5, (? - timestamp) / ?::float) AS weight
+			FROM transactions
+			WHERE timestamp >= ? AND timestamp <= ? AND type IN (?, ?)
+		), buyers AS (
+			SELECT collection_id, SUM(weight) AS unique_buyers
+			FROM (
+				SELECT collection_id, buyer_id, MAX(weight) AS weight
+				FROM weighted
+				WHERE type = ?
+				GROUP BY collection_id, buyer_id
+			) latest_purchases
+			GROUP BY collection_id
+		)
+		SELECT weighted.collection_id AS collection_id,
+			COALESCE(SUM(weighted.weight) FILTER (WHERE weighted.type = ?), 0) AS sales,
+			COALESCE(SUM(weighted.weight) FILTER (WHERE weighted.type = ?), 0) AS listings,
+			COALESCE(MAX(buyers.unique_buyers), 0) AS unique_buyers
+		FROM weighted
+		LEFT JOIN buyers ON buyers.collection_id = weighted.collection_id
+		GROUP BY weighted.collection_id`,
+		now, halfLifeSeconds, since, now, entities.BuyToken, entities.ListToken,
+		entities.BuyToken,
+		entities.BuyToken, entities.ListToken).
+		Scan(&records)
+
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return records, nil
+}
+
+// ReplaceCollectionTrendingScores swaps the whole score table, so collections that went quiet drop out.
+func ReplaceCollectionTrendingScores(scores []entities.CollectionTrendingScore) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		txDelete := tx.Where("1 = 1").Delete(&entities.CollectionTrendingScore{})
+		if txDelete.Error != nil {
+			return txDelete.Error
+		}
+
+		if len(scores) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(&scores, 100).Error
+	})
+}
+
+func GetCollectionTrendingScores() ([]entities.CollectionTrendingScore, error) {
+	var scores []entities.CollectionTrendingScore
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Order("score desc").Find(&scores)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return scores, nil
+}
+
+func AddOrUpdateCollectionTrendingOverride(override *entities.CollectionTrendingOverride) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	recordCount := int64(0)
+	err = database.Model(&entities.CollectionTrendingOverride{}).
+		Where("collection_id=?", override.CollectionId).
+		Count(&recordCount).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if recordCount == 0 {
+		txCreate := database.Create(&override)
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+		if txCreate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	} else {
+		txUpdate := database.Model(override).
+			Where("collection_id=?", override.CollectionId).
+			Update("mode", override.Mode)
+		if txUpdate.Error != nil {
+			return txUpdate.Error
+		}
+		if txUpdate.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+func DeleteCollectionTrendingOverride(collectionId uint64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	txDelete := database.Where("collection_id=?", collectionId).Delete(&entities.CollectionTrendingOverride{})
+	if txDelete.Error != nil {
+		return txDelete.Error
+	}
+
+	return nil
+}
+
+func GetCollectionTrendingOverrides() ([]entities.CollectionTrendingOverride, error) {
+	var overrides []entities.CollectionTrendingOverride
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&overrides)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"gorm.io/gorm"
+)
+
+// AddWhitelistMerkleSnapshot stores the root with the next version of its collection, together with its leaves.
+func AddWhitelistMerkleSnapshot(root *entities.WhitelistMerkleRoot, leaves []entities.WhitelistMerkleLeaf) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		var lastVersion uint64
+		txRead := tx.Model(&entities.WhitelistMerkleRoot{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("collection_id = ?", root.CollectionID).
+			Scan(&lastVersion)
+		if txRead.Error != nil {
+			return txRead.Error
+		}
+
+		root.Version = lastVersion + 1
+		txCreate := tx.Create(root)
+		if txCreate.Error != nil {
+			return txCreate.Error
+		}
+
+		for index := range leaves {
+			leaves[index].RootID = root.ID
+		}
+
+		if len(leaves) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(leaves, 500).Error
+	})
+}
+
+func GetLatestWhitelistMerkleRoot(collectionID uint64) (*entities.WhitelistMerkleRoot, error) {
+	var root entities.WhitelistMerkleRoot
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("collection_id = ?", collectionID).Order("version desc").Limit(1).Find(&root)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &root, nil
+}
+
+func GetWhitelistMerkleRoot(collectionID uint64, version uint64) (*entities.WhitelistMerkleRoot, error) {
+	var root entities.WhitelistMerkleRoot
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Find(&root, "collection_id = ? AND version = ?", collectionID, version)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+	if txRead.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &root, nil
+}
+
+func GetWhitelistMerkleLeaves(rootID uint64) ([]entities.WhitelistMerkleLeaf, error) {
+	var leaves []entities.WhitelistMerkleLeaf
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Where("root_id = ?", rootID).Order("position asc").Find(&leaves)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return leaves, nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/ENFT-DAO/youbei-api/data/entities"
 )
@@ -92,7 +93,43 @@ func GetWhitelistsByCollectionID(collectionID uint64) ([]entities.Whitelist, err
 		return nil, err
 	}
 
-	txRead := database.Find(&whitelists, "collectionID = ?", collectionID)
+	txRead := database.Order("address asc, type asc").Find(&whitelists, "collection_id = ?", collectionID)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return whitelists, nil
+}
+
+// GetWhitelistsByCollectionIDAndTypesAfter pages through the allocations of the given types by id,
+// so entries deleted from a page don't shift the next one.
+func GetWhitelistsByCollectionIDAndTypesAfter(collectionID uint64, types []uint64, afterID uint64, limit int) ([]entities.Whitelist, error) {
+	var whitelists []entities.Whitelist
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Order("id asc").
+		Limit(limit).
+		Find(&whitelists, "collection_id = ? AND type IN ? AND id > ?", collectionID, types, afterID)
+	if txRead.Error != nil {
+		return nil, txRead.Error
+	}
+
+	return whitelists, nil
+}
+
+func GetWhitelistsByAddressAndCollectionID(address string, collectionID uint64) ([]entities.Whitelist, error) {
+	var whitelists []entities.Whitelist
+
+	database, err := GetDBOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	txRead := database.Order("type asc").Find(&whitelists, "address = ? AND collection_id = ?", address, collectionID)
 	if txRead.Error != nil {
 		return nil, txRead.Error
 	}
@@ -118,13 +155,54 @@ func UpdateWhitelist(whitelist *entities.Whitelist) error {
 	return nil
 }
 
-func UpdateWhitelistAmountByAddress(amount uint64, address string) error {
+// AddOrUpdateWhitelists stores the allocations of a collection in one transaction.
+// An entry is identified by its collection, address and type, only its amount gets updated.
+func AddOrUpdateWhitelists(whitelists []entities.Whitelist) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		for index := range whitelists {
+			txUpsert := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "collection_id"}, {Name: "address"}, {Name: "type"}},
+				DoUpdates: clause.AssignmentColumns([]string{"amount", "modified_at"}),
+			}).Create(&whitelists[index])
+			if txUpsert.Error != nil {
+				return txUpsert.Error
+			}
+		}
+
+		return nil
+	})
+}
+
+func UpdateWhitelistAmount(collectionID uint64, address string, whitelistType uint64, amount uint64) error {
+	database, err := GetDBOrError()
+	if err != nil {
+		return err
+	}
+
+	tx := database.Model(&entities.Whitelist{}).
+		Where("collection_id = ? AND address = ? AND type = ?", collectionID, address, whitelistType).
+		Update("amount", amount)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func DeleteWhitelist(collectionID uint64, address string, whitelistType uint64) error {
 	database, err := GetDBOrError()
 	if err != nil {
 		return err
 	}
 
-	tx := database.Table("whitelists").Where("address = ?", address).Update("amount", amount)
+	tx := database.Where("collection_id = ? AND address = ? AND type = ?", collectionID, address, whitelistType).Delete(&entities.Whitelist{})
 	if tx.Error != nil {
 		return tx.Error
 	}
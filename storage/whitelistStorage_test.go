@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ENFT-DAO/youbei-api/data/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddOrUpdateWhitelists(t *testing.T) {
+	connectToTestDb()
+
+	whitelists := []entities.Whitelist{
+		{CollectionID: 11, Address: "erd_whitelisted", Amount: 2, Type: entities.Whitelist_type_buy},
+		{CollectionID: 11, Address: "erd_whitelisted", Amount: 1, Type: entities.Whitelist_type_mint},
+		{CollectionID: 12, Address: "erd_whitelisted", Amount: 5, Type: entities.Whitelist_type_buy},
+	}
+	err := AddOrUpdateWhitelists(whitelists)
+	require.Nil(t, err)
+
+	update := []entities.Whitelist{
+		{CollectionID: 11, Address: "erd_whitelisted", Amount: 4, Type: entities.Whitelist_type_buy},
+	}
+	err = AddOrUpdateWhitelists(update)
+	require.Nil(t, err)
+	require.Equal(t, whitelists[0].ID, update[0].ID)
+
+	err = UpdateWhitelistAmount(11, "erd_whitelisted", entities.Whitelist_type_mint, 0)
+	require.Nil(t, err)
+
+	entries, err := GetWhitelistsByAddressAndCollectionID("erd_whitelisted", 11)
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(4), entries[0].Amount)
+	require.Equal(t, uint64(0), entries[1].Amount)
+
+	other, err := GetWhitelistsByAddressAndCollectionID("erd_whitelisted", 12)
+	require.Nil(t, err)
+	require.Equal(t, uint64(5), other[0].Amount)
+
+	err = DeleteWhitelist(12, "erd_whitelisted", entities.Whitelist_type_buy)
+	require.Nil(t, err)
+}
+
+func Test_GetWhitelistsByCollectionIDAndTypesAfter(t *testing.T) {
+	connectToTestDb()
+
+	whitelists := []entities.Whitelist{
+		{CollectionID: 13, Address: "erd_first", Amount: 1, Type: entities.Whitelist_type_buy},
+		{CollectionID: 13, Address: "erd_second", Amount: 1, Type: entities.Whitelist_type_mint},
+		{CollectionID: 13, Address: "erd_third", Amount: 1, Type: entities.Whitelist_type_buy_mint},
+	}
+	err := AddOrUpdateWhitelists(whitelists)
+	require.Nil(t, err)
+
+	buyTypes := []uint64{entities.Whitelist_type_buy, entities.Whitelist_type_buy_mint}
+	page, err := GetWhitelistsByCollectionIDAndTypesAfter(13, buyTypes, 0, 1)
+	require.Nil(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "erd_first", page[0].Address)
+
+	page, err = GetWhitelistsByCollectionIDAndTypesAfter(13, buyTypes, page[0].ID, 10)
+	require.Nil(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "erd_third", page[0].Address)
+}
+
+func Test_AddWhitelistMerkleSnapshot(t *testing.T) {
+	connectToTestDb()
+
+	first := entities.WhitelistMerkleRoot{CollectionID: 21, Root: "aa", LeavesCount: 1}
+	err := AddWhitelistMerkleSnapshot(&first, []entities.WhitelistMerkleLeaf{{Position: 0, Address: "erd_first", Amount: 1}})
+	require.Nil(t, err)
+
+	second := entities.WhitelistMerkleRoot{CollectionID: 21, Root: "bb", LeavesCount: 2}
+	err = AddWhitelistMerkleSnapshot(&second, []entities.WhitelistMerkleLeaf{
+		{Position: 0, Address: "erd_first", Amount: 1},
+		{Position: 1, Address: "erd_second", Amount: 3},
+	})
+	require.Nil(t, err)
+	require.Equal(t, first.Version+1, second.Version)
+
+	latest, err := GetLatestWhitelistMerkleRoot(21)
+	require.Nil(t, err)
+	require.Equal(t, "bb", latest.Root)
+
+	leaves, err := GetWhitelistMerkleLeaves(second.ID)
+	require.Nil(t, err)
+	require.Len(t, leaves, 2)
+	require.Equal(t, "erd_second", leaves[1].Address)
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// JsonSchema is the subset of JSON Schema the API validates documents against:
+// type, enum, required, properties, additionalProperties, items and the usual length and range keywords.
+type JsonSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+func ParseJsonSchema(raw []byte) (*JsonSchema, error) {
+	var schema JsonSchema
+	err := json.Unmarshal(raw, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	err = schema.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+func (schema *JsonSchema) compile() error {
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return err
+		}
+		schema.pattern = pattern
+	}
+
+	for _, property := range schema.Properties {
+		err := property.compile()
+		if err != nil {
+			return err
+		}
+	}
+	if schema.Items != nil {
+		return schema.Items.compile()
+	}
+
+	return nil
+}
+
+// Validate decodes the json document and checks it against the schema.
+// The returned error names the path of the first offending value.
+func (schema *JsonSchema) Validate(document []byte) error {
+	var value interface{}
+	err := json.Unmarshal(document, &value)
+	if err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+
+	return schema.validateValue("$", value)
+}
+
+func (schema *JsonSchema) validateValue(path string, value interface{}) error {
+	if schema.Type != "" && !matchesJsonType(schema.Type, value) {
+		return fmt.Errorf("%s: expected %s", path, schema.Type)
+	}
+
+	if len(schema.Enum) > 0 && !containsJsonValue(schema.Enum, value) {
+		return fmt.Errorf("%s: value is not allowed", path)
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return fmt.Errorf("%s: shorter than %d characters", path, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return fmt.Errorf("%s: longer than %d characters", path, *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			return fmt.Errorf("%s: does not match %s", path, schema.Pattern)
+		}
+	case float64:
+		if schema.Minimum != nil && typed < *schema.Minimum {
+			return fmt.Errorf("%s: lower than %v", path, *schema.Minimum)
+		}
+		if schema.Maximum != nil && typed > *schema.Maximum {
+			return fmt.Errorf("%s: greater than %v", path, *schema.Maximum)
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			return fmt.Errorf("%s: fewer than %d items", path, *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(typed) > *schema.MaxItems {
+			return fmt.Errorf("%s: more than %d items", path, *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for index, item := range typed {
+				err := schema.Items.validateValue(fmt.Sprintf("%s[%d]", path, index), item)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := typed[name]; !ok {
+				return fmt.Errorf("%s.%s: is required", path, name)
+			}
+		}
+
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			property, ok := schema.Properties[key]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s.%s: unknown property", path, key)
+				}
+				continue
+			}
+
+			err := property.validateValue(path+"."+key, typed[key])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchesJsonType(expected string, value interface{}) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+
+	return false
+}
+
+func containsJsonValue(values []interface{}, value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+
+	for _, allowed := range values {
+		if allowed == value {
+			return true
+		}
+	}
+
+	return false
+}
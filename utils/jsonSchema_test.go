@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 5},
+		"ticker": {"type": "string", "pattern": "^[A-Z]+$"},
+		"royalties": {"type": "number", "minimum": 0, "maximum": 100},
+		"supply": {"type": "integer"},
+		"kind": {"enum": ["art", "music"]},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+	}
+}`
+
+func TestJsonSchema_Validate(t *testing.T) {
+	schema, err := ParseJsonSchema([]byte(testSchema))
+	require.Nil(t, err)
+
+	require.Nil(t, schema.Validate([]byte(`{"name": "ape", "ticker": "APE", "royalties": 2.5, "supply": 10, "kind": "art", "tags": ["a"]}`)))
+
+	invalid := map[string]string{
+		`[]`:                                       "$: expected object",
+		`{}`:                                       "$.name: is required",
+		`{"name": ""}`:                             "$.name: shorter than 1 characters",
+		`{"name": "apes ape"}`:                     "$.name: longer than 5 characters",
+		`{"name": "ape", "ticker": "ape"}`:         "$.ticker: does not match ^[A-Z]+$",
+		`{"name": "ape", "royalties": 101}`:        "$.royalties: greater than 100",
+		`{"name": "ape", "supply": 1.5}`:           "$.supply: expected integer",
+		`{"name": "ape", "kind": "video"}`:         "$.kind: value is not allowed",
+		`{"name": "ape", "tags": [1]}`:             "$.tags[0]: expected string",
+		`{"name": "ape", "tags": ["a", "b", "c"]}`: "$.tags: more than 2 items",
+		`{"name": "ape", "owner": "erd"}`:          "$.owner: unknown property",
+	}
+	for document, expected := range invalid {
+		err = schema.Validate([]byte(document))
+		require.NotNil(t, err, document)
+		require.Equal(t, expected, err.Error())
+	}
+
+	err = schema.Validate([]byte(`{"name":`))
+	require.NotNil(t, err)
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 func IndexInArray(arr []string, item string) int {
 	for index, it := range arr {
@@ -10,3 +13,26 @@ func IndexInArray(arr []string, item string) int {
 	}
 	return -1
 }
+
+// HourKey returns the YYYYMMDDHH integer used to key the hourly aggregated tables.
+func HourKey(t time.Time) int64 {
+	t = t.UTC()
+	return int64(t.Year())*1_000_000 + int64(t.Month())*10_000 + int64(t.Day())*100 + int64(t.Hour())
+}
+
+// DayKey returns the YYYYMMDD integer of the UTC day.
+func DayKey(t time.Time) int64 {
+	return HourKey(t) / 100
+}
+
+// WeekKey returns the DayKey of the monday starting the ISO week.
+func WeekKey(t time.Time) int64 {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return DayKey(t.AddDate(0, 0, -offset))
+}
+
+// MonthKey returns the YYYYMM integer of the UTC month.
+func MonthKey(t time.Time) int64 {
+	return HourKey(t) / 10_000
+}
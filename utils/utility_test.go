@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHourKey(t *testing.T) {
+	ts := time.Date(2022, time.March, 7, 9, 45, 0, 0, time.UTC)
+	require.Equal(t, int64(2022030709), HourKey(ts))
+
+	local := ts.In(time.FixedZone("UTC+3", 3*60*60))
+	require.Equal(t, int64(2022030709), HourKey(local))
+}
+
+func TestPeriodKeys(t *testing.T) {
+	// a sunday, the week started on monday the 28th of february
+	ts := time.Date(2022, time.March, 6, 23, 10, 0, 0, time.UTC)
+	require.Equal(t, int64(20220306), DayKey(ts))
+	require.Equal(t, int64(20220228), WeekKey(ts))
+	require.Equal(t, int64(202203), MonthKey(ts))
+
+	monday := time.Date(2022, time.March, 7, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, int64(20220307), WeekKey(monday))
+}